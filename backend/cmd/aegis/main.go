@@ -2,7 +2,8 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -10,94 +11,335 @@ import (
 	"syscall"
 	"time"
 
-	_ "github.com/lib/pq"
+	"golang.org/x/crypto/acme/autocert"
 
+	"github.com/backyonatan-alt/aegis/backend/internal/archive"
 	"github.com/backyonatan-alt/aegis/backend/internal/cache"
+	"github.com/backyonatan-alt/aegis/backend/internal/calendar"
 	"github.com/backyonatan-alt/aegis/backend/internal/config"
 	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/logtail"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/notify"
 	"github.com/backyonatan-alt/aegis/backend/internal/pipeline"
+	"github.com/backyonatan-alt/aegis/backend/internal/pulse"
+	"github.com/backyonatan-alt/aegis/backend/internal/report"
 	"github.com/backyonatan-alt/aegis/backend/internal/scheduler"
 	"github.com/backyonatan-alt/aegis/backend/internal/server"
 	"github.com/backyonatan-alt/aegis/backend/internal/store"
+	"github.com/backyonatan-alt/aegis/backend/internal/supervisor"
+	"github.com/backyonatan-alt/aegis/backend/internal/tenant"
+	"github.com/backyonatan-alt/aegis/backend/internal/webhook"
 )
 
 func main() {
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
 
-	cfg, err := config.Load()
+	cfg, err := config.Load(fetcher.ConfigSignals())
 	if err != nil {
 		slog.Error("failed to load config", "error", err)
 		os.Exit(1)
 	}
 
-	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	// Recent records are also kept in memory for the admin log stream
+	logs := logtail.New(cfg.LogTailSize)
+	slog.SetDefault(slog.New(logs.Handler(slog.Default().Handler())))
+
+	db, closeDB, err := openStore(cfg)
 	if err != nil {
 		slog.Error("failed to open database", "error", err)
 		os.Exit(1)
 	}
-	defer db.Close()
+	defer closeDB()
+
+	if err := db.MigrateAll(context.Background()); err != nil {
+		slog.Error("failed to run migrations", "error", err)
+		os.Exit(1)
+	}
 
-	db.SetMaxOpenConns(5)
-	db.SetMaxIdleConns(2)
-	db.SetConnMaxLifetime(30 * time.Minute)
+	st := store.NewInstrumented(db, cfg.DBPool.SlowQuery)
+	c := cache.New()
 
+	done := make(chan os.Signal, 1)
+	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
+	// SIGHUP, or the admin reload route, restarts the app with its
+	// configuration read afresh
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	for initial := true; ; initial = false {
+		if !run(withOverrides(st, cfg), st, c, logs, initial, done, reload) {
+			break
+		}
+		slog.Info("reloading configuration")
+	}
+	slog.Info("shutdown complete")
+}
+
+// database is a store that can create its own tables.
+type database interface {
+	store.Store
+	MigrateAll(ctx context.Context) error
+}
+
+// openStore connects to the database DATABASE_URL names: a sqlite:// file
+// for local development, otherwise a Postgres pool. The returned func
+// closes it.
+func openStore(cfg *config.Config) (database, func(), error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	if err := db.PingContext(ctx); err != nil {
-		slog.Error("failed to ping database", "error", err)
-		os.Exit(1)
+	defer cancel()
+
+	if store.IsSQLiteURL(cfg.DatabaseURL) {
+		lite, err := store.OpenSQLite(ctx, cfg.DatabaseURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		slog.Info("sqlite database ready", "url", cfg.DatabaseURL)
+		return lite, func() { lite.Close() }, nil
 	}
-	cancel()
 
-	pgStore := store.NewPostgres(db)
-	if err := pgStore.Migrate(context.Background()); err != nil {
-		slog.Error("failed to run migrations", "error", err)
-		os.Exit(1)
+	pool, err := store.NewPool(ctx, cfg.DatabaseURL, cfg.DBPool)
+	if err != nil {
+		return nil, nil, err
 	}
-	if err := pgStore.MigrateRadarIdeas(context.Background()); err != nil {
-		slog.Error("failed to run radar ideas migration", "error", err)
-		os.Exit(1)
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, nil, fmt.Errorf("ping database: %w", err)
 	}
+	slog.Info("database pool ready", "max_conns", cfg.DBPool.MaxConns, "statement_timeout", cfg.DBPool.StatementTimeout)
+	return store.NewPostgres(pool, cfg.DBPool.ScanTimeout), pool.Close, nil
+}
 
-	c := cache.New()
+// cachedRisk reads total risk from the cached snapshot.
+func cachedRisk(c *cache.Cache) (int, bool) {
+	data := c.Get()
+	if data == nil {
+		return 0, false
+	}
+	var snap model.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return 0, false
+	}
+	return snap.TotalRisk.Risk, true
+}
+
+// withOverrides layers the stored config overrides over the environment.
+// If they can't be read or don't make a valid configuration, base is used
+// so a bad row can't keep the app from starting.
+func withOverrides(st store.Store, base *config.Config) *config.Config {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	overrides, err := st.ConfigOverrides(ctx)
+	if err != nil {
+		slog.Warn("failed to load config overrides, using environment", "error", err)
+		return base
+	}
+	if len(overrides) == 0 {
+		return base
+	}
+	values := make(map[string]string, len(overrides))
+	keys := make([]string, 0, len(overrides))
+	for _, o := range overrides {
+		if err := config.CheckOverrideKey(fetcher.ConfigSignals(), o.Key); err != nil {
+			slog.Warn("ignoring config override", "key", o.Key, "error", err)
+			continue
+		}
+		values[o.Key] = o.Value
+		keys = append(keys, o.Key)
+	}
+	cfg, err := config.LoadWithOverrides(fetcher.ConfigSignals(), values)
+	if err != nil {
+		slog.Error("config overrides are invalid, using environment", "error", err)
+		return base
+	}
+	slog.Info("applied config overrides", "keys", keys)
+	return cfg
+}
+
+// run starts every component with cfg and serves until done or reload
+// fires, then shuts them down. It reports whether to reload. The initial
+// pipeline run is skipped on reloads, which keep serving the cached
+// snapshot.
+func run(cfg *config.Config, st store.Store, c *cache.Cache, logs *logtail.Buffer, initial bool, done, reload chan os.Signal) bool {
 	f := fetcher.New(cfg)
-	p := pipeline.New(pgStore, c, f)
+	if raw, err := st.LatestKeywords(context.Background()); err != nil {
+		slog.Warn("failed to load news keywords, using defaults", "error", err)
+	} else if raw != nil {
+		// Lists missing from older stored sets keep their defaults
+		kw := fetcher.DefaultKeywords()
+		if err := json.Unmarshal(raw, &kw); err != nil {
+			slog.Warn("failed to parse stored news keywords, using defaults", "error", err)
+		} else {
+			f.SetKeywords(kw)
+			slog.Info("applied stored news keywords", "alert", len(kw.Alert), "iran", len(kw.Iran), "negative", len(kw.Negative), "deescalation", len(kw.Deescalation))
+		}
+	}
+	if raw, err := st.LatestMarketRules(context.Background()); err != nil {
+		slog.Warn("failed to load polymarket rules, using defaults", "error", err)
+	} else if raw != nil {
+		var rules fetcher.MarketRules
+		if err := json.Unmarshal(raw, &rules); err != nil {
+			slog.Warn("failed to parse stored polymarket rules, using defaults", "error", err)
+		} else {
+			f.SetMarketRules(rules)
+			slog.Info("applied stored polymarket rules", "query", rules.Query, "topic", len(rules.Topic), "strike", len(rules.Strike))
+		}
+	}
+	p := pipeline.New(cfg, st, c, f)
+	if cfg.SensitiveDatesFile != "" {
+		cal, err := calendar.Load(cfg.SensitiveDatesFile)
+		if err != nil {
+			slog.Error("failed to load sensitive dates", "error", err)
+			os.Exit(1)
+		}
+		p.SetCalendar(cal)
+	}
+	// Threshold crossings, compared against the last stored snapshot so a
+	// restart doesn't miss or repeat one
+	prev, err := st.LatestSnapshot(context.Background())
+	if err != nil {
+		slog.Warn("failed to load latest snapshot for webhooks", "error", err)
+	}
+	hooks := webhook.NewDispatcher(st, prev)
+	p.AddNotifier(hooks)
+	var telegram *notify.Telegram
+	if cfg.Telegram.Enabled() {
+		telegram = notify.NewTelegram(cfg.Telegram, cfg.PublicURL, prev)
+		p.AddNotifier(telegram)
+	}
 
 	// Run pipeline once immediately on startup
-	slog.Info("running initial pipeline")
-	if err := p.Run(context.Background()); err != nil {
-		slog.Error("initial pipeline run failed", "error", err)
-		// Non-fatal: try to serve from DB cache
+	if initial {
+		slog.Info("running initial pipeline")
+		if err := p.Run(context.Background()); err != nil {
+			slog.Error("initial pipeline run failed", "error", err)
+			// Non-fatal: try to serve from DB cache
+		}
 	}
 
-	// Start scheduler
-	sched := scheduler.New(p, 30*time.Minute)
-	go sched.Start(context.Background())
+	// Background jobs, restarted by the supervisor if they crash
+	jobs := supervisor.New()
+	// Runs come closer together while total risk is high
+	jobs.Add("scheduler", scheduler.New(p, cfg.RunInterval,
+		scheduler.WithIntervals(cfg.Schedule.MinInterval, cfg.RunInterval),
+		scheduler.WithScore(cfg.Schedule.RiskThreshold, func() (int, bool) { return cachedRisk(c) }),
+	))
+	// Nightly model diagnostics
+	jobs.Add("model reporter", report.New(st, cfg))
+	// Daily public dataset, when an archive bucket is configured
+	if cfg.Archive.Enabled() {
+		jobs.Add("archive publisher", archive.New(cfg.Archive, st))
+	}
+	// Static copy of every snapshot for the frontend to fall back on
+	if cfg.Mirror.Enabled() {
+		mirror := archive.NewMirror(cfg.Mirror, cfg.Archive)
+		p.AddNotifier(mirror)
+		jobs.Add("snapshot mirror", mirror)
+	}
+	// API consumer usage accounting
+	tenants := tenant.New(st)
+	jobs.Add("tenant usage", tenants)
+	jobs.Add("webhooks", hooks)
+	// Band changes posted to a Telegram chat
+	if telegram != nil {
+		jobs.Add("telegram", telegram)
+	}
 
-	srv := server.New(cfg, c, pgStore)
-	httpServer := &http.Server{
-		Addr:         ":" + cfg.Port,
-		Handler:      srv.Router(),
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  60 * time.Second,
+	srv := server.New(cfg, c, st, p, f, tenants)
+	if raw, err := st.LatestPulseBaselines(context.Background()); err != nil {
+		slog.Warn("failed to load pulse baselines, using defaults", "error", err)
+	} else if raw != nil {
+		var b pulse.Baselines
+		if err := json.Unmarshal(raw, &b); err != nil {
+			slog.Warn("failed to parse stored pulse baselines, using defaults", "error", err)
+		} else if err := b.Validate(); err != nil {
+			slog.Warn("stored pulse baselines are invalid, using defaults", "error", err)
+		} else {
+			srv.Pulse().SetBaselines(b)
+			slog.Info("applied stored pulse baselines", "countries", len(b.Countries), "base_total", b.BaseTotal)
+		}
 	}
+	p.SetPulse(srv.Pulse())
+	// Visits logged by /api/pulse are counted off the request path
+	jobs.Add("pulse", srv.Pulse())
+	jobs.Start(context.Background())
 
-	// Graceful shutdown
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGTERM)
+	p.AddNotifier(srv)
+	srv.SetLogTail(logs)
+	srv.SetJobs(jobs)
+	alerts := []server.AlertChannel{hooks}
+	if telegram != nil {
+		alerts = append(alerts, telegram)
+	}
+	srv.SetAlertChannels(alerts...)
+	srv.SetReload(func() {
+		select {
+		case reload <- syscall.SIGHUP:
+		default:
+		}
+	})
+	httpServer := server.NewHTTPServer(":"+cfg.Port, srv.Router(), cfg.HTTP)
+	httpServer.RegisterOnShutdown(srv.CloseStreams)
+
+	// Standalone HTTPS: certificates are obtained on first request per domain
+	var redirectServer *http.Server
+	if cfg.TLS.Enabled() {
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLS.Domains...),
+			Cache:      autocert.DirCache(cfg.TLS.CacheDir),
+			Email:      cfg.TLS.Email,
+		}
+		httpServer.Addr = ":" + cfg.TLS.Port
+		httpServer.TLSConfig = manager.TLSConfig()
+		redirectServer = server.NewHTTPServer(":"+cfg.TLS.HTTPPort, manager.HTTPHandler(server.RedirectHTTPS(cfg.TLS.Port)), cfg.HTTP)
+	}
+	metricsServer := server.NewHTTPServer(cfg.MetricsAddr, server.MetricsRouter(), cfg.HTTP)
+	if err := server.ConfigureHTTP2(httpServer, cfg.HTTP); err != nil {
+		slog.Error("failed to configure HTTP/2", "error", err)
+		os.Exit(1)
+	}
 
 	go func() {
-		slog.Info("server starting", "port", cfg.Port)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if redirectServer != nil {
+			slog.Info("server starting with automatic TLS", "port", cfg.TLS.Port, "domains", cfg.TLS.Domains)
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			slog.Info("server starting", "port", cfg.Port)
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			slog.Error("server error", "error", err)
 			os.Exit(1)
 		}
 	}()
+	if redirectServer != nil {
+		go func() {
+			slog.Info("HTTP redirect server starting", "port", cfg.TLS.HTTPPort)
+			if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("redirect server error", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
 
-	<-done
-	slog.Info("shutting down")
+	go func() {
+		slog.Info("metrics server starting", "addr", cfg.MetricsAddr)
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("metrics server error", "error", err)
+			os.Exit(1)
+		}
+	}()
 
-	sched.Stop()
+	reloading := false
+	select {
+	case <-done:
+		slog.Info("shutting down")
+	case <-reload:
+		reloading = true
+		slog.Info("stopping for reload")
+	}
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer shutdownCancel()
@@ -105,6 +347,20 @@ func main() {
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		slog.Error("server shutdown error", "error", err)
 	}
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(shutdownCtx); err != nil {
+			slog.Error("redirect server shutdown error", "error", err)
+		}
+	}
+	if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+		slog.Error("metrics server shutdown error", "error", err)
+	}
 
-	slog.Info("shutdown complete")
+	// A run an admin triggered would otherwise carry on into the next
+	// config's pipeline after a reload
+	p.Stop()
+	// After the server drains, so in-flight requests are counted in the
+	// final tenant usage flush
+	jobs.Stop()
+	return reloading
 }
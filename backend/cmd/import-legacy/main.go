@@ -0,0 +1,156 @@
+// Command import-legacy loads snapshots written by the original Python
+// version (frontend/data.json and its git history) into the snapshots table,
+// converting them to the current schema.
+//
+//	mkdir legacy
+//	git log --format=%H -- frontend/data.json | while read rev; do
+//	    git show "$rev:frontend/data.json" > "legacy/$rev.json"
+//	done
+//	DATABASE_URL=... go run ./cmd/import-legacy legacy/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/legacy"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+)
+
+type legacySnapshot struct {
+	path      string
+	createdAt time.Time
+	response  []byte
+}
+
+func main() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo})))
+
+	dryRun := flag.Bool("dry-run", false, "convert and report without writing to the database")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: import-legacy [-dry-run] path...\n\nPaths may be JSON files or directories of them.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	snapshots, err := load(flag.Args())
+	if err != nil {
+		slog.Error("failed to read legacy snapshots", "error", err)
+		os.Exit(1)
+	}
+	slog.Info("legacy snapshots converted", "count", len(snapshots))
+	if *dryRun || len(snapshots) == 0 {
+		return
+	}
+
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		slog.Error("DATABASE_URL is required")
+		os.Exit(1)
+	}
+	poolCfg, err := config.LoadDBPool()
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	pool, err := store.NewPool(ctx, dsn, poolCfg)
+	if err != nil {
+		slog.Error("failed to open database", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	pgStore := store.NewPostgres(pool, poolCfg.ScanTimeout)
+	if err := pgStore.Migrate(ctx); err != nil {
+		slog.Error("failed to run migrations", "error", err)
+		os.Exit(1)
+	}
+
+	imported := 0
+	for _, snap := range snapshots {
+		ok, err := pgStore.ImportSnapshot(ctx, snap.response, snap.createdAt)
+		if err != nil {
+			slog.Error("failed to import snapshot", "path", snap.path, "error", err)
+			os.Exit(1)
+		}
+		if ok {
+			imported++
+		}
+	}
+	slog.Info("legacy import complete", "imported", imported, "skipped", len(snapshots)-imported)
+}
+
+// load reads and converts every snapshot under paths, sorted oldest first.
+// Files without a usable last_updated are skipped since they can't be placed
+// in history.
+func load(paths []string) ([]legacySnapshot, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+
+	var snapshots []legacySnapshot
+	seen := map[time.Time]bool{}
+	for _, path := range files {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var data map[string]any
+		if err := json.Unmarshal(raw, &data); err != nil {
+			slog.Warn("skipping unparseable file", "path", path, "error", err)
+			continue
+		}
+
+		legacy.Convert(data)
+		lastUpdated, _ := data["last_updated"].(string)
+		createdAt, ok := legacy.ParseTime(strings.TrimSpace(lastUpdated))
+		if !ok {
+			slog.Warn("skipping file without last_updated", "path", path)
+			continue
+		}
+		// The same data.json often appears in several commits.
+		if seen[createdAt] {
+			continue
+		}
+		seen[createdAt] = true
+
+		response, err := json.Marshal(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		snapshots = append(snapshots, legacySnapshot{path: path, createdAt: createdAt, response: response})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].createdAt.Before(snapshots[j].createdAt)
+	})
+	return snapshots, nil
+}
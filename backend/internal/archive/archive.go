@@ -0,0 +1,232 @@
+// Package archive publishes a daily public dataset of hourly risk values to
+// object storage, and can mirror the live snapshot there after every run.
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+)
+
+// backfillDays is how many completed days are checked for a missing dataset
+// on each tick, so outages are caught up automatically.
+const backfillDays = 7
+
+// checkInterval is how often the publisher looks for unpublished days.
+const checkInterval = time.Hour
+
+// signals are the dataset columns, in scoring order, after hour and total.
+var signals = fetcher.Names()
+
+// HourRow is one hour of the dataset: the mean of each score across the
+// pipeline runs in that hour. A signal no run in the hour scored, such as
+// one disabled for want of credentials, is left out rather than published
+// as 0, and its CSV cell is empty. Only scores are published; raw_data,
+// article titles and visitor counts never leave the database.
+type HourRow struct {
+	Hour    string         `json:"hour"`
+	Runs    int            `json:"runs"`
+	Total   int            `json:"total"`
+	Signals map[string]int `json:"signals"`
+}
+
+// Publisher uploads one dataset per UTC day.
+type Publisher struct {
+	store store.Store
+	s3    *s3Client
+	cfg   config.Archive
+	stop  chan struct{}
+}
+
+func New(cfg config.Archive, st store.Store) *Publisher {
+	return &Publisher{
+		store: st,
+		s3:    &s3Client{client: &http.Client{Timeout: 60 * time.Second}, cfg: cfg},
+		cfg:   cfg,
+		stop:  make(chan struct{}),
+	}
+}
+
+// Start publishes any missing days immediately and then hourly. Blocks
+// until Stop is called.
+func (p *Publisher) Start(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	slog.Info("archive publisher started", "bucket", p.cfg.Bucket)
+	p.catchUp(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			p.catchUp(ctx)
+		case <-p.stop:
+			slog.Info("archive publisher stopped")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop signals the publisher to stop.
+func (p *Publisher) Stop() {
+	close(p.stop)
+}
+
+func (p *Publisher) catchUp(ctx context.Context) {
+	exports, err := p.store.DatasetExports(ctx)
+	if err != nil {
+		slog.Error("archive: failed to list exports", "error", err)
+		return
+	}
+	published := make(map[string]bool, len(exports))
+	for _, e := range exports {
+		published[e.Day] = true
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for i := backfillDays; i >= 1; i-- {
+		day := today.AddDate(0, 0, -i)
+		if published[day.Format(time.DateOnly)] {
+			continue
+		}
+		if err := p.Publish(ctx, day); err != nil {
+			slog.Error("archive: failed to publish day", "day", day.Format(time.DateOnly), "error", err)
+		}
+	}
+}
+
+// Publish builds and uploads the dataset for the UTC day starting at day.
+// Days without any snapshots are skipped.
+func (p *Publisher) Publish(ctx context.Context, day time.Time) error {
+	rows, err := HourlyRows(ctx, p.store, day, day.Add(24*time.Hour))
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	name := day.Format(time.DateOnly)
+	jsonBody, err := json.Marshal(map[string]any{"day": name, "hours": rows})
+	if err != nil {
+		return err
+	}
+	csvBody, err := encodeCSV(rows)
+	if err != nil {
+		return err
+	}
+
+	export := model.DatasetExport{
+		Day:         name,
+		JSONKey:     p.cfg.Prefix + name + ".json",
+		CSVKey:      p.cfg.Prefix + name + ".csv",
+		Hours:       len(rows),
+		PublishedAt: time.Now().UTC(),
+	}
+	if err := p.s3.put(ctx, export.JSONKey, "application/json", jsonBody); err != nil {
+		return err
+	}
+	if err := p.s3.put(ctx, export.CSVKey, "text/csv", csvBody); err != nil {
+		return err
+	}
+	if err := p.store.SaveDatasetExport(ctx, export); err != nil {
+		return err
+	}
+	slog.Info("archive: published dataset", "day", name, "hours", len(rows))
+	return nil
+}
+
+// HourlyRows averages stored snapshots in [from, to) into one row per UTC
+// hour that has at least one run.
+func HourlyRows(ctx context.Context, st store.Store, from, to time.Time) ([]HourRow, error) {
+	type bucket struct {
+		hour  time.Time
+		runs  int
+		total int
+		sums  []int
+		// scored counts the runs that scored each signal
+		scored []int
+	}
+	var buckets []*bucket
+
+	err := st.SnapshotsBetween(ctx, from, to, func(createdAt time.Time, response []byte) error {
+		var snap model.Snapshot
+		if err := json.Unmarshal(response, &snap); err != nil {
+			return nil
+		}
+		hour := createdAt.UTC().Truncate(time.Hour)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].hour.Equal(hour) {
+			buckets = append(buckets, &bucket{hour: hour, sums: make([]int, len(signals)), scored: make([]int, len(signals))})
+		}
+		b := buckets[len(buckets)-1]
+		b.runs++
+		b.total += snap.TotalRisk.Risk
+		for i, name := range signals {
+			if sig := snap.Signal(name); sig != nil {
+				b.sums[i] += sig.Risk
+				b.scored[i]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshots: %w", err)
+	}
+
+	rows := make([]HourRow, 0, len(buckets))
+	for _, b := range buckets {
+		row := HourRow{
+			Hour:    b.hour.Format(time.RFC3339),
+			Runs:    b.runs,
+			Total:   mean(b.total, b.runs),
+			Signals: make(map[string]int, len(signals)),
+		}
+		for i, name := range signals {
+			if b.scored[i] > 0 {
+				row.Signals[name] = mean(b.sums[i], b.scored[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func encodeCSV(rows []HourRow) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(append([]string{"hour", "runs", "total"}, signals...))
+	for _, r := range rows {
+		record := []string{r.Hour, strconv.Itoa(r.Runs), strconv.Itoa(r.Total)}
+		for _, name := range signals {
+			cell := ""
+			if v, ok := r.Signals[name]; ok {
+				cell = strconv.Itoa(v)
+			}
+			record = append(record, cell)
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func mean(sum, n int) int {
+	if n == 0 {
+		return 0
+	}
+	return int(math.Round(float64(sum) / float64(n)))
+}
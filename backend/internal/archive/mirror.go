@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// Mirror uploads each snapshot the pipeline caches, with a badge showing
+// its total risk, to a static bucket. Uploads run in the background so a
+// slow bucket never holds up a pipeline run; if snapshots arrive faster
+// than they upload, only the newest is sent.
+type Mirror struct {
+	s3      *s3Client
+	cfg     config.Mirror
+	pending chan []byte
+	stop    chan struct{}
+}
+
+// NewMirror returns a mirror uploading through the archive's S3 endpoint
+// and credentials to cfg.Bucket.
+func NewMirror(cfg config.Mirror, s3cfg config.Archive) *Mirror {
+	s3cfg.Bucket = cfg.Bucket
+	if s3cfg.Region == "" {
+		s3cfg.Region = "auto"
+	}
+	return &Mirror{
+		s3:      &s3Client{client: &http.Client{Timeout: 60 * time.Second}, cfg: s3cfg, cacheControl: cfg.CacheControl},
+		cfg:     cfg,
+		pending: make(chan []byte, 1),
+		stop:    make(chan struct{}),
+	}
+}
+
+// SnapshotUpdated queues data for upload, replacing any snapshot still
+// waiting. It never blocks.
+func (m *Mirror) SnapshotUpdated(data []byte) {
+	for {
+		select {
+		case m.pending <- data:
+			return
+		default:
+		}
+		select {
+		case <-m.pending:
+		default:
+		}
+	}
+}
+
+// Start uploads queued snapshots until Stop is called.
+func (m *Mirror) Start(ctx context.Context) {
+	slog.Info("snapshot mirror started", "bucket", m.cfg.Bucket)
+	for {
+		select {
+		case data := <-m.pending:
+			if err := m.publish(ctx, data); err != nil {
+				slog.Error("mirror: failed to publish snapshot", "error", err)
+			}
+		case <-m.stop:
+			slog.Info("snapshot mirror stopped")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop signals the mirror to stop.
+func (m *Mirror) Stop() {
+	close(m.stop)
+}
+
+// publish uploads the snapshot, then its badge. The snapshot goes first so
+// the badge never shows a risk the mirrored data doesn't.
+func (m *Mirror) publish(ctx context.Context, data []byte) error {
+	var snap struct {
+		TotalRisk struct {
+			Risk int `json:"risk"`
+		} `json:"total_risk"`
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("parsing snapshot: %w", err)
+	}
+	if err := m.s3.put(ctx, m.cfg.Prefix+"data.json", "application/json", data); err != nil {
+		return err
+	}
+	if err := m.s3.put(ctx, m.cfg.Prefix+"badge.svg", "image/svg+xml", badgeSVG(snap.TotalRisk.Risk)); err != nil {
+		return err
+	}
+	slog.Info("mirror: published snapshot", "bytes", len(data), "total_risk", snap.TotalRisk.Risk)
+	return nil
+}
+
+// badgeColors colour the badge's value by risk band.
+var badgeColors = map[string]string{
+	"low":      "#4c1",
+	"elevated": "#dfb317",
+	"high":     "#fe7d37",
+	"imminent": "#e05d44",
+}
+
+// badgeSVG renders a flat two-part badge, "strike risk | 42% high". Text
+// widths are estimated at a fixed advance per character, which is close
+// enough for the 11px Verdana badges usually use.
+func badgeSVG(total int) []byte {
+	const label = "strike risk"
+	band := risk.Band(total)
+	value := fmt.Sprintf("%d%% %s", total, band)
+	labelW, valueW := 10+len(label)*7, 10+len(value)*7
+	w := labelW + valueW
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`+
+		`<title>%s: %s</title>`+
+		`<rect width="%d" height="20" fill="#555"/>`+
+		`<rect x="%d" width="%d" height="20" fill="%s"/>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%d" y="14">%s</text><text x="%d" y="14">%s</text></g></svg>`,
+		w, label, value, label, value,
+		labelW, labelW, valueW, badgeColors[band],
+		labelW/2, label, labelW+valueW/2, value))
+}
@@ -0,0 +1,105 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+)
+
+// s3Client uploads objects to an S3-compatible bucket using path-style URLs
+// and AWS Signature Version 4.
+type s3Client struct {
+	client *http.Client
+	cfg    config.Archive
+	// cacheControl, when set, is stored with each object for CDNs to honour
+	cacheControl string
+}
+
+func (c *s3Client) put(ctx context.Context, key, contentType string, body []byte) error {
+	u, err := url.Parse(c.cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("archive endpoint: %w", err)
+	}
+	u.Path = "/" + c.cfg.Bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if c.cacheControl != "" {
+		req.Header.Set("Cache-Control", c.cacheControl)
+	}
+	c.sign(req, body, time.Now().UTC())
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("archive upload: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("archive upload %s: %d %s", key, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// sign adds SigV4 headers for a single-chunk payload.
+func (c *s3Client) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, c.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
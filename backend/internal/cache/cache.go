@@ -1,27 +1,54 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"sync"
 	"time"
 )
 
-// Cache holds a pre-serialized JSON response in memory.
+// maxVersions is how many recent snapshots are kept for delta responses.
+// At one run per 30 minutes this covers clients a few hours behind.
+const maxVersions = 8
+
+// Cache holds a pre-serialized JSON response in memory, plus the few
+// versions before it keyed by content hash.
 type Cache struct {
 	mu        sync.RWMutex
 	data      []byte
+	hash      string
 	updatedAt time.Time
+	versions  []version
+}
+
+type version struct {
+	hash string
+	data []byte
 }
 
 func New() *Cache {
 	return &Cache{}
 }
 
+// Hash returns a short content hash identifying JSON bytes.
+func Hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
 // Set stores the pre-serialized JSON bytes.
 func (c *Cache) Set(data []byte) {
 	c.mu.Lock()
 	c.data = make([]byte, len(data))
 	copy(c.data, data)
+	c.hash = Hash(c.data)
 	c.updatedAt = time.Now()
+	if n := len(c.versions); n == 0 || c.versions[n-1].hash != c.hash {
+		c.versions = append(c.versions, version{hash: c.hash, data: c.data})
+		if len(c.versions) > maxVersions {
+			c.versions = c.versions[len(c.versions)-maxVersions:]
+		}
+	}
 	c.mu.Unlock()
 }
 
@@ -37,9 +64,67 @@ func (c *Cache) Get() []byte {
 	return out
 }
 
+// Current returns the cached JSON bytes and their hash, or nil and "" if
+// empty. The returned slice must not be modified.
+func (c *Cache) Current() ([]byte, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data, c.hash
+}
+
+// Version returns a recent snapshot by hash, or nil if it is no longer
+// held. The returned slice must not be modified.
+func (c *Cache) Version(hash string) []byte {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, v := range c.versions {
+		if v.hash == hash {
+			return v.data
+		}
+	}
+	return nil
+}
+
 // UpdatedAt returns the last time the cache was updated.
 func (c *Cache) UpdatedAt() time.Time {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.updatedAt
 }
+
+// Stats describes what the cache holds, for diagnosing stale data.
+type Stats struct {
+	Hash      string
+	Size      int
+	UpdatedAt time.Time
+	// Versions are the snapshots held for delta responses, oldest first.
+	Versions []VersionStats
+}
+
+// VersionStats describes one held snapshot version.
+type VersionStats struct {
+	Hash string
+	Size int
+}
+
+// Stats returns what the cache holds, with a zero UpdatedAt when empty.
+func (c *Cache) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	st := Stats{Hash: c.hash, Size: len(c.data), UpdatedAt: c.updatedAt, Versions: make([]VersionStats, len(c.versions))}
+	for i, v := range c.versions {
+		st.Versions[i] = VersionStats{Hash: v.hash, Size: len(v.data)}
+	}
+	return st
+}
+
+// Purge empties the cache, versions included, so the next read loads the
+// latest snapshot afresh.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	c.data = nil
+	c.hash = ""
+	c.updatedAt = time.Time{}
+	c.versions = nil
+	c.mu.Unlock()
+}
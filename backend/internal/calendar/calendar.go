@@ -0,0 +1,169 @@
+// Package calendar tracks militarily and politically sensitive dates that
+// nudge total risk as they approach.
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// upcomingDays is how far ahead dates are listed in the snapshot.
+const upcomingDays = 30
+
+// maxModifier caps any single date's contribution to total risk.
+const maxModifier = 10
+
+// Defaults for entries that leave Modifier or LeadDays unset.
+const (
+	defaultModifier = 3
+	defaultLeadDays = 2
+)
+
+// zone is where dates are observed. Iran has kept standard time year-round
+// since 2022.
+var zone = time.FixedZone("IRST", 3*3600+1800)
+
+// Date is one sensitive date. Annual dates set Month and Day; one-off dates,
+// used for lunar observances such as Quds Day, set Date as YYYY-MM-DD.
+// Modifier is the points added to total risk on the day itself, tapering
+// to zero over the LeadDays before it.
+type Date struct {
+	Name     string `json:"name"`
+	Month    int    `json:"month,omitempty"`
+	Day      int    `json:"day,omitempty"`
+	Date     string `json:"date,omitempty"`
+	Modifier int    `json:"modifier,omitempty"`
+	LeadDays int    `json:"lead_days,omitempty"`
+}
+
+// defaultDates are the built-in dates. Lunar observances are listed per
+// year and need extending as new years are announced.
+var defaultDates = []Date{
+	{Name: "Anniversary of Soleimani killing", Month: 1, Day: 3, Modifier: 4},
+	{Name: "Islamic Revolution anniversary (22 Bahman)", Month: 2, Day: 11},
+	{Name: "Islamic Republic Day", Month: 4, Day: 1, Modifier: 2},
+	{Name: "Anniversary of Israeli strikes on Iran", Month: 6, Day: 13, Modifier: 4},
+	{Name: "Anniversary of US strikes on Fordow", Month: 6, Day: 22, Modifier: 4},
+	{Name: "Anniversary of October 7 attack", Month: 10, Day: 7, Modifier: 4},
+	{Name: "US embassy seizure anniversary", Month: 11, Day: 4},
+	{Name: "Quds Day", Date: "2026-03-13", Modifier: 5},
+	{Name: "Quds Day", Date: "2027-03-05", Modifier: 5},
+	{Name: "Yom Kippur", Date: "2026-09-21"},
+	{Name: "Yom Kippur", Date: "2027-10-11"},
+}
+
+// Calendar is a validated set of sensitive dates.
+type Calendar struct {
+	dates []Date
+}
+
+// Default returns the built-in calendar.
+func Default() *Calendar {
+	c, err := New(defaultDates)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// New validates dates and fills in default modifiers and lead times.
+func New(dates []Date) (*Calendar, error) {
+	c := &Calendar{dates: make([]Date, 0, len(dates))}
+	for _, d := range dates {
+		if d.Name == "" {
+			return nil, fmt.Errorf("sensitive date without a name")
+		}
+		if d.Date != "" {
+			if _, err := time.Parse("2006-01-02", d.Date); err != nil {
+				return nil, fmt.Errorf("%s: invalid date %q", d.Name, d.Date)
+			}
+		} else if d.Month < 1 || d.Month > 12 || d.Day < 1 || d.Day > 31 {
+			return nil, fmt.Errorf("%s: needs a date or a month and day", d.Name)
+		}
+		if d.Modifier == 0 {
+			d.Modifier = defaultModifier
+		}
+		if d.Modifier < 0 || d.Modifier > maxModifier {
+			return nil, fmt.Errorf("%s: modifier must be between 1 and %d", d.Name, maxModifier)
+		}
+		if d.LeadDays == 0 {
+			d.LeadDays = defaultLeadDays
+		}
+		if d.LeadDays < 0 || d.LeadDays > upcomingDays {
+			return nil, fmt.Errorf("%s: lead_days must be between 1 and %d", d.Name, upcomingDays)
+		}
+		c.dates = append(c.dates, d)
+	}
+	return c, nil
+}
+
+// Load returns the built-in calendar extended with the JSON array of dates
+// in path. An empty path yields the defaults.
+func Load(path string) (*Calendar, error) {
+	if path == "" {
+		return Default(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sensitive dates: %w", err)
+	}
+	var extra []Date
+	if err := json.Unmarshal(raw, &extra); err != nil {
+		return nil, fmt.Errorf("parse sensitive dates: %w", err)
+	}
+	return New(append(append([]Date{}, defaultDates...), extra...))
+}
+
+// Upcoming lists the dates in the next upcomingDays days, soonest first,
+// and returns the risk modifier now in effect: the largest tapered
+// modifier of any date whose lead window has begun. Modifiers don't add
+// up, so a cluster of dates counts like its most sensitive one.
+func (c *Calendar) Upcoming(now time.Time) ([]model.SensitiveDate, int) {
+	local := now.In(zone)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, zone)
+
+	upcoming := []model.SensitiveDate{}
+	modifier := 0
+	for _, d := range c.dates {
+		on, ok := d.next(today)
+		if !ok {
+			continue
+		}
+		days := int(math.Round(on.Sub(today).Hours() / 24))
+		if days > upcomingDays {
+			continue
+		}
+		active := 0
+		if days <= d.LeadDays {
+			active = int(math.Round(float64(d.Modifier) * float64(d.LeadDays+1-days) / float64(d.LeadDays+1)))
+		}
+		modifier = max(modifier, active)
+		upcoming = append(upcoming, model.SensitiveDate{
+			Name:      d.Name,
+			Date:      on.Format("2006-01-02"),
+			DaysUntil: days,
+			Modifier:  active,
+		})
+	}
+	sort.SliceStable(upcoming, func(i, j int) bool { return upcoming[i].DaysUntil < upcoming[j].DaysUntil })
+	return upcoming, modifier
+}
+
+// next returns the first occurrence of d on or after today.
+func (d Date) next(today time.Time) (time.Time, bool) {
+	if d.Date != "" {
+		t, _ := time.ParseInLocation("2006-01-02", d.Date, zone)
+		return t, !t.Before(today)
+	}
+	t := time.Date(today.Year(), time.Month(d.Month), d.Day, 0, 0, 0, 0, zone)
+	if t.Before(today) {
+		t = t.AddDate(1, 0, 0)
+	}
+	return t, true
+}
@@ -1,41 +1,477 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	DatabaseURL        string
-	OpenWeatherAPIKey  string
+	DatabaseURL          string
+	OpenWeatherAPIKey    string
 	CloudflareRadarToken string
-	Port               string
-	AllowedOrigins     []string
+	Port                 string
+	RunInterval          time.Duration
+	RunBudget            time.Duration
+	Schedule             Schedule
+	FetchConcurrency     int
+	Retry                Retry
+	Breaker              Breaker
+	RateLimits           []RateLimit
+	OpenSky              OpenSky
+	NewsFeedDeadAfter    time.Duration
+	NewsLookback         time.Duration
+	PublicURL            string
+	AllowedOrigins       []string
+	CORS                 CORS
+	AdminToken           string
+	Weather              WeatherThresholds
+	TankerSaturation     int
+	Weights              Weights
+	DegradedAfter        int
+	DBPool               DBPool
+	PulseHonorDNT        bool
+	PulsePrivacy         PulsePrivacy
+	LogTailSize          int
+	Tracks               Tracks
+	Compression          Compression
+	Archive              Archive
+	Mirror               Mirror
+	Telegram             Telegram
+	DataCache            DataCache
+	TLS                  TLS
+	MetricsAddr          string
+	HTTP                 HTTP
+	Attention            Attention
+	Connectivity         Connectivity
+	GDELTQuery           string
+	Shipping             Shipping
+	Notam                Notam
+	Indicator            Indicator
+	PizzaClusters        []PizzaCluster
+	SeismicBox           BoundingBox
+	// SensitiveDatesFile is an optional JSON array of dates added to the
+	// built-in sensitive-date calendar.
+	SensitiveDatesFile string
+	// SnapshotMeta is static content served under the snapshot's "meta"
+	// key, such as a description, source list or disclaimer, so the
+	// frontend's copy can change without a frontend deploy.
+	SnapshotMeta map[string]any
+	// Disabled are the signals left unfetched and unweighted because a
+	// setting they require is unset.
+	Disabled map[string]bool
 }
 
-func Load() (*Config, error) {
-	dbURL := os.Getenv("DATABASE_URL")
+// CORS holds the cross-origin policies that differ from the site's own
+// AllowedOrigins. PublicOrigins may read public data without credentials
+// ("*" admits any site); AdminOrigins are matched exactly and may call the
+// admin routes with credentials. No AdminOrigins keeps admin same-origin.
+type CORS struct {
+	PublicOrigins []string
+	AdminOrigins  []string
+}
+
+// Retry controls how fetchers retry transient upstream failures. Each
+// request is tried up to Attempts times. Waits start at Backoff and double
+// per retry up to MaxBackoff, with jitter so concurrent fetches don't retry
+// in lockstep. Network errors and responses with a Statuses code are
+// retried; anything else is returned at once.
+type Retry struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	Statuses   []int
+}
+
+// Schedule tightens the run interval from RunInterval to MinInterval while
+// total risk is at or above RiskThreshold, and relaxes it again once risk
+// drops below. MinInterval equal to RunInterval keeps the interval fixed.
+type Schedule struct {
+	MinInterval   time.Duration
+	RiskThreshold int
+}
+
+// Breaker controls the per-upstream circuit breakers. After Threshold
+// consecutive failed fetches an upstream is skipped for Cooldown, after
+// which one trial fetch decides whether it closes again.
+type Breaker struct {
+	Threshold int
+	Cooldown  time.Duration
+}
+
+// RateLimit paces requests to one upstream host with a token bucket: one
+// request per Interval on average, with up to Burst sent back to back.
+type RateLimit struct {
+	Host     string
+	Interval time.Duration
+	Burst    int
+}
+
+// OpenSky configures access to the OpenSky Network state vectors behind
+// the aviation and tanker signals. With ClientID and ClientSecret set,
+// requests carry an OAuth2 client-credentials token, whose daily quota
+// is large enough for the wider boxes anonymous access runs out on.
+type OpenSky struct {
+	ClientID     string
+	ClientSecret string
+	AviationBox  BoundingBox
+	TankerBox    BoundingBox
+}
+
+// Authenticated reports whether OpenSky credentials are configured.
+func (o OpenSky) Authenticated() bool {
+	return o.ClientID != ""
+}
+
+// BoundingBox is an area in degrees, as OpenSky's lamin/lomin/lamax/lomax
+// query parameters.
+type BoundingBox struct {
+	LatMin, LonMin, LatMax, LonMax float64
+}
+
+func (b BoundingBox) String() string {
+	return fmt.Sprintf("%g,%g,%g,%g", b.LatMin, b.LonMin, b.LatMax, b.LonMax)
+}
+
+// Shipping configures the AISHub feed behind the shipping signal, which
+// counts vessels under way in Box. AISHub serves data only to members, who
+// share their own receiver's feed; with no AISHubUsername the signal is
+// disabled.
+type Shipping struct {
+	AISHubUsername string
+	Box            BoundingBox
+}
+
+// Notam configures the FAA NOTAM API behind the airspace signal, which
+// reads the NOTAMs in force for each of Locations: FIRs, whose closures
+// shut a country's airspace, and aerodromes. The API needs a ClientID and
+// ClientSecret, issued free on registration; without them the signal is
+// disabled.
+type Notam struct {
+	ClientID     string
+	ClientSecret string
+	Locations    []string
+}
+
+// Indicator is the attribution and license partners are asked to show
+// with the /api/v1/indicator feed.
+type Indicator struct {
+	Attribution string
+	License     string
+}
+
+// PizzaCluster is one facility whose nearby pizza places the pentagon
+// signal watches, reported on its own. Opening hours and late nights are
+// judged in Location, the facility's time zone.
+type PizzaCluster struct {
+	Name     string
+	Location *time.Location
+	Places   []PizzaPlace
+}
+
+// PizzaPlace is a watched pizza place and its Google Maps place ID.
+type PizzaPlace struct {
+	Name    string `json:"name"`
+	PlaceID string `json:"place_id"`
+	Address string `json:"address"`
+}
+
+// DefaultPizzaClusters is the Pentagon alone, the original pizza meter.
+func DefaultPizzaClusters() []PizzaCluster {
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		eastern = time.FixedZone("EST", -5*60*60)
+	}
+	return []PizzaCluster{{
+		Name:     "Pentagon",
+		Location: eastern,
+		Places: []PizzaPlace{
+			{Name: "Domino's Pizza", PlaceID: "ChIJN1t_tDeuEmsRUsoyG83frY4", Address: "Pentagon City"},
+			{Name: "Papa John's", PlaceID: "ChIJP3Sa8ziYEmsRUKgyFmh9AQM", Address: "Near Pentagon"},
+			{Name: "Pizza Hut", PlaceID: "ChIJrTLr-GyuEmsRBfy61i59si0", Address: "Pentagon Area"},
+		},
+	}}
+}
+
+// Connectivity lists the country codes, besides Iran, whose national Radar
+// traffic is tracked in the connectivity signal's country table.
+type Connectivity struct {
+	Locations []string
+}
+
+// Attention selects the external sources of the public-attention index.
+// Site pulse always contributes; Wikipedia pageviews for
+// WikipediaArticles join when Wikipedia is set.
+type Attention struct {
+	Wikipedia         bool
+	WikipediaArticles []string
+}
+
+// HTTP holds listener timeouts and HTTP/2 limits. WriteTimeout bounds
+// ordinary responses; streaming responses replace it with StreamTimeout
+// and send a heartbeat every StreamKeepAlive so idle proxies keep the
+// connection open. H2C accepts cleartext HTTP/2 for reverse proxies that
+// speak it to the backend; over TLS HTTP/2 is always offered.
+type HTTP struct {
+	ReadHeaderTimeout    time.Duration
+	ReadTimeout          time.Duration
+	WriteTimeout         time.Duration
+	IdleTimeout          time.Duration
+	StreamTimeout        time.Duration
+	StreamKeepAlive      time.Duration
+	MaxHeaderBytes       int
+	MaxConcurrentStreams uint32
+	H2C                  bool
+}
+
+// TLS configures automatic HTTPS certificates from an ACME CA (Let's
+// Encrypt) for standalone deployments. It is disabled unless Domains is
+// set, leaving TLS to a reverse proxy. HTTPPort serves ACME challenges and
+// redirects everything else to HTTPS on Port.
+type TLS struct {
+	Domains  []string
+	Email    string
+	CacheDir string
+	Port     string
+	HTTPPort string
+}
+
+// Enabled reports whether any domain is configured.
+func (t TLS) Enabled() bool {
+	return len(t.Domains) > 0
+}
+
+// DataCache is the Cache-Control policy for /api/data. While total risk is
+// at or above HotMinRisk the shorter Hot* lifetimes apply, so CDN copies
+// don't lag a fast-moving situation.
+type DataCache struct {
+	MaxAge     time.Duration
+	SMaxAge    time.Duration
+	HotMaxAge  time.Duration
+	HotSMaxAge time.Duration
+	HotMinRisk int
+}
+
+// Archive configures daily dataset publishing to an S3-compatible bucket.
+// Publishing is disabled when Bucket is empty.
+type Archive struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Prefix          string
+	PublicURL       string
+}
+
+// Enabled reports whether a bucket is configured.
+func (a Archive) Enabled() bool {
+	return a.Bucket != ""
+}
+
+// Mirror configures publishing each new snapshot, and a badge rendered
+// from it, to a static bucket the frontend can read when the API is down.
+// It uploads through the archive's S3 endpoint and credentials, and is
+// disabled when Bucket is empty.
+type Mirror struct {
+	Bucket       string
+	Prefix       string
+	CacheControl string
+	PublicURL    string
+}
+
+// Enabled reports whether a mirror bucket is configured.
+func (m Mirror) Enabled() bool {
+	return m.Bucket != ""
+}
+
+// Telegram configures posting a message to a chat when total risk changes
+// band. It is disabled unless both the bot token and chat ID are set.
+type Telegram struct {
+	BotToken string
+	ChatID   string
+}
+
+// Enabled reports whether a bot and chat are configured.
+func (t Telegram) Enabled() bool {
+	return t.BotToken != "" && t.ChatID != ""
+}
+
+// PulsePrivacy controls how small per-country visitor counts are
+// published by /api/pulse. The zero value publishes exact counts.
+type PulsePrivacy struct {
+	// MinCount withholds counts below it, reporting them as "<MinCount".
+	MinCount int
+	// RoundTo rounds published counts to a multiple of it.
+	RoundTo int
+}
+
+// Compression controls gzip of responses for clients that accept it.
+// Bodies under MinBytes are sent as they are.
+type Compression struct {
+	Enabled  bool
+	MinBytes int
+}
+
+// Tracks controls persistence of per-run aircraft positions for replay.
+type Tracks struct {
+	Enabled   bool
+	Retention time.Duration
+}
+
+// DBPool holds connection pool sizing and timeouts for the Postgres store.
+type DBPool struct {
+	MaxConns         int32
+	MinConns         int32
+	MaxConnLifetime  time.Duration
+	MaxConnIdleTime  time.Duration
+	ConnectTimeout   time.Duration
+	StatementTimeout time.Duration
+	SlowQuery        time.Duration
+	// ScanTimeout bounds reads over a time range, such as the archive
+	// export, in place of StatementTimeout. 0 means no limit.
+	ScanTimeout time.Duration
+}
+
+// WeatherThresholds are the strike-favorability bands used to score the
+// weather signal. Visibility is in meters, clouds in percent, wind in m/s.
+// DustCodes and HazeCodes are the OpenWeather condition IDs treated as
+// airborne dust or sand, and as haze or smoke.
+type WeatherThresholds struct {
+	ClearVisibility int
+	MinVisibility   int
+	ClearClouds     int
+	MaxClouds       int
+	MaxWind         float64
+	DustCodes       []int
+	HazeCodes       []int
+}
+
+// Signal is what the configuration needs to know of a scored signal.
+type Signal struct {
+	Name string
+	// Weight is the signal's share of total risk unless WEIGHT_<NAME> sets
+	// another.
+	Weight float64
+	// Requires are the settings the signal can't be fetched without. With
+	// any unset the signal is disabled.
+	Requires []string
+}
+
+// Weights are each signal's share of the total risk, keyed by signal name.
+// They must sum to 1.
+type Weights map[string]float64
+
+// DefaultWeights is the published model: each signal's own weight.
+func DefaultWeights(signals []Signal) Weights {
+	w := make(Weights, len(signals))
+	for _, s := range signals {
+		w[s.Name] = s.Weight
+	}
+	return w
+}
+
+// Validate checks that no weight is negative and that they sum to 1,
+// allowing for rounding in hand-written values.
+func (w Weights) Validate() error {
+	sum := 0.0
+	for name, v := range w {
+		if v < 0 || v > 1 {
+			return fmt.Errorf("weight for %s must be between 0 and 1, got %g", name, v)
+		}
+		sum += v
+	}
+	if math.Abs(sum-1) > 0.001 {
+		return fmt.Errorf("signal weights must sum to 1, got %.3f", sum)
+	}
+	return nil
+}
+
+// Load reads the configuration from the environment. signals are the
+// scored signals, whose weights it reads.
+func Load(signals []Signal) (*Config, error) {
+	return LoadWithOverrides(signals, nil)
+}
+
+// LoadWithOverrides reads the configuration like Load, with overrides taking
+// precedence over the environment for the keys it sets. An override holding
+// "" stands for an unset variable, so the setting gets its default.
+func LoadWithOverrides(signals []Signal, overrides map[string]string) (*Config, error) {
+	return loader{overrides: overrides, signals: signals}.load()
+}
+
+func (l loader) load() (*Config, error) {
+	dbURL := l.getenv("DATABASE_URL")
 	if dbURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
 
-	weatherKey := os.Getenv("OPENWEATHER_API_KEY")
-	if weatherKey == "" {
-		return nil, fmt.Errorf("OPENWEATHER_API_KEY is required")
-	}
+	// Optional: weather falls back to the keyless Open-Meteo provider
+	weatherKey := l.getenv("OPENWEATHER_API_KEY")
 
-	cfToken := os.Getenv("CLOUDFLARE_RADAR_TOKEN")
+	cfToken := l.getenv("CLOUDFLARE_RADAR_TOKEN")
 	if cfToken == "" {
 		return nil, fmt.Errorf("CLOUDFLARE_RADAR_TOKEN is required")
 	}
 
-	port := os.Getenv("PORT")
+	port := l.getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
 
-	origins := os.Getenv("ALLOWED_ORIGINS")
+	runInterval, err := l.envDuration("RUN_INTERVAL", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	if runInterval < time.Minute {
+		return nil, fmt.Errorf("RUN_INTERVAL must be at least 1m")
+	}
+	runBudget, err := l.envDuration("RUN_BUDGET", 60*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if runBudget < 10*time.Second || runBudget >= runInterval {
+		return nil, fmt.Errorf("RUN_BUDGET must be at least 10s and below RUN_INTERVAL")
+	}
+	schedule, err := l.loadSchedule(runInterval, runBudget)
+	if err != nil {
+		return nil, err
+	}
+	fetchConcurrency, err := l.envInt("FETCH_CONCURRENCY", 6)
+	if err != nil {
+		return nil, err
+	}
+	if fetchConcurrency < 1 {
+		return nil, fmt.Errorf("FETCH_CONCURRENCY must be at least 1")
+	}
+	retry, err := l.loadRetry()
+	if err != nil {
+		return nil, err
+	}
+	breaker, err := l.loadBreaker()
+	if err != nil {
+		return nil, err
+	}
+	rateLimits, err := l.loadRateLimits()
+	if err != nil {
+		return nil, err
+	}
+	openSky, err := l.loadOpenSky()
+	if err != nil {
+		return nil, err
+	}
+
+	// Base URL clients use to reach this API, for links built server-side
+	publicURL := strings.TrimSuffix(l.getenv("PUBLIC_URL"), "/")
+	if publicURL == "" {
+		publicURL = "https://api.usstrikeradar.com"
+	}
+
+	origins := l.getenv("ALLOWED_ORIGINS")
 	var allowedOrigins []string
 	if origins != "" {
 		allowedOrigins = strings.Split(origins, ",")
@@ -43,11 +479,1112 @@ func Load() (*Config, error) {
 		allowedOrigins = []string{"https://usstrikeradar.com"}
 	}
 
+	// Optional: admin routes are disabled when unset
+	adminToken := l.getenv("ADMIN_TOKEN")
+
+	weather, err := l.loadWeatherThresholds()
+	if err != nil {
+		return nil, err
+	}
+
+	// Tanker count scoring 90 while the tanker signal has no baseline yet;
+	// the curve approaches 100 beyond it rather than passing it
+	tankerSaturation, err := l.envInt("TANKER_SATURATION", 20)
+	if err != nil {
+		return nil, err
+	}
+	if tankerSaturation < 1 {
+		return nil, fmt.Errorf("TANKER_SATURATION must be at least 1")
+	}
+
+	disabled := l.disabledSignals()
+	weights, err := l.loadWeights(disabled)
+	if err != nil {
+		return nil, err
+	}
+
+	// With more stale signals than this, fallbacks are left out of total
+	// risk; the number of signals never degrades
+	degradedAfter, err := l.envInt("DEGRADED_AFTER", 4)
+	if err != nil {
+		return nil, err
+	}
+	if degradedAfter < 0 || degradedAfter > len(l.signals) {
+		return nil, fmt.Errorf("DEGRADED_AFTER must be between 0 and %d", len(l.signals))
+	}
+
+	dbPool, err := l.loadDBPool()
+	if err != nil {
+		return nil, err
+	}
+
+	// A feed with no successful read for this long stops counting against
+	// news coverage
+	newsFeedDeadAfter, err := l.envDuration("NEWS_FEED_DEAD_AFTER", 72*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if newsFeedDeadAfter < runInterval {
+		return nil, fmt.Errorf("NEWS_FEED_DEAD_AFTER must be at least RUN_INTERVAL")
+	}
+
+	// Only items published this recently count towards news; 0 counts
+	// whole feeds. Undated items always count.
+	newsLookback, err := l.envDuration("NEWS_LOOKBACK", 6*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if newsLookback < 0 {
+		return nil, fmt.Errorf("NEWS_LOOKBACK must not be negative")
+	}
+
+	pulseHonorDNT, err := l.envBool("PULSE_HONOR_DNT", true)
+	if err != nil {
+		return nil, err
+	}
+
+	pulsePrivacy, err := l.loadPulsePrivacy()
+	if err != nil {
+		return nil, err
+	}
+
+	// Records kept in memory for the admin log tail
+	logTailSize, err := l.envInt("LOG_TAIL_SIZE", 1000)
+	if err != nil {
+		return nil, err
+	}
+	if logTailSize < 1 {
+		return nil, fmt.Errorf("LOG_TAIL_SIZE must be at least 1")
+	}
+
+	tracks, err := l.loadTracks()
+	if err != nil {
+		return nil, err
+	}
+
+	compression, err := l.loadCompression()
+	if err != nil {
+		return nil, err
+	}
+
+	attention, err := l.loadAttention()
+	if err != nil {
+		return nil, err
+	}
+
+	connLocations, err := l.loadConnectivityLocations()
+	if err != nil {
+		return nil, err
+	}
+
+	// GDELT DOC API query the geopolitics signal counts coverage for
+	gdeltQuery := l.getenv("GDELT_QUERY")
+	if gdeltQuery == "" {
+		gdeltQuery = `iran (israel OR "united states" OR pentagon) (strike OR attack OR missile OR military)`
+	}
+
+	shipping, err := l.loadShipping()
+	if err != nil {
+		return nil, err
+	}
+
+	notam, err := l.loadNotam()
+	if err != nil {
+		return nil, err
+	}
+
+	// Credit line and license sent with the aggregator feed
+	indicator := Indicator{
+		Attribution: l.getenv("INDICATOR_ATTRIBUTION"),
+		License:     l.getenv("INDICATOR_LICENSE"),
+	}
+	if indicator.Attribution == "" {
+		indicator.Attribution = "US Strike Radar, https://usstrikeradar.com"
+	}
+	if indicator.License == "" {
+		indicator.License = "CC-BY-4.0"
+	}
+
+	archive, err := l.loadArchive()
+	if err != nil {
+		return nil, err
+	}
+	mirror, err := l.loadMirror(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	telegram, err := l.loadTelegram()
+	if err != nil {
+		return nil, err
+	}
+
+	dataCache, err := l.loadDataCache()
+	if err != nil {
+		return nil, err
+	}
+
+	tls := l.loadTLS()
+
+	// Where /metrics is served, on a listener of its own so it is never
+	// public unless an operator binds it so.
+	metricsAddr := l.getenv("METRICS_ADDR")
+	if metricsAddr == "" {
+		metricsAddr = "127.0.0.1:9100"
+	}
+
+	cors, err := l.loadCORS()
+	if err != nil {
+		return nil, err
+	}
+
+	httpCfg, err := l.loadHTTP()
+	if err != nil {
+		return nil, err
+	}
+
+	pizzaClusters, err := l.loadPizzaClusters()
+	if err != nil {
+		return nil, err
+	}
+
+	// Area the seismic signal reads USGS events for, Iran by default
+	seismicBox, err := l.envBoundingBox("SEISMIC_BBOX", BoundingBox{25, 44, 40, 63.5})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotMeta, err := l.loadSnapshotMeta()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
-		DatabaseURL:        dbURL,
-		OpenWeatherAPIKey:  weatherKey,
+		DatabaseURL:          dbURL,
+		OpenWeatherAPIKey:    weatherKey,
 		CloudflareRadarToken: cfToken,
-		Port:               port,
-		AllowedOrigins:     allowedOrigins,
+		Port:                 port,
+		RunInterval:          runInterval,
+		RunBudget:            runBudget,
+		Schedule:             schedule,
+		FetchConcurrency:     fetchConcurrency,
+		Retry:                retry,
+		Breaker:              breaker,
+		RateLimits:           rateLimits,
+		OpenSky:              openSky,
+		NewsFeedDeadAfter:    newsFeedDeadAfter,
+		NewsLookback:         newsLookback,
+		PublicURL:            publicURL,
+		AllowedOrigins:       allowedOrigins,
+		CORS:                 cors,
+		AdminToken:           adminToken,
+		Weather:              weather,
+		TankerSaturation:     tankerSaturation,
+		Weights:              weights,
+		Disabled:             disabled,
+		DegradedAfter:        degradedAfter,
+		DBPool:               dbPool,
+		PulseHonorDNT:        pulseHonorDNT,
+		PulsePrivacy:         pulsePrivacy,
+		LogTailSize:          logTailSize,
+		Tracks:               tracks,
+		Compression:          compression,
+		Archive:              archive,
+		Mirror:               mirror,
+		Telegram:             telegram,
+		DataCache:            dataCache,
+		TLS:                  tls,
+		MetricsAddr:          metricsAddr,
+		HTTP:                 httpCfg,
+		Attention:            attention,
+		Connectivity:         Connectivity{Locations: connLocations},
+		GDELTQuery:           gdeltQuery,
+		Shipping:             shipping,
+		Notam:                notam,
+		Indicator:            indicator,
+		PizzaClusters:        pizzaClusters,
+		SeismicBox:           seismicBox,
+		SensitiveDatesFile:   l.getenv("SENSITIVE_DATES_FILE"),
+		SnapshotMeta:         snapshotMeta,
 	}, nil
 }
+
+// LoadDBPool reads the DB_* pool settings on their own, for tools that only
+// need a database connection.
+func LoadDBPool() (DBPool, error) {
+	return loader{}.loadDBPool()
+}
+
+func (l loader) loadDBPool() (DBPool, error) {
+	var p DBPool
+	maxConns, err := l.envInt("DB_MAX_CONNS", 20)
+	if err != nil {
+		return p, err
+	}
+	minConns, err := l.envInt("DB_MIN_CONNS", 2)
+	if err != nil {
+		return p, err
+	}
+	if maxConns < 1 || minConns < 0 || minConns > maxConns {
+		return p, fmt.Errorf("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS (>= 1)")
+	}
+	p.MaxConns = int32(maxConns)
+	p.MinConns = int32(minConns)
+
+	if p.MaxConnLifetime, err = l.envDuration("DB_MAX_CONN_LIFETIME", 30*time.Minute); err != nil {
+		return p, err
+	}
+	if p.MaxConnIdleTime, err = l.envDuration("DB_MAX_CONN_IDLE_TIME", 5*time.Minute); err != nil {
+		return p, err
+	}
+	if p.ConnectTimeout, err = l.envDuration("DB_CONNECT_TIMEOUT", 10*time.Second); err != nil {
+		return p, err
+	}
+	if p.StatementTimeout, err = l.envDuration("DB_STATEMENT_TIMEOUT", 5*time.Second); err != nil {
+		return p, err
+	}
+	if p.ScanTimeout, err = l.envDuration("DB_SCAN_TIMEOUT", 2*time.Minute); err != nil {
+		return p, err
+	}
+	if p.ScanTimeout < 0 {
+		return p, fmt.Errorf("DB_SCAN_TIMEOUT must not be negative")
+	}
+	if p.SlowQuery, err = l.envDuration("DB_SLOW_QUERY", 500*time.Millisecond); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+func (l loader) loadDataCache() (DataCache, error) {
+	var c DataCache
+	var err error
+	if c.MaxAge, err = l.envDuration("DATA_MAX_AGE", 60*time.Second); err != nil {
+		return c, err
+	}
+	if c.SMaxAge, err = l.envDuration("DATA_S_MAXAGE", 300*time.Second); err != nil {
+		return c, err
+	}
+	if c.HotMaxAge, err = l.envDuration("DATA_HOT_MAX_AGE", 15*time.Second); err != nil {
+		return c, err
+	}
+	if c.HotSMaxAge, err = l.envDuration("DATA_HOT_S_MAXAGE", 30*time.Second); err != nil {
+		return c, err
+	}
+	if c.HotMinRisk, err = l.envInt("DATA_HOT_MIN_RISK", 61); err != nil {
+		return c, err
+	}
+	if c.MaxAge < 0 || c.SMaxAge < 0 || c.HotMaxAge < 0 || c.HotSMaxAge < 0 {
+		return c, fmt.Errorf("DATA_*MAX_AGE values must not be negative")
+	}
+	return c, nil
+}
+
+func (l loader) loadHTTP() (HTTP, error) {
+	var h HTTP
+	var err error
+	if h.ReadHeaderTimeout, err = l.envDuration("HTTP_READ_HEADER_TIMEOUT", 5*time.Second); err != nil {
+		return h, err
+	}
+	if h.ReadTimeout, err = l.envDuration("HTTP_READ_TIMEOUT", 5*time.Second); err != nil {
+		return h, err
+	}
+	if h.WriteTimeout, err = l.envDuration("HTTP_WRITE_TIMEOUT", 10*time.Second); err != nil {
+		return h, err
+	}
+	if h.IdleTimeout, err = l.envDuration("HTTP_IDLE_TIMEOUT", 60*time.Second); err != nil {
+		return h, err
+	}
+	if h.StreamTimeout, err = l.envDuration("HTTP_STREAM_TIMEOUT", time.Hour); err != nil {
+		return h, err
+	}
+	if h.StreamKeepAlive, err = l.envDuration("HTTP_STREAM_KEEPALIVE", 25*time.Second); err != nil {
+		return h, err
+	}
+	if h.MaxHeaderBytes, err = l.envInt("HTTP_MAX_HEADER_BYTES", 1<<20); err != nil {
+		return h, err
+	}
+	streams, err := l.envInt("HTTP2_MAX_CONCURRENT_STREAMS", 250)
+	if err != nil {
+		return h, err
+	}
+	if h.H2C, err = l.envBool("HTTP_H2C", false); err != nil {
+		return h, err
+	}
+	if h.ReadHeaderTimeout <= 0 || h.ReadTimeout <= 0 || h.WriteTimeout <= 0 || h.IdleTimeout <= 0 {
+		return h, fmt.Errorf("HTTP_*_TIMEOUT values must be positive")
+	}
+	if h.StreamTimeout < h.WriteTimeout {
+		return h, fmt.Errorf("HTTP_STREAM_TIMEOUT must be at least HTTP_WRITE_TIMEOUT")
+	}
+	if h.StreamKeepAlive <= 0 || h.StreamKeepAlive >= h.StreamTimeout {
+		return h, fmt.Errorf("HTTP_STREAM_KEEPALIVE must be positive and below HTTP_STREAM_TIMEOUT")
+	}
+	if h.MaxHeaderBytes < 4096 {
+		return h, fmt.Errorf("HTTP_MAX_HEADER_BYTES must be at least 4096")
+	}
+	if streams < 1 {
+		return h, fmt.Errorf("HTTP2_MAX_CONCURRENT_STREAMS must be at least 1")
+	}
+	h.MaxConcurrentStreams = uint32(streams)
+	return h, nil
+}
+
+func (l loader) loadTLS() TLS {
+	t := TLS{
+		Email:    l.getenv("TLS_ACME_EMAIL"),
+		CacheDir: l.getenv("TLS_CACHE_DIR"),
+		Port:     l.getenv("TLS_PORT"),
+		HTTPPort: l.getenv("TLS_HTTP_PORT"),
+	}
+	for _, d := range strings.Split(l.getenv("TLS_DOMAINS"), ",") {
+		if d = strings.TrimSpace(d); d != "" {
+			t.Domains = append(t.Domains, d)
+		}
+	}
+	if t.CacheDir == "" {
+		t.CacheDir = "autocert-cache"
+	}
+	if t.Port == "" {
+		t.Port = "443"
+	}
+	if t.HTTPPort == "" {
+		t.HTTPPort = "80"
+	}
+	return t
+}
+
+func (l loader) loadCORS() (CORS, error) {
+	c := CORS{
+		PublicOrigins: splitOrigins(l.getenv("CORS_PUBLIC_ORIGINS")),
+		AdminOrigins:  splitOrigins(l.getenv("CORS_ADMIN_ORIGINS")),
+	}
+	if c.PublicOrigins == nil {
+		c.PublicOrigins = []string{"*"}
+	}
+	for _, o := range c.AdminOrigins {
+		if o == "*" {
+			return c, fmt.Errorf("CORS_ADMIN_ORIGINS must list exact origins, not *")
+		}
+	}
+	return c, nil
+}
+
+func splitOrigins(s string) []string {
+	var origins []string
+	for _, o := range strings.Split(s, ",") {
+		if o = strings.TrimSuffix(strings.TrimSpace(o), "/"); o != "" {
+			origins = append(origins, o)
+		}
+	}
+	return origins
+}
+
+func (l loader) loadArchive() (Archive, error) {
+	a := Archive{
+		Endpoint:        strings.TrimSuffix(l.getenv("ARCHIVE_S3_ENDPOINT"), "/"),
+		Region:          l.getenv("ARCHIVE_S3_REGION"),
+		Bucket:          l.getenv("ARCHIVE_BUCKET"),
+		AccessKeyID:     l.getenv("ARCHIVE_ACCESS_KEY_ID"),
+		SecretAccessKey: l.getenv("ARCHIVE_SECRET_ACCESS_KEY"),
+		Prefix:          l.getenv("ARCHIVE_PREFIX"),
+		PublicURL:       strings.TrimSuffix(l.getenv("ARCHIVE_PUBLIC_URL"), "/"),
+	}
+	if !a.Enabled() {
+		return a, nil
+	}
+	if a.Endpoint == "" || a.AccessKeyID == "" || a.SecretAccessKey == "" {
+		return a, fmt.Errorf("ARCHIVE_BUCKET requires ARCHIVE_S3_ENDPOINT, ARCHIVE_ACCESS_KEY_ID and ARCHIVE_SECRET_ACCESS_KEY")
+	}
+	if a.Region == "" {
+		a.Region = "auto"
+	}
+	if a.Prefix == "" {
+		a.Prefix = "datasets/"
+	}
+	if a.PublicURL == "" {
+		a.PublicURL = a.Endpoint + "/" + a.Bucket
+	}
+	return a, nil
+}
+
+func (l loader) loadMirror(a Archive) (Mirror, error) {
+	m := Mirror{
+		Bucket:       l.getenv("MIRROR_BUCKET"),
+		Prefix:       l.getenv("MIRROR_PREFIX"),
+		CacheControl: l.getenv("MIRROR_CACHE_CONTROL"),
+		PublicURL:    strings.TrimSuffix(l.getenv("MIRROR_PUBLIC_URL"), "/"),
+	}
+	if !m.Enabled() {
+		return m, nil
+	}
+	if a.Endpoint == "" || a.AccessKeyID == "" || a.SecretAccessKey == "" {
+		return m, fmt.Errorf("MIRROR_BUCKET requires ARCHIVE_S3_ENDPOINT, ARCHIVE_ACCESS_KEY_ID and ARCHIVE_SECRET_ACCESS_KEY")
+	}
+	if m.CacheControl == "" {
+		// Short enough that the mirror is never more than a run behind
+		m.CacheControl = "public, max-age=60"
+	}
+	if m.PublicURL == "" {
+		m.PublicURL = a.Endpoint + "/" + m.Bucket
+	}
+	return m, nil
+}
+
+// loadPizzaClusters reads PIZZA_CLUSTERS_FILE, a JSON array of facilities
+// such as
+//
+//	[{"name": "CENTCOM Tampa", "timezone": "America/New_York",
+//	  "places": [{"name": "...", "place_id": "...", "address": "..."}]}]
+//
+// which replaces the default Pentagon cluster; list it too to keep it.
+func (l loader) loadPizzaClusters() ([]PizzaCluster, error) {
+	path := l.getenv("PIZZA_CLUSTERS_FILE")
+	if path == "" {
+		return DefaultPizzaClusters(), nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("PIZZA_CLUSTERS_FILE: %w", err)
+	}
+	var entries []struct {
+		Name     string       `json:"name"`
+		Timezone string       `json:"timezone"`
+		Places   []PizzaPlace `json:"places"`
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("PIZZA_CLUSTERS_FILE: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("PIZZA_CLUSTERS_FILE must list at least one cluster")
+	}
+	clusters := make([]PizzaCluster, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.Name == "" || seen[e.Name] {
+			return nil, fmt.Errorf("PIZZA_CLUSTERS_FILE: cluster names must be set and unique, got %q", e.Name)
+		}
+		seen[e.Name] = true
+		if len(e.Places) == 0 {
+			return nil, fmt.Errorf("PIZZA_CLUSTERS_FILE: cluster %q has no places", e.Name)
+		}
+		// The timezone is required: the server's own zone has nothing to do
+		// with when a facility works late
+		if e.Timezone == "" {
+			return nil, fmt.Errorf("PIZZA_CLUSTERS_FILE: cluster %q has no timezone", e.Name)
+		}
+		loc, err := time.LoadLocation(e.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("PIZZA_CLUSTERS_FILE: cluster %q: %w", e.Name, err)
+		}
+		clusters = append(clusters, PizzaCluster{Name: e.Name, Location: loc, Places: e.Places})
+	}
+	return clusters, nil
+}
+
+// maxSnapshotMetaSize bounds the static metadata, which every snapshot
+// carries.
+const maxSnapshotMetaSize = 64 << 10
+
+// loadSnapshotMeta reads the snapshot metadata, a JSON object given inline
+// in SNAPSHOT_META or in the file SNAPSHOT_META_FILE names.
+func (l loader) loadSnapshotMeta() (map[string]any, error) {
+	inline, path := l.getenv("SNAPSHOT_META"), l.getenv("SNAPSHOT_META_FILE")
+	key, raw := "SNAPSHOT_META", []byte(inline)
+	switch {
+	case inline != "" && path != "":
+		return nil, fmt.Errorf("SNAPSHOT_META and SNAPSHOT_META_FILE can't both be set")
+	case path != "":
+		var err error
+		if raw, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("SNAPSHOT_META_FILE: %w", err)
+		}
+		key = "SNAPSHOT_META_FILE"
+	case inline == "":
+		return nil, nil
+	}
+	if len(raw) > maxSnapshotMetaSize {
+		return nil, fmt.Errorf("%s must be at most %d bytes", key, maxSnapshotMetaSize)
+	}
+	var meta map[string]any
+	if err := json.Unmarshal(raw, &meta); err != nil || meta == nil {
+		return nil, fmt.Errorf("%s must be a JSON object", key)
+	}
+	return meta, nil
+}
+
+func (l loader) loadTelegram() (Telegram, error) {
+	t := Telegram{
+		BotToken: l.getenv("TELEGRAM_BOT_TOKEN"),
+		ChatID:   l.getenv("TELEGRAM_CHAT_ID"),
+	}
+	if (t.BotToken == "") != (t.ChatID == "") {
+		return t, fmt.Errorf("TELEGRAM_BOT_TOKEN and TELEGRAM_CHAT_ID must be set together")
+	}
+	return t, nil
+}
+
+func (l loader) loadRetry() (Retry, error) {
+	var r Retry
+	var err error
+	if r.Attempts, err = l.envInt("FETCH_RETRY_ATTEMPTS", 3); err != nil {
+		return r, err
+	}
+	if r.Attempts < 1 {
+		return r, fmt.Errorf("FETCH_RETRY_ATTEMPTS must be at least 1")
+	}
+	if r.Backoff, err = l.envDuration("FETCH_RETRY_BACKOFF", 500*time.Millisecond); err != nil {
+		return r, err
+	}
+	if r.MaxBackoff, err = l.envDuration("FETCH_RETRY_MAX_BACKOFF", 5*time.Second); err != nil {
+		return r, err
+	}
+	if r.Backoff <= 0 || r.MaxBackoff < r.Backoff {
+		return r, fmt.Errorf("FETCH_RETRY_BACKOFF must be positive and at most FETCH_RETRY_MAX_BACKOFF")
+	}
+	// 429 is left out by default: the quotas we hit reset daily, not in seconds
+	v := l.getenv("FETCH_RETRY_STATUSES")
+	if v == "" {
+		v = "502,503,504"
+	}
+	for _, code := range strings.Split(v, ",") {
+		if code = strings.TrimSpace(code); code == "" {
+			continue
+		}
+		n, err := strconv.Atoi(code)
+		if err != nil || n < 400 || n > 599 {
+			return r, fmt.Errorf("FETCH_RETRY_STATUSES: invalid status %q", code)
+		}
+		r.Statuses = append(r.Statuses, n)
+	}
+	return r, nil
+}
+
+func (l loader) loadSchedule(runInterval, runBudget time.Duration) (Schedule, error) {
+	var s Schedule
+	var err error
+	if s.MinInterval, err = l.envDuration("RUN_INTERVAL_MIN", min(10*time.Minute, runInterval)); err != nil {
+		return s, err
+	}
+	if s.MinInterval < time.Minute || s.MinInterval > runInterval {
+		return s, fmt.Errorf("RUN_INTERVAL_MIN must be between 1m and RUN_INTERVAL")
+	}
+	if runBudget >= s.MinInterval {
+		return s, fmt.Errorf("RUN_BUDGET must be below RUN_INTERVAL_MIN")
+	}
+	// The high band by default
+	if s.RiskThreshold, err = l.envInt("RUN_INTERVAL_RISK_THRESHOLD", 61); err != nil {
+		return s, err
+	}
+	if s.RiskThreshold < 1 || s.RiskThreshold > 100 {
+		return s, fmt.Errorf("RUN_INTERVAL_RISK_THRESHOLD must be 1-100")
+	}
+	return s, nil
+}
+
+func (l loader) loadBreaker() (Breaker, error) {
+	var b Breaker
+	var err error
+	if b.Threshold, err = l.envInt("BREAKER_THRESHOLD", 3); err != nil {
+		return b, err
+	}
+	if b.Threshold < 1 {
+		return b, fmt.Errorf("BREAKER_THRESHOLD must be at least 1")
+	}
+	if b.Cooldown, err = l.envDuration("BREAKER_COOLDOWN", time.Hour); err != nil {
+		return b, err
+	}
+	if b.Cooldown < 0 {
+		return b, fmt.Errorf("BREAKER_COOLDOWN must not be negative")
+	}
+	return b, nil
+}
+
+// loadRateLimits reads FETCH_RATE_LIMITS, a comma-separated list of
+// host=interval[/burst] entries, or "none" for no limits. By default
+// OpenSky gets one request every 2s, the spacing anonymous access needs
+// between the aviation and tanker queries, and GDELT one every 5s as its
+// API asks.
+func (l loader) loadRateLimits() ([]RateLimit, error) {
+	v := l.getenv("FETCH_RATE_LIMITS")
+	if v == "" {
+		v = "opensky-network.org=2s,api.gdeltproject.org=5s"
+	}
+	if v == "none" {
+		return nil, nil
+	}
+	var limits []RateLimit
+	for _, entry := range strings.Split(v, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		host, spec, ok := strings.Cut(entry, "=")
+		if !ok || host == "" {
+			return nil, fmt.Errorf("FETCH_RATE_LIMITS: want host=interval[/burst], got %q", entry)
+		}
+		rl := RateLimit{Host: strings.ToLower(host), Burst: 1}
+		interval, burst, hasBurst := strings.Cut(spec, "/")
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("FETCH_RATE_LIMITS: invalid interval %q for %s", interval, host)
+		}
+		rl.Interval = d
+		if hasBurst {
+			if rl.Burst, err = strconv.Atoi(burst); err != nil || rl.Burst < 1 {
+				return nil, fmt.Errorf("FETCH_RATE_LIMITS: invalid burst %q for %s", burst, host)
+			}
+		}
+		limits = append(limits, rl)
+	}
+	return limits, nil
+}
+
+func (l loader) loadOpenSky() (OpenSky, error) {
+	o := OpenSky{
+		ClientID:     l.getenv("OPENSKY_CLIENT_ID"),
+		ClientSecret: l.getenv("OPENSKY_CLIENT_SECRET"),
+	}
+	if (o.ClientID == "") != (o.ClientSecret == "") {
+		return o, fmt.Errorf("OPENSKY_CLIENT_ID and OPENSKY_CLIENT_SECRET must be set together")
+	}
+	var err error
+	if o.AviationBox, err = l.envBoundingBox("OPENSKY_AVIATION_BBOX", BoundingBox{25, 44, 40, 64}); err != nil {
+		return o, err
+	}
+	if o.TankerBox, err = l.envBoundingBox("OPENSKY_TANKER_BBOX", BoundingBox{20, 40, 40, 65}); err != nil {
+		return o, err
+	}
+	return o, nil
+}
+
+// loadShipping reads AISHUB_USERNAME and HORMUZ_BBOX, which defaults to the
+// strait between the Gulf of Oman and the Persian Gulf.
+func (l loader) loadShipping() (Shipping, error) {
+	box, err := l.envBoundingBox("HORMUZ_BBOX", BoundingBox{25.8, 55.6, 27.0, 57.2})
+	if err != nil {
+		return Shipping{}, err
+	}
+	return Shipping{AISHubUsername: l.getenv("AISHUB_USERNAME"), Box: box}, nil
+}
+
+// loadNotam reads FAA_NOTAM_CLIENT_ID, FAA_NOTAM_CLIENT_SECRET and
+// NOTAM_LOCATIONS, ICAO codes that default to the FIRs of Iran, Israel,
+// their neighbours and the Gulf states, plus the Tehran and Tel Aviv
+// international airports.
+func (l loader) loadNotam() (Notam, error) {
+	n := Notam{
+		ClientID:     l.getenv("FAA_NOTAM_CLIENT_ID"),
+		ClientSecret: l.getenv("FAA_NOTAM_CLIENT_SECRET"),
+	}
+	if (n.ClientID == "") != (n.ClientSecret == "") {
+		return n, fmt.Errorf("FAA_NOTAM_CLIENT_ID and FAA_NOTAM_CLIENT_SECRET must be set together")
+	}
+	v := l.getenv("NOTAM_LOCATIONS")
+	if v == "" {
+		v = "OIIX,LLLL,ORBB,OJAC,OSTT,OLBB,OKAC,OBBB,OTDF,OMAE,OEJD,OOMM,OIIE,LLBG"
+	}
+	for _, code := range strings.Split(v, ",") {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if len(code) != 4 || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+			return n, fmt.Errorf("NOTAM_LOCATIONS: invalid ICAO code %q", code)
+		}
+		n.Locations = append(n.Locations, code)
+	}
+	if len(n.Locations) == 0 {
+		return n, fmt.Errorf("NOTAM_LOCATIONS must list at least one ICAO code")
+	}
+	return n, nil
+}
+
+// envBoundingBox reads a box given as "latmin,lonmin,latmax,lonmax".
+func (l loader) envBoundingBox(key string, def BoundingBox) (BoundingBox, error) {
+	v := l.getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	parts := strings.Split(v, ",")
+	if len(parts) != 4 {
+		return def, fmt.Errorf("%s: want latmin,lonmin,latmax,lonmax, got %q", key, v)
+	}
+	var n [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return def, fmt.Errorf("%s: invalid number %q", key, p)
+		}
+		n[i] = f
+	}
+	b := BoundingBox{LatMin: n[0], LonMin: n[1], LatMax: n[2], LonMax: n[3]}
+	if b.LatMin < -90 || b.LatMax > 90 || b.LatMin >= b.LatMax ||
+		b.LonMin < -180 || b.LonMax > 180 || b.LonMin >= b.LonMax {
+		return def, fmt.Errorf("%s: box %q is out of range or empty", key, v)
+	}
+	return b, nil
+}
+
+func (l loader) loadConnectivityLocations() ([]string, error) {
+	v := l.getenv("CONNECTIVITY_LOCATIONS")
+	if v == "" {
+		v = "IL,LB,IQ"
+	}
+	var codes []string
+	for _, cc := range strings.Split(v, ",") {
+		cc = strings.ToUpper(strings.TrimSpace(cc))
+		if cc == "" {
+			continue
+		}
+		if len(cc) != 2 || cc[0] < 'A' || cc[0] > 'Z' || cc[1] < 'A' || cc[1] > 'Z' {
+			return nil, fmt.Errorf("CONNECTIVITY_LOCATIONS: invalid country code %q", cc)
+		}
+		if cc != "IR" {
+			codes = append(codes, cc)
+		}
+	}
+	return codes, nil
+}
+
+func (l loader) loadAttention() (Attention, error) {
+	var a Attention
+	var err error
+	if a.Wikipedia, err = l.envBool("ATTENTION_WIKIPEDIA", false); err != nil {
+		return a, err
+	}
+	articles := l.getenv("ATTENTION_WIKIPEDIA_ARTICLES")
+	if articles == "" {
+		articles = "Iran,Iran–United_States_relations,Iran–Israel_proxy_conflict"
+	}
+	for _, title := range strings.Split(articles, ",") {
+		// Page titles use underscores in the pageviews API
+		if title = strings.ReplaceAll(strings.TrimSpace(title), " ", "_"); title != "" {
+			a.WikipediaArticles = append(a.WikipediaArticles, title)
+		}
+	}
+	if a.Wikipedia && len(a.WikipediaArticles) == 0 {
+		return a, fmt.Errorf("ATTENTION_WIKIPEDIA requires at least one article")
+	}
+	return a, nil
+}
+
+func (l loader) loadPulsePrivacy() (PulsePrivacy, error) {
+	var p PulsePrivacy
+	var err error
+	if p.MinCount, err = l.envInt("PULSE_MIN_COUNT", 0); err != nil {
+		return p, err
+	}
+	if p.MinCount < 0 || p.MinCount > 100 {
+		return p, fmt.Errorf("PULSE_MIN_COUNT must be between 0 and 100")
+	}
+	if p.RoundTo, err = l.envInt("PULSE_ROUND_TO", 0); err != nil {
+		return p, err
+	}
+	if p.RoundTo < 0 || p.RoundTo > 100 {
+		return p, fmt.Errorf("PULSE_ROUND_TO must be between 0 and 100")
+	}
+	return p, nil
+}
+
+func (l loader) loadCompression() (Compression, error) {
+	var c Compression
+	var err error
+	if c.Enabled, err = l.envBool("COMPRESSION_ENABLED", true); err != nil {
+		return c, err
+	}
+	if c.MinBytes, err = l.envInt("COMPRESSION_MIN_BYTES", 1024); err != nil {
+		return c, err
+	}
+	if c.MinBytes < 0 {
+		return c, fmt.Errorf("COMPRESSION_MIN_BYTES must not be negative")
+	}
+	return c, nil
+}
+
+func (l loader) loadTracks() (Tracks, error) {
+	var t Tracks
+	var err error
+	if t.Enabled, err = l.envBool("TRACKS_ENABLED", false); err != nil {
+		return t, err
+	}
+	if t.Retention, err = l.envDuration("TRACKS_RETENTION", 48*time.Hour); err != nil {
+		return t, err
+	}
+	if t.Retention < time.Hour {
+		return t, fmt.Errorf("TRACKS_RETENTION must be at least 1h")
+	}
+	return t, nil
+}
+
+// loadWeights takes each WEIGHT_<NAME> as given and shares what is left
+// among the unset signals in proportion to their defaults, so an operator
+// can set a few weights, or a full set written for fewer signals, and still
+// sum to 1. Disabled signals then get no weight, and the others are scaled
+// up to make up for it.
+func (l loader) loadWeights(disabled map[string]bool) (Weights, error) {
+	w := DefaultWeights(l.signals)
+	var set, unset float64
+	anySet := false
+	for _, sig := range l.signals {
+		key := weightKey(sig.Name)
+		if l.getenv(key) == "" {
+			unset += sig.Weight
+			continue
+		}
+		anySet = true
+		v, err := l.envFloat(key, sig.Weight)
+		if err != nil {
+			return w, err
+		}
+		if v < 0 || v > 1 {
+			return w, fmt.Errorf("%s must be between 0 and 1, got %g", key, v)
+		}
+		w[sig.Name] = v
+		set += v
+	}
+	if set > 1.001 {
+		return w, fmt.Errorf("WEIGHT_* settings sum to %.3f, above 1", set)
+	}
+	if anySet && unset > 0 {
+		rest := max(1-set, 0)
+		for _, sig := range l.signals {
+			if l.getenv(weightKey(sig.Name)) == "" {
+				w[sig.Name] = sig.Weight / unset * rest
+			}
+		}
+	}
+	if err := w.Validate(); err != nil {
+		return w, fmt.Errorf("WEIGHT_*: %w", err)
+	}
+
+	var off float64
+	for name := range disabled {
+		off += w[name]
+		w[name] = 0
+	}
+	if off > 0 {
+		if off > 0.999 {
+			return w, fmt.Errorf("WEIGHT_*: every weighted signal is disabled")
+		}
+		for name := range w {
+			w[name] /= 1 - off
+		}
+	}
+	return w, nil
+}
+
+// disabledSignals returns the signals missing a setting they require.
+func (l loader) disabledSignals() map[string]bool {
+	disabled := map[string]bool{}
+	for _, sig := range l.signals {
+		for _, key := range sig.Requires {
+			if l.getenv(key) == "" {
+				disabled[sig.Name] = true
+			}
+		}
+	}
+	return disabled
+}
+
+// weightKey is the setting holding a signal's weight.
+func weightKey(signal string) string {
+	return "WEIGHT_" + strings.ToUpper(signal)
+}
+
+func (l loader) loadWeatherThresholds() (WeatherThresholds, error) {
+	var t WeatherThresholds
+	var err error
+	if t.ClearVisibility, err = l.envInt("WEATHER_CLEAR_VISIBILITY", 10000); err != nil {
+		return t, err
+	}
+	if t.MinVisibility, err = l.envInt("WEATHER_MIN_VISIBILITY", 3000); err != nil {
+		return t, err
+	}
+	if t.ClearClouds, err = l.envInt("WEATHER_CLEAR_CLOUDS", 25); err != nil {
+		return t, err
+	}
+	if t.MaxClouds, err = l.envInt("WEATHER_MAX_CLOUDS", 85); err != nil {
+		return t, err
+	}
+	if t.MaxWind, err = l.envFloat("WEATHER_MAX_WIND", 15); err != nil {
+		return t, err
+	}
+	if t.DustCodes, err = l.envConditionCodes("WEATHER_DUST_CODES", []int{731, 751, 761, 762}); err != nil {
+		return t, err
+	}
+	if t.HazeCodes, err = l.envConditionCodes("WEATHER_HAZE_CODES", []int{711, 721}); err != nil {
+		return t, err
+	}
+	if t.MinVisibility >= t.ClearVisibility {
+		return t, fmt.Errorf("WEATHER_MIN_VISIBILITY must be below WEATHER_CLEAR_VISIBILITY")
+	}
+	if t.ClearClouds >= t.MaxClouds {
+		return t, fmt.Errorf("WEATHER_CLEAR_CLOUDS must be below WEATHER_MAX_CLOUDS")
+	}
+	if t.MaxWind <= 0 {
+		return t, fmt.Errorf("WEATHER_MAX_WIND must be positive")
+	}
+	return t, nil
+}
+
+// envConditionCodes reads a comma-separated list of OpenWeather condition
+// IDs, or "none" for an empty list.
+func (l loader) envConditionCodes(key string, def []int) ([]int, error) {
+	v := l.getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	if v == "none" {
+		return nil, nil
+	}
+	var codes []int
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 200 || n > 804 {
+			return nil, fmt.Errorf("%s: invalid condition code %q", key, s)
+		}
+		codes = append(codes, n)
+	}
+	return codes, nil
+}
+
+// CheckOverrideKey returns why key can't be stored as an override, or nil
+// if it can. Keys are the environment variables the configuration reads,
+// so a misspelt one is refused rather than stored to no effect. The
+// database and log tail settings are excluded because they are read
+// before overrides are. signals are the scored signals, as given to Load.
+func CheckOverrideKey(signals []Signal, key string) error {
+	if key == "" {
+		return fmt.Errorf("key is required")
+	}
+	if key == "DATABASE_URL" || key == "LOG_TAIL_SIZE" || strings.HasPrefix(key, "DB_") {
+		return fmt.Errorf("%s can't be overridden: it is read before overrides are loaded", key)
+	}
+	keys, err := settingKeys(signals)
+	if err != nil {
+		return err
+	}
+	if !keys[key] {
+		return fmt.Errorf("%s is not a setting", key)
+	}
+	return nil
+}
+
+// secretKeys are the settings holding credentials, which are never echoed
+// back once stored.
+var secretKeys = map[string]bool{
+	"ADMIN_TOKEN":               true,
+	"AISHUB_USERNAME":           true,
+	"ARCHIVE_ACCESS_KEY_ID":     true,
+	"ARCHIVE_SECRET_ACCESS_KEY": true,
+	"CLOUDFLARE_RADAR_TOKEN":    true,
+	"FAA_NOTAM_CLIENT_ID":       true,
+	"FAA_NOTAM_CLIENT_SECRET":   true,
+	"OPENSKY_CLIENT_ID":         true,
+	"OPENSKY_CLIENT_SECRET":     true,
+	"OPENWEATHER_API_KEY":       true,
+	"TELEGRAM_BOT_TOKEN":        true,
+}
+
+// SecretKey reports whether the setting key holds a credential.
+func SecretKey(key string) bool {
+	return secretKeys[key]
+}
+
+// Defaults loads the configuration from settings alone, ignoring the
+// environment, so every setting they leave out takes its default. Tests use
+// it to run with production defaults whatever the developer's shell holds.
+func Defaults(signals []Signal, settings map[string]string) (*Config, error) {
+	return loader{overrides: settings, census: map[string]bool{}, signals: signals}.load()
+}
+
+// settingKeys returns every environment variable the configuration reads,
+// found by loading it from defaults and recording the keys asked for.
+// Every setting is read on each load, whatever the others hold.
+func settingKeys(signals []Signal) (map[string]bool, error) {
+	l := loader{
+		overrides: map[string]string{"DATABASE_URL": "postgres://localhost/aegis", "CLOUDFLARE_RADAR_TOKEN": "census"},
+		census:    map[string]bool{},
+		signals:   signals,
+	}
+	if _, err := l.load(); err != nil {
+		return nil, fmt.Errorf("listing settings: %w", err)
+	}
+	return l.census, nil
+}
+
+// loader reads settings from the environment, consulting overrides first.
+// A census loader reads only its overrides and records each key asked for.
+type loader struct {
+	overrides map[string]string
+	census    map[string]bool
+	signals   []Signal
+}
+
+func (l loader) getenv(key string) string {
+	if l.census != nil {
+		l.census[key] = true
+	}
+	if v, ok := l.overrides[key]; ok {
+		return v
+	}
+	if l.census != nil {
+		return ""
+	}
+	return os.Getenv(key)
+}
+
+// envInt reads an optional integer environment variable.
+func (l loader) envInt(key string, def int) (int, error) {
+	v := l.getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid integer %q", key, v)
+	}
+	return n, nil
+}
+
+// envBool reads an optional boolean environment variable.
+func (l loader) envBool(key string, def bool) (bool, error) {
+	v := l.getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: invalid boolean %q", key, v)
+	}
+	return b, nil
+}
+
+// envDuration reads an optional duration environment variable (e.g. "30s").
+func (l loader) envDuration(key string, def time.Duration) (time.Duration, error) {
+	v := l.getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid duration %q", key, v)
+	}
+	return d, nil
+}
+
+// envFloat reads an optional float environment variable.
+func (l loader) envFloat(key string, def float64) (float64, error) {
+	v := l.getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid number %q", key, v)
+	}
+	return f, nil
+}
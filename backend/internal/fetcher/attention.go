@@ -0,0 +1,119 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// wikipediaBaselineDays is how many days before the latest one make up the
+// pageview baseline.
+const wikipediaBaselineDays = 14
+
+func (f *Fetcher) fetchAttention(ctx context.Context) (model.AttentionData, map[string]any, error) {
+	data := model.AttentionData{
+		Components: []model.AttentionComponent{},
+		Timestamp:  time.Now().Format(time.RFC3339),
+	}
+	if f.cfg.Attention.Wikipedia {
+		c, err := f.fetchWikipediaAttention(ctx, f.cfg.Attention.WikipediaArticles)
+		if err != nil {
+			return model.AttentionData{}, nil, err
+		}
+		data.Components = append(data.Components, c)
+	}
+	return data, structToMap(data), nil
+}
+
+// fetchWikipediaAttention compares the latest complete day of pageviews,
+// summed over articles, to the mean of the days before it.
+func (f *Fetcher) fetchWikipediaAttention(ctx context.Context, articles []string) (model.AttentionComponent, error) {
+	slog.Info("fetching wikipedia pageviews", "articles", len(articles))
+
+	// Daily counts for yesterday usually land a few hours into the UTC day,
+	// so ask for a day extra and use whatever the latest returned day is.
+	end := time.Now().UTC().AddDate(0, 0, -1)
+	start := end.AddDate(0, 0, -(wikipediaBaselineDays + 1))
+	daily := map[string]int{}
+	for _, article := range articles {
+		views, err := f.wikipediaPageviews(ctx, article, start, end)
+		if err != nil {
+			return model.AttentionComponent{}, err
+		}
+		for day, n := range views {
+			daily[day] += n
+		}
+	}
+
+	latest := ""
+	for day := range daily {
+		if day > latest {
+			latest = day
+		}
+	}
+	baseline, days := 0, 0
+	for day, n := range daily {
+		if day != latest {
+			baseline += n
+			days++
+		}
+	}
+	if days == 0 || baseline == 0 {
+		return model.AttentionComponent{}, failure(KindParse, "wikipedia pageviews: no baseline days returned")
+	}
+
+	mean := float64(baseline) / float64(days)
+	ratio := math.Round(float64(daily[latest])/mean*100) / 100
+	slog.Info("wikipedia pageviews", "day", latest, "views", daily[latest], "baseline", int(mean), "ratio", ratio)
+	return model.AttentionComponent{
+		Source: "wikipedia",
+		Ratio:  ratio,
+		Detail: fmt.Sprintf("%d views vs %d/day", daily[latest], int(mean)),
+	}, nil
+}
+
+// wikipediaPageviews returns one English Wikipedia article's daily user
+// pageviews between start and end, keyed by YYYYMMDD.
+func (f *Fetcher) wikipediaPageviews(ctx context.Context, article string, start, end time.Time) (map[string]int, error) {
+	u := "https://wikimedia.org/api/rest_v1/metrics/pageviews/per-article/en.wikipedia.org/all-access/user/" +
+		url.PathEscape(article) + "/daily/" + start.Format("20060102") + "/" + end.Format("20060102")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, failure(KindUnknown, "wikipedia request: %w", err)
+	}
+	// Wikimedia rejects requests without an identifying agent
+	req.Header.Set("User-Agent", "StrikeRadar/1.0 (https://usstrikeradar.com)")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, failure(KindNetwork, "wikipedia request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, statusFailure("wikipedia pageviews API error for "+article, resp.StatusCode)
+	}
+
+	var result struct {
+		Items []struct {
+			Timestamp string `json:"timestamp"`
+			Views     int    `json:"views"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, failure(KindParse, "wikipedia parse: %w", err)
+	}
+	views := make(map[string]int, len(result.Items))
+	for _, item := range result.Items {
+		if len(item.Timestamp) >= 8 {
+			views[item.Timestamp[:8]] = item.Views
+		}
+	}
+	return views, nil
+}
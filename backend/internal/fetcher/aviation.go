@@ -1,8 +1,8 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"log/slog"
 	"math"
@@ -13,31 +13,33 @@ import (
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
-func (f *Fetcher) fetchAviation() (model.AviationData, map[string]any, error) {
+func (f *Fetcher) fetchAviation(ctx context.Context) (model.AviationData, map[string]any, error) {
 	slog.Info("fetching aviation data")
 
-	resp, err := f.client.Get("https://opensky-network.org/api/states/all?lamin=25&lomin=44&lamax=40&lomax=64")
+	resp, err := f.openSkyGet(ctx, "opensky", openSkyStatesURL(f.cfg.OpenSky.AviationBox))
 	if err != nil {
-		return model.AviationData{}, nil, fmt.Errorf("opensky request: %w", err)
+		return model.AviationData{}, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return model.AviationData{}, nil, fmt.Errorf("opensky API error: %d", resp.StatusCode)
+		return model.AviationData{}, nil, statusFailure("opensky API error", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return model.AviationData{}, nil, fmt.Errorf("opensky read body: %w", err)
+		return model.AviationData{}, nil, failure(KindNetwork, "opensky read body: %w", err)
 	}
 
 	var data map[string]any
 	if err := json.Unmarshal(body, &data); err != nil {
-		return model.AviationData{}, nil, fmt.Errorf("opensky parse: %w", err)
+		return model.AviationData{}, nil, failure(KindParse, "opensky parse: %w", err)
 	}
 
 	civilCount := 0
 	var airlines []string
+	var carriers []string
+	var positions []model.Position
 
 	if states, ok := data["states"].([]any); ok {
 		for _, s := range states {
@@ -67,17 +69,23 @@ func (f *Fetcher) fetchAviation() (model.AviationData, map[string]any, error) {
 			}
 
 			civilCount++
+			if pos, ok := statePosition(aircraft, icao, callsign); ok {
+				positions = append(positions, pos)
+			}
 			if len(callsign) >= 3 {
 				code := callsign[:3]
 				if !sliceContains(airlines, code) {
 					airlines = append(airlines, code)
 				}
+				if model.MajorCarrier(code) && !sliceContains(carriers, code) {
+					carriers = append(carriers, code)
+				}
 			}
 		}
 	}
 
 	risk := int(math.Max(3, 95-math.Round(float64(civilCount)*0.8)))
-	slog.Info("aviation result", "aircraft", civilCount, "airlines", len(airlines), "risk", risk)
+	slog.Info("aviation result", "aircraft", civilCount, "airlines", len(airlines), "major_carriers", carriers, "risk", risk)
 
 	if len(airlines) > 10 {
 		airlines = airlines[:10]
@@ -88,12 +96,30 @@ func (f *Fetcher) fetchAviation() (model.AviationData, map[string]any, error) {
 		AircraftCount: civilCount,
 		AirlineCount:  len(airlines),
 		Airlines:      airlines,
+		MajorCarriers: carriers,
 		Timestamp:     now.Format(time.RFC3339),
+		Positions:     positions,
 	}
 	rawMap := structToMap(result)
 	return result, rawMap, nil
 }
 
+// statePosition reads the location fields of an OpenSky state vector
+// (longitude 5, latitude 6, barometric altitude 7, true track 10).
+func statePosition(state []any, icao, callsign string) (model.Position, bool) {
+	if len(state) < 11 {
+		return model.Position{}, false
+	}
+	lon, okLon := state[5].(float64)
+	lat, okLat := state[6].(float64)
+	if !okLon || !okLat {
+		return model.Position{}, false
+	}
+	alt, _ := state[7].(float64)
+	heading, _ := state[10].(float64)
+	return model.Position{ICAO: icao, Callsign: callsign, Lat: lat, Lon: lon, Altitude: alt, Heading: heading}, true
+}
+
 func sliceContains(slice []string, item string) bool {
 	for _, s := range slice {
 		if s == item {
@@ -0,0 +1,131 @@
+package fetcher
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// breaker is one upstream's circuit breaker. It opens after
+// cfg.Threshold consecutive failures and stays open for cfg.Cooldown; the
+// first fetch after that is a trial that closes it on success or reopens
+// it on failure.
+type breaker struct {
+	source string
+	cfg    config.Breaker
+
+	mu        sync.Mutex
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	lastError string
+}
+
+// allow reports whether a fetch may go ahead, or a KindCircuitOpen error
+// explaining why not.
+func (b *breaker) allow(now time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	retryAt := b.openedAt.Add(b.cfg.Cooldown)
+	if now.Before(retryAt) {
+		return failure(KindCircuitOpen, "%s: circuit open after %d failures, retrying at %s",
+			b.source, b.failures, retryAt.UTC().Format(time.RFC3339))
+	}
+	if b.trial {
+		// Another signal on this upstream is already making the trial fetch
+		return failure(KindCircuitOpen, "%s: circuit half-open, trial fetch in progress", b.source)
+	}
+	b.trial = true
+	return nil
+}
+
+// record updates the breaker with a fetch outcome.
+func (b *breaker) record(err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if err == nil {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		b.lastError = ""
+		return
+	}
+	b.failures++
+	b.lastError = err.Error()
+	if !b.openedAt.IsZero() || b.failures >= b.cfg.Threshold {
+		b.openedAt = now
+	}
+}
+
+// abandon ends a trial fetch without counting its outcome.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+func (b *breaker) status(now time.Time) model.BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st := model.BreakerStatus{Source: b.source, State: "closed", Failures: b.failures, LastError: b.lastError}
+	if !b.openedAt.IsZero() {
+		openedAt, retryAt := b.openedAt, b.openedAt.Add(b.cfg.Cooldown)
+		st.OpenedAt, st.RetryAt = &openedAt, &retryAt
+		st.State = "open"
+		if !now.Before(retryAt) {
+			st.State = "half_open"
+		}
+	}
+	return st
+}
+
+// guarded wraps fetch so it is skipped while b is open and its outcome is
+// recorded otherwise. A fetch cut short by its context says nothing about
+// the upstream, so it only ends a trial.
+func guarded[T any](b *breaker, fetch func(context.Context) (T, map[string]any, error)) func(context.Context) (T, map[string]any, error) {
+	return func(ctx context.Context) (T, map[string]any, error) {
+		if err := b.allow(time.Now()); err != nil {
+			var zero T
+			return zero, nil, err
+		}
+		d, raw, err := fetch(ctx)
+		if err != nil && ctx.Err() != nil {
+			b.abandon()
+		} else {
+			b.record(err, time.Now())
+		}
+		return d, raw, err
+	}
+}
+
+// breaker returns the breaker for an upstream, creating it on first use.
+func (f *Fetcher) breaker(source string) *breaker {
+	f.breakerMu.Lock()
+	defer f.breakerMu.Unlock()
+	b, ok := f.breakers[source]
+	if !ok {
+		b = &breaker{source: source, cfg: f.cfg.Breaker}
+		f.breakers[source] = b
+	}
+	return b
+}
+
+// Breakers returns every upstream's circuit breaker, ordered by source.
+func (f *Fetcher) Breakers() []model.BreakerStatus {
+	f.breakerMu.Lock()
+	defer f.breakerMu.Unlock()
+	now := time.Now()
+	out := make([]model.BreakerStatus, 0, len(f.breakers))
+	for _, b := range f.breakers {
+		out = append(out, b.status(now))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
@@ -1,6 +1,7 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,65 +13,40 @@ import (
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
-func (f *Fetcher) fetchConnectivity() (model.ConnectivityData, map[string]any, error) {
-	slog.Info("fetching digital connectivity")
-
-	if f.cfg.CloudflareRadarToken == "" {
-		return model.ConnectivityData{}, nil, fmt.Errorf("cloudflare radar token not configured")
-	}
+// radarStatusError is returned when Cloudflare Radar responds with a non-200 status.
+type radarStatusError struct {
+	status int
+}
 
-	url := fmt.Sprintf("%s/http/timeseries?location=%s&dateRange=1d",
-		cloudflareRadarBaseURL, cloudflareRadarLocation)
+func (e *radarStatusError) Error() string {
+	return fmt.Sprintf("API returned %d", e.status)
+}
 
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return model.ConnectivityData{}, nil, fmt.Errorf("connectivity request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+f.cfg.CloudflareRadarToken)
-	req.Header.Set("Content-Type", "application/json")
+func (f *Fetcher) fetchConnectivity(ctx context.Context) (model.ConnectivityData, map[string]any, error) {
+	slog.Info("fetching digital connectivity")
 
-	resp, err := f.client.Do(req)
-	if err != nil {
-		return model.ConnectivityData{}, nil, fmt.Errorf("connectivity fetch: %w", err)
+	if f.cfg.CloudflareRadarToken == "" {
+		return model.ConnectivityData{}, nil, failure(KindAuth, "cloudflare radar token not configured")
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		slog.Warn("cloudflare radar API error", "status", resp.StatusCode)
+	parsedValues, parsedTimes, err := f.fetchRadarTimeseries(ctx, "location="+cloudflareRadarLocation)
+	if statusErr, ok := err.(*radarStatusError); ok {
+		slog.Warn("cloudflare radar API error", "status", statusErr.status)
 		stale := model.ConnectivityData{
 			Status:    "STALE",
 			Risk:      0,
 			Trend:     0,
 			Values:    nil,
 			Timestamp: time.Now().Format(time.RFC3339),
-			Error:     fmt.Sprintf("API returned %d", resp.StatusCode),
+			Error:     statusErr.Error(),
 		}
 		return stale, structToMap(stale), nil
 	}
-
-	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return model.ConnectivityData{}, nil, fmt.Errorf("connectivity read body: %w", err)
-	}
-
-	var data map[string]any
-	if err := json.Unmarshal(body, &data); err != nil {
-		return model.ConnectivityData{}, nil, fmt.Errorf("connectivity parse: %w", err)
-	}
-
-	// Extract timeseries values
-	result, ok := data["result"].(map[string]any)
-	if !ok {
-		return model.ConnectivityData{Status: "STALE"}, nil, fmt.Errorf("no result in response")
-	}
-
-	series, ok := result["serie_0"].(map[string]any)
-	if !ok {
-		return model.ConnectivityData{Status: "STALE"}, nil, fmt.Errorf("no serie_0 in result")
+		return model.ConnectivityData{Status: "STALE"}, nil, err
 	}
 
-	rawValues, ok := series["values"].([]any)
-	if !ok || len(rawValues) == 0 {
+	if len(parsedValues) == 0 {
 		stale := model.ConnectivityData{
 			Status:    "STALE",
 			Timestamp: time.Now().Format(time.RFC3339),
@@ -79,71 +55,245 @@ func (f *Fetcher) fetchConnectivity() (model.ConnectivityData, map[string]any, e
 		return stale, structToMap(stale), nil
 	}
 
-	var parsedValues []float64
-	for _, v := range rawValues {
-		if f, err := toFloatSafe(v); err == nil {
-			parsedValues = append(parsedValues, f)
-		}
-	}
-
 	slog.Info("connectivity data points", "count", len(parsedValues))
 
 	if len(parsedValues) < 8 {
 		stale := model.ConnectivityData{
 			Status:    "STALE",
-			Values:    parsedValues,
+			Values:    lastN(parsedValues, 24),
 			Timestamp: time.Now().Format(time.RFC3339),
 			Error:     "Not enough data points",
 		}
 		return stale, structToMap(stale), nil
 	}
 
-	// Calculate baseline (first 75%) vs recent (last 25%)
-	splitPoint := int(float64(len(parsedValues)) * 0.75)
-	baselineValues := parsedValues[:splitPoint]
-	recentValues := parsedValues[splitPoint:]
-
-	baselineAvg := average(baselineValues)
-	recentAvg := average(recentValues)
+	baselineAvg, recentAvg, method := connectivityBaseline(parsedValues, parsedTimes)
 
 	var trend float64
 	if baselineAvg > 0 {
 		trend = (recentAvg - baselineAvg) / baselineAvg
 	}
 
-	slog.Info("connectivity analysis", "baseline", baselineAvg, "recent", recentAvg, "trend", trend*100)
+	slog.Info("connectivity analysis", "method", method, "baseline", baselineAvg, "recent", recentAvg, "trend", trend*100)
 
 	// Determine risk based on traffic drop thresholds
-	var risk float64
-	var status string
-	if trend <= -0.90 {
-		risk = 25
-		status = "BLACKOUT"
-	} else if trend <= -0.50 {
-		risk = 20
-		status = "CRITICAL"
-	} else if trend <= -0.15 {
-		risk = 10
-		status = "ANOMALOUS"
-	} else {
-		risk = 0
-		status = "STABLE"
-	}
-
-	slog.Info("connectivity result", "status", status, "risk", risk)
+	risk, status := connectivityStatus(trend)
+
+	// Per-network breakdown: a shutdown targeting specific operators (e.g.
+	// mobile networks only) can hide inside a stable national aggregate.
+	breakdown := f.fetchConnectivityBreakdown(ctx)
+	degraded := 0
+	for _, n := range breakdown {
+		if n.Domestic && (n.Status == "CRITICAL" || n.Status == "BLACKOUT") {
+			degraded++
+		}
+	}
+	if degraded > 0 && risk < 20 {
+		if degraded >= 2 {
+			risk = 20
+		} else {
+			risk = math.Max(risk, 15)
+		}
+		status = "TARGETED"
+	}
+
+	countries := f.fetchConnectivityCountries(ctx, f.cfg.Connectivity.Locations)
+
+	slog.Info("connectivity result", "status", status, "risk", risk, "degraded_networks", degraded, "countries", len(countries))
 
 	now := time.Now()
 	connData := model.ConnectivityData{
 		Status:    status,
 		Risk:      risk,
 		Trend:     math.Round(trend*1000) / 10, // Convert to percentage with 1 decimal
-		Values:    parsedValues,
+		Values:    lastN(parsedValues, 24),
+		Baseline:  method,
+		Networks:  breakdown,
+		Countries: countries,
 		Timestamp: now.Format(time.RFC3339),
 	}
 	rawMap := structToMap(connData)
 	return connData, rawMap, nil
 }
 
+// fetchConnectivityBreakdown queries each tracked network individually.
+// Networks that fail to fetch are reported as STALE rather than dropped so
+// the breakdown always lists the same set.
+func (f *Fetcher) fetchConnectivityBreakdown(ctx context.Context) []model.NetworkStatus {
+	var breakdown []model.NetworkStatus
+
+	for _, n := range connectivityNetworks {
+		values, times, err := f.fetchRadarTimeseries(ctx, n.Query)
+		entry := model.NetworkStatus{Name: n.Name, Query: n.Query, Domestic: n.Domestic, Status: "STALE"}
+		if err != nil {
+			slog.Warn("connectivity network fetch failed", "network", n.Name, "error", err)
+			breakdown = append(breakdown, entry)
+			continue
+		}
+		if len(values) < 8 {
+			breakdown = append(breakdown, entry)
+			continue
+		}
+
+		baseline, recent, _ := connectivityBaseline(values, times)
+		var trend float64
+		if baseline > 0 {
+			trend = (recent - baseline) / baseline
+		}
+		_, entry.Status = connectivityStatus(trend)
+		entry.Trend = math.Round(trend*1000) / 10
+		breakdown = append(breakdown, entry)
+	}
+
+	return breakdown
+}
+
+// fetchConnectivityCountries builds the regional table, one national query
+// per country. Like the network breakdown, failed countries stay listed as
+// STALE. The table is informational and does not change the Iran score.
+func (f *Fetcher) fetchConnectivityCountries(ctx context.Context, codes []string) []model.CountryStatus {
+	countries := make([]model.CountryStatus, 0, len(codes))
+	for _, cc := range codes {
+		entry := model.CountryStatus{Code: cc, Name: cc, Status: "STALE"}
+		if name, ok := countryNames[cc]; ok {
+			entry.Name = name
+		}
+		values, times, err := f.fetchRadarTimeseries(ctx, "location="+cc)
+		if err != nil {
+			slog.Warn("connectivity country fetch failed", "country", cc, "error", err)
+			countries = append(countries, entry)
+			continue
+		}
+		if len(values) < 8 {
+			countries = append(countries, entry)
+			continue
+		}
+
+		baseline, recent, _ := connectivityBaseline(values, times)
+		var trend float64
+		if baseline > 0 {
+			trend = (recent - baseline) / baseline
+		}
+		_, entry.Status = connectivityStatus(trend)
+		entry.Trend = math.Round(trend*1000) / 10
+		entry.Values = lastN(values, 24)
+		countries = append(countries, entry)
+	}
+	return countries
+}
+
+// fetchRadarTimeseries fetches a week of hourly HTTP traffic points for the
+// given Radar filter (e.g. "location=IR" or "asn=44244") so the latest hour
+// can be compared against the same hour on previous days.
+func (f *Fetcher) fetchRadarTimeseries(ctx context.Context, filter string) ([]float64, []time.Time, error) {
+	url := fmt.Sprintf("%s/http/timeseries?%s&dateRange=%dd&aggInterval=1h",
+		cloudflareRadarBaseURL, filter, connectivityBaselineDays)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connectivity request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+f.cfg.CloudflareRadarToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, nil, failure(KindNetwork, "connectivity fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, nil, &radarStatusError{status: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, failure(KindNetwork, "connectivity read body: %w", err)
+	}
+
+	var data map[string]any
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, nil, failure(KindParse, "connectivity parse: %w", err)
+	}
+
+	// Extract timeseries values
+	result, ok := data["result"].(map[string]any)
+	if !ok {
+		return nil, nil, failure(KindParse, "no result in response")
+	}
+
+	series, ok := result["serie_0"].(map[string]any)
+	if !ok {
+		return nil, nil, failure(KindParse, "no serie_0 in result")
+	}
+
+	rawValues, _ := series["values"].([]any)
+	rawTimestamps, _ := series["timestamps"].([]any)
+
+	var parsedValues []float64
+	var parsedTimes []time.Time
+	for i, v := range rawValues {
+		f, err := toFloatSafe(v)
+		if err != nil {
+			continue
+		}
+		parsedValues = append(parsedValues, f)
+		if i < len(rawTimestamps) {
+			if ts, ok := rawTimestamps[i].(string); ok {
+				if t, err := time.Parse(time.RFC3339, ts); err == nil {
+					parsedTimes = append(parsedTimes, t)
+				}
+			}
+		}
+	}
+
+	return parsedValues, parsedTimes, nil
+}
+
+// connectivityStatus maps a traffic trend (fractional change) to a risk
+// score and status label.
+func connectivityStatus(trend float64) (float64, string) {
+	switch {
+	case trend <= -0.90:
+		return 25, "BLACKOUT"
+	case trend <= -0.50:
+		return 20, "CRITICAL"
+	case trend <= -0.15:
+		return 10, "ANOMALOUS"
+	default:
+		return 0, "STABLE"
+	}
+}
+
+// connectivityBaseline returns the baseline and recent traffic averages.
+// When timestamps are available, the latest hour is compared against the
+// same UTC hour on previous days so ordinary nightly dips are not flagged.
+// Otherwise it falls back to comparing the last 25% of points to the first 75%.
+func connectivityBaseline(values []float64, times []time.Time) (baseline, recent float64, method string) {
+	if len(times) == len(values) {
+		last := times[len(times)-1]
+		var sameHour []float64
+		for i, t := range times[:len(times)-1] {
+			if t.UTC().Hour() == last.UTC().Hour() && last.Sub(t) >= 20*time.Hour {
+				sameHour = append(sameHour, values[i])
+			}
+		}
+		if len(sameHour) >= 2 {
+			return average(sameHour), values[len(values)-1], "diurnal"
+		}
+	}
+
+	splitPoint := int(float64(len(values)) * 0.75)
+	return average(values[:splitPoint]), average(values[splitPoint:]), "split"
+}
+
+func lastN(values []float64, n int) []float64 {
+	if len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
 func toFloatSafe(v any) (float64, error) {
 	switch n := v.(type) {
 	case float64:
@@ -1,15 +1,5 @@
 package fetcher
 
-var pizzaPlaces = []struct {
-	Name    string
-	PlaceID string
-	Address string
-}{
-	{Name: "Domino's Pizza", PlaceID: "ChIJN1t_tDeuEmsRUsoyG83frY4", Address: "Pentagon City"},
-	{Name: "Papa John's", PlaceID: "ChIJP3Sa8ziYEmsRUKgyFmh9AQM", Address: "Near Pentagon"},
-	{Name: "Pizza Hut", PlaceID: "ChIJrTLr-GyuEmsRBfy61i59si0", Address: "Pentagon Area"},
-}
-
 var alertKeywords = []string{
 	"strike", "attack", "military", "bomb", "missile", "war", "imminent", "troops", "forces",
 }
@@ -18,10 +8,21 @@ var iranKeywords = []string{
 	"iran", "tehran", "persian gulf", "strait of hormuz",
 }
 
+var deescalationKeywords = []string{
+	"ceasefire", "cease-fire", "truce", "talks resume", "resume talks", "deal reached",
+	"agreement reached", "de-escalat", "peace talks", "diplomatic breakthrough",
+}
+
 var strikeKeywords = []string{
 	"strike", "attack", "bomb", "military action",
 }
 
+// strikeEventTitles are Polymarket event titles whose markets all count as
+// strike markets.
+var strikeEventTitles = []string{
+	"will us or israel strike iran", "us strikes iran by",
+}
+
 var negativeKeywords = []string{
 	" not ", "won't", "will not", "doesn't", "does not",
 }
@@ -51,7 +52,56 @@ var months = []string{
 	"july", "august", "september", "october", "november", "december",
 }
 
+// weatherTileLayers maps the layer names exposed in raw_data to OpenWeather
+// map layer IDs.
+var weatherTileLayers = map[string]string{
+	"clouds":        "clouds_new",
+	"precipitation": "precipitation_new",
+	"wind":          "wind_new",
+	"temperature":   "temp_new",
+}
+
+// Region covered by weather imagery (same box as the aviation query) and the
+// zoom levels the tile proxy serves.
+const (
+	weatherTileMinLon  = 44.0
+	weatherTileMinLat  = 25.0
+	weatherTileMaxLon  = 64.0
+	weatherTileMaxLat  = 40.0
+	weatherTileMinZoom = 3
+	weatherTileMaxZoom = 8
+)
+
 const (
 	cloudflareRadarBaseURL  = "https://api.cloudflare.com/client/v4/radar"
 	cloudflareRadarLocation = "IR"
+
+	// Days of hourly history requested for the diurnal connectivity baseline
+	connectivityBaselineDays = 7
 )
+
+// connectivityNetworks are the Radar filters for the per-network breakdown of
+// major Iranian operators by ASN. Only domestic networks feed into the
+// targeted-shutdown scoring; neighboring countries are tracked separately
+// in the country table.
+var connectivityNetworks = []struct {
+	Name     string
+	Query    string
+	Domestic bool
+}{
+	{Name: "MCI (Hamrah-e Aval)", Query: "asn=197207", Domestic: true},
+	{Name: "Irancell", Query: "asn=44244", Domestic: true},
+	{Name: "RighTel", Query: "asn=57218", Domestic: true},
+	{Name: "TCI", Query: "asn=58224", Domestic: true},
+	{Name: "Shatel", Query: "asn=31549", Domestic: true},
+}
+
+// countryNames label the connectivity country table. Codes not listed are
+// shown as the bare code.
+var countryNames = map[string]string{
+	"IL": "Israel", "LB": "Lebanon", "IQ": "Iraq", "SY": "Syria",
+	"JO": "Jordan", "SA": "Saudi Arabia", "AE": "United Arab Emirates",
+	"QA": "Qatar", "BH": "Bahrain", "KW": "Kuwait", "OM": "Oman",
+	"YE": "Yemen", "TR": "Turkey", "EG": "Egypt", "AZ": "Azerbaijan",
+	"AM": "Armenia", "AF": "Afghanistan", "PK": "Pakistan",
+}
@@ -0,0 +1,117 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// ErrorKind classifies why a fetch failed, so an exhausted quota can be told
+// apart from a changed schema without reading logs.
+type ErrorKind string
+
+const (
+	// KindNetwork covers DNS, connect, TLS and timeout failures, and bodies
+	// cut off mid-read.
+	KindNetwork ErrorKind = "network"
+	// KindAuth means credentials were missing or rejected (401/403).
+	KindAuth ErrorKind = "auth"
+	// KindRateLimit means the upstream refused for quota reasons (429).
+	KindRateLimit ErrorKind = "rate_limit"
+	// KindParse means the response arrived but did not have the expected shape.
+	KindParse ErrorKind = "parse"
+	// KindUpstream means the upstream answered with a 5xx.
+	KindUpstream ErrorKind = "upstream_5xx"
+	// KindHTTP is any other unexpected HTTP status.
+	KindHTTP ErrorKind = "http_status"
+	// KindBudget means the pipeline stopped waiting for the fetch because
+	// its run budget ran out.
+	KindBudget ErrorKind = "run_budget"
+	// KindCircuitOpen means the fetch was skipped because its upstream's
+	// circuit breaker is open.
+	KindCircuitOpen ErrorKind = "circuit_open"
+	// KindCanceled means the run was cancelled, e.g. by shutdown or its
+	// budget running out, while the fetch was in flight.
+	KindCanceled ErrorKind = "canceled"
+	// KindUnknown is anything not otherwise classified.
+	KindUnknown ErrorKind = "unknown"
+)
+
+// FetchError is a classified fetch failure. Status is the HTTP status when
+// the failure came from one.
+type FetchError struct {
+	Kind   ErrorKind
+	Status int
+	Err    error
+}
+
+func (e *FetchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// failure builds a FetchError of the given kind with a formatted message.
+// A wrapped context cancellation or deadline overrides kind with
+// KindCanceled, since the upstream never got to answer.
+func failure(kind ErrorKind, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		kind = KindCanceled
+	}
+	return &FetchError{Kind: kind, Err: err}
+}
+
+// withoutURL strips the request URL a *url.Error would print, for requests
+// whose URL carries a credential.
+func withoutURL(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return uerr.Err
+	}
+	return err
+}
+
+// statusFailure builds a FetchError for an unexpected HTTP status, worded
+// "<prefix>: <status>".
+func statusFailure(prefix string, status int) error {
+	kind := KindHTTP
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		kind = KindAuth
+	case status == http.StatusTooManyRequests:
+		kind = KindRateLimit
+	case status >= 500:
+		kind = KindUpstream
+	}
+	return &FetchError{Kind: kind, Status: status, Err: fmt.Errorf("%s: %d", prefix, status)}
+}
+
+// Classify returns the kind of a fetch error, or "" for nil. Errors that
+// were not built as a *FetchError are classified by their underlying type
+// where possible.
+func Classify(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		return fe.Kind
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return KindNetwork
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return KindParse
+	}
+	return KindUnknown
+}
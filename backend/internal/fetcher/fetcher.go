@@ -1,53 +1,67 @@
 package fetcher
 
 import (
+	"context"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/backyonatan-alt/aegis/backend/internal/config"
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
+// Interface supplies the data sources the pipeline runs. *Fetcher is the
+// production implementation; Mock serves canned results.
+type Interface interface {
+	Signals() []Signal
+	// Breakers reports each upstream's circuit breaker.
+	Breakers() []model.BreakerStatus
+}
+
+var _ Interface = (*Fetcher)(nil)
+
 // Fetcher holds the shared HTTP client and config for all API fetchers.
 type Fetcher struct {
 	client *http.Client
 	cfg    *config.Config
-}
-
-func New(cfg *config.Config) *Fetcher {
-	return &Fetcher{
-		client: &http.Client{Timeout: 30 * time.Second},
-		cfg:    cfg,
-	}
-}
 
-// FetchAll runs all fetchers and returns structured results plus raw data maps.
-// Aviation and tanker must be called sequentially (OpenSky rate limit).
-// The caller is responsible for the 2-second delay between aviation and tanker.
-func (f *Fetcher) FetchPolymarket() (model.PolymarketData, map[string]any, error) {
-	return f.fetchPolymarket()
-}
+	mu          sync.RWMutex
+	keywords    Keywords
+	marketRules MarketRules
+	// lastNews is what the last news run read, for testing keywords on
+	lastNews         []NewsItem
+	lastNewsKeywords Keywords
 
-func (f *Fetcher) FetchNews() (model.NewsData, map[string]any, error) {
-	return f.fetchNews()
-}
+	breakerMu sync.Mutex
+	breakers  map[string]*breaker
 
-func (f *Fetcher) FetchAviation() (model.AviationData, map[string]any, error) {
-	return f.fetchAviation()
-}
-
-func (f *Fetcher) FetchTanker() (model.TankerData, map[string]any, error) {
-	return f.fetchTanker()
-}
+	providerMu sync.Mutex
+	providers  map[string]*providerStats
+	probes     map[string]int
 
-func (f *Fetcher) FetchWeather() (model.WeatherData, map[string]any, error) {
-	return f.fetchWeather()
+	openSkyMu    sync.Mutex
+	openSkyToken string
+	openSkyUntil time.Time
 }
 
-func (f *Fetcher) FetchConnectivity() (model.ConnectivityData, map[string]any, error) {
-	return f.fetchConnectivity()
+func New(cfg *config.Config) *Fetcher {
+	transport := newRetryTransport(newRateLimitTransport(http.DefaultTransport, cfg.RateLimits), cfg.Retry)
+	return &Fetcher{
+		client:      &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		cfg:         cfg,
+		keywords:    DefaultKeywords(),
+		marketRules: DefaultMarketRules(),
+		breakers:    make(map[string]*breaker),
+		providers:   make(map[string]*providerStats),
+		probes:      make(map[string]int),
+	}
 }
 
-func (f *Fetcher) FetchPentagon() (model.PentagonData, map[string]any) {
-	return f.fetchPentagon()
+// get issues a GET bound to ctx, so a cancelled run abandons the request.
+func (f *Fetcher) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return f.client.Do(req)
 }
@@ -0,0 +1,141 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	gdeltDocAPI = "https://api.gdeltproject.org/api/v2/doc/doc"
+	// gdeltTimespan is how much coverage is read: the last day plus the
+	// week before it as the baseline.
+	gdeltTimespan = "8d"
+	gdeltRecent   = 24 * time.Hour
+)
+
+// gdeltPoint is one interval of a GDELT DOC API timeline.
+type gdeltPoint struct {
+	at    time.Time
+	value float64
+}
+
+func (f *Fetcher) fetchGeopolitics(ctx context.Context) (model.GeopoliticsData, map[string]any, error) {
+	slog.Info("fetching gdelt coverage")
+
+	volume, err := f.gdeltTimeline(ctx, "timelinevolraw")
+	if err != nil {
+		return model.GeopoliticsData{}, nil, err
+	}
+	tone, err := f.gdeltTimeline(ctx, "timelinetone")
+	if err != nil {
+		return model.GeopoliticsData{}, nil, err
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-gdeltRecent)
+	var recent, earlier float64
+	earliest := cutoff
+	counts := make(map[time.Time]float64, len(volume))
+	for _, p := range volume {
+		counts[p.at] = p.value
+		if p.at.Before(earliest) {
+			earliest = p.at
+		}
+		if p.at.Before(cutoff) {
+			earlier += p.value
+		} else {
+			recent += p.value
+		}
+	}
+	baselineDays := cutoff.Sub(earliest).Hours() / 24
+	if baselineDays < 1 || earlier == 0 {
+		return model.GeopoliticsData{}, nil, failure(KindParse, "gdelt: no baseline coverage returned")
+	}
+
+	// Tone is weighted by each interval's article count, so quiet hours
+	// don't swing the average
+	var recentTone, recentWeight, earlierTone, earlierWeight float64
+	for _, p := range tone {
+		w := counts[p.at]
+		if p.at.Before(cutoff) {
+			earlierTone += p.value * w
+			earlierWeight += w
+		} else {
+			recentTone += p.value * w
+			recentWeight += w
+		}
+	}
+
+	perDay := earlier / baselineDays
+	data := model.GeopoliticsData{
+		ArticleCount:   int(recent),
+		BaselinePerDay: math.Round(perDay*10) / 10,
+		Ratio:          math.Round(recent/perDay*100) / 100,
+		Timestamp:      now.Format(time.RFC3339),
+	}
+	if recentWeight > 0 {
+		data.AverageTone = math.Round(recentTone/recentWeight*100) / 100
+	}
+	if earlierWeight > 0 {
+		data.BaselineTone = math.Round(earlierTone/earlierWeight*100) / 100
+	}
+	slog.Info("gdelt coverage", "articles", data.ArticleCount, "baseline_per_day", data.BaselinePerDay, "tone", data.AverageTone, "baseline_tone", data.BaselineTone)
+	return data, structToMap(data), nil
+}
+
+// gdeltTimeline reads one timeline mode of the DOC API for the configured
+// query over gdeltTimespan.
+func (f *Fetcher) gdeltTimeline(ctx context.Context, mode string) ([]gdeltPoint, error) {
+	q := url.Values{
+		"query":    {f.cfg.GDELTQuery},
+		"mode":     {mode},
+		"timespan": {gdeltTimespan},
+		"format":   {"json"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gdeltDocAPI+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, failure(KindUnknown, "gdelt request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, failure(KindNetwork, "gdelt request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, statusFailure("gdelt API error", resp.StatusCode)
+	}
+
+	// Query errors come back as 200 with a plain-text message, which
+	// fails to decode here
+	var result struct {
+		Timeline []struct {
+			Data []struct {
+				Date  string  `json:"date"`
+				Value float64 `json:"value"`
+			} `json:"data"`
+		} `json:"timeline"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, failure(KindParse, "gdelt parse: %w", err)
+	}
+	if len(result.Timeline) == 0 {
+		return nil, failure(KindParse, "gdelt: empty %s timeline", mode)
+	}
+	var points []gdeltPoint
+	for _, d := range result.Timeline[0].Data {
+		at, err := time.Parse("20060102T150405Z", d.Date)
+		if err != nil {
+			continue
+		}
+		points = append(points, gdeltPoint{at: at, value: d.Value})
+	}
+	return points, nil
+}
@@ -0,0 +1,106 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	adsbLolAPI = "https://api.adsb.lol/v2/point"
+	// gpsDegradedNIC is the ADS-B navigation integrity category below which
+	// an aircraft's position counts as degraded, as on gpsjam.org.
+	gpsDegradedNIC = 7
+)
+
+// gpsZone is an area read for GPS interference: aircraft within radiusNM
+// nautical miles of its centre.
+type gpsZone struct {
+	name     string
+	lat, lon float64
+	radiusNM int
+}
+
+// gpsZones are watched for jamming. The feed serves at most 250 nm around
+// a point, so Iran is read from its centre.
+var gpsZones = []gpsZone{
+	{name: "Israel", lat: 31.8, lon: 35.0, radiusNM: 100},
+	{name: "Iran", lat: 32.4, lon: 53.7, radiusNM: 250},
+	{name: "Gulf", lat: 26.5, lon: 52.5, radiusNM: 250},
+}
+
+func (f *Fetcher) fetchGPS(ctx context.Context) (model.GPSData, map[string]any, error) {
+	slog.Info("fetching gps interference")
+
+	data := model.GPSData{Zones: []model.GPSZone{}}
+	var lastErr error
+	for _, z := range gpsZones {
+		reading, err := f.gpsZone(ctx, z)
+		if err != nil {
+			slog.Warn("gps zone fetch failed", "zone", z.name, "error", err)
+			data.Unavailable = append(data.Unavailable, z.name)
+			lastErr = err
+			continue
+		}
+		data.Zones = append(data.Zones, reading)
+	}
+	if len(data.Unavailable) == len(gpsZones) {
+		return model.GPSData{}, nil, lastErr
+	}
+	data.Timestamp = time.Now().Format(time.RFC3339)
+
+	for _, z := range data.Zones {
+		slog.Info("gps interference", "zone", z.Name, "aircraft", z.Aircraft, "degraded", z.Degraded)
+	}
+	return data, structToMap(data), nil
+}
+
+// gpsZone counts the aircraft in one zone reporting degraded integrity.
+// Aircraft without a NIC, positioned by multilateration rather than their
+// own GPS, are left out.
+func (f *Fetcher) gpsZone(ctx context.Context, z gpsZone) (model.GPSZone, error) {
+	u := fmt.Sprintf("%s/%v/%v/%d", adsbLolAPI, z.lat, z.lon, z.radiusNM)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return model.GPSZone{}, failure(KindUnknown, "adsb.lol request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return model.GPSZone{}, failure(KindNetwork, "adsb.lol request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return model.GPSZone{}, statusFailure("adsb.lol API error", resp.StatusCode)
+	}
+
+	var result struct {
+		Aircraft []struct {
+			NIC *int `json:"nic"`
+		} `json:"ac"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return model.GPSZone{}, failure(KindParse, "adsb.lol parse: %w", err)
+	}
+
+	reading := model.GPSZone{Name: z.name}
+	for _, ac := range result.Aircraft {
+		if ac.NIC == nil {
+			continue
+		}
+		reading.Aircraft++
+		if *ac.NIC < gpsDegradedNIC {
+			reading.Degraded++
+		}
+	}
+	if reading.Aircraft > 0 {
+		reading.Ratio = math.Round(float64(reading.Degraded)/float64(reading.Aircraft)*1000) / 1000
+	}
+	return reading, nil
+}
@@ -0,0 +1,112 @@
+package fetcher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Keywords are the runtime-tunable keyword lists used to classify news
+// articles and screen Polymarket questions. Deescalation terms mark
+// Iran-related articles that pull news risk down rather than up.
+type Keywords struct {
+	Alert        []string `json:"alert"`
+	Iran         []string `json:"iran"`
+	Negative     []string `json:"negative"`
+	Deescalation []string `json:"deescalation"`
+}
+
+// DefaultKeywords returns the built-in keyword lists.
+func DefaultKeywords() Keywords {
+	return Keywords{
+		Alert:        append([]string{}, alertKeywords...),
+		Iran:         append([]string{}, iranKeywords...),
+		Negative:     append([]string{}, negativeKeywords...),
+		Deescalation: append([]string{}, deescalationKeywords...),
+	}
+}
+
+// Normalize lowercases every keyword and drops blank entries. Surrounding
+// spaces are kept because they are significant for matching (e.g. " not ").
+func (k Keywords) Normalize() Keywords {
+	return Keywords{
+		Alert:        normalizeList(k.Alert),
+		Iran:         normalizeList(k.Iran),
+		Negative:     normalizeList(k.Negative),
+		Deescalation: normalizeList(k.Deescalation),
+	}
+}
+
+// Validate checks that the lists required for classification are present.
+func (k Keywords) Validate() error {
+	if len(k.Alert) == 0 {
+		return fmt.Errorf("alert keywords must not be empty")
+	}
+	if len(k.Iran) == 0 {
+		return fmt.Errorf("iran keywords must not be empty")
+	}
+	return nil
+}
+
+// Classify reports whether text is Iran-related and, if so, whether it
+// counts as an alert, using the given keyword lists.
+func (k Keywords) Classify(text string) (relevant, alert bool) {
+	topicHits, alertHits := k.Match(text)
+	return len(topicHits) > 0, len(topicHits) > 0 && len(alertHits) > 0
+}
+
+// Match returns the Iran keywords and alert keywords found in text. Alert
+// hits are only reported when the text is Iran-related.
+func (k Keywords) Match(text string) (topicHits, alertHits []string) {
+	lower := strings.ToLower(text)
+	topicHits = matchAll(lower, k.Iran)
+	if len(topicHits) == 0 {
+		return nil, nil
+	}
+	return topicHits, matchAll(lower, k.Alert)
+}
+
+// MatchDeescalation returns the de-escalation keywords found in text, or
+// nil when the text is not Iran-related.
+func (k Keywords) MatchDeescalation(text string) []string {
+	lower := strings.ToLower(text)
+	if len(matchAll(lower, k.Iran)) == 0 {
+		return nil
+	}
+	return matchAll(lower, k.Deescalation)
+}
+
+func matchAll(s string, keywords []string) []string {
+	var hits []string
+	for _, kw := range keywords {
+		if strings.Contains(s, kw) {
+			hits = append(hits, kw)
+		}
+	}
+	return hits
+}
+
+// Keywords returns the keyword lists currently applied to the fetcher.
+func (f *Fetcher) Keywords() Keywords {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.keywords
+}
+
+// SetKeywords hot-applies new keyword lists; the next fetch uses them.
+func (f *Fetcher) SetKeywords(k Keywords) {
+	f.mu.Lock()
+	f.keywords = k
+	f.mu.Unlock()
+}
+
+func normalizeList(list []string) []string {
+	out := []string{}
+	for _, kw := range list {
+		kw = strings.ToLower(kw)
+		if strings.TrimSpace(kw) == "" || sliceContains(out, kw) {
+			continue
+		}
+		out = append(out, kw)
+	}
+	return out
+}
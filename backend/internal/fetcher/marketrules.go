@@ -0,0 +1,159 @@
+package fetcher
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MarketRules control which Polymarket markets feed the market signal. The
+// search Query selects candidate events. A market is a strike market if its
+// event title contains one of EventTitles, or if its question mentions a
+// Topic term and a Strike term. Only when no strike market has odds does
+// any Topic-related market count. Questions matching the negative keywords
+// are always skipped.
+type MarketRules struct {
+	Query       string   `json:"query"`
+	Topic       []string `json:"topic"`
+	Strike      []string `json:"strike"`
+	EventTitles []string `json:"event_titles"`
+}
+
+// MarketMatch is one market selected by MarketRules. Rule names the clause
+// that matched: "event_title", "strike_question" or "topic".
+type MarketMatch struct {
+	Event    string `json:"event"`
+	Question string `json:"question"`
+	Odds     int    `json:"odds"`
+	Rule     string `json:"rule"`
+	TokenID  string `json:"token_id,omitempty"`
+}
+
+// DefaultMarketRules returns the built-in Polymarket rules.
+func DefaultMarketRules() MarketRules {
+	return MarketRules{
+		Query:       "iran",
+		Topic:       []string{"iran"},
+		Strike:      append([]string{}, strikeKeywords...),
+		EventTitles: append([]string{}, strikeEventTitles...),
+	}
+}
+
+// Normalize trims the query and lowercases every term, dropping blanks.
+func (m MarketRules) Normalize() MarketRules {
+	return MarketRules{
+		Query:       strings.TrimSpace(m.Query),
+		Topic:       normalizeList(m.Topic),
+		Strike:      normalizeList(m.Strike),
+		EventTitles: normalizeList(m.EventTitles),
+	}
+}
+
+// Validate checks that the rules can select any market at all.
+func (m MarketRules) Validate() error {
+	if m.Query == "" {
+		return fmt.Errorf("query must not be empty")
+	}
+	if len(m.Topic) == 0 {
+		return fmt.Errorf("topic terms must not be empty")
+	}
+	return nil
+}
+
+// Match returns the near-term markets in events selected by the rules, in
+// response order. Strike markets are returned if any has odds; otherwise
+// the broader topic markets are.
+func (m MarketRules) Match(events []map[string]any, negative []string, now time.Time) []MarketMatch {
+	var strike []MarketMatch
+	found := false
+	for _, event := range events {
+		title := getString(event, "title")
+		titleLower := strings.ToLower(title)
+		markets, _ := event["markets"].([]any)
+
+		if containsAny(titleLower, m.EventTitles) && isNearTermMarket(title, now) {
+			// Every market of a strike event is already listed, so the
+			// question check below would only add duplicates.
+			for _, mk := range markets {
+				if market, ok := mk.(map[string]any); ok {
+					odds := getMarketOdds(market)
+					found = found || odds > 0
+					strike = append(strike, MarketMatch{
+						Event:    title,
+						Question: getStringOr(market, "question", title),
+						Odds:     odds,
+						Rule:     "event_title",
+						TokenID:  yesTokenID(market),
+					})
+				}
+			}
+			continue
+		}
+
+		for _, mk := range markets {
+			market, ok := mk.(map[string]any)
+			if !ok {
+				continue
+			}
+			name := getString(market, "question")
+			question := strings.ToLower(name)
+			if containsAny(question, negative) || !containsAny(question, m.Topic) || !containsAny(question, m.Strike) {
+				continue
+			}
+			if !isNearTermMarket(name, now) {
+				continue
+			}
+			if odds := getMarketOdds(market); odds > 0 {
+				found = true
+				strike = append(strike, MarketMatch{Event: title, Question: name, Odds: odds, Rule: "strike_question", TokenID: yesTokenID(market)})
+			}
+		}
+	}
+	if found {
+		return strike
+	}
+
+	var topic []MarketMatch
+	for _, event := range events {
+		title := getString(event, "title")
+		titleLower := strings.ToLower(title)
+		if containsAny(titleLower, negative) || !containsAny(titleLower, m.Topic) {
+			continue
+		}
+		if !isNearTermMarket(title, now) {
+			continue
+		}
+		markets, _ := event["markets"].([]any)
+		for _, mk := range markets {
+			market, ok := mk.(map[string]any)
+			if !ok {
+				continue
+			}
+			if containsAny(strings.ToLower(getString(market, "question")), negative) {
+				continue
+			}
+			name := getStringOr(market, "question", title)
+			if !isNearTermMarket(name, now) {
+				continue
+			}
+			if odds := getMarketOdds(market); odds > 0 {
+				topic = append(topic, MarketMatch{Event: title, Question: name, Odds: odds, Rule: "topic", TokenID: yesTokenID(market)})
+			}
+		}
+	}
+	return topic
+}
+
+// MarketRules returns the Polymarket rules currently applied to the fetcher.
+func (f *Fetcher) MarketRules() MarketRules {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.marketRules
+}
+
+// SetMarketRules hot-applies new Polymarket rules; the next fetch uses them.
+func (f *Fetcher) SetMarketRules(m MarketRules) {
+	f.mu.Lock()
+	f.marketRules = m
+	f.mu.Unlock()
+}
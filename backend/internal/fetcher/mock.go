@@ -0,0 +1,56 @@
+package fetcher
+
+import (
+	"context"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// Mock is an Interface that returns fixed results without network access.
+// Maps are keyed by snapshot signal name (e.g. "flight" for aviation).
+// Data holds each signal's fetched data, of the type its production fetch
+// returns; a signal without any fetches its zero value. An Errs entry
+// simulates a failed fetch, and its data is then ignored. Raw maps default
+// to empty when nil.
+type Mock struct {
+	Data map[string]any
+	Errs map[string]error
+	// Raw overrides the raw_data map returned for a signal.
+	Raw map[string]map[string]any
+}
+
+var _ Interface = (*Mock)(nil)
+
+func (m *Mock) raw(name string) map[string]any {
+	if r, ok := m.Raw[name]; ok && r != nil {
+		return r
+	}
+	return map[string]any{}
+}
+
+// Signals serves the mock's results under the production signal names and
+// stages. Restore and Score are the production ones, so fallback and
+// scoring behave as in a real run.
+func (m *Mock) Signals() []Signal {
+	out := make([]Signal, len(registry))
+	for i, e := range registry {
+		out[i] = e.mock(m)
+	}
+	return out
+}
+
+// Breakers is empty: mock signals have no upstreams.
+func (m *Mock) Breakers() []model.BreakerStatus {
+	return nil
+}
+
+func mockFetch[T any](m *Mock, name string) func(context.Context) (T, map[string]any, error) {
+	return func(context.Context) (T, map[string]any, error) {
+		var zero T
+		if err := m.Errs[name]; err != nil {
+			return zero, nil, err
+		}
+		d, _ := m.Data[name].(T)
+		return d, m.raw(name), nil
+	}
+}
@@ -1,9 +1,12 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/xml"
+	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"strings"
 	"time"
@@ -24,6 +27,7 @@ type rssChannel struct {
 type rssItem struct {
 	Title       string `xml:"title"`
 	Description string `xml:"description"`
+	PubDate     string `xml:"pubDate"`
 }
 
 // Atom feed structures
@@ -33,22 +37,38 @@ type atomFeed struct {
 }
 
 type atomEntry struct {
-	Title   string `xml:"title"`
-	Summary string `xml:"summary"`
+	Title     string `xml:"title"`
+	Summary   string `xml:"summary"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
 }
 
-func (f *Fetcher) fetchNews() (model.NewsData, map[string]any, error) {
+func (f *Fetcher) fetchNews(ctx context.Context) (model.NewsData, map[string]any, error) {
 	slog.Info("fetching news intelligence")
 
+	keywords := f.Keywords()
+	lookback := f.cfg.NewsLookback
+	now := time.Now()
 	var allArticles []map[string]any
+	var read []NewsItem
 	alertCount := 0
+	deescalationCount := 0
+	feeds := make([]model.FeedResult, 0, len(rssFeeds))
+	feedsOK := 0
 
 	for _, feedURL := range rssFeeds {
+		// Feeds not reached are left out rather than recorded as failing
+		if err := ctx.Err(); err != nil {
+			return model.NewsData{}, nil, failure(KindCanceled, "news: stopped after %d of %d feeds: %w", len(feeds), len(rssFeeds), err)
+		}
 		slog.Info("fetching RSS feed", "url", feedURL)
+		feeds = append(feeds, model.FeedResult{URL: feedURL})
+		feed := &feeds[len(feeds)-1]
 
-		req, err := http.NewRequest("GET", feedURL, nil)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 		if err != nil {
 			slog.Warn("news request create failed", "url", feedURL, "error", err)
+			feed.Error = err.Error()
 			continue
 		}
 		req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; StrikeRadar/1.0)")
@@ -56,6 +76,7 @@ func (f *Fetcher) fetchNews() (model.NewsData, map[string]any, error) {
 		resp, err := f.client.Do(req)
 		if err != nil {
 			slog.Warn("news fetch failed", "url", feedURL, "error", err)
+			feed.Error = err.Error()
 			continue
 		}
 
@@ -63,36 +84,66 @@ func (f *Fetcher) fetchNews() (model.NewsData, map[string]any, error) {
 		resp.Body.Close()
 		if err != nil {
 			slog.Warn("news read body failed", "url", feedURL, "error", err)
+			feed.Error = err.Error()
 			continue
 		}
 		if resp.StatusCode != 200 {
 			slog.Warn("news feed error", "url", feedURL, "status", resp.StatusCode)
+			feed.Error = fmt.Sprintf("status %d", resp.StatusCode)
 			continue
 		}
+		feed.Fetched = true
 
 		// Try RSS first, then Atom
-		items := parseRSS(body)
-		if len(items) == 0 {
-			items = parseAtom(body)
+		items, ok := parseRSS(body)
+		if !ok {
+			items, ok = parseAtom(body)
+		}
+		if !ok {
+			slog.Warn("news feed unparseable", "url", feedURL)
+			feed.Error = "not an RSS or Atom document"
+			continue
 		}
+		feed.Parsed = true
+		feed.Items = len(items)
+		feedsOK++
 
 		for _, item := range items {
-			combined := strings.ToLower(item.title + " " + item.desc)
-			if !containsAny(combined, iranKeywords) {
+			if lookback > 0 && !item.published.IsZero() && now.Sub(item.published) > lookback {
 				continue
 			}
-			isAlert := containsAny(combined, alertKeywords)
-			if isAlert {
-				alertCount++
-			}
 			title := item.title
 			if len(title) > 100 {
 				title = title[:100]
 			}
-			allArticles = append(allArticles, map[string]any{
+			text := item.title + " " + item.desc
+			read = append(read, NewsItem{Title: title, Text: text})
+			topicHits, alertHits := keywords.Match(text)
+			if len(topicHits) == 0 {
+				continue
+			}
+			feed.Matched++
+			isAlert := len(alertHits) > 0
+			if isAlert {
+				alertCount++
+			}
+			deescalationHits := keywords.MatchDeescalation(text)
+			if len(deescalationHits) > 0 {
+				deescalationCount++
+			}
+			article := map[string]any{
 				"title":    title,
 				"is_alert": isAlert,
-			})
+				"keywords": topicHits,
+			}
+			if isAlert {
+				article["alert_keywords"] = alertHits
+			}
+			if len(deescalationHits) > 0 {
+				article["is_deescalation"] = true
+				article["deescalation_keywords"] = deescalationHits
+			}
+			allArticles = append(allArticles, article)
 		}
 	}
 
@@ -101,61 +152,160 @@ func (f *Fetcher) fetchNews() (model.NewsData, map[string]any, error) {
 	var unique []map[string]any
 	for _, article := range allArticles {
 		title, _ := article["title"].(string)
-		key := strings.ToLower(title)
-		if len(key) > 40 {
-			key = key[:40]
-		}
-		if !seen[key] {
+		if key := articleKey(title); !seen[key] {
 			seen[key] = true
 			unique = append(unique, article)
 		}
 	}
 
-	slog.Info("news result", "articles", len(unique), "critical", alertCount)
+	slog.Info("news result", "articles", len(unique), "critical", alertCount, "deescalation", deescalationCount,
+		"feeds_ok", feedsOK, "feeds", len(feeds))
 
-	now := time.Now()
 	result := model.NewsData{
-		Articles:   unique,
-		TotalCount: len(unique),
-		AlertCount: alertCount,
-		Timestamp:  now.Format(time.RFC3339),
+		Articles:          unique,
+		TotalCount:        len(unique),
+		AlertCount:        alertCount,
+		DeescalationCount: deescalationCount,
+		Timestamp:         now.Format(time.RFC3339),
+		Feeds:             model.FeedCoverage{OK: feedsOK, Live: len(feeds), Results: feeds},
+	}
+	if lookback > 0 {
+		result.Lookback = shortDuration(lookback)
+		result.ArticlesPerHour = math.Round(float64(len(unique))/lookback.Hours()*10) / 10
 	}
 
 	rawMap := map[string]any{
-		"articles":    unique,
-		"total_count": len(unique),
-		"alert_count": alertCount,
-		"timestamp":   now.Format(time.RFC3339),
+		"articles":           unique,
+		"total_count":        len(unique),
+		"alert_count":        alertCount,
+		"deescalation_count": deescalationCount,
+		"timestamp":          now.Format(time.RFC3339),
+		"feeds":              result.Feeds,
+	}
+	if lookback > 0 {
+		rawMap["lookback"] = result.Lookback
+		rawMap["articles_per_hour"] = result.ArticlesPerHour
 	}
 
+	// Zero articles from zero feeds is an outage, not a quiet news day. The
+	// per-feed results are still returned for health tracking.
+	if feedsOK == 0 {
+		return result, rawMap, failure(KindNetwork, "news: none of %d feeds could be read", len(feeds))
+	}
+	f.mu.Lock()
+	f.lastNews, f.lastNewsKeywords = dedupeItems(read), keywords
+	f.mu.Unlock()
 	return result, rawMap, nil
 }
 
+// NewsItem is a feed item a news run read within its lookback. Text is
+// what keywords are matched against: the title and description together.
+// Title is cut to the length stored in the snapshot.
+type NewsItem struct {
+	Title string
+	Text  string
+}
+
+// LastNews returns the items the last successful news run read, one per
+// title as the run deduplicates its articles, and the keywords it
+// classified them with. ok is false until a run has read a feed.
+func (f *Fetcher) LastNews() (items []NewsItem, kw Keywords, ok bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.lastNews, f.lastNewsKeywords, f.lastNews != nil
+}
+
+// articleKey is what articles are deduplicated by: the start of the title,
+// regardless of case.
+func articleKey(title string) string {
+	key := strings.ToLower(title)
+	if len(key) > 40 {
+		key = key[:40]
+	}
+	return key
+}
+
+func dedupeItems(items []NewsItem) []NewsItem {
+	seen := make(map[string]bool)
+	unique := []NewsItem{}
+	for _, item := range items {
+		if key := articleKey(item.Title); !seen[key] {
+			seen[key] = true
+			unique = append(unique, item)
+		}
+	}
+	return unique
+}
+
+// newsItem is a feed entry. published is zero when the feed gave no
+// readable date.
 type newsItem struct {
-	title string
-	desc  string
+	title     string
+	desc      string
+	published time.Time
 }
 
-func parseRSS(data []byte) []newsItem {
+// feedTimeLayouts are the date formats seen in RSS pubDate (RFC 822 and
+// its common deviations) and Atom (RFC 3339) elements.
+var feedTimeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	time.RFC3339,
+}
+
+// shortDuration drops the zero minute and second fields time.Duration
+// prints, so 6h reads "6h" rather than "6h0m0s".
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseRSS returns the items of an RSS document, and false if data is not
+// one.
+func parseRSS(data []byte) ([]newsItem, bool) {
 	var feed rssRoot
 	if err := xml.Unmarshal(data, &feed); err != nil {
-		return nil
+		return nil, false
 	}
 	var items []newsItem
 	for _, item := range feed.Channel.Items {
-		items = append(items, newsItem{title: item.Title, desc: item.Description})
+		items = append(items, newsItem{title: item.Title, desc: item.Description, published: parseFeedTime(item.PubDate)})
 	}
-	return items
+	return items, true
 }
 
-func parseAtom(data []byte) []newsItem {
+// parseAtom returns the entries of an Atom document, and false if data is
+// not one.
+func parseAtom(data []byte) ([]newsItem, bool) {
 	var feed atomFeed
 	if err := xml.Unmarshal(data, &feed); err != nil {
-		return nil
+		return nil, false
 	}
 	var items []newsItem
 	for _, entry := range feed.Entries {
-		items = append(items, newsItem{title: entry.Title, desc: entry.Summary})
+		published := parseFeedTime(entry.Published)
+		if published.IsZero() {
+			published = parseFeedTime(entry.Updated)
+		}
+		items = append(items, newsItem{title: entry.Title, desc: entry.Summary, published: published})
 	}
-	return items
+	return items, true
 }
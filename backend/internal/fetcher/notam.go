@@ -0,0 +1,166 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const faaNotamAPI = "https://external-api.faa.gov/notamapi/v1/notams"
+
+// warningSubjects are the Q-code subjects of navigation warnings that read
+// as military: missile, gun or rocket firing, and exercises.
+var warningSubjects = map[string]bool{"WM": true, "WE": true}
+
+// notam is the part of an FAA NOTAM API record the airspace signal reads.
+type notam struct {
+	Number         string `json:"number"`
+	Location       string `json:"icaoLocation"`
+	SelectionCode  string `json:"selectionCode"`
+	EffectiveStart string `json:"effectiveStart"`
+	Text           string `json:"text"`
+}
+
+func (f *Fetcher) fetchAirspace(ctx context.Context) (model.AirspaceData, map[string]any, error) {
+	slog.Info("fetching notams")
+
+	cfg := f.cfg.Notam
+	if cfg.ClientID == "" {
+		return model.AirspaceData{}, nil, failure(KindAuth, "faa notam credentials not configured")
+	}
+
+	now := time.Now()
+	data := model.AirspaceData{Closures: []model.AirspaceClosure{}}
+	closed := make(map[string]bool)
+	var lastErr error
+	for _, loc := range cfg.Locations {
+		notams, err := f.notamsFor(ctx, loc)
+		if err != nil {
+			slog.Warn("notam fetch failed", "location", loc, "error", err)
+			data.Unavailable = append(data.Unavailable, loc)
+			lastErr = err
+			continue
+		}
+		for _, n := range notams {
+			// Scheduled NOTAMs are listed before they take effect
+			if start, err := time.Parse(time.RFC3339, n.EffectiveStart); err == nil && start.After(now) {
+				continue
+			}
+			data.NotamCount++
+			if kind := notamClosureKind(n); kind != "" {
+				if n.Location == "" {
+					n.Location = loc
+				}
+				// One closure is often repeated across several NOTAMs
+				if key := n.Location + "/" + kind; !closed[key] {
+					closed[key] = true
+					data.Closures = append(data.Closures, model.AirspaceClosure{Location: n.Location, Kind: kind, Number: n.Number})
+				}
+				continue
+			}
+			if notamRestriction(n) {
+				data.Restrictions++
+			} else if notamWarning(n) {
+				data.Warnings++
+			}
+		}
+	}
+	if len(data.Unavailable) == len(cfg.Locations) {
+		return model.AirspaceData{}, nil, lastErr
+	}
+	data.Timestamp = now.Format(time.RFC3339)
+
+	slog.Info("notams", "count", data.NotamCount, "closures", len(data.Closures), "restrictions", data.Restrictions, "warnings", data.Warnings, "unavailable", len(data.Unavailable))
+	return data, structToMap(data), nil
+}
+
+// notamsFor returns the NOTAMs the FAA lists for one ICAO location.
+func (f *Fetcher) notamsFor(ctx context.Context, location string) ([]notam, error) {
+	q := url.Values{
+		"icaoLocation":   {location},
+		"responseFormat": {"geoJson"},
+		"pageSize":       {"1000"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, faaNotamAPI+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, failure(KindUnknown, "notam request: %w", err)
+	}
+	req.Header.Set("client_id", f.cfg.Notam.ClientID)
+	req.Header.Set("client_secret", f.cfg.Notam.ClientSecret)
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, failure(KindNetwork, "notam request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, statusFailure("notam API error", resp.StatusCode)
+	}
+
+	var result struct {
+		Items []struct {
+			Properties struct {
+				CoreNOTAMData struct {
+					Notam notam `json:"notam"`
+				} `json:"coreNOTAMData"`
+			} `json:"properties"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, failure(KindParse, "notam parse: %w", err)
+	}
+	notams := make([]notam, len(result.Items))
+	for i, item := range result.Items {
+		notams[i] = item.Properties.CoreNOTAMData.Notam
+	}
+	return notams, nil
+}
+
+// notamQCode returns a NOTAM's Q-code subject (e.g. "AF", FIR) and
+// condition (e.g. "LC", closed), or empty strings when it has none.
+func notamQCode(n notam) (subject, condition string) {
+	code := strings.ToUpper(strings.TrimSpace(n.SelectionCode))
+	if len(code) != 5 || code[0] != 'Q' {
+		return "", ""
+	}
+	return code[1:3], code[3:5]
+}
+
+// notamClosureKind returns "fir" or "aerodrome" for a NOTAM closing one,
+// or "" for any other. NOTAMs without a Q-code are read from their text.
+func notamClosureKind(n notam) string {
+	subject, condition := notamQCode(n)
+	switch {
+	case subject == "AF" && condition == "LC":
+		return "fir"
+	case subject == "FA" && condition == "LC":
+		return "aerodrome"
+	case subject == "" && strings.Contains(strings.ToUpper(n.Text), "AIRSPACE CLOSED"):
+		return "fir"
+	}
+	return ""
+}
+
+// notamRestriction reports whether a NOTAM activates a restricted, danger
+// or prohibited area.
+func notamRestriction(n notam) bool {
+	subject, condition := notamQCode(n)
+	return subject != "" && subject[0] == 'R' && condition == "CA"
+}
+
+// notamWarning reports whether a NOTAM warns of military activity, by its
+// Q-code or, for conflict-zone bulletins, its text.
+func notamWarning(n notam) bool {
+	subject, _ := notamQCode(n)
+	if warningSubjects[subject] {
+		return true
+	}
+	text := strings.ToUpper(n.Text)
+	return strings.Contains(text, "CONFLICT ZONE") || strings.Contains(text, "MILITARY ACTIVITY")
+}
@@ -0,0 +1,142 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+)
+
+// WMO codes have no dust or haze class, so the fallback reads both from
+// the Open-Meteo air-quality API: dust at or above openMeteoDustUgm3
+// (µg/m³ near the surface) reports as OpenWeather's dust condition, and
+// aerosol optical depth at or above openMeteoHazeAOD as haze. Either only
+// replaces a clear or cloudy code; fog and precipitation stand.
+const (
+	openMeteoDustUgm3 = 150
+	openMeteoHazeAOD  = 0.6
+)
+
+// openMeteo is the keyless fallback provider (Open-Meteo forecast API,
+// current conditions).
+type openMeteo struct{}
+
+func (openMeteo) Name() string { return "open-meteo" }
+
+func (openMeteo) Current(ctx context.Context, f *Fetcher) (weatherObservation, error) {
+	resp, err := f.get(ctx, "https://api.open-meteo.com/v1/forecast?latitude=35.6892&longitude=51.389"+
+		"&current=temperature_2m,cloud_cover,wind_speed_10m,weather_code,visibility&wind_speed_unit=ms")
+	if err != nil {
+		return weatherObservation{}, failure(KindNetwork, "open-meteo request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return weatherObservation{}, statusFailure("open-meteo API error", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return weatherObservation{}, failure(KindNetwork, "open-meteo read body: %w", err)
+	}
+
+	var data struct {
+		Current *struct {
+			Temperature float64  `json:"temperature_2m"`
+			CloudCover  float64  `json:"cloud_cover"`
+			WindSpeed   float64  `json:"wind_speed_10m"`
+			WeatherCode int      `json:"weather_code"`
+			Visibility  *float64 `json:"visibility"`
+		} `json:"current"`
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return weatherObservation{}, failure(KindParse, "open-meteo parse: %w", err)
+	}
+	if data.Current == nil {
+		return weatherObservation{}, failure(KindParse, "open-meteo: no current data")
+	}
+
+	c := data.Current
+	id, desc := wmoCondition(c.WeatherCode)
+	obs := weatherObservation{
+		Temp:        c.Temperature,
+		Visibility:  10000,
+		Clouds:      int(c.CloudCover),
+		WindSpeed:   c.WindSpeed,
+		ConditionID: id,
+		Description: desc,
+	}
+	if c.Visibility != nil {
+		// OpenWeather caps visibility at 10 km; match it so thresholds agree
+		obs.Visibility = min(10000, int(*c.Visibility))
+	}
+	if c.WeatherCode <= 3 {
+		if dust, aod, err := openMeteoAerosols(ctx, f); err != nil {
+			// Dust and haze go unseen this run rather than failing the reading
+			slog.Warn("open-meteo air quality failed", "error", err)
+		} else if dust >= openMeteoDustUgm3 {
+			obs.ConditionID, obs.Description = 761, "dust"
+		} else if aod >= openMeteoHazeAOD {
+			obs.ConditionID, obs.Description = 721, "haze"
+		}
+	}
+	return obs, nil
+}
+
+// openMeteoAerosols returns the current surface dust concentration and
+// aerosol optical depth over Tehran.
+func openMeteoAerosols(ctx context.Context, f *Fetcher) (dust, aod float64, err error) {
+	resp, err := f.get(ctx, "https://air-quality-api.open-meteo.com/v1/air-quality?latitude=35.6892&longitude=51.389"+
+		"&current=dust,aerosol_optical_depth")
+	if err != nil {
+		return 0, 0, failure(KindNetwork, "open-meteo air quality request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return 0, 0, statusFailure("open-meteo air quality API error", resp.StatusCode)
+	}
+	var data struct {
+		Current *struct {
+			Dust float64 `json:"dust"`
+			AOD  float64 `json:"aerosol_optical_depth"`
+		} `json:"current"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return 0, 0, failure(KindParse, "open-meteo air quality parse: %w", err)
+	}
+	if data.Current == nil {
+		return 0, 0, failure(KindParse, "open-meteo air quality: no current data")
+	}
+	return data.Current.Dust, data.Current.AOD, nil
+}
+
+// wmoCondition maps a WMO weather interpretation code to the nearest
+// OpenWeather condition ID and a description.
+func wmoCondition(code int) (int, string) {
+	switch {
+	case code == 0:
+		return 800, "clear sky"
+	case code == 1:
+		return 801, "few clouds"
+	case code == 2:
+		return 802, "scattered clouds"
+	case code == 3:
+		return 804, "overcast clouds"
+	case code == 45 || code == 48:
+		return 741, "fog"
+	case code >= 51 && code <= 57:
+		return 300, "drizzle"
+	case code >= 61 && code <= 67:
+		return 500, "rain"
+	case code >= 71 && code <= 77:
+		return 600, "snow"
+	case code >= 80 && code <= 82:
+		return 521, "shower rain"
+	case code == 85 || code == 86:
+		return 621, "shower snow"
+	case code >= 95:
+		return 211, "thunderstorm"
+	}
+	return 800, "clear"
+}
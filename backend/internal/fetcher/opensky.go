@@ -0,0 +1,130 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+)
+
+const (
+	openSkyTokenURL = "https://auth.opensky-network.org/auth/realms/opensky-network/protocol/openid-connect/token"
+	// openSkyTokenMargin renews a token this long before it expires, so one
+	// doesn't lapse between being handed out and reaching the API.
+	openSkyTokenMargin = time.Minute
+)
+
+// openSkyStatesURL is the state-vector query for the aircraft inside box.
+func openSkyStatesURL(box config.BoundingBox) string {
+	return fmt.Sprintf("https://opensky-network.org/api/states/all?lamin=%g&lomin=%g&lamax=%g&lomax=%g",
+		box.LatMin, box.LonMin, box.LatMax, box.LonMax)
+}
+
+// openSkyGet requests an OpenSky API URL, authenticated when credentials
+// are configured. A 401 means the cached token was revoked or expired
+// early, so it is dropped and the request repeated once with a fresh one.
+// prefix names the caller in returned errors, which are already classified.
+func (f *Fetcher) openSkyGet(ctx context.Context, prefix, rawURL string) (*http.Response, error) {
+	if !f.cfg.OpenSky.Authenticated() {
+		resp, err := f.get(ctx, rawURL)
+		if err != nil {
+			return nil, failure(KindNetwork, "%s request: %w", prefix, err)
+		}
+		return resp, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		token, err := f.openSkyAccessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, failure(KindUnknown, "%s request: %w", prefix, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := f.client.Do(req)
+		if err != nil {
+			return nil, failure(KindNetwork, "%s request: %w", prefix, err)
+		}
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 1 {
+			return resp, nil
+		}
+		resp.Body.Close()
+		slog.Info("opensky: token rejected, renewing")
+		f.dropOpenSkyToken(token)
+	}
+}
+
+// openSkyAccessToken returns a cached bearer token, fetching a new one
+// with the client-credentials grant when none is cached or it is about
+// to expire.
+func (f *Fetcher) openSkyAccessToken(ctx context.Context) (string, error) {
+	f.openSkyMu.Lock()
+	defer f.openSkyMu.Unlock()
+	if f.openSkyToken != "" && time.Now().Before(f.openSkyUntil) {
+		return f.openSkyToken, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {f.cfg.OpenSky.ClientID},
+		"client_secret": {f.cfg.OpenSky.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, openSkyTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", failure(KindUnknown, "opensky token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", failure(KindNetwork, "opensky token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The token endpoint answers bad credentials with 400 invalid_client
+	if resp.StatusCode == http.StatusBadRequest {
+		return "", failure(KindAuth, "opensky token: credentials rejected")
+	}
+	if resp.StatusCode != 200 {
+		return "", statusFailure("opensky token error", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", failure(KindNetwork, "opensky token read body: %w", err)
+	}
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil || tok.AccessToken == "" {
+		return "", failure(KindParse, "opensky token parse: no access_token in response")
+	}
+
+	lifetime := time.Duration(tok.ExpiresIn)*time.Second - openSkyTokenMargin
+	if lifetime <= 0 {
+		// Too short-lived to cache; use it for this request only
+		return tok.AccessToken, nil
+	}
+	f.openSkyToken = tok.AccessToken
+	f.openSkyUntil = time.Now().Add(lifetime)
+	return tok.AccessToken, nil
+}
+
+// dropOpenSkyToken forgets token unless another fetch has already
+// replaced it.
+func (f *Fetcher) dropOpenSkyToken(token string) {
+	f.openSkyMu.Lock()
+	defer f.openSkyMu.Unlock()
+	if f.openSkyToken == token {
+		f.openSkyToken = ""
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
@@ -15,6 +16,39 @@ func (f *Fetcher) fetchPentagon() (model.PentagonData, map[string]any) {
 	slog.Info("computing pentagon pizza meter")
 
 	now := time.Now()
+	var readings []model.PizzaReading
+	busiest := -1
+	for _, cluster := range f.cfg.PizzaClusters {
+		reading := pizzaReading(cluster, now.In(cluster.Location))
+		// The earliest listed cluster wins a tie
+		if busiest < 0 || reading.Score > readings[busiest].Score {
+			busiest = len(readings)
+		}
+		readings = append(readings, reading)
+	}
+
+	result := model.PentagonData{Score: 30, Timestamp: now.Format(time.RFC3339)}
+	if busiest >= 0 {
+		top := readings[busiest]
+		result.Score = top.Score
+		result.Places = top.Places
+		result.IsLateNight = top.IsLateNight
+		result.IsWeekend = top.IsWeekend
+		result.Cluster = top.Name
+		if len(readings) > 1 {
+			result.Clusters = readings
+		}
+	}
+	result.RiskContribution, result.Status = pizzaStatus(result.Score)
+
+	slog.Info("pentagon result", "cluster", result.Cluster, "status", result.Status, "score", result.Score, "risk_contribution", result.RiskContribution)
+	rawMap := structToMap(result)
+	return result, rawMap
+}
+
+// pizzaReading scores one cluster's places at now, given in the cluster's
+// own time zone.
+func pizzaReading(cluster config.PizzaCluster, now time.Time) model.PizzaReading {
 	currentHour := now.Hour()
 	currentDay := now.Weekday() // Sunday=0, need Monday=0
 
@@ -23,7 +57,7 @@ func (f *Fetcher) fetchPentagon() (model.PentagonData, map[string]any) {
 
 	var busynessData []map[string]any
 
-	for _, place := range pizzaPlaces {
+	for _, place := range cluster.Places {
 		baseScore := 30
 		status := "normal"
 
@@ -51,7 +85,7 @@ func (f *Fetcher) fetchPentagon() (model.PentagonData, map[string]any) {
 			"status": status,
 			"score":  baseScore,
 		})
-		slog.Info("pentagon place", "name", place.Name, "status", status, "score", baseScore)
+		slog.Info("pentagon place", "cluster", cluster.Name, "name", place.Name, "status", status, "score", baseScore)
 	}
 
 	isLateNight := currentHour >= 22 || currentHour < 6
@@ -77,34 +111,27 @@ func (f *Fetcher) fetchPentagon() (model.PentagonData, map[string]any) {
 		avg := totalScore / float64(validReadings)
 		activityScore = int(math.Round(math.Min(100, math.Max(0, avg))))
 	}
-
-	var riskContribution int
-	var pentagonStatus string
-	if activityScore >= 80 {
-		riskContribution = 10
-		pentagonStatus = "High Activity"
-	} else if activityScore >= 60 {
-		riskContribution = 7
-		pentagonStatus = "Elevated"
-	} else if activityScore >= 40 {
-		riskContribution = 3
-		pentagonStatus = "Normal"
-	} else {
-		riskContribution = 1
-		pentagonStatus = "Low Activity"
+	_, status := pizzaStatus(activityScore)
+
+	return model.PizzaReading{
+		Name:        cluster.Name,
+		Score:       activityScore,
+		Status:      status,
+		Places:      busynessData,
+		IsLateNight: isLateNight,
+		IsWeekend:   isWeekend,
 	}
+}
 
-	slog.Info("pentagon result", "status", pentagonStatus, "score", activityScore, "risk_contribution", riskContribution)
-
-	result := model.PentagonData{
-		Score:            activityScore,
-		RiskContribution: riskContribution,
-		Status:           pentagonStatus,
-		Places:           busynessData,
-		Timestamp:        now.Format(time.RFC3339),
-		IsLateNight:      isLateNight,
-		IsWeekend:        isWeekend,
+// pizzaStatus maps an activity score to its risk contribution and status.
+func pizzaStatus(activityScore int) (int, string) {
+	switch {
+	case activityScore >= 80:
+		return 10, "High Activity"
+	case activityScore >= 60:
+		return 7, "Elevated"
+	case activityScore >= 40:
+		return 3, "Normal"
 	}
-	rawMap := structToMap(result)
-	return result, rawMap
+	return 1, "Low Activity"
 }
@@ -1,11 +1,13 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"math"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
@@ -13,29 +15,80 @@ import (
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
-func (f *Fetcher) fetchPolymarket() (model.PolymarketData, map[string]any, error) {
+func (f *Fetcher) fetchPolymarket(ctx context.Context) (model.PolymarketData, map[string]any, error) {
 	slog.Info("fetching polymarket odds")
 
-	resp, err := f.client.Get("https://gamma-api.polymarket.com/public-search?q=iran")
+	_, result, err := f.matchPolymarket(ctx, f.MarketRules())
 	if err != nil {
-		return model.PolymarketData{}, nil, fmt.Errorf("polymarket request: %w", err)
+		return model.PolymarketData{}, nil, err
+	}
+	slog.Info("polymarket result", "odds", result.Odds, "market", truncate(result.Market, 70))
+
+	// The book only qualifies the odds, so a failed lookup leaves it unset
+	if result.TokenID != "" {
+		book, err := f.fetchOrderBook(ctx, result.TokenID)
+		if err != nil {
+			slog.Warn("polymarket order book unavailable", "token_id", result.TokenID, "error", err)
+		} else {
+			result.OrderBook = book
+			slog.Info("polymarket order book", "spread", book.Spread, "bid_depth", book.BidDepth, "ask_depth", book.AskDepth)
+		}
+	}
+
+	rawMap := structToMap(result)
+	return result, rawMap, nil
+}
+
+// PolymarketDryRun searches Polymarket with candidate rules and returns every
+// market they match plus the result a fetch would produce, without applying
+// the rules.
+func (f *Fetcher) PolymarketDryRun(ctx context.Context, rules MarketRules) ([]MarketMatch, model.PolymarketData, error) {
+	return f.matchPolymarket(ctx, rules)
+}
+
+func (f *Fetcher) matchPolymarket(ctx context.Context, rules MarketRules) ([]MarketMatch, model.PolymarketData, error) {
+	events, err := f.searchPolymarket(ctx, rules.Query)
+	if err != nil {
+		return nil, model.PolymarketData{}, err
+	}
+	slog.Info("polymarket scanning events", "count", len(events))
+
+	now := time.Now()
+	matches := rules.Match(events, f.Keywords().Negative, now)
+	result := model.PolymarketData{Timestamp: now.Format(time.RFC3339)}
+	for _, m := range matches {
+		if m.Odds > result.Odds {
+			result.Odds = m.Odds
+			result.Market = m.Question
+			result.TokenID = m.TokenID
+		}
+	}
+	return matches, result, nil
+}
+
+// searchPolymarket runs a public search and returns the events in the
+// response.
+func (f *Fetcher) searchPolymarket(ctx context.Context, query string) ([]map[string]any, error) {
+	resp, err := f.get(ctx, "https://gamma-api.polymarket.com/public-search?q="+url.QueryEscape(query))
+	if err != nil {
+		return nil, failure(KindNetwork, "polymarket request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return model.PolymarketData{}, nil, fmt.Errorf("polymarket API error: %d", resp.StatusCode)
+		return nil, statusFailure("polymarket API error", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return model.PolymarketData{}, nil, fmt.Errorf("polymarket read body: %w", err)
+		return nil, failure(KindNetwork, "polymarket read body: %w", err)
 	}
 
 	// Parse response - could be list or object with events/data key
 	var events []map[string]any
 	var raw any
 	if err := json.Unmarshal(body, &raw); err != nil {
-		return model.PolymarketData{}, nil, fmt.Errorf("polymarket parse: %w", err)
+		return nil, failure(KindParse, "polymarket parse: %w", err)
 	}
 
 	switch v := raw.(type) {
@@ -66,104 +119,86 @@ func (f *Fetcher) fetchPolymarket() (model.PolymarketData, map[string]any, error
 	}
 
 	if events == nil {
-		return model.PolymarketData{}, nil, fmt.Errorf("unexpected polymarket response format")
+		return nil, failure(KindParse, "unexpected polymarket response format")
 	}
+	return events, nil
+}
 
-	slog.Info("polymarket scanning events", "count", len(events))
+// bookDepthBand is how far from the best price resting orders count towards
+// depth: liquidity five cents away still moves the price if the market is
+// hit, anything further out mostly does not.
+const bookDepthBand = 0.05
 
-	highestOdds := 0
-	marketTitle := ""
-	now := time.Now()
+// fetchOrderBook reads the CLOB book for one outcome token and summarizes
+// the top of book.
+func (f *Fetcher) fetchOrderBook(ctx context.Context, tokenID string) (*model.OrderBook, error) {
+	resp, err := f.get(ctx, "https://clob.polymarket.com/book?token_id="+url.QueryEscape(tokenID))
+	if err != nil {
+		return nil, failure(KindNetwork, "polymarket book request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, statusFailure("polymarket book API error", resp.StatusCode)
+	}
 
-	// First pass: specific strike markets
-	for _, event := range events {
-		eventTitle := strings.ToLower(getString(event, "title"))
+	type level struct {
+		Price string `json:"price"`
+		Size  string `json:"size"`
+	}
+	var raw struct {
+		Bids []level `json:"bids"`
+		Asks []level `json:"asks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, failure(KindParse, "polymarket book parse: %w", err)
+	}
+	if len(raw.Bids) == 0 || len(raw.Asks) == 0 {
+		return nil, failure(KindParse, "polymarket book is one-sided (%d bids, %d asks)", len(raw.Bids), len(raw.Asks))
+	}
 
-		if strings.Contains(eventTitle, "will us or israel strike iran") ||
-			strings.Contains(eventTitle, "us strikes iran by") {
-			if !isNearTermMarket(getString(event, "title"), now) {
-				continue
-			}
-			if markets, ok := event["markets"].([]any); ok {
-				for _, m := range markets {
-					if market, ok := m.(map[string]any); ok {
-						odds := getMarketOdds(market)
-						if odds > highestOdds {
-							highestOdds = odds
-							marketTitle = getStringOr(market, "question", getString(event, "title"))
-						}
-					}
-				}
-			}
+	// Levels are not guaranteed to be sorted best-first
+	book := &model.OrderBook{BestBid: 0, BestAsk: 1, DepthBand: bookDepthBand}
+	for _, l := range raw.Bids {
+		book.BestBid = math.Max(book.BestBid, toFloat(l.Price))
+	}
+	for _, l := range raw.Asks {
+		book.BestAsk = math.Min(book.BestAsk, toFloat(l.Price))
+	}
+	for _, l := range raw.Bids {
+		if p := toFloat(l.Price); p >= book.BestBid-bookDepthBand {
+			book.BidDepth += p * toFloat(l.Size)
 		}
-
-		if markets, ok := event["markets"].([]any); ok {
-			for _, m := range markets {
-				if market, ok := m.(map[string]any); ok {
-					question := strings.ToLower(getString(market, "question"))
-					if containsAny(question, negativeKeywords) {
-						continue
-					}
-					if strings.Contains(question, "iran") && containsAny(question, strikeKeywords) {
-						name := getString(market, "question")
-						if !isNearTermMarket(name, now) {
-							continue
-						}
-						odds := getMarketOdds(market)
-						if odds > 0 && odds > highestOdds {
-							highestOdds = odds
-							marketTitle = name
-						}
-					}
-				}
-			}
+	}
+	for _, l := range raw.Asks {
+		if p := toFloat(l.Price); p <= book.BestAsk+bookDepthBand {
+			book.AskDepth += p * toFloat(l.Size)
 		}
 	}
+	book.Spread = math.Round((book.BestAsk-book.BestBid)*1000) / 1000
+	book.BidDepth = math.Round(book.BidDepth)
+	book.AskDepth = math.Round(book.AskDepth)
+	return book, nil
+}
 
-	// Second pass: any Iran-related market
-	if highestOdds == 0 {
-		for _, event := range events {
-			eventTitle := strings.ToLower(getString(event, "title"))
-			if containsAny(eventTitle, negativeKeywords) {
-				continue
-			}
-			if !strings.Contains(eventTitle, "iran") {
-				continue
-			}
-			if !isNearTermMarket(getString(event, "title"), now) {
-				continue
-			}
-			if markets, ok := event["markets"].([]any); ok {
-				for _, m := range markets {
-					if market, ok := m.(map[string]any); ok {
-						question := strings.ToLower(getString(market, "question"))
-						if containsAny(question, negativeKeywords) {
-							continue
-						}
-						name := getStringOr(market, "question", getString(event, "title"))
-						if !isNearTermMarket(name, now) {
-							continue
-						}
-						odds := getMarketOdds(market)
-						if odds > 0 && odds > highestOdds {
-							highestOdds = odds
-							marketTitle = name
-						}
-					}
-				}
+// yesTokenID returns the CLOB token of a market's first (YES) outcome. The
+// gamma API encodes clobTokenIds as a JSON string holding an array.
+func yesTokenID(market map[string]any) string {
+	var ids []string
+	switch v := market["clobTokenIds"].(type) {
+	case string:
+		json.Unmarshal([]byte(v), &ids)
+	case []any:
+		for _, id := range v {
+			if s, ok := id.(string); ok {
+				ids = append(ids, s)
 			}
 		}
 	}
-
-	slog.Info("polymarket result", "odds", highestOdds, "market", truncate(marketTitle, 70))
-
-	result := model.PolymarketData{
-		Odds:      highestOdds,
-		Market:    marketTitle,
-		Timestamp: now.Format(time.RFC3339),
+	if len(ids) == 0 {
+		return ""
 	}
-	rawMap := structToMap(result)
-	return result, rawMap, nil
+	return ids[0]
 }
 
 func getMarketOdds(market map[string]any) int {
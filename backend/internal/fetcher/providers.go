@@ -0,0 +1,123 @@
+package fetcher
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// providerWindow is how many recent fetches a provider is judged on.
+	providerWindow = 20
+	// providerLatencyScale is the mean latency that halves a provider's
+	// score against an equally reliable one that answers instantly.
+	providerLatencyScale = 2 * time.Second
+	// providerProbeEvery is how many of a signal's fetches pass between
+	// probes of the providers it didn't use, so a provider that lost the
+	// ranking is judged on recent outcomes rather than the ones it lost on.
+	providerProbeEvery = 12
+)
+
+// providerStats holds a provider's most recent fetch outcomes.
+type providerStats struct {
+	mu       sync.Mutex
+	outcomes [providerWindow]providerOutcome
+	n        int
+	next     int
+}
+
+type providerOutcome struct {
+	ok      bool
+	latency time.Duration
+}
+
+// record adds one fetch outcome, dropping the oldest once the window is
+// full.
+func (s *providerStats) record(o providerOutcome) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outcomes[s.next] = o
+	s.next = (s.next + 1) % providerWindow
+	s.n = min(s.n+1, providerWindow)
+}
+
+// score rates the provider from 0 to 1: its success rate over the window,
+// discounted by the mean latency of its successful fetches. tried is false
+// for a provider not tried yet, which has no score of its own.
+func (s *providerStats) score() (score float64, tried bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.n == 0 {
+		return 0, false
+	}
+	var ok int
+	var latency time.Duration
+	for _, o := range s.outcomes[:s.n] {
+		if o.ok {
+			ok++
+			latency += o.latency
+		}
+	}
+	if ok == 0 {
+		return 0, true
+	}
+	mean := latency / time.Duration(ok)
+	return float64(ok) / float64(s.n) / (1 + float64(mean)/float64(providerLatencyScale)), true
+}
+
+// providerStats returns the stats for one of a signal's providers.
+func (f *Fetcher) providerStats(signal, provider string) *providerStats {
+	f.providerMu.Lock()
+	defer f.providerMu.Unlock()
+	key := signal + "/" + provider
+	s, ok := f.providers[key]
+	if !ok {
+		s = &providerStats{}
+		f.providers[key] = s
+	}
+	return s
+}
+
+// rankProviders orders a signal's providers healthiest first. Providers
+// that score the same keep the order given, and one not tried yet is
+// assumed to score as the primary does, so a configured primary stays first
+// until a fallback has done better.
+func rankProviders[P interface{ Name() string }](f *Fetcher, signal string, providers []P) []P {
+	scores := make(map[string]float64, len(providers))
+	prior := 1.0
+	for i, p := range providers {
+		score, ok := f.providerStats(signal, p.Name()).score()
+		if !ok {
+			score = prior
+		}
+		if i == 0 {
+			prior = score
+		}
+		scores[p.Name()] = score
+	}
+	ranked := append([]P(nil), providers...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].Name()] > scores[ranked[j].Name()]
+	})
+	return ranked
+}
+
+// probeDue reports whether this fetch of signal should also probe the
+// providers it doesn't use, which it does every providerProbeEvery fetches.
+func (f *Fetcher) probeDue(signal string) bool {
+	f.providerMu.Lock()
+	defer f.providerMu.Unlock()
+	f.probes[signal]++
+	return f.probes[signal]%providerProbeEvery == 0
+}
+
+// recordProvider notes how one provider's fetch went. A fetch cut short by
+// ctx, the run, ending says nothing about the provider and isn't counted;
+// one that timed out on its own does.
+func (f *Fetcher) recordProvider(ctx context.Context, signal, provider string, latency time.Duration, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	f.providerStats(signal, provider).record(providerOutcome{ok: err == nil, latency: latency})
+}
@@ -0,0 +1,97 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+)
+
+var rateLimitWait = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "aegis",
+	Subsystem: "fetcher",
+	Name:      "rate_limit_wait_seconds_total",
+	Help:      "Time upstream requests spent waiting on a host rate limit, by host.",
+}, []string{"host"})
+
+// rateLimitTransport holds each request to a rate-limited host until that
+// host's bucket has a token. It sits below retryTransport, so retries are
+// paced along with first attempts.
+type rateLimitTransport struct {
+	next    http.RoundTripper
+	buckets map[string]*bucket
+}
+
+func newRateLimitTransport(next http.RoundTripper, limits []config.RateLimit) *rateLimitTransport {
+	buckets := make(map[string]*bucket, len(limits))
+	for _, rl := range limits {
+		buckets[rl.Host] = &bucket{interval: rl.Interval, burst: float64(rl.Burst), tokens: float64(rl.Burst)}
+	}
+	return &rateLimitTransport{next: next, buckets: buckets}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	if b, ok := t.buckets[host]; ok {
+		wait, err := b.wait(req.Context(), time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if wait > 0 {
+			slog.Info("fetch: waiting for rate limit", "host", host, "wait", wait)
+			rateLimitWait.WithLabelValues(host).Add(wait.Seconds())
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+// bucket is a token bucket refilled at one token per interval, holding at
+// most burst.
+type bucket struct {
+	interval time.Duration
+	burst    float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token, blocking until one is available or ctx is done, and
+// returns how long it blocked. Tokens are reserved up front, so concurrent
+// callers queue in arrival order rather than racing for each refill.
+func (b *bucket) wait(ctx context.Context, now time.Time) (time.Duration, error) {
+	b.mu.Lock()
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+float64(now.Sub(b.last))/float64(b.interval))
+	}
+	b.last = now
+	b.tokens--
+	var d time.Duration
+	if b.tokens < 0 {
+		d = time.Duration(-b.tokens * float64(b.interval))
+	}
+	b.mu.Unlock()
+	if d == 0 {
+		return 0, nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// Give the reservation back for whoever is queued behind
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return 0, ctx.Err()
+	case <-timer.C:
+		return d, nil
+	}
+}
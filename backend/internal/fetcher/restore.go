@@ -0,0 +1,258 @@
+package fetcher
+
+import "github.com/backyonatan-alt/aegis/backend/internal/model"
+
+// Restore functions convert the raw_data maps of a previous snapshot back to
+// typed data, for scoring runs where a fetch failed.
+
+func restorePolymarket(m map[string]any) model.PolymarketData {
+	d := model.PolymarketData{
+		Odds:      intFromAny(m["odds"]),
+		Market:    strFromAny(m["market"]),
+		TokenID:   strFromAny(m["token_id"]),
+		Timestamp: strFromAny(m["timestamp"]),
+	}
+	if b, ok := m["order_book"].(map[string]any); ok {
+		d.OrderBook = &model.OrderBook{
+			BestBid:   floatFromAny(b["best_bid"]),
+			BestAsk:   floatFromAny(b["best_ask"]),
+			Spread:    floatFromAny(b["spread"]),
+			BidDepth:  floatFromAny(b["bid_depth"]),
+			AskDepth:  floatFromAny(b["ask_depth"]),
+			DepthBand: floatFromAny(b["depth_band"]),
+		}
+	}
+	return d
+}
+
+func restoreNews(m map[string]any) model.NewsData {
+	d := model.NewsData{
+		TotalCount:        intFromAny(m["total_count"]),
+		AlertCount:        intFromAny(m["alert_count"]),
+		DeescalationCount: intFromAny(m["deescalation_count"]),
+		Timestamp:         strFromAny(m["timestamp"]),
+		Lookback:          strFromAny(m["lookback"]),
+		ArticlesPerHour:   floatFromAny(m["articles_per_hour"]),
+	}
+	if f, ok := m["feeds"].(map[string]any); ok {
+		d.Feeds = model.FeedCoverage{OK: intFromAny(f["ok"]), Live: intFromAny(f["live"])}
+	}
+	return d
+}
+
+func restoreAviation(m map[string]any) model.AviationData {
+	return model.AviationData{
+		AircraftCount:   intFromAny(m["aircraft_count"]),
+		AirlineCount:    intFromAny(m["airline_count"]),
+		MajorCarriers:   strSliceFromAny(m["major_carriers"]),
+		MissingCarriers: strSliceFromAny(m["missing_carriers"]),
+		Baseline:        floatFromAny(m["baseline"]),
+		BaselineSamples: intFromAny(m["baseline_samples"]),
+		Timestamp:       strFromAny(m["timestamp"]),
+	}
+}
+
+func restoreWeather(m map[string]any) model.WeatherData {
+	return model.WeatherData{
+		Temp:        intFromAny(m["temp"]),
+		Visibility:  intFromAny(m["visibility"]),
+		Clouds:      intFromAny(m["clouds"]),
+		WindSpeed:   floatFromAny(m["wind_speed"]),
+		ConditionID: intFromAny(m["condition_id"]),
+		Dust:        boolFromAny(m["dust"]),
+		Haze:        boolFromAny(m["haze"]),
+		Description: strFromAny(m["description"]),
+		Condition:   strFromAny(m["condition"]),
+		Timestamp:   strFromAny(m["timestamp"]),
+	}
+}
+
+func restoreConnectivity(m map[string]any) model.ConnectivityData {
+	return model.ConnectivityData{
+		Status:    strFromAny(m["status"]),
+		Risk:      floatFromAny(m["risk"]),
+		Trend:     floatFromAny(m["trend"]),
+		Timestamp: strFromAny(m["timestamp"]),
+	}
+}
+
+func restoreTanker(m map[string]any) model.TankerData {
+	return model.TankerData{
+		TankerCount:     intFromAny(m["tanker_count"]),
+		Baseline:        floatFromAny(m["baseline"]),
+		BaselineSamples: intFromAny(m["baseline_samples"]),
+		Timestamp:       strFromAny(m["timestamp"]),
+	}
+}
+
+func restorePentagon(m map[string]any) model.PentagonData {
+	return model.PentagonData{
+		Score:            intFromAny(m["score"]),
+		RiskContribution: intFromAny(m["risk_contribution"]),
+		Status:           strFromAny(m["status"]),
+		Timestamp:        strFromAny(m["timestamp"]),
+		IsLateNight:      boolFromAny(m["is_late_night"]),
+		IsWeekend:        boolFromAny(m["is_weekend"]),
+		Cluster:          strFromAny(m["cluster"]),
+	}
+}
+
+func restoreAttention(m map[string]any) model.AttentionData {
+	d := model.AttentionData{Timestamp: strFromAny(m["timestamp"])}
+	items, _ := m["components"].([]any)
+	for _, item := range items {
+		c, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		d.Components = append(d.Components, model.AttentionComponent{
+			Source: strFromAny(c["source"]),
+			Ratio:  floatFromAny(c["ratio"]),
+			Detail: strFromAny(c["detail"]),
+		})
+	}
+	return d
+}
+
+func restoreGeopolitics(m map[string]any) model.GeopoliticsData {
+	return model.GeopoliticsData{
+		ArticleCount:   intFromAny(m["article_count"]),
+		BaselinePerDay: floatFromAny(m["baseline_per_day"]),
+		Ratio:          floatFromAny(m["ratio"]),
+		AverageTone:    floatFromAny(m["average_tone"]),
+		BaselineTone:   floatFromAny(m["baseline_tone"]),
+		Timestamp:      strFromAny(m["timestamp"]),
+	}
+}
+
+func restoreShipping(m map[string]any) model.ShippingData {
+	return model.ShippingData{
+		VesselCount:     intFromAny(m["vessel_count"]),
+		TankerCount:     intFromAny(m["tanker_count"]),
+		Baseline:        floatFromAny(m["baseline"]),
+		TankerBaseline:  floatFromAny(m["tanker_baseline"]),
+		BaselineSamples: intFromAny(m["baseline_samples"]),
+		Timestamp:       strFromAny(m["timestamp"]),
+	}
+}
+
+func restoreAirspace(m map[string]any) model.AirspaceData {
+	d := model.AirspaceData{
+		NotamCount:   intFromAny(m["notam_count"]),
+		Closures:     []model.AirspaceClosure{},
+		Restrictions: intFromAny(m["restrictions"]),
+		Warnings:     intFromAny(m["warnings"]),
+		Unavailable:  strSliceFromAny(m["unavailable"]),
+		Timestamp:    strFromAny(m["timestamp"]),
+	}
+	items, _ := m["closures"].([]any)
+	for _, item := range items {
+		c, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		d.Closures = append(d.Closures, model.AirspaceClosure{
+			Location: strFromAny(c["location"]),
+			Kind:     strFromAny(c["kind"]),
+			Number:   strFromAny(c["number"]),
+		})
+	}
+	return d
+}
+
+func restoreSeismic(m map[string]any) model.SeismicData {
+	d := model.SeismicData{
+		EventCount: intFromAny(m["event_count"]),
+		Suspicious: intFromAny(m["suspicious"]),
+		Events:     []model.SeismicEvent{},
+		Timestamp:  strFromAny(m["timestamp"]),
+	}
+	items, _ := m["events"].([]any)
+	for _, item := range items {
+		e, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		d.Events = append(d.Events, model.SeismicEvent{
+			ID:         strFromAny(e["id"]),
+			Place:      strFromAny(e["place"]),
+			Magnitude:  floatFromAny(e["magnitude"]),
+			DepthKm:    floatFromAny(e["depth_km"]),
+			Lat:        floatFromAny(e["lat"]),
+			Lon:        floatFromAny(e["lon"]),
+			Time:       strFromAny(e["time"]),
+			Type:       strFromAny(e["type"]),
+			Suspicious: e["suspicious"] == true,
+		})
+	}
+	return d
+}
+
+func restoreGPS(m map[string]any) model.GPSData {
+	d := model.GPSData{
+		Zones:       []model.GPSZone{},
+		Unavailable: strSliceFromAny(m["unavailable"]),
+		Timestamp:   strFromAny(m["timestamp"]),
+	}
+	items, _ := m["zones"].([]any)
+	for _, item := range items {
+		z, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		d.Zones = append(d.Zones, model.GPSZone{
+			Name:     strFromAny(z["name"]),
+			Aircraft: intFromAny(z["aircraft"]),
+			Degraded: intFromAny(z["degraded"]),
+			Ratio:    floatFromAny(z["ratio"]),
+		})
+	}
+	return d
+}
+
+func intFromAny(v any) int {
+	switch n := v.(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
+
+func strFromAny(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func strSliceFromAny(v any) []string {
+	var out []string
+	switch arr := v.(type) {
+	case []any:
+		for _, item := range arr {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+	case []string:
+		out = arr
+	}
+	return out
+}
+
+func boolFromAny(v any) bool {
+	b, _ := v.(bool)
+	return b
+}
+
+func floatFromAny(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package fetcher
+
+import (
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+)
+
+var fetchRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "aegis",
+	Subsystem: "fetcher",
+	Name:      "retries_total",
+	Help:      "Upstream requests retried after a transient failure, by host.",
+}, []string{"host"})
+
+// retryTransport retries idempotent requests that failed transiently, per
+// config.Retry. It sits under the shared client, so every fetcher gets the
+// same policy and the client timeout still bounds all attempts together.
+type retryTransport struct {
+	next     http.RoundTripper
+	cfg      config.Retry
+	statuses map[int]bool
+}
+
+func newRetryTransport(next http.RoundTripper, cfg config.Retry) *retryTransport {
+	statuses := make(map[int]bool, len(cfg.Statuses))
+	for _, code := range cfg.Statuses {
+		statuses[code] = true
+	}
+	return &retryTransport{next: next, cfg: cfg, statuses: statuses}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.cfg.Attempts || !t.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		attrs := []any{"host", req.URL.Host, "attempt", attempt, "wait", wait}
+		if resp != nil {
+			if d, ok := retryAfter(resp); ok && d > wait {
+				wait = min(d, t.cfg.MaxBackoff)
+			}
+			attrs = append(attrs, "status", resp.StatusCode)
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		} else {
+			attrs = append(attrs, "error", err)
+		}
+		slog.Info("fetch: retrying", attrs...)
+		fetchRetries.WithLabelValues(req.URL.Host).Inc()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether a failed attempt is worth repeating. Only
+// requests without a body are retried; every fetcher issues plain GETs.
+func (t *retryTransport) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if err != nil {
+		// A cancelled or timed-out request has no time left to retry in
+		return req.Context().Err() == nil
+	}
+	return t.statuses[resp.StatusCode]
+}
+
+// backoff is the wait before retry n (from 1): Backoff doubled n-1 times,
+// capped at MaxBackoff, then jittered to between half and all of that.
+func (t *retryTransport) backoff(n int) time.Duration {
+	d := t.cfg.Backoff
+	for i := 1; i < n && d < t.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	d = min(d, t.cfg.MaxBackoff)
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryAfter reads a Retry-After header given in seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
@@ -0,0 +1,115 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	usgsEventAPI = "https://earthquake.usgs.gov/fdsnws/event/1/query"
+	// seismicWindow is how far back the feed is read.
+	seismicWindow = 24 * time.Hour
+	// Natural earthquakes in Iran mostly start 10 km down or deeper; an
+	// event shallower than seismicShallowKm at no more than
+	// seismicMaxMagnitude looks more like a detonation near the surface.
+	seismicShallowKm    = 5.0
+	seismicMaxMagnitude = 4.5
+)
+
+func (f *Fetcher) fetchSeismic(ctx context.Context) (model.SeismicData, map[string]any, error) {
+	slog.Info("fetching usgs seismic events")
+
+	box := f.cfg.SeismicBox
+	now := time.Now()
+	q := url.Values{
+		"format":       {"geojson"},
+		"starttime":    {now.Add(-seismicWindow).UTC().Format(time.RFC3339)},
+		"minlatitude":  {fmt.Sprint(box.LatMin)},
+		"maxlatitude":  {fmt.Sprint(box.LatMax)},
+		"minlongitude": {fmt.Sprint(box.LonMin)},
+		"maxlongitude": {fmt.Sprint(box.LonMax)},
+		"orderby":      {"time"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, usgsEventAPI+"?"+q.Encode(), nil)
+	if err != nil {
+		return model.SeismicData{}, nil, failure(KindUnknown, "usgs request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return model.SeismicData{}, nil, failure(KindNetwork, "usgs request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return model.SeismicData{}, nil, statusFailure("usgs API error", resp.StatusCode)
+	}
+
+	var result struct {
+		Features []struct {
+			ID         string `json:"id"`
+			Properties struct {
+				Mag   *float64 `json:"mag"`
+				Place string   `json:"place"`
+				Time  int64    `json:"time"`
+				Type  string   `json:"type"`
+			} `json:"properties"`
+			Geometry struct {
+				Coordinates []float64 `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return model.SeismicData{}, nil, failure(KindParse, "usgs parse: %w", err)
+	}
+
+	data := model.SeismicData{Events: []model.SeismicEvent{}, Timestamp: now.Format(time.RFC3339)}
+	for _, feat := range result.Features {
+		// Coordinates are longitude, latitude and depth in km
+		coords := feat.Geometry.Coordinates
+		if len(coords) < 3 {
+			continue
+		}
+		e := model.SeismicEvent{
+			ID:      feat.ID,
+			Place:   feat.Properties.Place,
+			DepthKm: coords[2],
+			Lat:     coords[1],
+			Lon:     coords[0],
+			Time:    time.UnixMilli(feat.Properties.Time).UTC().Format(time.RFC3339),
+			Type:    feat.Properties.Type,
+		}
+		if feat.Properties.Mag != nil {
+			e.Magnitude = *feat.Properties.Mag
+		}
+		e.Suspicious = seismicSuspicious(e)
+		if e.Suspicious {
+			data.Suspicious++
+		}
+		data.Events = append(data.Events, e)
+	}
+	data.EventCount = len(data.Events)
+
+	slog.Info("usgs seismic events", "count", data.EventCount, "suspicious", data.Suspicious)
+	return data, structToMap(data), nil
+}
+
+// seismicSuspicious reports whether an event looks unlike natural
+// seismicity: USGS typed it an explosion, or it was both very shallow and
+// small. Quarry and mining blasts are typed apart and left out.
+func seismicSuspicious(e model.SeismicEvent) bool {
+	if strings.Contains(e.Type, "blast") {
+		return false
+	}
+	if strings.Contains(e.Type, "explosion") {
+		return true
+	}
+	return e.DepthKm <= seismicShallowKm && e.Magnitude <= seismicMaxMagnitude
+}
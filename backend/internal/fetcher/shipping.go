@@ -0,0 +1,126 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	aisHubAPI = "https://data.aishub.net/ws.php"
+	// aisHubMaxAge is the age in minutes past which AISHub leaves a
+	// vessel's last position out, so ships that went dark aren't counted.
+	aisHubMaxAge = 60
+	// underWayKnots is the speed over ground above which a vessel counts as
+	// transiting rather than anchored or drifting.
+	underWayKnots = 1.0
+)
+
+// aisVessel is one position record from AISHub's human-readable format.
+type aisVessel struct {
+	MMSI int     `json:"MMSI"`
+	SOG  float64 `json:"SOG"`
+	Type int     `json:"TYPE"`
+}
+
+// isTanker reports whether an AIS ship type is one of the tanker types.
+func isTanker(shipType int) bool {
+	return shipType >= 80 && shipType <= 89
+}
+
+func (f *Fetcher) fetchShipping(ctx context.Context) (model.ShippingData, map[string]any, error) {
+	slog.Info("fetching hormuz shipping")
+
+	cfg := f.cfg.Shipping
+	if cfg.AISHubUsername == "" {
+		return model.ShippingData{}, nil, failure(KindAuth, "aishub username not configured")
+	}
+
+	q := url.Values{
+		"username": {cfg.AISHubUsername},
+		"format":   {"1"},
+		"output":   {"json"},
+		"compress": {"0"},
+		"latmin":   {fmt.Sprint(cfg.Box.LatMin)},
+		"latmax":   {fmt.Sprint(cfg.Box.LatMax)},
+		"lonmin":   {fmt.Sprint(cfg.Box.LonMin)},
+		"lonmax":   {fmt.Sprint(cfg.Box.LonMax)},
+		"interval": {fmt.Sprint(aisHubMaxAge)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, aisHubAPI+"?"+q.Encode(), nil)
+	if err != nil {
+		return model.ShippingData{}, nil, failure(KindUnknown, "aishub request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// The request URL holds the account name
+		return model.ShippingData{}, nil, failure(KindNetwork, "aishub request: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return model.ShippingData{}, nil, statusFailure("aishub API error", resp.StatusCode)
+	}
+
+	// The body is a two-element array: a status header, then the vessels.
+	// A refused request returns the header alone.
+	var body []json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return model.ShippingData{}, nil, failure(KindParse, "aishub parse: %w", err)
+	}
+	if len(body) == 0 {
+		return model.ShippingData{}, nil, failure(KindParse, "aishub: empty response")
+	}
+	var header struct {
+		Error        bool   `json:"ERROR"`
+		ErrorMessage string `json:"ERROR_MESSAGE"`
+	}
+	if err := json.Unmarshal(body[0], &header); err != nil {
+		return model.ShippingData{}, nil, failure(KindParse, "aishub parse header: %w", err)
+	}
+	if header.Error {
+		return model.ShippingData{}, nil, aisHubFailure(header.ErrorMessage)
+	}
+
+	var vessels []aisVessel
+	if len(body) > 1 {
+		if err := json.Unmarshal(body[1], &vessels); err != nil {
+			return model.ShippingData{}, nil, failure(KindParse, "aishub parse vessels: %w", err)
+		}
+	}
+
+	data := model.ShippingData{Timestamp: time.Now().Format(time.RFC3339)}
+	seen := make(map[int]bool, len(vessels))
+	for _, v := range vessels {
+		if v.SOG <= underWayKnots || seen[v.MMSI] {
+			continue
+		}
+		seen[v.MMSI] = true
+		data.VesselCount++
+		if isTanker(v.Type) {
+			data.TankerCount++
+		}
+	}
+	slog.Info("hormuz shipping", "reported", len(vessels), "under_way", data.VesselCount, "tankers", data.TankerCount)
+	return data, structToMap(data), nil
+}
+
+// aisHubFailure classifies an error AISHub reports in its status header,
+// which comes back with a 200.
+func aisHubFailure(msg string) error {
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "username"):
+		return failure(KindAuth, "aishub: %s", msg)
+	case strings.Contains(lower, "frequent"):
+		return failure(KindRateLimit, "aishub: %s", msg)
+	}
+	return failure(KindUpstream, "aishub: %s", msg)
+}
@@ -0,0 +1,262 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"math"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+	"github.com/backyonatan-alt/aegis/backend/internal/schema"
+)
+
+// Signal is one data source read by each pipeline run.
+type Signal interface {
+	// Name is the snapshot key the signal is stored under, e.g. "flight".
+	Name() string
+	// Stage orders fetches: every signal of a stage runs concurrently, and
+	// a stage starts once the one before it has finished.
+	Stage() int
+	// Fetch reads the source. data is the signal's model type, such as
+	// model.NewsData; it may be partially filled when err is set.
+	Fetch(ctx context.Context) (data any, raw map[string]any, err error)
+	// Restore rebuilds data from the raw_data a previous snapshot stored,
+	// for runs where Fetch failed.
+	Restore(raw map[string]any) any
+	// Check validates raw_data against the signal's published schema.
+	Check(raw map[string]any) error
+	// Score rates data, as returned by Fetch or Restore; data of any other
+	// type is scored as the signal's zero value.
+	Score(data any, cfg *config.Config) model.NamedSignalScore
+}
+
+// Descriptor is what the rest of the backend knows of a signal besides
+// its data.
+type Descriptor struct {
+	Name  string
+	Label string
+	// ElevatedMin is the displayed risk from which the signal counts as
+	// elevated.
+	ElevatedMin int
+	// Weight is the signal's share of total risk in the published model,
+	// set from its core weight or share when the registry is built.
+	Weight float64
+	// Schema is what its raw_data must match to be stored.
+	Schema *schema.Schema
+	// Requires are the settings without which the signal is disabled: not
+	// fetched, and given no weight.
+	Requires []string
+
+	// breaker is the upstream whose circuit breaker guards its fetches,
+	// or "" for a source that can't fail.
+	breaker string
+	stage   int
+	// core is the weight of one of the original seven signals, which sum to
+	// 1; share is the fixed part of total risk a signal added since takes.
+	core, share float64
+}
+
+// Meta is the signal as /api/meta describes it, at its published weight.
+func (d Descriptor) Meta() model.SignalMeta {
+	return model.SignalMeta{Name: d.Name, Label: d.Label, Weight: d.Weight, ElevatedMin: d.ElevatedMin}
+}
+
+// registry lists every signal in scoring order, which is also the order
+// of dataset columns and alert messages. Adding a source means writing its
+// fetch, restore and score functions and its raw_data schema, and a line
+// here giving it a share of total risk; see publish. Flight and tanker
+// share OpenSky's breaker, and the client's rate limit spaces their
+// requests. Connectivity's ElevatedMin is its displayed risk at a 10% drop
+// in traffic.
+var registry = publish(
+	register(Descriptor{Name: "news", Label: "News", ElevatedMin: 31, core: 0.20, Schema: schema.News, breaker: "rss"}, (*Fetcher).fetchNews, restoreNews, risk.ScoreNews),
+	register(Descriptor{Name: "connectivity", Label: "Connectivity", ElevatedMin: 38, core: 0.20, Schema: schema.Connectivity, breaker: "cloudflare_radar"}, (*Fetcher).fetchConnectivity, restoreConnectivity, risk.ScoreConnectivity),
+	register(Descriptor{Name: "flight", Label: "Flight", ElevatedMin: 51, core: 0.15, Schema: schema.Flight, breaker: "opensky"}, (*Fetcher).fetchAviation, restoreAviation, risk.ScoreFlight),
+	register(Descriptor{Name: "tanker", Label: "Tanker", ElevatedMin: 31, core: 0.15, Schema: schema.Tanker, breaker: "opensky"}, (*Fetcher).fetchTanker, restoreTanker, risk.ScoreTanker),
+	register(Descriptor{Name: "weather", Label: "Weather", ElevatedMin: 71, core: 0.05, Schema: schema.Weather, breaker: "weather"}, (*Fetcher).fetchWeather, restoreWeather, risk.ScoreWeather),
+	register(Descriptor{Name: "polymarket", Label: "Polymarket", ElevatedMin: 31, core: 0.15, Schema: schema.Polymarket, breaker: "polymarket"}, (*Fetcher).fetchPolymarket, restorePolymarket, risk.ScorePolymarket),
+	register(Descriptor{Name: "pentagon", Label: "Pentagon", ElevatedMin: 51, core: 0.10, Schema: schema.Pentagon}, infallible((*Fetcher).fetchPentagon), restorePentagon, risk.ScorePentagon),
+	register(Descriptor{Name: "attention", Label: "Attention", ElevatedMin: 51, share: 0.05, Schema: schema.Attention, breaker: "wikipedia"}, (*Fetcher).fetchAttention, restoreAttention, risk.ScoreAttention),
+	register(Descriptor{Name: "geopolitics", Label: "Geopolitics", ElevatedMin: 51, share: 0.05, Schema: schema.Geopolitics, breaker: "gdelt"}, (*Fetcher).fetchGeopolitics, restoreGeopolitics, risk.ScoreGeopolitics),
+	register(Descriptor{Name: "shipping", Label: "Shipping", ElevatedMin: 41, share: 0.05, Schema: schema.Shipping, Requires: []string{"AISHUB_USERNAME"}, breaker: "aishub"}, (*Fetcher).fetchShipping, restoreShipping, risk.ScoreShipping),
+	register(Descriptor{Name: "airspace", Label: "Airspace", ElevatedMin: 31, share: 0.05, Schema: schema.Airspace, Requires: []string{"FAA_NOTAM_CLIENT_ID", "FAA_NOTAM_CLIENT_SECRET"}, breaker: "faa_notam"}, (*Fetcher).fetchAirspace, restoreAirspace, risk.ScoreAirspace),
+	register(Descriptor{Name: "seismic", Label: "Seismic", ElevatedMin: 41, share: 0.05, Schema: schema.Seismic, breaker: "usgs"}, (*Fetcher).fetchSeismic, restoreSeismic, risk.ScoreSeismic),
+	register(Descriptor{Name: "gps", Label: "GPS", ElevatedMin: 51, share: 0.05, Schema: schema.GPS, breaker: "adsb_lol"}, (*Fetcher).fetchGPS, restoreGPS, risk.ScoreGPS),
+)
+
+// publish sets each signal's Weight in the published model. A signal added
+// since the original seven takes its share, and the original seven split
+// what is left in their original proportions: adding a signal scales them
+// all down evenly, rather than taking from any one of them. Weights are
+// rounded to four places so /api/meta shows them as written.
+func publish(entries ...entry) []entry {
+	added := 0.0
+	for _, e := range entries {
+		added += e.descriptor().share
+	}
+	for _, e := range entries {
+		d := e.descriptor()
+		w := d.share
+		if w == 0 {
+			w = d.core * (1 - added)
+		}
+		e.weigh(math.Round(w*1e4) / 1e4)
+	}
+	return entries
+}
+
+// Descriptors returns every signal's descriptor in scoring order.
+func Descriptors() []Descriptor {
+	out := make([]Descriptor, len(registry))
+	for i, e := range registry {
+		out[i] = e.descriptor()
+	}
+	return out
+}
+
+// Enabled returns the descriptors of the signals cfg leaves enabled, in
+// scoring order.
+func Enabled(cfg *config.Config) []Descriptor {
+	var out []Descriptor
+	for _, e := range registry {
+		if d := e.descriptor(); !cfg.Disabled[d.Name] {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// Names returns every signal's name in scoring order.
+func Names() []string {
+	out := make([]string, len(registry))
+	for i, e := range registry {
+		out[i] = e.descriptor().Name
+	}
+	return out
+}
+
+// Lookup returns the descriptor of the signal called name, if there is one.
+func Lookup(name string) (Descriptor, bool) {
+	for _, e := range registry {
+		if d := e.descriptor(); d.Name == name {
+			return d, true
+		}
+	}
+	return Descriptor{}, false
+}
+
+// Meta returns every signal's meta in scoring order.
+func Meta() []model.SignalMeta {
+	out := make([]model.SignalMeta, len(registry))
+	for i, e := range registry {
+		out[i] = e.descriptor().Meta()
+	}
+	return out
+}
+
+// ConfigSignals returns the signals as config.Load takes them.
+func ConfigSignals() []config.Signal {
+	out := make([]config.Signal, len(registry))
+	for i, e := range registry {
+		d := e.descriptor()
+		out[i] = config.Signal{Name: d.Name, Weight: d.Weight, Requires: d.Requires}
+	}
+	return out
+}
+
+// Signals lists the production data sources in scoring order, leaving out
+// those the configuration disables. Fetches from an upstream go through
+// that upstream's circuit breaker.
+func (f *Fetcher) Signals() []Signal {
+	out := make([]Signal, 0, len(registry))
+	for _, e := range registry {
+		if f.cfg.Disabled[e.descriptor().Name] {
+			continue
+		}
+		out = append(out, e.bind(f))
+	}
+	return out
+}
+
+// entry is a registered source of any data type.
+type entry interface {
+	descriptor() Descriptor
+	// weigh sets the published weight.
+	weigh(w float64)
+	// bind serves the source from f.
+	bind(f *Fetcher) Signal
+	// mock serves the source from m's canned results.
+	mock(m *Mock) Signal
+}
+
+// source is a registered signal with its typed fetch, restore and score
+// functions.
+type source[T any] struct {
+	Descriptor
+	fetch   func(*Fetcher, context.Context) (T, map[string]any, error)
+	restore func(map[string]any) T
+	score   func(T, *config.Config) (int, string)
+}
+
+func register[T any](d Descriptor, fetch func(*Fetcher, context.Context) (T, map[string]any, error), restore func(map[string]any) T, score func(T, *config.Config) (int, string)) entry {
+	return &source[T]{Descriptor: d, fetch: fetch, restore: restore, score: score}
+}
+
+func (s *source[T]) descriptor() Descriptor { return s.Descriptor }
+
+func (s *source[T]) weigh(w float64) { s.Weight = w }
+
+func (s *source[T]) bind(f *Fetcher) Signal {
+	run := func(ctx context.Context) (T, map[string]any, error) { return s.fetch(f, ctx) }
+	if s.breaker != "" {
+		run = guarded(f.breaker(s.breaker), run)
+	}
+	return &typedSignal[T]{source: s, run: run}
+}
+
+func (s *source[T]) mock(m *Mock) Signal {
+	return &typedSignal[T]{source: s, run: mockFetch[T](m, s.Name)}
+}
+
+// typedSignal adapts a source's typed functions to Signal, fetching with run.
+type typedSignal[T any] struct {
+	*source[T]
+	run func(context.Context) (T, map[string]any, error)
+}
+
+func (s *typedSignal[T]) Name() string { return s.Descriptor.Name }
+
+func (s *typedSignal[T]) Stage() int { return s.stage }
+
+func (s *typedSignal[T]) Fetch(ctx context.Context) (any, map[string]any, error) {
+	return s.run(ctx)
+}
+
+func (s *typedSignal[T]) Restore(raw map[string]any) any {
+	return s.restore(raw)
+}
+
+func (s *typedSignal[T]) Check(raw map[string]any) error {
+	return schema.Check(s.Schema, raw)
+}
+
+func (s *typedSignal[T]) Score(data any, cfg *config.Config) model.NamedSignalScore {
+	d, _ := data.(T)
+	r, detail := s.score(d, cfg)
+	slog.Info("risk: "+s.Descriptor.Name, "risk", r, "detail", detail)
+	return model.NamedSignalScore{
+		Name:        s.Descriptor.Name,
+		Label:       s.Label,
+		ElevatedMin: s.ElevatedMin,
+		SignalScore: model.SignalScore{Risk: r, Detail: detail, Elevated: r >= s.ElevatedMin},
+	}
+}
+
+// infallible adapts a source that computes its data locally and can't fail.
+func infallible[T any](fetch func(*Fetcher) (T, map[string]any)) func(*Fetcher, context.Context) (T, map[string]any, error) {
+	return func(f *Fetcher, _ context.Context) (T, map[string]any, error) {
+		d, raw := fetch(f)
+		return d, raw, nil
+	}
+}
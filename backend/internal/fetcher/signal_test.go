@@ -0,0 +1,41 @@
+package fetcher
+
+import (
+	"math"
+	"testing"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+)
+
+func TestDefaultWeights(t *testing.T) {
+	// The original seven keep their proportions in the 70% the six added
+	// signals leave them
+	want := map[string]float64{
+		"news":         0.14,
+		"connectivity": 0.14,
+		"flight":       0.105,
+		"tanker":       0.105,
+		"weather":      0.035,
+		"polymarket":   0.105,
+		"pentagon":     0.07,
+		"attention":    0.05,
+		"geopolitics":  0.05,
+		"shipping":     0.05,
+		"airspace":     0.05,
+		"seismic":      0.05,
+		"gps":          0.05,
+	}
+
+	got := config.DefaultWeights(ConfigSignals())
+	if len(got) != len(want) {
+		t.Errorf("%d weights, want %d", len(got), len(want))
+	}
+	for name, w := range want {
+		if math.Abs(got[name]-w) > 1e-9 {
+			t.Errorf("weight of %s = %g, want %g", name, got[name], w)
+		}
+	}
+	if err := got.Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,8 +1,8 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"io"
 	"log/slog"
 	"strconv"
@@ -12,31 +12,32 @@ import (
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
-func (f *Fetcher) fetchTanker() (model.TankerData, map[string]any, error) {
+func (f *Fetcher) fetchTanker(ctx context.Context) (model.TankerData, map[string]any, error) {
 	slog.Info("fetching tanker activity")
 
-	resp, err := f.client.Get("https://opensky-network.org/api/states/all?lamin=20&lomin=40&lamax=40&lomax=65")
+	resp, err := f.openSkyGet(ctx, "opensky tanker", openSkyStatesURL(f.cfg.OpenSky.TankerBox))
 	if err != nil {
-		return model.TankerData{}, nil, fmt.Errorf("opensky tanker request: %w", err)
+		return model.TankerData{}, nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return model.TankerData{}, nil, fmt.Errorf("opensky tanker API error: %d", resp.StatusCode)
+		return model.TankerData{}, nil, statusFailure("opensky tanker API error", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return model.TankerData{}, nil, fmt.Errorf("opensky tanker read body: %w", err)
+		return model.TankerData{}, nil, failure(KindNetwork, "opensky tanker read body: %w", err)
 	}
 
 	var data map[string]any
 	if err := json.Unmarshal(body, &data); err != nil {
-		return model.TankerData{}, nil, fmt.Errorf("opensky tanker parse: %w", err)
+		return model.TankerData{}, nil, failure(KindParse, "opensky tanker parse: %w", err)
 	}
 
 	tankerCount := 0
 	var tankerCallsigns []string
+	var positions []model.Position
 
 	if states, ok := data["states"].([]any); ok {
 		for _, s := range states {
@@ -76,6 +77,9 @@ func (f *Fetcher) fetchTanker() (model.TankerData, map[string]any, error) {
 				if callsign != "" {
 					tankerCallsigns = append(tankerCallsigns, callsign)
 				}
+				if pos, ok := statePosition(aircraft, icao, callsign); ok {
+					positions = append(positions, pos)
+				}
 			}
 		}
 	}
@@ -91,6 +95,7 @@ func (f *Fetcher) fetchTanker() (model.TankerData, map[string]any, error) {
 		TankerCount: tankerCount,
 		Callsigns:   tankerCallsigns,
 		Timestamp:   now.Format(time.RFC3339),
+		Positions:   positions,
 	}
 	rawMap := structToMap(result)
 	return result, rawMap, nil
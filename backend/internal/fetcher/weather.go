@@ -1,89 +1,248 @@
 package fetcher
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"math"
 	"time"
 
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
-func (f *Fetcher) fetchWeather() (model.WeatherData, map[string]any, error) {
+// weatherObservation is the provider-independent current weather over
+// Tehran. ConditionID uses OpenWeather condition codes.
+type weatherObservation struct {
+	Temp        float64
+	Visibility  int
+	Clouds      int
+	WindSpeed   float64
+	ConditionID int
+	Description string
+}
+
+// weatherProvider is a source of current weather. Providers are tried
+// healthiest first until one succeeds.
+type weatherProvider interface {
+	Name() string
+	Current(ctx context.Context, f *Fetcher) (weatherObservation, error)
+}
+
+// weatherProviders returns the providers to try, primary first. OpenWeather
+// needs an API key; Open-Meteo is keyless and always available as fallback.
+func (f *Fetcher) weatherProviders() []weatherProvider {
+	var providers []weatherProvider
+	if f.cfg.OpenWeatherAPIKey != "" {
+		providers = append(providers, openWeather{})
+	}
+	return append(providers, openMeteo{})
+}
+
+func (f *Fetcher) fetchWeather(ctx context.Context) (model.WeatherData, map[string]any, error) {
 	slog.Info("fetching weather data")
 
+	var obs weatherObservation
+	var provider string
+	var errs []error
+	probe := f.probeDue("weather")
+	for _, p := range rankProviders(f, "weather", f.weatherProviders()) {
+		if provider != "" && !probe {
+			break
+		}
+		start := time.Now()
+		o, err := p.Current(ctx, f)
+		f.recordProvider(ctx, "weather", p.Name(), time.Since(start), err)
+		switch {
+		case provider != "":
+			// A probe: only its outcome is kept
+			slog.Info("weather provider probed", "provider", p.Name(), "ok", err == nil)
+		case err != nil:
+			slog.Warn("weather provider failed", "provider", p.Name(), "error", err)
+			errs = append(errs, err)
+		default:
+			obs, provider = o, p.Name()
+		}
+	}
+	if provider == "" {
+		return model.WeatherData{}, nil, errors.Join(errs...)
+	}
+
+	temp := int(math.Round(obs.Temp))
+	visibility, clouds, windSpeed := obs.Visibility, obs.Clouds, obs.WindSpeed
+	description, conditionID := obs.Description, obs.ConditionID
+	t := f.cfg.Weather
+	dust := intSliceContains(t.DustCodes, conditionID)
+	haze := intSliceContains(t.HazeCodes, conditionID)
+	condition := weatherCondition(t, visibility, clouds, windSpeed, dust, haze)
+
+	slog.Info("weather result", "provider", provider, "temp", temp, "clouds", clouds, "wind", windSpeed, "dust", dust, "haze", haze, "condition", condition)
+
+	now := time.Now()
+	result := model.WeatherData{
+		Temp:        temp,
+		Visibility:  visibility,
+		Clouds:      clouds,
+		WindSpeed:   windSpeed,
+		ConditionID: conditionID,
+		Dust:        dust,
+		Haze:        haze,
+		Description: description,
+		Condition:   condition,
+		Provider:    provider,
+		Source:      provider,
+		Timestamp:   now.Format(time.RFC3339),
+	}
+	if f.cfg.OpenWeatherAPIKey != "" {
+		result.Imagery = f.weatherImagery()
+	}
+	rawMap := structToMap(result)
+	return result, rawMap, nil
+}
+
+// weatherCondition classifies conditions as Favorable, Marginal or Poor.
+// Haze rules out Favorable; dust or sand also makes anything short of
+// clear visibility Poor.
+func weatherCondition(t config.WeatherThresholds, visibility, clouds int, windSpeed float64, dust, haze bool) string {
+	switch {
+	case visibility >= t.ClearVisibility && clouds <= t.ClearClouds && windSpeed <= t.MaxWind && !dust && !haze:
+		return "Favorable"
+	case dust && visibility < t.ClearVisibility:
+		return "Poor"
+	case visibility >= t.MinVisibility && clouds < t.MaxClouds && windSpeed <= t.MaxWind:
+		return "Marginal"
+	}
+	return "Poor"
+}
+
+// openWeather is the primary provider (OpenWeather current weather API).
+type openWeather struct{}
+
+func (openWeather) Name() string { return "openweather" }
+
+func (openWeather) Current(ctx context.Context, f *Fetcher) (weatherObservation, error) {
 	url := fmt.Sprintf(
 		"https://api.openweathermap.org/data/2.5/weather?lat=35.6892&lon=51.389&appid=%s&units=metric",
 		f.cfg.OpenWeatherAPIKey,
 	)
 
-	resp, err := f.client.Get(url)
+	resp, err := f.get(ctx, url)
 	if err != nil {
-		return model.WeatherData{}, nil, fmt.Errorf("weather request: %w", err)
+		// The request URL holds the API key
+		return weatherObservation{}, failure(KindNetwork, "weather request: %w", withoutURL(err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return model.WeatherData{}, nil, fmt.Errorf("weather API error: %d", resp.StatusCode)
+		return weatherObservation{}, statusFailure("weather API error", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return model.WeatherData{}, nil, fmt.Errorf("weather read body: %w", err)
+		return weatherObservation{}, failure(KindNetwork, "weather read body: %w", err)
 	}
 
 	var data map[string]any
 	if err := json.Unmarshal(body, &data); err != nil {
-		return model.WeatherData{}, nil, fmt.Errorf("weather parse: %w", err)
+		return weatherObservation{}, failure(KindParse, "weather parse: %w", err)
 	}
 
 	mainData, ok := data["main"].(map[string]any)
 	if !ok {
-		return model.WeatherData{}, nil, fmt.Errorf("weather: no main data")
+		return weatherObservation{}, failure(KindParse, "weather: no main data")
 	}
 
-	temp := int(math.Round(toFloat(mainData["temp"])))
-	visibility := 10000
+	obs := weatherObservation{
+		Temp:        toFloat(mainData["temp"]),
+		Visibility:  10000,
+		Description: "clear",
+		ConditionID: 800,
+	}
 	if v, ok := data["visibility"]; ok {
-		visibility = int(toFloat(v))
+		obs.Visibility = int(toFloat(v))
 	}
-
-	clouds := 0
 	if cloudsMap, ok := data["clouds"].(map[string]any); ok {
-		clouds = int(toFloat(cloudsMap["all"]))
+		obs.Clouds = int(toFloat(cloudsMap["all"]))
+	}
+	if windMap, ok := data["wind"].(map[string]any); ok {
+		obs.WindSpeed = toFloat(windMap["speed"])
 	}
-
-	description := "clear"
 	if weatherArr, ok := data["weather"].([]any); ok && len(weatherArr) > 0 {
 		if w, ok := weatherArr[0].(map[string]any); ok {
 			if d, ok := w["description"].(string); ok {
-				description = d
+				obs.Description = d
+			}
+			if id, ok := w["id"]; ok {
+				obs.ConditionID = int(toFloat(id))
 			}
 		}
 	}
+	return obs, nil
+}
 
-	condition := "Favorable"
-	if visibility >= 10000 && clouds < 30 {
-		condition = "Favorable"
-	} else if visibility >= 7000 && clouds < 60 {
-		condition = "Marginal"
-	} else {
-		condition = "Poor"
+// weatherImagery builds tile URL templates pointing at this server's proxy.
+func (f *Fetcher) weatherImagery() *model.WeatherImagery {
+	layers := make(map[string]string, len(weatherTileLayers))
+	for name := range weatherTileLayers {
+		layers[name] = fmt.Sprintf("%s/api/weather/tiles/%s/{z}/{x}/{y}.png", f.cfg.PublicURL, name)
 	}
+	return &model.WeatherImagery{
+		Layers:      layers,
+		Bounds:      [4]float64{weatherTileMinLon, weatherTileMinLat, weatherTileMaxLon, weatherTileMaxLat},
+		MinZoom:     weatherTileMinZoom,
+		MaxZoom:     weatherTileMaxZoom,
+		Attribution: "Weather data © OpenWeather",
+	}
+}
 
-	slog.Info("weather result", "temp", temp, "clouds", clouds, "condition", condition)
+// ErrTileOutOfRange is returned by WeatherTile for unknown layers or tiles
+// outside the covered region and zoom levels.
+var ErrTileOutOfRange = errors.New("tile out of range")
 
-	now := time.Now()
-	result := model.WeatherData{
-		Temp:        temp,
-		Visibility:  visibility,
-		Clouds:      clouds,
-		Description: description,
-		Condition:   condition,
-		Timestamp:   now.Format(time.RFC3339),
+// WeatherTile fetches one OpenWeather map tile. Only tiles that overlap the
+// imagery region are served so the proxy can't be used for the whole globe.
+func (f *Fetcher) WeatherTile(ctx context.Context, layer string, z, x, y int) ([]byte, error) {
+	owLayer, ok := weatherTileLayers[layer]
+	if !ok || f.cfg.OpenWeatherAPIKey == "" || z < weatherTileMinZoom || z > weatherTileMaxZoom {
+		return nil, ErrTileOutOfRange
 	}
-	rawMap := structToMap(result)
-	return result, rawMap, nil
+	minX, minY := tileXY(weatherTileMinLon, weatherTileMaxLat, z)
+	maxX, maxY := tileXY(weatherTileMaxLon, weatherTileMinLat, z)
+	if x < minX || x > maxX || y < minY || y > maxY {
+		return nil, ErrTileOutOfRange
+	}
+
+	url := fmt.Sprintf("https://tile.openweathermap.org/map/%s/%d/%d/%d.png?appid=%s", owLayer, z, x, y, f.cfg.OpenWeatherAPIKey)
+	// The request URL holds the API key
+	resp, err := f.get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("weather tile request: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("weather tile API error: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// tileXY returns the Web Mercator tile containing lon/lat at zoom z.
+func tileXY(lon, lat float64, z int) (int, int) {
+	n := math.Exp2(float64(z))
+	x := int((lon + 180) / 360 * n)
+	latRad := lat * math.Pi / 180
+	y := int((1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n)
+	return x, y
+}
+
+func intSliceContains(slice []int, item int) bool {
+	for _, n := range slice {
+		if n == item {
+			return true
+		}
+	}
+	return false
 }
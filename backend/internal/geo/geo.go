@@ -0,0 +1,148 @@
+// Package geo assembles the situation map served at /api/map as GeoJSON.
+package geo
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// Layer names, set as the "layer" property on every feature.
+const (
+	LayerAircraft     = "aircraft"
+	LayerTanker       = "tanker"
+	LayerConnectivity = "connectivity"
+	LayerAlert        = "alert"
+)
+
+// FeatureCollection is a GeoJSON FeatureCollection of point features.
+type FeatureCollection struct {
+	Type        string    `json:"type"`
+	GeneratedAt string    `json:"generated_at"`
+	Features    []Feature `json:"features"`
+}
+
+// Feature is a GeoJSON point feature.
+type Feature struct {
+	Type       string         `json:"type"`
+	Geometry   Geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry is a GeoJSON Point; Coordinates are [lon, lat].
+type Geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+func point(lon, lat float64, layer string, props map[string]any) Feature {
+	props["layer"] = layer
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Point", Coordinates: []float64{lon, lat}},
+		Properties: props,
+	}
+}
+
+// Build assembles the map layers from one pipeline run's fetcher output.
+// Connectivity is placed at country centroids, and alert articles are placed
+// at the first known location named in their title.
+func Build(aviation model.AviationData, tanker model.TankerData, conn model.ConnectivityData, news model.NewsData) FeatureCollection {
+	fc := FeatureCollection{
+		Type:        "FeatureCollection",
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Features:    []Feature{},
+	}
+
+	for _, p := range aviation.Positions {
+		fc.Features = append(fc.Features, point(p.Lon, p.Lat, LayerAircraft, positionProps(p)))
+	}
+	for _, p := range tanker.Positions {
+		fc.Features = append(fc.Features, point(p.Lon, p.Lat, LayerTanker, positionProps(p)))
+	}
+
+	for _, n := range conn.Networks {
+		c, ok := networkLocation(n)
+		if !ok {
+			continue
+		}
+		fc.Features = append(fc.Features, point(c[0], c[1], LayerConnectivity, map[string]any{
+			"name":     n.Name,
+			"status":   n.Status,
+			"trend":    n.Trend,
+			"domestic": n.Domestic,
+		}))
+	}
+	for _, c := range conn.Countries {
+		centroid, ok := countryCentroids[c.Code]
+		if !ok {
+			continue
+		}
+		fc.Features = append(fc.Features, point(centroid[0], centroid[1], LayerConnectivity, map[string]any{
+			"name":     c.Name,
+			"status":   c.Status,
+			"trend":    c.Trend,
+			"domestic": false,
+		}))
+	}
+
+	for _, a := range news.Articles {
+		if isAlert, _ := a["is_alert"].(bool); !isAlert {
+			continue
+		}
+		title, _ := a["title"].(string)
+		name, c, ok := locate(title)
+		if !ok {
+			continue
+		}
+		fc.Features = append(fc.Features, point(c[0], c[1], LayerAlert, map[string]any{
+			"title":    title,
+			"location": name,
+		}))
+	}
+
+	return fc
+}
+
+func positionProps(p model.Position) map[string]any {
+	return map[string]any{
+		"icao":     p.ICAO,
+		"callsign": p.Callsign,
+		"altitude": math.Round(p.Altitude),
+		"heading":  math.Round(p.Heading),
+	}
+}
+
+// Filter returns a copy of fc containing only the given layers (all layers
+// when none are given) with coordinates rounded to decimals places. One
+// decimal is roughly 11 km, which is enough for a regional overview.
+func Filter(fc FeatureCollection, layers []string, decimals int) FeatureCollection {
+	scale := math.Pow(10, float64(decimals))
+	out := FeatureCollection{Type: fc.Type, GeneratedAt: fc.GeneratedAt, Features: []Feature{}}
+	for _, f := range fc.Features {
+		if len(layers) > 0 {
+			layer, _ := f.Properties["layer"].(string)
+			if !containsFold(layers, layer) {
+				continue
+			}
+		}
+		coords := make([]float64, len(f.Geometry.Coordinates))
+		for i, v := range f.Geometry.Coordinates {
+			coords[i] = math.Round(v*scale) / scale
+		}
+		f.Geometry = Geometry{Type: f.Geometry.Type, Coordinates: coords}
+		out.Features = append(out.Features, f)
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package geo
+
+import (
+	"strings"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// countryCentroids are approximate [lon, lat] centers for connectivity
+// markers. Iranian networks are drawn at the Iran centroid.
+var countryCentroids = map[string][2]float64{
+	"IR": {53.69, 32.43},
+	"IQ": {43.68, 33.22},
+	"IL": {34.85, 31.05},
+	"LB": {35.86, 33.85},
+	"SY": {38.51, 35.01},
+	"JO": {36.24, 30.59},
+	"SA": {45.08, 23.89},
+	"AE": {54.30, 23.68},
+	"QA": {51.18, 25.35},
+	"BH": {50.56, 26.07},
+	"KW": {47.48, 29.31},
+	"OM": {56.09, 21.47},
+	"YE": {47.59, 15.55},
+	"TR": {35.24, 38.96},
+	"EG": {30.80, 26.82},
+	"AZ": {47.58, 40.14},
+	"AM": {45.04, 40.07},
+	"AF": {67.71, 33.94},
+	"PK": {69.35, 30.38},
+}
+
+// places are locations commonly named in alert headlines, matched in order
+// so that specific sites win over the country they are in.
+var places = []struct {
+	Name   string
+	Coords [2]float64
+}{
+	{"Natanz", [2]float64{51.73, 33.72}},
+	{"Fordow", [2]float64{50.99, 34.88}},
+	{"Isfahan", [2]float64{51.67, 32.65}},
+	{"Bushehr", [2]float64{50.84, 28.92}},
+	{"Bandar Abbas", [2]float64{56.27, 27.18}},
+	{"Kharg", [2]float64{50.32, 29.26}},
+	{"Hormuz", [2]float64{56.45, 26.57}},
+	{"Tehran", [2]float64{51.39, 35.69}},
+	{"Tabriz", [2]float64{46.29, 38.08}},
+	{"Shiraz", [2]float64{52.53, 29.59}},
+	{"Baghdad", [2]float64{44.36, 33.31}},
+	{"Erbil", [2]float64{44.01, 36.19}},
+	{"Beirut", [2]float64{35.50, 33.89}},
+	{"Damascus", [2]float64{36.29, 33.51}},
+	{"Tel Aviv", [2]float64{34.78, 32.09}},
+	{"Jerusalem", [2]float64{35.21, 31.77}},
+	{"Haifa", [2]float64{34.99, 32.79}},
+	{"Sanaa", [2]float64{44.21, 15.37}},
+	{"Red Sea", [2]float64{38.50, 20.00}},
+	{"Persian Gulf", [2]float64{51.00, 27.00}},
+	{"Iraq", [2]float64{43.68, 33.22}},
+	{"Israel", [2]float64{34.85, 31.05}},
+	{"Lebanon", [2]float64{35.86, 33.85}},
+	{"Iran", [2]float64{53.69, 32.43}},
+}
+
+// locate returns the first known place named in text.
+func locate(text string) (string, [2]float64, bool) {
+	lower := strings.ToLower(text)
+	for _, p := range places {
+		if strings.Contains(lower, strings.ToLower(p.Name)) {
+			return p.Name, p.Coords, true
+		}
+	}
+	return "", [2]float64{}, false
+}
+
+// networkLocation places a connectivity network: location filters at their
+// country's centroid, domestic ASNs at Iran's.
+func networkLocation(n model.NetworkStatus) ([2]float64, bool) {
+	if cc, ok := strings.CutPrefix(n.Query, "location="); ok {
+		c, ok := countryCentroids[strings.ToUpper(cc)]
+		return c, ok
+	}
+	if n.Domestic {
+		return countryCentroids["IR"], true
+	}
+	return [2]float64{}, false
+}
@@ -0,0 +1,89 @@
+package harness
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// Fixtures returns a fetcher whose every source succeeds with a quiet but
+// realistic reading. Raw data mirrors the structured data, as the real
+// fetchers produce, so fallbacks behave as in production when a test later
+// sets an Errs entry.
+func Fixtures() *fetcher.Mock {
+	now := time.Now().Format(time.RFC3339)
+	data := map[string]any{
+		"polymarket": model.PolymarketData{Odds: 12, Market: "US strikes Iran by next week?", Timestamp: now},
+		"news": model.NewsData{
+			Articles: []map[string]any{
+				{"title": "Iran and IAEA resume talks in Vienna", "is_alert": false, "keywords": []string{"iran"}},
+				{"title": "Military drills reported near Strait of Hormuz", "is_alert": true, "keywords": []string{"strait of hormuz"}, "alert_keywords": []string{"military"}},
+			},
+			TotalCount: 2,
+			AlertCount: 1,
+			Timestamp:  now,
+		},
+		"flight": model.AviationData{
+			AircraftCount: 140,
+			AirlineCount:  3,
+			Airlines:      []string{"IRA", "UAE", "QTR"},
+			MajorCarriers: []string{"UAE", "QTR"},
+			Timestamp:     now,
+			Positions: []model.Position{
+				{ICAO: "730001", Callsign: "IRA712", Lat: 35.6, Lon: 51.3, Altitude: 10600, Heading: 270},
+			},
+		},
+		"tanker": model.TankerData{TankerCount: 1, Callsigns: []string{"PEARL21"}, Timestamp: now},
+		"weather": model.WeatherData{
+			Temp: 24, Visibility: 10000, Clouds: 10, WindSpeed: 3.5, ConditionID: 800,
+			Description: "clear sky", Condition: "Favorable", Provider: "fixture", Timestamp: now,
+		},
+		"connectivity": model.ConnectivityData{Status: "STABLE", Risk: 2, Trend: -0.5, Timestamp: now},
+		"pentagon": model.PentagonData{
+			Score: 20, RiskContribution: 2, Status: "Normal", Timestamp: now,
+			Places: []map[string]any{{"name": "Domino's Pizza", "status": "normal"}},
+		},
+		"attention": model.AttentionData{
+			Components: []model.AttentionComponent{{Source: "wikipedia", Ratio: 1.1, Detail: "5500 views vs 5000/day"}},
+			Timestamp:  now,
+		},
+		"geopolitics": model.GeopoliticsData{
+			ArticleCount: 420, BaselinePerDay: 380, Ratio: 1.11,
+			AverageTone: -4.2, BaselineTone: -3.9, Timestamp: now,
+		},
+		"shipping": model.ShippingData{
+			VesselCount: 46, TankerCount: 19, Timestamp: now,
+		},
+		"airspace": model.AirspaceData{
+			NotamCount: 240, Closures: []model.AirspaceClosure{}, Restrictions: 6, Warnings: 1, Timestamp: now,
+		},
+		"seismic": model.SeismicData{
+			EventCount: 1, Timestamp: now,
+			Events: []model.SeismicEvent{
+				{ID: "us7000fixture", Place: "42 km SW of Bandar Abbas, Iran", Magnitude: 4.3, DepthKm: 12, Lat: 26.9, Lon: 55.9, Time: now, Type: "earthquake"},
+			},
+		},
+		"gps": model.GPSData{
+			Zones: []model.GPSZone{
+				{Name: "Israel", Aircraft: 38, Degraded: 5, Ratio: 0.132},
+				{Name: "Iran", Aircraft: 61, Degraded: 0, Ratio: 0},
+				{Name: "Gulf", Aircraft: 214, Degraded: 3, Ratio: 0.014},
+			},
+			Timestamp: now,
+		},
+	}
+	m := &fetcher.Mock{Data: data, Raw: make(map[string]map[string]any, len(data))}
+	for name, d := range data {
+		m.Raw[name] = toMap(d)
+	}
+	return m
+}
+
+func toMap(v any) map[string]any {
+	data, _ := json.Marshal(v)
+	var m map[string]any
+	json.Unmarshal(data, &m)
+	return m
+}
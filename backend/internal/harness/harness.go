@@ -0,0 +1,221 @@
+// Package harness runs the full aegis stack — fixture-backed fetchers, the
+// pipeline, a real database and the HTTP server — for black-box tests of
+// the HTTP contract:
+//
+//	h, err := harness.New(ctx, harness.Options{})
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer h.Close()
+//	h.Fetcher.Data["polymarket"] = model.PolymarketData{Odds: 80}
+//	if err := h.Run(ctx); err != nil {
+//		t.Fatal(err)
+//	}
+//	resp, err := h.Get("/api/data")
+//
+// Each harness gets its own database on the server named by
+// AEGIS_TEST_DATABASE_URL, or on a postgres container started via docker
+// when that is unset. Without either it falls back to a SQLite file, so the
+// harness runs anywhere the tests do.
+package harness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/cache"
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/pipeline"
+	"github.com/backyonatan-alt/aegis/backend/internal/pulse"
+	"github.com/backyonatan-alt/aegis/backend/internal/server"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+	"github.com/backyonatan-alt/aegis/backend/internal/tenant"
+)
+
+// DefaultAdminToken is the admin token unless Options overrides it.
+const DefaultAdminToken = "harness-admin-token"
+
+// Options customizes a harness. The zero value is ready to use.
+type Options struct {
+	// DatabaseURL overrides AEGIS_TEST_DATABASE_URL.
+	DatabaseURL string
+	// Fetcher replaces Fixtures().
+	Fetcher *fetcher.Mock
+	// Configure may adjust the config before anything is built from it.
+	Configure func(*config.Config)
+}
+
+// Harness is a running aegis stack. Fetcher may be modified between runs to
+// change what the next Run sees.
+type Harness struct {
+	// URL is the base URL of the HTTP server, without a trailing slash.
+	URL      string
+	Config   *config.Config
+	Store    store.Store
+	Cache    *cache.Cache
+	Fetcher  *fetcher.Mock
+	Pipeline *pipeline.Pipeline
+
+	client     *http.Client
+	server     *httptest.Server
+	pulse      *pulse.Tracker
+	closeStore func()
+	db         *database
+}
+
+// New provisions a database, migrates it and starts the server. The
+// pipeline has not run yet, so /api/data answers 404 until Run is called.
+func New(ctx context.Context, opts Options) (*Harness, error) {
+	serverURL := opts.DatabaseURL
+	if serverURL == "" {
+		serverURL = os.Getenv(DatabaseURLEnv)
+	}
+	db, err := createDatabase(ctx, serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := defaultConfig(db.url)
+	if err != nil {
+		db.drop()
+		return nil, err
+	}
+	if opts.Configure != nil {
+		opts.Configure(cfg)
+	}
+
+	st, closeStore, err := openStore(ctx, cfg)
+	if err != nil {
+		db.drop()
+		return nil, err
+	}
+
+	mock := opts.Fetcher
+	if mock == nil {
+		mock = Fixtures()
+	}
+	c := cache.New()
+	p := pipeline.New(cfg, st, c, mock)
+	srv := server.New(cfg, c, st, p, fetcher.New(cfg), tenant.New(st))
+	p.SetPulse(srv.Pulse())
+	p.AddNotifier(srv)
+	go srv.Pulse().Start(context.Background())
+	ts := httptest.NewServer(srv.Router())
+
+	return &Harness{
+		URL:        ts.URL,
+		Config:     cfg,
+		Store:      st,
+		Cache:      c,
+		Fetcher:    mock,
+		Pipeline:   p,
+		pulse:      srv.Pulse(),
+		client:     ts.Client(),
+		server:     ts,
+		closeStore: closeStore,
+		db:         db,
+	}, nil
+}
+
+// migrator is a store that can create its own tables.
+type migrator interface {
+	store.Store
+	MigrateAll(ctx context.Context) error
+}
+
+// openStore opens and migrates the database cfg.DatabaseURL names, picking
+// the store the way the app does. The returned func closes it.
+func openStore(ctx context.Context, cfg *config.Config) (store.Store, func(), error) {
+	var db migrator
+	var closeDB func()
+	if store.IsSQLiteURL(cfg.DatabaseURL) {
+		lite, err := store.OpenSQLite(ctx, cfg.DatabaseURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open test database: %w", err)
+		}
+		db, closeDB = lite, func() { lite.Close() }
+	} else {
+		pool, err := store.NewPool(ctx, cfg.DatabaseURL, cfg.DBPool)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open test database: %w", err)
+		}
+		db, closeDB = store.NewPostgres(pool, cfg.DBPool.ScanTimeout), pool.Close
+	}
+	if err := db.MigrateAll(ctx); err != nil {
+		closeDB()
+		return nil, nil, err
+	}
+	return db, closeDB, nil
+}
+
+// defaultConfig is config.Load's defaults, whatever the environment holds,
+// with the settings a run needs. The shipping and airspace credentials are
+// placeholders that enable those signals; the fixtures never reach their
+// upstreams.
+func defaultConfig(databaseURL string) (*config.Config, error) {
+	return config.Defaults(fetcher.ConfigSignals(), map[string]string{
+		"DATABASE_URL":            databaseURL,
+		"CLOUDFLARE_RADAR_TOKEN":  "harness",
+		"PORT":                    "0",
+		"PUBLIC_URL":              "http://localhost",
+		"ALLOWED_ORIGINS":         "http://localhost",
+		"ADMIN_TOKEN":             DefaultAdminToken,
+		"DB_MAX_CONNS":            "4",
+		"DB_MIN_CONNS":            "0",
+		"AISHUB_USERNAME":         "harness",
+		"FAA_NOTAM_CLIENT_ID":     "harness",
+		"FAA_NOTAM_CLIENT_SECRET": "harness",
+	})
+}
+
+// Run executes one pipeline run synchronously against the fixtures.
+func (h *Harness) Run(ctx context.Context) error {
+	return h.Pipeline.Run(ctx)
+}
+
+// Get issues an anonymous GET for path (e.g. "/api/data?tz=UTC").
+func (h *Harness) Get(path string) (*http.Response, error) {
+	return h.client.Get(h.URL + path)
+}
+
+// GetJSON GETs path and decodes a 200 response into v.
+func (h *Harness) GetJSON(path string, v any) error {
+	resp, err := h.Get(path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// Admin issues a request authenticated with the admin token.
+func (h *Harness) Admin(method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, h.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+h.Config.AdminToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return h.client.Do(req)
+}
+
+// Close stops the server and drops the test database.
+func (h *Harness) Close() error {
+	h.server.Close()
+	h.Pipeline.Stop()
+	h.pulse.Stop()
+	h.closeStore()
+	return h.db.drop()
+}
@@ -0,0 +1,53 @@
+package harness
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+func TestRunServesSnapshot(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	h, err := New(ctx, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	resp, err := h.Get("/api/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("GET /api/data before a run = %s, want 404", resp.Status)
+	}
+
+	if err := h.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var snap model.Snapshot
+	if err := h.GetJSON("/api/data", &snap); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range fetcher.Names() {
+		sig := snap.Signal(name)
+		if sig == nil {
+			t.Errorf("snapshot has no %s signal", name)
+			continue
+		}
+		if sig.IsStale {
+			t.Errorf("%s is stale after a run on fixtures", name)
+		}
+	}
+	if snap.TotalRisk.Risk < 0 || snap.TotalRisk.Risk > 100 {
+		t.Errorf("total risk = %d, want 0-100", snap.TotalRisk.Risk)
+	}
+}
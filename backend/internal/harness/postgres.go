@@ -0,0 +1,179 @@
+package harness
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DatabaseURLEnv names a Postgres server to create test databases on. When
+// unset, a throwaway container is started with docker, or a SQLite file is
+// used if docker isn't running.
+const DatabaseURLEnv = "AEGIS_TEST_DATABASE_URL"
+
+// postgresImage is the container image used when no server is given.
+const postgresImage = "postgres:16-alpine"
+
+// readyTimeout bounds how long a fresh container may take to accept
+// connections.
+const readyTimeout = 30 * time.Second
+
+// database is a dedicated database on a Postgres server, or a SQLite file.
+// drop removes it and, for a harness-started container, the container too.
+type database struct {
+	url  string
+	drop func() error
+}
+
+// createDatabase makes a uniquely named database on serverURL, or on a new
+// container when serverURL is empty. With neither a server nor docker it
+// falls back to SQLite.
+func createDatabase(ctx context.Context, serverURL string) (*database, error) {
+	stopContainer := func() error { return nil }
+	if serverURL == "" && !dockerRunning(ctx) {
+		return createSQLite()
+	}
+	if serverURL == "" {
+		var err error
+		serverURL, stopContainer, err = startContainer(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	suffix := make([]byte, 6)
+	if _, err := rand.Read(suffix); err != nil {
+		stopContainer()
+		return nil, err
+	}
+	name := "aegis_test_" + hex.EncodeToString(suffix)
+
+	admin, err := pgx.Connect(ctx, serverURL)
+	if err != nil {
+		stopContainer()
+		return nil, fmt.Errorf("connect to test postgres: %w", err)
+	}
+	defer admin.Close(ctx)
+	if _, err := admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		stopContainer()
+		return nil, fmt.Errorf("create test database: %w", err)
+	}
+
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		stopContainer()
+		return nil, fmt.Errorf("parse %s: %w", DatabaseURLEnv, err)
+	}
+	u.Path = "/" + name
+
+	drop := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		var errs []string
+		if conn, err := pgx.Connect(ctx, serverURL); err != nil {
+			errs = append(errs, err.Error())
+		} else {
+			if _, err := conn.Exec(ctx, "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)"); err != nil {
+				errs = append(errs, err.Error())
+			}
+			conn.Close(ctx)
+		}
+		if err := stopContainer(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("drop test database: %s", strings.Join(errs, "; "))
+		}
+		return nil
+	}
+	return &database{url: u.String(), drop: drop}, nil
+}
+
+// startContainer runs postgresImage on a random local port and waits until
+// it accepts connections. It returns the server URL and a func removing
+// the container.
+func startContainer(ctx context.Context) (string, func() error, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return "", nil, fmt.Errorf("set %s or install docker to run the harness", DatabaseURLEnv)
+	}
+
+	id, err := docker(ctx, "run", "-d", "--rm",
+		"-e", "POSTGRES_USER=aegis", "-e", "POSTGRES_PASSWORD=aegis", "-e", "POSTGRES_DB=aegis",
+		"-p", "127.0.0.1::5432", postgresImage)
+	if err != nil {
+		return "", nil, fmt.Errorf("start postgres container: %w", err)
+	}
+	stop := func() error {
+		_, err := docker(context.Background(), "rm", "-f", id)
+		return err
+	}
+
+	// Prints one line per address family, e.g. "127.0.0.1:55012"
+	port, err := docker(ctx, "port", id, "5432/tcp")
+	if err != nil {
+		stop()
+		return "", nil, fmt.Errorf("find postgres container port: %w", err)
+	}
+	addr, _, _ := strings.Cut(port, "\n")
+	serverURL := "postgres://aegis:aegis@" + strings.TrimSpace(addr) + "/aegis?sslmode=disable"
+
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		conn, err := pgx.Connect(ctx, serverURL)
+		if err == nil {
+			err = conn.Ping(ctx)
+			conn.Close(ctx)
+		}
+		if err == nil {
+			return serverURL, stop, nil
+		}
+		if time.Now().After(deadline) || ctx.Err() != nil {
+			stop()
+			return "", nil, fmt.Errorf("postgres container not ready: %w", err)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+}
+
+// dockerRunning reports whether a docker daemon answers.
+func dockerRunning(ctx context.Context) bool {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return false
+	}
+	_, err := docker(ctx, "info")
+	return err == nil
+}
+
+// createSQLite makes a database file in a fresh temporary directory.
+func createSQLite() (*database, error) {
+	dir, err := os.MkdirTemp("", "aegis-harness-")
+	if err != nil {
+		return nil, fmt.Errorf("create test database: %w", err)
+	}
+	return &database{
+		url:  "sqlite://" + filepath.Join(dir, "aegis.db"),
+		drop: func() error { return os.RemoveAll(dir) },
+	}, nil
+}
+
+func docker(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("docker %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,91 @@
+// Package jsonpatch computes RFC 6902 JSON Patch documents between two
+// decoded JSON values.
+package jsonpatch
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Operation is a single JSON Patch operation.
+type Operation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Diff returns the operations that turn from into to. Objects are diffed key
+// by key; arrays of equal length element by element, otherwise replaced
+// whole, which suits the short fixed-window histories in a snapshot.
+func Diff(from, to any) []Operation {
+	ops := []Operation{}
+	return diff(ops, "", from, to)
+}
+
+func diff(ops []Operation, path string, from, to any) []Operation {
+	switch f := from.(type) {
+	case map[string]any:
+		t, ok := to.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(f)+len(t))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		for k := range t {
+			if _, ok := f[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := path + "/" + escape(k)
+			fv, inFrom := f[k]
+			tv, inTo := t[k]
+			switch {
+			case !inTo:
+				ops = append(ops, Operation{Op: "remove", Path: p})
+			case !inFrom:
+				ops = append(ops, Operation{Op: "add", Path: p, Value: nullable(tv)})
+			default:
+				ops = diff(ops, p, fv, tv)
+			}
+		}
+		return ops
+	case []any:
+		t, ok := to.([]any)
+		if !ok || len(f) != len(t) {
+			break
+		}
+		for i := range f {
+			ops = diff(ops, path+"/"+strconv.Itoa(i), f[i], t[i])
+		}
+		return ops
+	}
+
+	if reflect.DeepEqual(from, to) {
+		return ops
+	}
+	return append(ops, Operation{Op: "replace", Path: path, Value: nullable(to)})
+}
+
+// escape encodes a key as a JSON Pointer reference token.
+func escape(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// nullValue marshals as JSON null without being dropped by omitempty, so
+// add and replace operations always carry a value.
+type nullValue struct{}
+
+func (nullValue) MarshalJSON() ([]byte, error) { return []byte("null"), nil }
+
+func nullable(v any) any {
+	if v == nil {
+		return nullValue{}
+	}
+	return v
+}
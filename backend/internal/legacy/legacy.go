@@ -0,0 +1,103 @@
+// Package legacy converts snapshots written by the original Python
+// update_data.py into the current snapshot schema.
+package legacy
+
+import (
+	"time"
+)
+
+// signals are the per-signal keys every snapshot carries.
+var signals = []string{"news", "connectivity", "flight", "tanker", "weather", "polymarket", "pentagon"}
+
+// pythonTimeLayouts are the datetime.isoformat() shapes the Python version
+// wrote. It ran on UTC hosts and emitted naive timestamps.
+var pythonTimeLayouts = []string{
+	"2006-01-02T15:04:05.999999",
+	"2006-01-02T15:04:05",
+}
+
+// Convert rewrites a legacy snapshot in place and reports whether anything
+// changed. It handles the oldest layout (top-level "history" and
+// "signalHistory"), a bare numeric total_risk, signals missing history or
+// raw_data, and naive last_updated timestamps. Current-format snapshots are
+// returned unchanged.
+func Convert(data map[string]any) bool {
+	changed := false
+
+	totalRisk, ok := data["total_risk"].(map[string]any)
+	if !ok {
+		totalRisk = map[string]any{}
+		if n, isNum := data["total_risk"].(float64); isNum {
+			totalRisk["risk"] = n
+		}
+		data["total_risk"] = totalRisk
+		changed = true
+	}
+	if hist, ok := data["history"].([]any); ok {
+		if _, has := totalRisk["history"]; !has {
+			totalRisk["history"] = hist
+		}
+		delete(data, "history")
+		changed = true
+	}
+	if _, ok := totalRisk["history"].([]any); !ok {
+		totalRisk["history"] = []any{}
+		changed = true
+	}
+	if _, ok := totalRisk["risk"]; !ok {
+		totalRisk["risk"] = float64(0)
+		changed = true
+	}
+
+	signalHistory, _ := data["signalHistory"].(map[string]any)
+	if _, ok := data["signalHistory"]; ok {
+		delete(data, "signalHistory")
+		changed = true
+	}
+
+	for _, name := range signals {
+		sig, ok := data[name].(map[string]any)
+		if !ok {
+			sig = map[string]any{"risk": float64(0), "detail": ""}
+			data[name] = sig
+			changed = true
+		}
+		if _, ok := sig["history"].([]any); !ok {
+			hist, _ := signalHistory[name].([]any)
+			if hist == nil {
+				hist = []any{}
+			}
+			sig["history"] = hist
+			changed = true
+		}
+		if _, ok := sig["raw_data"].(map[string]any); !ok {
+			sig["raw_data"] = map[string]any{}
+			changed = true
+		}
+	}
+
+	if s, ok := data["last_updated"].(string); ok {
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			if t, ok := ParseTime(s); ok {
+				data["last_updated"] = t.Format(time.RFC3339)
+				changed = true
+			}
+		}
+	}
+
+	return changed
+}
+
+// ParseTime parses a snapshot last_updated value, accepting both RFC 3339
+// and the Python version's naive UTC timestamps.
+func ParseTime(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), true
+	}
+	for _, layout := range pythonTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,187 @@
+// Package logtail keeps the most recent log records in memory so admins can
+// watch them live on platforms that give no access to process output.
+package logtail
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// subscriberBuffer is how many records a slow subscriber may fall behind
+// before newer ones are dropped for it. Logging never waits on a reader.
+const subscriberBuffer = 256
+
+// Record is one captured log record. Attribute keys inside groups are
+// joined with dots, as the text handler prints them.
+type Record struct {
+	Seq     uint64         `json:"seq"`
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"msg"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+
+	level slog.Level
+}
+
+// Buffer is a fixed-size ring of recent records with live subscribers.
+type Buffer struct {
+	mu      sync.Mutex
+	records []Record
+	next    int
+	full    bool
+	seq     uint64
+	subs    map[chan Record]slog.Level
+}
+
+// New returns a buffer holding the last size records.
+func New(size int) *Buffer {
+	return &Buffer{
+		records: make([]Record, size),
+		subs:    make(map[chan Record]slog.Level),
+	}
+}
+
+// Handler returns a slog.Handler that records into b and then passes each
+// record on to next, which also decides which levels are enabled.
+func (b *Buffer) Handler(next slog.Handler) slog.Handler {
+	return &handler{buf: b, next: next}
+}
+
+// Recent returns the buffered records at or above minLevel, oldest first.
+func (b *Buffer) Recent(minLevel slog.Level) []Record {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ordered := b.records[:b.next]
+	if b.full {
+		ordered = append(append([]Record{}, b.records[b.next:]...), b.records[:b.next]...)
+	}
+	out := make([]Record, 0, len(ordered))
+	for _, r := range ordered {
+		if r.level >= minLevel {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Subscribe returns a channel of new records at or above minLevel, and a func
+// that unsubscribes and closes the channel.
+func (b *Buffer) Subscribe(minLevel slog.Level) (<-chan Record, func()) {
+	ch := make(chan Record, subscriberBuffer)
+	b.mu.Lock()
+	b.subs[ch] = minLevel
+	b.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (b *Buffer) add(r Record) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	r.Seq = b.seq
+	b.records[b.next] = r
+	b.next = (b.next + 1) % len(b.records)
+	if b.next == 0 {
+		b.full = true
+	}
+	for ch, minLevel := range b.subs {
+		if r.level < minLevel {
+			continue
+		}
+		select {
+		case ch <- r:
+		default:
+		}
+	}
+}
+
+type handler struct {
+	buf    *Buffer
+	next   slog.Handler
+	attrs  map[string]any
+	prefix string
+}
+
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	rec := Record{
+		Time:    r.Time,
+		Level:   r.Level.String(),
+		Message: r.Message,
+		level:   r.Level,
+	}
+	if len(h.attrs) > 0 || r.NumAttrs() > 0 {
+		rec.Attrs = make(map[string]any, len(h.attrs)+r.NumAttrs())
+		for k, v := range h.attrs {
+			rec.Attrs[k] = v
+		}
+		r.Attrs(func(a slog.Attr) bool {
+			addAttr(rec.Attrs, h.prefix, a)
+			return true
+		})
+	}
+	h.buf.add(rec)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	merged := make(map[string]any, len(h.attrs)+len(attrs))
+	for k, v := range h.attrs {
+		merged[k] = v
+	}
+	for _, a := range attrs {
+		addAttr(merged, h.prefix, a)
+	}
+	return &handler{buf: h.buf, next: h.next.WithAttrs(attrs), attrs: merged, prefix: h.prefix}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &handler{buf: h.buf, next: h.next.WithGroup(name), attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// addAttr flattens a into m. Values that don't marshal usefully as JSON,
+// such as errors, are stored as their string form.
+func addAttr(m map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addAttr(m, p, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	switch v.Kind() {
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			m[prefix+a.Key] = err.Error()
+			return
+		}
+		m[prefix+a.Key] = v.Any()
+	case slog.KindDuration:
+		m[prefix+a.Key] = v.Duration().String()
+	default:
+		m[prefix+a.Key] = v.Any()
+	}
+}
@@ -1,25 +1,91 @@
 package model
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
 // Signal represents a single risk signal with history and raw data.
 type Signal struct {
-	Risk    int            `json:"risk"`
-	Detail  string         `json:"detail"`
-	History []int          `json:"history"`
-	RawData map[string]any `json:"raw_data"`
+	Risk     int            `json:"risk"`
+	Detail   string         `json:"detail"`
+	Elevated bool           `json:"elevated"`
+	History  []int          `json:"history"`
+	RawData  map[string]any `json:"raw_data"`
+	// Overridden marks a risk set by an operator instead of computed from
+	// RawData; Override says who set it, why and until when.
+	Overridden bool            `json:"overridden,omitempty"`
+	Override   *SignalOverride `json:"override,omitempty"`
+	// FetchedAt is when RawData was fetched. IsStale marks a signal whose
+	// fetch failed this run, so RawData is carried over from an earlier
+	// one, or empty if there was none; StaleSince is the first run it
+	// failed in.
+	FetchedAt  string `json:"fetched_at,omitempty"`
+	IsStale    bool   `json:"is_stale"`
+	StaleSince string `json:"stale_since,omitempty"`
 }
 
 // TotalRiskPoint is a single point in the total risk history timeline.
+// Uncertainty is the half-width of its band, see TotalRisk.
 type TotalRiskPoint struct {
-	Timestamp int64 `json:"timestamp"`
-	Risk      int   `json:"risk"`
-	Pinned    bool  `json:"pinned,omitempty"`
+	Timestamp   int64 `json:"timestamp"`
+	Risk        int   `json:"risk"`
+	Uncertainty int   `json:"uncertainty,omitempty"`
+	Pinned      bool  `json:"pinned,omitempty"`
 }
 
 // TotalRisk holds the aggregated risk and its history.
 type TotalRisk struct {
 	Risk          int              `json:"risk"`
 	History       []TotalRiskPoint `json:"history"`
-	ElevatedCount int             `json:"elevated_count"`
+	ElevatedCount int              `json:"elevated_count"`
+	Forecast      []ForecastPoint  `json:"forecast,omitempty"`
+	// Comparisons is total risk 24h, 7d and 30d ago, for those periods
+	// with a stored run close enough to the mark.
+	Comparisons []RiskComparison `json:"comparisons,omitempty"`
+	// CalendarModifier is the points Risk includes for nearby sensitive dates.
+	CalendarModifier int `json:"calendar_modifier"`
+	// Uncertainty is how many points either way Risk could move if the
+	// signals served from stale or missing data were fetched fresh.
+	Uncertainty int `json:"uncertainty"`
+	// Degraded is set when too many signals were stale for a normal
+	// score, and Risk was computed without ExcludedSignals.
+	Degraded        bool     `json:"degraded"`
+	ExcludedSignals []string `json:"excluded_signals,omitempty"`
+}
+
+// RiskComparison is total risk one period ago, from the stored run nearest
+// that time. Delta is the current risk minus Risk.
+type RiskComparison struct {
+	Period    string `json:"period"`
+	Timestamp int64  `json:"timestamp"`
+	Risk      int    `json:"risk"`
+	Delta     int    `json:"delta"`
+}
+
+// SensitiveDate is an upcoming date of military or political significance.
+// Modifier is what it currently adds to total risk, zero until its lead
+// window begins.
+type SensitiveDate struct {
+	Name      string `json:"name"`
+	Date      string `json:"date"`
+	DaysUntil int    `json:"days_until"`
+	Modifier  int    `json:"modifier"`
+}
+
+// ForecastPoint is the projected total risk a number of hours ahead, with
+// its 95% confidence interval.
+type ForecastPoint struct {
+	Hours int    `json:"hours"`
+	Risk  int    `json:"risk"`
+	Low   int    `json:"low"`
+	High  int    `json:"high"`
+	Band  string `json:"band"`
 }
 
 // PulseIsrael holds Israel-specific pulse statistics.
@@ -46,111 +112,673 @@ type Pulse struct {
 	TotalCountries     int            `json:"total_countries"`
 }
 
-// Snapshot is the full API response served to the frontend.
+// Snapshot is the full API response served to the frontend. Each signal
+// is a top-level member named after it, alongside the fields below; see
+// MarshalJSON.
 type Snapshot struct {
-	News         Signal    `json:"news"`
-	Connectivity Signal    `json:"connectivity"`
-	Flight       Signal    `json:"flight"`
-	Tanker       Signal    `json:"tanker"`
-	Weather      Signal    `json:"weather"`
-	Polymarket   Signal    `json:"polymarket"`
-	Pentagon     Signal    `json:"pentagon"`
-	TotalRisk    TotalRisk `json:"total_risk"`
-	LastUpdated  string    `json:"last_updated"`
-	Pulse        *Pulse    `json:"pulse,omitempty"`
+	Signals     map[string]*Signal `json:"-"`
+	TotalRisk   TotalRisk          `json:"total_risk"`
+	LastUpdated string             `json:"last_updated"`
+	Pulse       *Pulse             `json:"pulse,omitempty"`
+
+	ChangesSinceLast []SignalChange  `json:"changes_since_last"`
+	SensitiveDates   []SensitiveDate `json:"upcoming_sensitive_dates"`
+	DataQuality      *DataQuality    `json:"data_quality,omitempty"`
+
+	// ServerTimezone is the zone whose midnight and noon pin total risk history.
+	ServerTimezone string `json:"server_timezone"`
+
+	// Meta is the operator's static content from SNAPSHOT_META, passed
+	// through as configured.
+	Meta map[string]any `json:"meta,omitempty"`
+}
+
+// Signal returns the signal stored under name, or nil for an unknown name.
+func (s *Snapshot) Signal(name string) *Signal {
+	return s.Signals[name]
+}
+
+// snapshotFields is the plain form of Snapshot, encoded without its signals.
+type snapshotFields Snapshot
+
+// fieldNames are the JSON members Snapshot's fields are encoded under.
+var fieldNames = sync.OnceValue(func() map[string]bool {
+	names := map[string]bool{}
+	t := reflect.TypeOf(snapshotFields{})
+	for i := 0; i < t.NumField(); i++ {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+})
+
+// MarshalJSON encodes the signals as members of the snapshot itself, in
+// name order ahead of the other fields.
+func (s Snapshot) MarshalJSON() ([]byte, error) {
+	rest, err := json.Marshal(snapshotFields(s))
+	if err != nil || len(s.Signals) == 0 {
+		return rest, err
+	}
+	signals, err := json.Marshal(s.Signals)
+	if err != nil {
+		return nil, err
+	}
+	out := append(signals[:len(signals)-1], ',')
+	return append(out, rest[1:]...), nil
+}
+
+// UnmarshalJSON reads every object-valued member that isn't one of the
+// snapshot's fields as a signal.
+func (s *Snapshot) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*snapshotFields)(s)); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+	s.Signals = make(map[string]*Signal)
+	for name, raw := range members {
+		if fieldNames()[name] || !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+			continue
+		}
+		var sig Signal
+		if err := json.Unmarshal(raw, &sig); err != nil {
+			return fmt.Errorf("signal %s: %w", name, err)
+		}
+		s.Signals[name] = &sig
+	}
+	return nil
+}
+
+// DataQuality summarizes how much of a snapshot rests on data fetched in
+// this run versus data carried over from earlier runs.
+type DataQuality struct {
+	Score             float64                  `json:"score"`
+	Fresh             int                      `json:"fresh"`
+	Total             int                      `json:"total"`
+	AverageAgeSeconds int64                    `json:"average_age_seconds"`
+	Signals           map[string]SignalQuality `json:"signals"`
+	// Upstreams is each upstream's circuit breaker at the end of the run.
+	Upstreams []BreakerStatus `json:"upstreams,omitempty"`
+	// Anomalies are the fresh readings that jumped implausibly far from
+	// the previous run's.
+	Anomalies []Anomaly `json:"anomalies,omitempty"`
+}
+
+// Anomaly is a reading that moved more than MaxDelta since the previous
+// run, suspected to be an upstream glitch. A withheld signal is left out
+// of total risk for the run; one flagged on the run before is not withheld
+// again, so a real change is only held back once.
+type Anomaly struct {
+	Signal   string  `json:"signal"`
+	Field    string  `json:"field"`
+	Previous float64 `json:"previous"`
+	Current  float64 `json:"current"`
+	MaxDelta float64 `json:"max_delta"`
+	Withheld bool    `json:"withheld"`
+}
+
+// BreakerStatus is one upstream's circuit breaker. State is "closed" while
+// it is called normally, "open" while it is skipped until RetryAt, and
+// "half_open" while a trial fetch is allowed.
+type BreakerStatus struct {
+	Source    string     `json:"source"`
+	State     string     `json:"state"`
+	Failures  int        `json:"consecutive_failures"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// SignalQuality is the provenance of one signal's data. Status is "ok" when
+// fetched in this run, "fallback" when reused from a previous snapshot, and
+// "missing" when no data was available. ErrorKind classifies this run's
+// fetch failure (e.g. "rate_limit", "parse") when the status is not "ok".
+// AgeSeconds is measured when the snapshot is built; data older than
+// ExpectedMaxAgeSeconds (compare against fetched_at at read time) is
+// outside the signal's freshness SLA.
+type SignalQuality struct {
+	Status                string `json:"status"`
+	FetchedAt             string `json:"fetched_at,omitempty"`
+	AgeSeconds            int64  `json:"age_seconds"`
+	ExpectedMaxAgeSeconds int64  `json:"expected_max_age_seconds"`
+	ErrorKind             string `json:"error_kind,omitempty"`
+	// Confidence is how far the signal's data is trusted, from 1 when
+	// fresh down to 0 when missing or older than the stale cutoff.
+	Confidence float64 `json:"confidence"`
+}
+
+// SignalChange describes a notable risk movement since the previous run.
+type SignalChange struct {
+	Signal      string `json:"signal"`
+	From        int    `json:"from"`
+	To          int    `json:"to"`
+	Delta       int    `json:"delta"`
+	Description string `json:"description"`
 }
 
 // RiskScores holds the output of the risk calculator before history is applied.
 type RiskScores struct {
-	News         SignalScore
-	Connectivity SignalScore
-	Flight       SignalScore
-	Tanker       SignalScore
-	Weather      SignalScore
-	Polymarket   SignalScore
-	Pentagon     SignalScore
-	TotalRisk    int
+	// Signals are the signal scores in scoring order.
+	Signals       []NamedSignalScore
+	TotalRisk     int
 	ElevatedCount int
+	// Uncertainty is set once data quality is known; see TotalRisk.
+	Uncertainty int
 }
 
 // SignalScore is a single signal's computed risk and detail string.
+// Override is set when an operator's hold replaced the computed risk.
 type SignalScore struct {
-	Risk   int
-	Detail string
+	Risk     int
+	Detail   string
+	Elevated bool
+	Override *SignalOverride
 }
 
-// RawResults holds the raw API data keyed by signal name.
-type RawResults struct {
-	News         map[string]any
-	Connectivity map[string]any
-	Flight       map[string]any
-	Tanker       map[string]any
-	Weather      map[string]any
-	Polymarket   map[string]any
-	Pentagon     map[string]any
-}
-
-// FetchResults holds the structured data returned by fetchers, used for risk calculation.
-type FetchResults struct {
-	News         NewsData
-	Connectivity ConnectivityData
-	Aviation     AviationData
-	Tanker       TankerData
-	Weather      WeatherData
-	Polymarket   PolymarketData
-	Pentagon     PentagonData
+// NamedSignalScore pairs a signal score with its snapshot key, its label
+// and the risk at which it counts as elevated.
+type NamedSignalScore struct {
+	Name        string
+	Label       string
+	ElevatedMin int
+	SignalScore
 }
 
+// Score returns the score stored under a snapshot signal name, or nil for
+// an unscored name.
+func (r *RiskScores) Score(name string) *SignalScore {
+	for i := range r.Signals {
+		if r.Signals[i].Name == name {
+			return &r.Signals[i].SignalScore
+		}
+	}
+	return nil
+}
+
+// RawResults holds the raw API data keyed by signal name.
+type RawResults map[string]map[string]any
+
 type NewsData struct {
-	Articles   []map[string]any `json:"articles"`
-	TotalCount int              `json:"total_count"`
-	AlertCount int              `json:"alert_count"`
-	Timestamp  string           `json:"timestamp"`
+	Articles          []map[string]any `json:"articles"`
+	TotalCount        int              `json:"total_count"`
+	AlertCount        int              `json:"alert_count"`
+	DeescalationCount int              `json:"deescalation_count"`
+	Timestamp         string           `json:"timestamp"`
+	Feeds             FeedCoverage     `json:"feeds"`
+	// Lookback is the publish-date window articles were counted over, empty
+	// when whole feeds were; ArticlesPerHour is the rate within it.
+	Lookback        string  `json:"lookback,omitempty"`
+	ArticlesPerHour float64 `json:"articles_per_hour,omitempty"`
+}
+
+// FeedCoverage is how many news feeds answered this run. Live excludes
+// feeds that have been dead long enough to stop counting against coverage.
+type FeedCoverage struct {
+	OK      int          `json:"ok"`
+	Live    int          `json:"live"`
+	Results []FeedResult `json:"results,omitempty"`
+}
+
+// FeedResult is one news feed's outcome in a run. Parsed means the body
+// was a readable RSS or Atom document; Matched counts the items that hit a
+// topic keyword.
+type FeedResult struct {
+	URL     string `json:"url"`
+	Fetched bool   `json:"fetched"`
+	Parsed  bool   `json:"parsed"`
+	Items   int    `json:"items"`
+	Matched int    `json:"matched"`
+	Error   string `json:"error,omitempty"`
+	Dead    bool   `json:"dead,omitempty"`
+}
+
+// FeedHealth summarizes a news feed's recent runs. Status is ok when the
+// latest run succeeded, dead when none has for the configured period, and
+// failing otherwise.
+type FeedHealth struct {
+	URL            string     `json:"url"`
+	Status         string     `json:"status"`
+	Checks         int        `json:"checks"`
+	FetchOK        int        `json:"fetch_ok"`
+	ParseOK        int        `json:"parse_ok"`
+	AvgItems       float64    `json:"avg_items"`
+	LastCheckedAt  time.Time  `json:"last_checked_at"`
+	LastSuccessAt  *time.Time `json:"last_success_at,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	FirstCheckedAt time.Time  `json:"-"`
 }
 
 type ConnectivityData struct {
-	Status    string    `json:"status"`
-	Risk      float64   `json:"risk"`
-	Trend     float64   `json:"trend"`
-	Values    []float64 `json:"values"`
-	Timestamp string    `json:"timestamp"`
-	Error     string    `json:"error,omitempty"`
+	Status    string          `json:"status"`
+	Risk      float64         `json:"risk"`
+	Trend     float64         `json:"trend"`
+	Values    []float64       `json:"values"`
+	Baseline  string          `json:"baseline,omitempty"`
+	Networks  []NetworkStatus `json:"networks,omitempty"`
+	Countries []CountryStatus `json:"countries,omitempty"`
+	Timestamp string          `json:"timestamp"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// CountryStatus is one row of the regional connectivity table: a country's
+// national traffic trend against its own diurnal baseline.
+type CountryStatus struct {
+	Code   string    `json:"code"`
+	Name   string    `json:"name"`
+	Status string    `json:"status"`
+	Trend  float64   `json:"trend"`
+	Values []float64 `json:"values,omitempty"`
+}
+
+// NetworkStatus is the connectivity state of a single ASN or country.
+type NetworkStatus struct {
+	Name     string  `json:"name"`
+	Query    string  `json:"query"`
+	Domestic bool    `json:"domestic"`
+	Status   string  `json:"status"`
+	Trend    float64 `json:"trend"`
 }
 
 type AviationData struct {
-	AircraftCount int      `json:"aircraft_count"`
-	AirlineCount  int      `json:"airline_count"`
-	Airlines      []string `json:"airlines"`
-	Timestamp     string   `json:"timestamp"`
+	AircraftCount   int      `json:"aircraft_count"`
+	AirlineCount    int      `json:"airline_count"`
+	Airlines        []string `json:"airlines"`
+	MajorCarriers   []string `json:"major_carriers"`
+	MissingCarriers []string `json:"missing_carriers,omitempty"`
+	Baseline        float64  `json:"baseline,omitempty"`
+	BaselineSamples int      `json:"baseline_samples,omitempty"`
+	Timestamp       string   `json:"timestamp"`
+
+	// Positions are served by /api/map only, not stored in raw_data.
+	Positions []Position `json:"-"`
+}
+
+// majorCarriers are the ICAO airline prefixes whose disappearance from
+// Iranian airspace is treated as an avoidance signal.
+var majorCarriers = map[string]string{
+	"UAE": "Emirates",
+	"QTR": "Qatar Airways",
+	"THY": "Turkish Airlines",
+	"FDB": "flydubai",
+	"ETD": "Etihad",
+	"PGT": "Pegasus",
+	"AIC": "Air India",
+	"AFL": "Aeroflot",
+	"AZG": "Silk Way",
+	"KAC": "Kuwait Airways",
+}
+
+// MajorCarrier reports whether an airline prefix is a tracked major carrier.
+func MajorCarrier(code string) bool {
+	_, ok := majorCarriers[code]
+	return ok
+}
+
+// CarrierName returns the display name for a major carrier prefix, or the
+// prefix itself if it is not a tracked carrier.
+func CarrierName(code string) string {
+	if name, ok := majorCarriers[code]; ok {
+		return name
+	}
+	return code
 }
 
 type TankerData struct {
-	TankerCount int      `json:"tanker_count"`
-	Callsigns   []string `json:"callsigns"`
-	Timestamp   string   `json:"timestamp"`
+	TankerCount     int      `json:"tanker_count"`
+	Callsigns       []string `json:"callsigns"`
+	Baseline        float64  `json:"baseline,omitempty"`
+	BaselineSamples int      `json:"baseline_samples,omitempty"`
+	Timestamp       string   `json:"timestamp"`
+
+	Positions []Position `json:"-"`
+}
+
+// TrackPoint is a stored position from one pipeline run. Kind is "aircraft"
+// or "tanker".
+type TrackPoint struct {
+	Kind       string    `json:"kind"`
+	ObservedAt time.Time `json:"observed_at"`
+	Position
+}
+
+// Position is an airborne aircraft's reported location.
+type Position struct {
+	ICAO     string  `json:"icao"`
+	Callsign string  `json:"callsign"`
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	Altitude float64 `json:"altitude"`
+	Heading  float64 `json:"heading"`
 }
 
 type WeatherData struct {
-	Temp        int    `json:"temp"`
-	Visibility  int    `json:"visibility"`
-	Clouds      int    `json:"clouds"`
-	Description string `json:"description"`
-	Condition   string `json:"condition"`
-	Timestamp   string `json:"timestamp"`
+	Temp        int     `json:"temp"`
+	Visibility  int     `json:"visibility"`
+	Clouds      int     `json:"clouds"`
+	WindSpeed   float64 `json:"wind_speed"`
+	ConditionID int     `json:"condition_id"`
+	Dust        bool    `json:"dust"`
+	Haze        bool    `json:"haze"`
+	Description string  `json:"description"`
+	Condition   string  `json:"condition"`
+	Provider    string  `json:"provider,omitempty"`
+	// Source is the provider chosen for this run, as recorded by every
+	// signal with more than one; Provider predates it.
+	Source    string `json:"source,omitempty"`
+	Timestamp string `json:"timestamp"`
+
+	Imagery *WeatherImagery `json:"imagery,omitempty"`
+}
+
+// WeatherImagery describes map tile layers for the region. Layer URLs are
+// XYZ templates on the aegis tile proxy, so no API key reaches the client.
+type WeatherImagery struct {
+	Layers      map[string]string `json:"layers"`
+	Bounds      [4]float64        `json:"bounds"`
+	MinZoom     int               `json:"min_zoom"`
+	MaxZoom     int               `json:"max_zoom"`
+	Attribution string            `json:"attribution"`
 }
 
 type PolymarketData struct {
-	Odds      int    `json:"odds"`
-	Market    string `json:"market"`
-	Timestamp string `json:"timestamp"`
+	Odds      int        `json:"odds"`
+	Market    string     `json:"market"`
+	TokenID   string     `json:"token_id,omitempty"`
+	OrderBook *OrderBook `json:"order_book,omitempty"`
+	Timestamp string     `json:"timestamp"`
+}
+
+// OrderBook summarizes the CLOB book for a market's YES outcome. Prices are
+// probabilities (0–1); depth is the dollar value resting within DepthBand
+// of the best bid and ask.
+type OrderBook struct {
+	BestBid   float64 `json:"best_bid"`
+	BestAsk   float64 `json:"best_ask"`
+	Spread    float64 `json:"spread"`
+	BidDepth  float64 `json:"bid_depth"`
+	AskDepth  float64 `json:"ask_depth"`
+	DepthBand float64 `json:"depth_band"`
+}
+
+// AttentionData holds one reading per enabled public-attention source.
+type AttentionData struct {
+	Components []AttentionComponent `json:"components"`
+	Timestamp  string               `json:"timestamp"`
+}
+
+// AttentionComponent is one source of the attention index. Ratio is current
+// activity over the source's usual level, so 1 means normal.
+type AttentionComponent struct {
+	Source string  `json:"source"`
+	Ratio  float64 `json:"ratio"`
+	Detail string  `json:"detail"`
+}
+
+// GeopoliticsData is GDELT's coverage of conflict between Iran, Israel and
+// the US. GDELT's DOC API counts coverage in articles: ArticleCount is the
+// last 24 hours' worth and Ratio its rate over the week before. Tone runs
+// from -100 to +100, though coverage rarely leaves -10 to +10; the more
+// negative, the more hostile.
+type GeopoliticsData struct {
+	ArticleCount   int     `json:"article_count"`
+	BaselinePerDay float64 `json:"baseline_per_day"`
+	Ratio          float64 `json:"ratio"`
+	AverageTone    float64 `json:"average_tone"`
+	BaselineTone   float64 `json:"baseline_tone"`
+	Timestamp      string  `json:"timestamp"`
+}
+
+// ShippingData counts AIS-reporting vessels under way in the Strait of
+// Hormuz, with tankers (AIS ship types 80-89) counted apart so tankers
+// rerouting shows even when other traffic holds up. Baseline and
+// TankerBaseline are the same-hour averages, set by the pipeline.
+type ShippingData struct {
+	VesselCount     int     `json:"vessel_count"`
+	TankerCount     int     `json:"tanker_count"`
+	Baseline        float64 `json:"baseline,omitempty"`
+	TankerBaseline  float64 `json:"tanker_baseline,omitempty"`
+	BaselineSamples int     `json:"baseline_samples,omitempty"`
+	Timestamp       string  `json:"timestamp"`
+}
+
+// AirspaceData summarises the NOTAMs in force for the watched FIRs and
+// aerodromes. Closures lists each closed FIR or aerodrome; Restrictions
+// counts restricted, danger and prohibited areas activated, and Warnings
+// NOTAMs warning of military activity or conflict. Unavailable lists the
+// locations whose NOTAMs couldn't be read this run.
+type AirspaceData struct {
+	NotamCount   int               `json:"notam_count"`
+	Closures     []AirspaceClosure `json:"closures"`
+	Restrictions int               `json:"restrictions"`
+	Warnings     int               `json:"warnings"`
+	Unavailable  []string          `json:"unavailable,omitempty"`
+	Timestamp    string            `json:"timestamp"`
+}
+
+// AirspaceClosure is one NOTAM closing a FIR or aerodrome. Kind is "fir"
+// or "aerodrome".
+type AirspaceClosure struct {
+	Location string `json:"location"`
+	Kind     string `json:"kind"`
+	Number   string `json:"number"`
+}
+
+// SeismicData lists the USGS events of the last day in the watched area.
+// Suspicious counts those unlike natural seismicity there: very shallow and
+// small, the signature of a detonation, or typed an explosion by USGS.
+type SeismicData struct {
+	EventCount int            `json:"event_count"`
+	Suspicious int            `json:"suspicious"`
+	Events     []SeismicEvent `json:"events"`
+	Timestamp  string         `json:"timestamp"`
+}
+
+// SeismicEvent is one USGS event. Type is USGS's own, e.g. "earthquake",
+// "explosion" or "quarry blast".
+type SeismicEvent struct {
+	ID         string  `json:"id"`
+	Place      string  `json:"place"`
+	Magnitude  float64 `json:"magnitude"`
+	DepthKm    float64 `json:"depth_km"`
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+	Time       string  `json:"time"`
+	Type       string  `json:"type"`
+	Suspicious bool    `json:"suspicious"`
+}
+
+// GPSData is the share of aircraft reporting degraded GPS integrity in
+// each watched zone, the measure of jamming gpsjam.org maps. Zones that
+// couldn't be read are listed in Unavailable.
+type GPSData struct {
+	Zones       []GPSZone `json:"zones"`
+	Unavailable []string  `json:"unavailable,omitempty"`
+	Timestamp   string    `json:"timestamp"`
+}
+
+// GPSZone is one zone's reading. Aircraft counts those reporting an
+// integrity category at all, and Degraded those of them below the
+// threshold; Ratio is Degraded over Aircraft, 0 with none.
+type GPSZone struct {
+	Name     string  `json:"name"`
+	Aircraft int     `json:"aircraft"`
+	Degraded int     `json:"degraded"`
+	Ratio    float64 `json:"ratio"`
 }
 
 type PentagonData struct {
 	Score            int              `json:"score"`
-	RiskContribution int             `json:"risk_contribution"`
-	Status           string          `json:"status"`
+	RiskContribution int              `json:"risk_contribution"`
+	Status           string           `json:"status"`
 	Places           []map[string]any `json:"places"`
-	Timestamp        string          `json:"timestamp"`
-	IsLateNight      bool            `json:"is_late_night"`
-	IsWeekend        bool            `json:"is_weekend"`
+	Timestamp        string           `json:"timestamp"`
+	IsLateNight      bool             `json:"is_late_night"`
+	IsWeekend        bool             `json:"is_weekend"`
+	// Cluster names the facility the fields above describe, the busiest
+	// when several are watched; Clusters has each one's own reading.
+	Cluster  string         `json:"cluster,omitempty"`
+	Clusters []PizzaReading `json:"clusters,omitempty"`
+}
+
+// PizzaReading is the pizza meter at one watched facility, with late night
+// and weekend judged in that facility's time zone.
+type PizzaReading struct {
+	Name        string           `json:"name"`
+	Score       int              `json:"score"`
+	Status      string           `json:"status"`
+	Places      []map[string]any `json:"places"`
+	IsLateNight bool             `json:"is_late_night"`
+	IsWeekend   bool             `json:"is_weekend"`
+}
+
+// Incident records a run in which several signals spiked together: the
+// signals involved with their moves, and the leading headlines at the
+// time.
+type Incident struct {
+	ID         int64          `json:"id"`
+	RunID      string         `json:"run_id"`
+	DetectedAt time.Time      `json:"detected_at"`
+	TotalRisk  int            `json:"total_risk"`
+	Signals    []SignalChange `json:"signals"`
+	Headlines  []string       `json:"headlines"`
+}
+
+// Annotation is an operator note attached to a point in time.
+type Annotation struct {
+	ID         int64     `json:"id"`
+	Body       string    `json:"body"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Tenant is an API consumer identified by a key. RateLimit is in requests
+// per minute; zero means unlimited.
+type Tenant struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	RateLimit int       `json:"rate_limit"`
+	Disabled  bool      `json:"disabled"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TenantUsage is one tenant's request and response-byte totals for a UTC day.
+type TenantUsage struct {
+	TenantID int64  `json:"tenant_id"`
+	Name     string `json:"name,omitempty"`
+	Day      string `json:"day"`
+	Requests int64  `json:"requests"`
+	Bytes    int64  `json:"bytes"`
+}
+
+// Meta describes how signals are weighted and judged elevated.
+type Meta struct {
+	Signals              []SignalMeta `json:"signals"`
+	EscalationSignals    int          `json:"escalation_signals"`
+	EscalationMultiplier float64      `json:"escalation_multiplier"`
+	Bands                []BandMeta   `json:"bands"`
+}
+
+// SignalMeta is one signal's weight and elevation threshold. A signal is
+// elevated when its displayed risk is at least ElevatedMin.
+type SignalMeta struct {
+	Name        string  `json:"name"`
+	Label       string  `json:"label"`
+	Weight      float64 `json:"weight"`
+	ElevatedMin int     `json:"elevated_min"`
+}
+
+// BandMeta is the lowest total risk in a status band.
+type BandMeta struct {
+	Name string `json:"name"`
+	Min  int    `json:"min"`
+}
+
+// DatasetExport records one published daily dataset. Keys are object keys
+// in the archive bucket.
+type DatasetExport struct {
+	Day         string    `json:"day"`
+	JSONKey     string    `json:"-"`
+	CSVKey      string    `json:"-"`
+	Hours       int       `json:"hours"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// ConfigOverride is a stored setting layered over the environment. Key is
+// the environment variable it replaces.
+type ConfigOverride struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedBy string    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SignalOverride is an operator's hold on one signal's risk, used in place
+// of the computed score until ExpiresAt.
+type SignalOverride struct {
+	Signal    string    `json:"signal"`
+	Risk      int       `json:"risk"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AlertTestResult is one notification channel's outcome for a test alert.
+type AlertTestResult struct {
+	Channel string `json:"channel"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PulseWindow is the visits by country over one completed 10-minute pulse
+// window, ending at EndedAt.
+type PulseWindow struct {
+	EndedAt time.Time      `json:"ended_at"`
+	Counts  map[string]int `json:"counts"`
+}
+
+// Webhook is a subscription to total risk crossing Threshold in Direction:
+// "up", "down" or "both". Secret signs each delivery and is only shown when
+// the webhook is created.
+type Webhook struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Threshold   int       `json:"threshold"`
+	Direction   string    `json:"direction"`
+	PayloadMode string    `json:"payload_mode"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SignalScoreRow is one stored per-signal score from a pipeline run.
+type SignalScoreRow struct {
+	RunID     string
+	Signal    string
+	Risk      int
+	Detail    string
+	Elevated  bool
+	CreatedAt time.Time
+}
+
+// ModelReport is a periodic diagnostic of how the signals behave and how
+// much each drives the total.
+type ModelReport struct {
+	GeneratedAt  string                        `json:"generated_at"`
+	WindowDays   int                           `json:"window_days"`
+	Runs         int                           `json:"runs"`
+	Signals      map[string]SignalStats        `json:"signals"`
+	Correlations map[string]map[string]float64 `json:"correlations"`
+}
+
+// SignalStats summarizes one signal over the report window. Contribution is
+// the signal's share of the summed weighted risk.
+type SignalStats struct {
+	Mean         float64 `json:"mean"`
+	Variance     float64 `json:"variance"`
+	StdDev       float64 `json:"std_dev"`
+	Min          int     `json:"min"`
+	Max          int     `json:"max"`
+	Contribution float64 `json:"contribution"`
 }
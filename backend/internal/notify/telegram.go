@@ -0,0 +1,206 @@
+// Package notify posts alerts about the risk picture to chat services.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// telegramAPI is the Bot API base URL; the token is appended to it.
+const telegramAPI = "https://api.telegram.org/bot"
+
+// signals lists the signals in a message, in dashboard order.
+var signals = fetcher.Names()
+
+// Telegram posts to a chat whenever total risk moves into a different
+// band, with each signal's risk and detail. Messages are sent in the
+// background so a slow Bot API never holds up a pipeline run.
+type Telegram struct {
+	cfg       config.Telegram
+	publicURL string
+	client    *http.Client
+
+	// band and total are those of the last snapshot seen. Only
+	// SnapshotUpdated, called from the pipeline run, touches them.
+	band    string
+	total   int
+	pending chan string
+	stop    chan struct{}
+}
+
+// NewTelegram returns a notifier for the configured chat. prev is the
+// snapshot the next one is compared against, nil if there is none yet; a
+// message links to publicURL when it is set.
+func NewTelegram(cfg config.Telegram, publicURL string, prev []byte) *Telegram {
+	t := &Telegram{
+		cfg:       cfg,
+		publicURL: publicURL,
+		client:    &http.Client{Timeout: 15 * time.Second},
+		pending:   make(chan string, 8),
+		stop:      make(chan struct{}),
+	}
+	if prev != nil {
+		var snap model.Snapshot
+		if err := json.Unmarshal(prev, &snap); err == nil {
+			t.band, t.total = risk.Band(snap.TotalRisk.Risk), snap.TotalRisk.Risk
+		}
+	}
+	return t
+}
+
+// SnapshotUpdated queues a message if data's total risk is in a different
+// band from the previous snapshot's. It never blocks.
+func (t *Telegram) SnapshotUpdated(data []byte) {
+	var snap model.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		slog.Warn("telegram: failed to parse snapshot", "error", err)
+		return
+	}
+	prev, prevTotal, band := t.band, t.total, risk.Band(snap.TotalRisk.Risk)
+	t.band, t.total = band, snap.TotalRisk.Risk
+	if prev == "" || prev == band {
+		return
+	}
+	select {
+	case t.pending <- t.message(&snap, prev, prevTotal):
+	default:
+		slog.Warn("telegram: queue full, dropping band change", "from", prev, "to", band)
+	}
+}
+
+// Start sends queued messages until Stop is called.
+func (t *Telegram) Start(ctx context.Context) {
+	slog.Info("telegram notifier started", "chat", t.cfg.ChatID)
+	for {
+		select {
+		case text := <-t.pending:
+			if err := t.send(ctx, text); err != nil {
+				slog.Error("telegram: failed to send message", "error", err)
+			}
+		case <-t.stop:
+			slog.Info("telegram notifier stopped")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop signals the notifier to stop.
+func (t *Telegram) Stop() {
+	close(t.stop)
+}
+
+// TestAlert sends the band change from prev to cur now, marked as a test,
+// and reports whether the Bot API accepted it.
+func (t *Telegram) TestAlert(ctx context.Context, prev, cur []byte) []model.AlertTestResult {
+	result := model.AlertTestResult{Channel: "telegram"}
+	var p, c model.Snapshot
+	if err := json.Unmarshal(prev, &p); err != nil {
+		result.Error = "parsing snapshot: " + err.Error()
+		return []model.AlertTestResult{result}
+	}
+	if err := json.Unmarshal(cur, &c); err != nil {
+		result.Error = "parsing snapshot: " + err.Error()
+		return []model.AlertTestResult{result}
+	}
+	text := "🧪 TEST ALERT, no action needed\n\n" + t.message(&c, risk.Band(p.TotalRisk.Risk), p.TotalRisk.Risk)
+	if err := t.send(ctx, text); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.OK = true
+	}
+	return []model.AlertTestResult{result}
+}
+
+// message describes snap's move out of band prev, where total risk was
+// prevTotal.
+func (t *Telegram) message(snap *model.Snapshot, prev string, prevTotal int) string {
+	total := snap.TotalRisk.Risk
+	verb, mark := "rose", "🔺"
+	if total < prevTotal {
+		verb, mark = "fell", "🔻"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s Strike risk %s to %s: %d%% (was %s, %d%%)\n", mark, verb, strings.ToUpper(risk.Band(total)), total, prev, prevTotal)
+	b.WriteString("\n")
+	for _, name := range signals {
+		sig := snap.Signal(name)
+		if sig == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s: %d%%", signalMark(sig), name, sig.Risk)
+		if sig.Detail != "" {
+			fmt.Fprintf(&b, " (%s)", sig.Detail)
+		}
+		b.WriteString("\n")
+	}
+	if t.publicURL != "" {
+		b.WriteString("\n")
+		b.WriteString(t.publicURL)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// signalMark flags elevated signals so they stand out in the breakdown.
+func signalMark(sig *model.Signal) string {
+	if sig.Elevated {
+		return "⚠️"
+	}
+	return "•"
+}
+
+// send posts text to the chat with the Bot API's sendMessage.
+func (t *Telegram) send(ctx context.Context, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  t.cfg.ChatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, telegramAPI+t.cfg.BotToken+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		// The request URL holds the bot token, which *url.Error would print
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("sendMessage: %w", err)
+	}
+	defer resp.Body.Close()
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil {
+		return fmt.Errorf("sendMessage: status %d", resp.StatusCode)
+	}
+	if !result.OK {
+		return fmt.Errorf("sendMessage: %s", result.Description)
+	}
+	slog.Info("telegram: sent band change")
+	return nil
+}
@@ -0,0 +1,108 @@
+package pipeline
+
+import (
+	"log/slog"
+	"math"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+var signalAnomalies = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "aegis",
+	Subsystem: "pipeline",
+	Name:      "signal_anomalies_total",
+	Help:      "Fresh readings flagged as implausible jumps from the previous run, by signal.",
+}, []string{"signal"})
+
+// anomalyLimit is the largest move a raw_data field can plausibly make
+// between two runs.
+type anomalyLimit struct {
+	field    string
+	maxDelta float64
+}
+
+// anomalyLimits are checked on each fresh fetch. They are set well past
+// anything seen between runs, so only an upstream glitch, such as a feed
+// returning an empty or duplicated region, trips them.
+var anomalyLimits = map[string][]anomalyLimit{
+	"flight":       {{field: "aircraft_count", maxDelta: 300}},
+	"tanker":       {{field: "tanker_count", maxDelta: 25}},
+	"shipping":     {{field: "vessel_count", maxDelta: 150}},
+	"connectivity": {{field: "trend", maxDelta: 60}},
+	"airspace":     {{field: "notam_count", maxDelta: 500}},
+}
+
+// detectAnomalies compares this run's fresh raw_data with the previous
+// snapshot's and flags the fields that moved more than their limit, in
+// signal order. Fallbacks are skipped, being the previous data already.
+func detectAnomalies(results signalResults, current map[string]any) []model.Anomaly {
+	flagged := previouslyWithheld(current)
+	var anomalies []model.Anomaly
+	for name, limits := range anomalyLimits {
+		r := results[name]
+		if r == nil || r.err != nil {
+			continue
+		}
+		prev := previousRawData(current, name)
+		if prev == nil {
+			continue
+		}
+		for _, l := range limits {
+			was, ok1 := numberFromAny(prev[l.field])
+			now, ok2 := numberFromAny(r.raw[l.field])
+			if !ok1 || !ok2 || math.Abs(now-was) <= l.maxDelta {
+				continue
+			}
+			a := model.Anomaly{Signal: name, Field: l.field, Previous: was, Current: now, MaxDelta: l.maxDelta, Withheld: !flagged[name]}
+			signalAnomalies.WithLabelValues(name).Inc()
+			slog.Warn("data quality: implausible jump, suspected glitch", "signal", name, "field", l.field,
+				"previous", was, "current", now, "withheld", a.Withheld)
+			anomalies = append(anomalies, a)
+		}
+	}
+	sort.SliceStable(anomalies, func(i, j int) bool { return anomalies[i].Signal < anomalies[j].Signal })
+	return anomalies
+}
+
+// withheldSignals returns the signals to leave out of total risk for
+// their anomalies. A signal held by an operator override counts as
+// trusted, as in degradedSignals.
+func withheldSignals(anomalies []model.Anomaly, scores *model.RiskScores) []string {
+	var names []string
+	for _, a := range anomalies {
+		if !a.Withheld || (len(names) > 0 && names[len(names)-1] == a.Signal) {
+			continue
+		}
+		if s := scores.Score(a.Signal); s != nil && s.Override == nil {
+			names = append(names, a.Signal)
+		}
+	}
+	return names
+}
+
+// previouslyWithheld returns the signals the previous snapshot withheld.
+func previouslyWithheld(current map[string]any) map[string]bool {
+	out := map[string]bool{}
+	dq, _ := current["data_quality"].(map[string]any)
+	items, _ := dq["anomalies"].([]any)
+	for _, item := range items {
+		if a, ok := item.(map[string]any); ok && a["withheld"] == true {
+			out[strFromAny(a["signal"])] = true
+		}
+	}
+	return out
+}
+
+func numberFromAny(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
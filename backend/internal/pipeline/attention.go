@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/pulse"
+)
+
+// PulseSource reports live visitor activity for the attention index.
+// *pulse.Tracker implements it.
+type PulseSource interface {
+	GetStats() pulse.Stats
+}
+
+// SetPulse attaches the site's visitor tracker. Runs before it is set score
+// attention on the external sources alone.
+func (p *Pipeline) SetPulse(src PulseSource) {
+	p.mu.Lock()
+	p.pulse = src
+	p.mu.Unlock()
+}
+
+// applyPulse adds the live pulse reading to the attention components,
+// replacing one carried over from a fallback, and returns the updated raw
+// map.
+func (p *Pipeline) applyPulse(data *model.AttentionData, raw map[string]any) map[string]any {
+	p.mu.Lock()
+	src := p.pulse
+	p.mu.Unlock()
+
+	components := make([]model.AttentionComponent, 0, len(data.Components)+1)
+	for _, c := range data.Components {
+		if c.Source != "pulse" {
+			components = append(components, c)
+		}
+	}
+	if src != nil {
+		stats := src.GetStats()
+		components = append(components, model.AttentionComponent{
+			Source: "pulse",
+			Ratio:  stats.ActivityMultiplier,
+			Detail: fmt.Sprintf("%d watching (%s)", stats.WatchingNow, stats.ActivityLevel),
+		})
+	}
+	data.Components = components
+
+	if raw == nil {
+		raw = map[string]any{}
+	}
+	raw["components"] = components
+	return raw
+}
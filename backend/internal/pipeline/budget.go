@@ -0,0 +1,115 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+)
+
+// fetchBudget runs fetches with bounded concurrency until a shared deadline.
+// Each fetch gets a context that is cancelled at the deadline, aborting its
+// in-flight requests; any result it still returns is dropped, and the run
+// carries on as if the fetch had failed.
+type fetchBudget struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	deadline time.Time
+	budget   time.Duration
+	sem      chan struct{}
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	expired bool
+	pending map[*error]string
+}
+
+// newFetchBudget starts a budget of the given length from start. Fetch
+// contexts derive from ctx, so cancelling it stops them too. Close must
+// be called once the budget is no longer needed.
+func newFetchBudget(ctx context.Context, start time.Time, budget time.Duration, concurrency int) *fetchBudget {
+	ctx, cancel := context.WithDeadline(ctx, start.Add(budget))
+	return &fetchBudget{
+		ctx:      ctx,
+		cancel:   cancel,
+		deadline: start.Add(budget),
+		budget:   budget,
+		sem:      make(chan struct{}, concurrency),
+		pending:  make(map[*error]string),
+	}
+}
+
+// Go starts fetch for the named signal. fetch performs the network call
+// under the ctx it is given and returns a func that stores its results;
+// that is only called if the fetch finished within budget. Otherwise *errp
+// is set to a KindBudget error.
+func (b *fetchBudget) Go(name string, errp *error, fetch func(ctx context.Context) (commit func())) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.expired || !time.Now().Before(b.deadline) {
+		*errp = b.exceeded()
+		return
+	}
+	b.pending[errp] = name
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.sem <- struct{}{}
+		defer func() { <-b.sem }()
+
+		// Don't start work the run has already given up on
+		b.mu.Lock()
+		expired := b.expired
+		b.mu.Unlock()
+		if expired {
+			return
+		}
+
+		commit := fetch(b.ctx)
+		b.mu.Lock()
+		if !b.expired {
+			commit()
+			delete(b.pending, errp)
+		}
+		b.mu.Unlock()
+	}()
+}
+
+// Wait blocks until every started fetch has finished or the deadline
+// passes, then marks whatever is still outstanding as over budget.
+func (b *fetchBudget) Wait() {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(time.Until(b.deadline))
+	defer timer.Stop()
+	select {
+	case <-done:
+		return
+	case <-timer.C:
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expired = true
+	b.cancel()
+	for errp, name := range b.pending {
+		slog.Warn("fetch abandoned: run budget exceeded", "signal", name, "budget", b.budget)
+		*errp = b.exceeded()
+	}
+	clear(b.pending)
+}
+
+// Close releases the budget's context.
+func (b *fetchBudget) Close() {
+	b.cancel()
+}
+
+func (b *fetchBudget) exceeded() error {
+	return &fetcher.FetchError{Kind: fetcher.KindBudget, Err: fmt.Errorf("run budget of %s exceeded", b.budget)}
+}
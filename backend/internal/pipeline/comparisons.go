@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// comparisonPeriods are how far back total risk is compared. Tolerance is
+// how far from the mark a stored run may be and still stand for it, wider
+// for longer periods where an hour either way matters less.
+var comparisonPeriods = []struct {
+	name      string
+	ago       time.Duration
+	tolerance time.Duration
+}{
+	{"24h", 24 * time.Hour, 2 * time.Hour},
+	{"7d", 7 * 24 * time.Hour, 6 * time.Hour},
+	{"30d", 30 * 24 * time.Hour, 12 * time.Hour},
+}
+
+// comparisons looks up total risk one period ago, for each period with a
+// stored run near enough. Failures only drop that period.
+func (p *Pipeline) comparisons(ctx context.Context, now time.Time, totalRisk int) []model.RiskComparison {
+	var out []model.RiskComparison
+	for _, period := range comparisonPeriods {
+		mark := now.Add(-period.ago)
+		points, err := p.store.TotalRiskBetween(ctx, mark.Add(-period.tolerance), mark.Add(period.tolerance))
+		if err != nil {
+			slog.Warn("failed to load total risk for comparison", "period", period.name, "error", err)
+			continue
+		}
+		nearest, ok := nearestPoint(points, mark)
+		if !ok {
+			continue
+		}
+		out = append(out, model.RiskComparison{
+			Period:    period.name,
+			Timestamp: nearest.Timestamp,
+			Risk:      nearest.Risk,
+			Delta:     totalRisk - nearest.Risk,
+		})
+	}
+	return out
+}
+
+// nearestPoint returns the point closest in time to mark.
+func nearestPoint(points []model.TotalRiskPoint, mark time.Time) (model.TotalRiskPoint, bool) {
+	var best model.TotalRiskPoint
+	bestDist := int64(-1)
+	for _, pt := range points {
+		dist := pt.Timestamp - mark.UnixMilli()
+		if dist < 0 {
+			dist = -dist
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = pt, dist
+		}
+	}
+	return best, bestDist >= 0
+}
@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// epochSuffix is appended to a key to hold its timestamp as Unix
+// milliseconds, matching total_risk.history timestamps.
+const epochSuffix = "_epoch_ms"
+
+// withEpochs adds a "<key>_epoch_ms" sibling next to every RFC 3339 string
+// in the serialized snapshot, so clients can localize times without parsing
+// them.
+func withEpochs(data []byte) ([]byte, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	addEpochs(doc)
+	return json.Marshal(doc)
+}
+
+func addEpochs(v any) {
+	switch n := v.(type) {
+	case map[string]any:
+		epochs := map[string]int64{}
+		for k, val := range n {
+			if strings.HasSuffix(k, epochSuffix) {
+				continue
+			}
+			if s, ok := val.(string); ok {
+				if t, err := time.Parse(time.RFC3339, s); err == nil {
+					epochs[k+epochSuffix] = t.UnixMilli()
+				}
+				continue
+			}
+			addEpochs(val)
+		}
+		for k, ms := range epochs {
+			n[k] = ms
+		}
+	case []any:
+		for _, item := range n {
+			addEpochs(item)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// recordFeedHealth stores this run's per-feed news results, refreshes the
+// feed health summary and marks the feeds that have been dead for
+// NewsFeedDeadAfter. Dead feeds are left out of the live count, so a feed
+// that has gone away for good stops reading as partial coverage. It runs
+// even when the news fetch failed, since an outage is what it tracks.
+func (p *Pipeline) recordFeedHealth(ctx context.Context, data *model.NewsData, raw map[string]any) {
+	results := data.Feeds.Results
+	if len(results) == 0 {
+		return
+	}
+	now := time.Now()
+	if err := p.store.SaveFeedChecks(ctx, results, now); err != nil {
+		slog.Warn("failed to save feed checks", "error", err)
+	}
+
+	deadAfter := p.cfg.NewsFeedDeadAfter
+	summary, err := p.store.FeedHealth(ctx, now.Add(-2*deadAfter))
+	if err != nil {
+		slog.Warn("failed to load feed health", "error", err)
+		return
+	}
+	dead := make(map[string]bool)
+	for i := range summary {
+		h := &summary[i]
+		h.Status = feedStatus(*h, now, deadAfter)
+		if h.Status == "dead" {
+			dead[h.URL] = true
+		}
+	}
+	p.feedMu.Lock()
+	p.feedHealth = summary
+	p.feedMu.Unlock()
+
+	live := 0
+	for i := range results {
+		if dead[results[i].URL] {
+			results[i].Dead = true
+			slog.Warn("news feed dead", "url", results[i].URL, "dead_after", deadAfter)
+			continue
+		}
+		live++
+	}
+	data.Feeds.Live = live
+	if raw != nil {
+		raw["feeds"] = data.Feeds
+	}
+}
+
+// feedStatus judges a feed from its summary. A feed is dead once its last
+// successful read, or its first check if it never had one, is older than
+// deadAfter.
+func feedStatus(h model.FeedHealth, now time.Time, deadAfter time.Duration) string {
+	switch {
+	case h.LastSuccessAt != nil && h.LastSuccessAt.Equal(h.LastCheckedAt):
+		return "ok"
+	case h.LastSuccessAt == nil && now.Sub(h.FirstCheckedAt) >= deadAfter:
+		return "dead"
+	case h.LastSuccessAt != nil && now.Sub(*h.LastSuccessAt) >= deadAfter:
+		return "dead"
+	default:
+		return "failing"
+	}
+}
+
+// FeedHealth returns the news feed summary from the latest run, or nil
+// before the first one.
+func (p *Pipeline) FeedHealth() []model.FeedHealth {
+	p.feedMu.RLock()
+	defer p.feedMu.RUnlock()
+	return p.feedHealth
+}
@@ -3,31 +3,226 @@ package pipeline
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
+	"slices"
+	"sync"
 	"time"
 
-	"golang.org/x/sync/errgroup"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 
 	"github.com/backyonatan-alt/aegis/backend/internal/cache"
+	"github.com/backyonatan-alt/aegis/backend/internal/calendar"
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
 	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/geo"
+	"github.com/backyonatan-alt/aegis/backend/internal/legacy"
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 	"github.com/backyonatan-alt/aegis/backend/internal/risk"
 	"github.com/backyonatan-alt/aegis/backend/internal/store"
 )
 
+// aircraftBaselineDays is how far back same-hour aircraft counts are averaged.
+const aircraftBaselineDays = 14
+
+// tankerBaselineWeeks is how far back same hour-of-week tanker counts are averaged.
+const tankerBaselineWeeks = 8
+
+// shippingBaselineDays is how far back same-hour Hormuz vessel counts are averaged.
+const shippingBaselineDays = 14
+
+// carrierBaselineDays is how far back same-hour carrier sightings are
+// compared against.
+const carrierBaselineDays = 14
+
+// carrierBaselineMinSamples is how many same-hour runs the carrier baseline
+// needs before a missing carrier counts as avoidance.
+const carrierBaselineMinSamples = 3
+
+// forecastLookbackDays is how much stored total risk history feeds the forecast.
+const forecastLookbackDays = 7
+
+var fetchFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "aegis",
+	Subsystem: "pipeline",
+	Name:      "fetch_failures_total",
+	Help:      "Failed signal fetches by signal and error kind.",
+}, []string{"signal", "kind"})
+
 // Pipeline orchestrates: fetch -> calculate -> store.
 type Pipeline struct {
+	cfg     *config.Config
 	store   store.Store
 	cache   *cache.Cache
-	fetcher *fetcher.Fetcher
+	fetcher fetcher.Interface
+
+	// mu guards activeRun. Only one run may execute at a time so that
+	// scheduled and manual runs never interleave history updates.
+	mu        sync.Mutex
+	activeRun string
+	runSeq    int
+	paused    bool
+	runs      []RunSummary
+	pulse     PulseSource
+	calendar  *calendar.Calendar
+	notifiers []Notifier
+	// stopped is set by Stop, after which no run may begin. running counts
+	// the runs that have, so Stop can wait for them.
+	stopped bool
+	running sync.WaitGroup
+
+	// life is the pipeline's lifetime, cancelled by Stop. Every run ends
+	// with it, including those Trigger starts with no caller to cancel them.
+	life   context.Context
+	cancel context.CancelFunc
+
+	// geoMu guards geoMap, the situation map from the latest run.
+	geoMu  sync.RWMutex
+	geoMap *geo.FeatureCollection
+
+	// feedMu guards feedHealth, the news feed summary from the latest run.
+	feedMu     sync.RWMutex
+	feedHealth []model.FeedHealth
+}
+
+// RunInProgressError is returned when a run is requested while another one
+// is still executing.
+type RunInProgressError struct {
+	RunID string
+}
+
+func (e *RunInProgressError) Error() string {
+	return fmt.Sprintf("run already in progress: %s", e.RunID)
+}
+
+// ErrStopped is returned when a run is requested after Stop.
+var ErrStopped = errors.New("pipeline stopped")
+
+func New(cfg *config.Config, store store.Store, cache *cache.Cache, fetcher fetcher.Interface) *Pipeline {
+	life, cancel := context.WithCancel(context.Background())
+	return &Pipeline{cfg: cfg, store: store, cache: cache, fetcher: fetcher, calendar: calendar.Default(), life: life, cancel: cancel}
+}
+
+// Stop cancels the run in progress, if any, and waits for it to end. Runs
+// requested afterwards fail with ErrStopped.
+func (p *Pipeline) Stop() {
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
+	p.cancel()
+	p.running.Wait()
+}
+
+// SetCalendar replaces the built-in sensitive-date calendar.
+func (p *Pipeline) SetCalendar(c *calendar.Calendar) {
+	p.mu.Lock()
+	p.calendar = c
+	p.mu.Unlock()
+}
+
+// Notifier is told about each snapshot the pipeline caches, so live
+// clients can be pushed the update instead of polling for it.
+type Notifier interface {
+	SnapshotUpdated(data []byte)
 }
 
-func New(store store.Store, cache *cache.Cache, fetcher *fetcher.Fetcher) *Pipeline {
-	return &Pipeline{store: store, cache: cache, fetcher: fetcher}
+// AddNotifier attaches n to receive every newly cached snapshot, after
+// any notifiers added before it.
+func (p *Pipeline) AddNotifier(n Notifier) {
+	p.mu.Lock()
+	p.notifiers = append(p.notifiers, n)
+	p.mu.Unlock()
 }
 
+// Run executes a pipeline run synchronously. It returns a *RunInProgressError
+// if another run is already active.
 func (p *Pipeline) Run(ctx context.Context) error {
-	slog.Info("pipeline run starting")
+	runID, err := p.begin()
+	if err != nil {
+		return err
+	}
+	// Stop ends this run too, not only ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(p.life, cancel)()
+
+	rec := RunSummary{ID: runID, StartedAt: time.Now()}
+	err = p.run(ctx, &rec)
+	p.end(&rec, err)
+	return err
+}
+
+// Map returns the situation map built by the latest run, or nil before the
+// first run completes.
+func (p *Pipeline) Map() *geo.FeatureCollection {
+	p.geoMu.RLock()
+	defer p.geoMu.RUnlock()
+	return p.geoMap
+}
+
+// Trigger starts a pipeline run in the background and returns its run ID.
+// It returns a *RunInProgressError if another run is already active. The
+// run is bound to the pipeline's lifetime, so Stop cancels it.
+func (p *Pipeline) Trigger() (string, error) {
+	runID, err := p.begin()
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		rec := RunSummary{ID: runID, StartedAt: time.Now()}
+		err := p.run(p.life, &rec)
+		p.end(&rec, err)
+		if err != nil {
+			slog.Error("triggered pipeline run failed", "run_id", runID, "error", err)
+		}
+	}()
+	return runID, nil
+}
+
+// ActiveRun returns the ID of the currently executing run, or "" if idle.
+func (p *Pipeline) ActiveRun() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.activeRun
+}
+
+func (p *Pipeline) begin() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return "", ErrStopped
+	}
+	if p.activeRun != "" {
+		return "", &RunInProgressError{RunID: p.activeRun}
+	}
+	p.running.Add(1)
+	p.runSeq++
+	p.activeRun = fmt.Sprintf("%s-%d", time.Now().UTC().Format("20060102T150405"), p.runSeq)
+	return p.activeRun, nil
+}
+
+// end clears the active run and records its outcome.
+func (p *Pipeline) end(rec *RunSummary, err error) {
+	rec.Duration = time.Since(rec.StartedAt).Round(time.Millisecond)
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	p.mu.Lock()
+	p.activeRun = ""
+	p.runs = append(p.runs, *rec)
+	if len(p.runs) > maxRunSummaries {
+		p.runs = p.runs[len(p.runs)-maxRunSummaries:]
+	}
+	p.mu.Unlock()
+	p.running.Done()
+}
+
+func (p *Pipeline) run(ctx context.Context, rec *RunSummary) error {
+	runID := rec.ID
+	slog.Info("pipeline run starting", "run_id", runID)
 
 	// 1. Load previous snapshot from DB (for history continuity)
 	var currentData map[string]any
@@ -38,222 +233,327 @@ func (p *Pipeline) Run(ctx context.Context) error {
 		if err := json.Unmarshal(prevBytes, &currentData); err != nil {
 			slog.Warn("failed to parse previous snapshot", "error", err)
 		} else {
+			if legacy.Convert(currentData) {
+				slog.Info("converted legacy-format previous snapshot")
+			}
 			slog.Info("loaded previous snapshot", "bytes", len(prevBytes))
 		}
 	}
 
-	// 2. Fetch 5 APIs concurrently
-	var (
-		polyData     model.PolymarketData
-		polyRaw      map[string]any
-		polyErr      error
-		newsData     model.NewsData
-		newsRaw      map[string]any
-		newsErr      error
-		aviationData model.AviationData
-		aviationRaw  map[string]any
-		aviationErr  error
-		weatherData  model.WeatherData
-		weatherRaw   map[string]any
-		weatherErr   error
-		connData     model.ConnectivityData
-		connRaw      map[string]any
-		connErr      error
-	)
-
-	g, _ := errgroup.WithContext(ctx)
-
-	g.Go(func() error {
-		polyData, polyRaw, polyErr = p.fetcher.FetchPolymarket()
-		return nil // don't fail the group
-	})
-	g.Go(func() error {
-		newsData, newsRaw, newsErr = p.fetcher.FetchNews()
-		return nil
-	})
-	g.Go(func() error {
-		aviationData, aviationRaw, aviationErr = p.fetcher.FetchAviation()
-		return nil
-	})
-	g.Go(func() error {
-		weatherData, weatherRaw, weatherErr = p.fetcher.FetchWeather()
-		return nil
-	})
-	g.Go(func() error {
-		connData, connRaw, connErr = p.fetcher.FetchConnectivity()
-		return nil
-	})
-
-	_ = g.Wait()
-
-	// Log errors
-	for name, err := range map[string]error{
-		"polymarket": polyErr, "news": newsErr, "aviation": aviationErr,
-		"weather": weatherErr, "connectivity": connErr,
-	} {
-		if err != nil {
-			slog.Error("fetch failed", "signal", name, "error", err)
+	// 2. Fetch every signal stage by stage, all within one time budget
+	signals := p.fetcher.Signals()
+	results := make(signalResults, len(signals))
+	budget := newFetchBudget(ctx, rec.StartedAt, p.cfg.RunBudget, p.cfg.FetchConcurrency)
+	defer budget.Close()
+	for _, stage := range fetchStages(signals) {
+		for _, sig := range stage {
+			r := &signalResult{}
+			results[sig.Name()] = r
+			budget.Go(sig.Name(), &r.err, func(ctx context.Context) func() {
+				d, raw, err := sig.Fetch(ctx)
+				return func() { r.data, r.raw, r.err = d, raw, err }
+			})
 		}
-	}
-
-	// 3. Wait 2 seconds for OpenSky rate limit, then fetch tanker
-	slog.Info("waiting 2s for OpenSky rate limit")
-	time.Sleep(2 * time.Second)
-
-	tankerData, tankerRaw, tankerErr := p.fetcher.FetchTanker()
-	if tankerErr != nil {
-		slog.Error("fetch failed", "signal", "tanker", "error", tankerErr)
-	}
-
-	// 4. Compute pentagon (no API)
-	pentagonData, pentagonRaw := p.fetcher.FetchPentagon()
-
-	// 5. Fallback: use previous snapshot raw_data for failed fetches
-	if polyErr != nil && currentData != nil {
-		if sig, ok := currentData["polymarket"].(map[string]any); ok {
-			if rd, ok := sig["raw_data"].(map[string]any); ok {
-				polyRaw = rd
-				polyData = extractPolymarket(rd)
+		budget.Wait()
+		for _, sig := range stage {
+			r := results[sig.Name()]
+			if r.err != nil {
+				slog.Error("fetch failed", "signal", sig.Name(), "kind", fetcher.Classify(r.err), "error", r.err)
 			}
+			p.afterFetch(ctx, r)
+			checkRawData(sig, r)
 		}
 	}
-	if newsErr != nil && currentData != nil {
-		if sig, ok := currentData["news"].(map[string]any); ok {
-			if rd, ok := sig["raw_data"].(map[string]any); ok {
-				newsRaw = rd
-				newsData = extractNews(rd)
-			}
+
+	// 3. Fallback: use previous snapshot raw_data for failed fetches, if it
+	// still matches the schema
+	for _, sig := range signals {
+		r := results[sig.Name()]
+		if r.err == nil || currentData == nil {
+			continue
 		}
-	}
-	if aviationErr != nil && currentData != nil {
-		if sig, ok := currentData["flight"].(map[string]any); ok {
-			if rd, ok := sig["raw_data"].(map[string]any); ok {
-				aviationRaw = rd
-				aviationData = extractAviation(rd)
-			}
+		if rd := previousRawData(currentData, sig.Name()); rd != nil && sig.Check(rd) == nil {
+			r.raw = rd
+			r.data = sig.Restore(rd)
 		}
 	}
-	if weatherErr != nil && currentData != nil {
-		if sig, ok := currentData["weather"].(map[string]any); ok {
-			if rd, ok := sig["raw_data"].(map[string]any); ok {
-				weatherRaw = rd
-				weatherData = extractWeather(rd)
-			}
-		}
+	if r := results["attention"]; r != nil {
+		attention, _ := r.data.(model.AttentionData)
+		r.raw = p.applyPulse(&attention, r.raw)
+		r.data = attention
 	}
-	if connErr != nil && currentData != nil {
-		if sig, ok := currentData["connectivity"].(map[string]any); ok {
-			if rd, ok := sig["raw_data"].(map[string]any); ok {
-				connRaw = rd
-				connData = extractConnectivity(rd)
-			}
-		}
+	aviation := dataOf[model.AviationData](results, "flight")
+	tanker := dataOf[model.TankerData](results, "tanker")
+	news := dataOf[model.NewsData](results, "news")
+
+	if p.cfg.Tracks.Enabled {
+		p.saveTracks(ctx, aviation.Positions, tanker.Positions)
 	}
-	if tankerErr != nil && currentData != nil {
-		if sig, ok := currentData["tanker"].(map[string]any); ok {
-			if rd, ok := sig["raw_data"].(map[string]any); ok {
-				tankerRaw = rd
-				tankerData = extractTanker(rd)
-			}
-		}
+
+	// Live positions are never carried over from fallbacks, so the map only
+	// shows aircraft seen in this run.
+	geoMap := geo.Build(aviation, tanker, dataOf[model.ConnectivityData](results, "connectivity"), news)
+	p.geoMu.Lock()
+	p.geoMap = &geoMap
+	p.geoMu.Unlock()
+
+	// 4. Calculate risk scores
+	signalScores := make([]model.NamedSignalScore, len(signals))
+	for i, sig := range signals {
+		signalScores[i] = sig.Score(results[sig.Name()].data, p.cfg)
+	}
+	scores := risk.Calculate(signalScores, p.cfg.Weights)
+
+	// Operator holds stand in for computed scores until they expire
+	if overrides, err := p.store.SignalOverrides(ctx, time.Now()); err != nil {
+		slog.Warn("failed to load signal overrides", "error", err)
+	} else {
+		risk.ApplyOverrides(&scores, overrides, p.cfg.Weights)
+	}
+
+	fetchErrs := results.errs()
+	quality := dataQuality(time.Now(), p.cfg.RunInterval, currentData, fetchErrs)
+	quality.Upstreams = p.fetcher.Breakers()
+	quality.Anomalies = detectAnomalies(results, currentData)
+
+	// Too many fallbacks and the total is scored from fresh signals only;
+	// a suspected glitch is left out of it for the one run
+	excluded := degradedSignals(quality, &scores, p.cfg.DegradedAfter)
+	withheld := withheldSignals(quality.Anomalies, &scores)
+	if !risk.Degrade(&scores, append(withheld, excluded...), p.cfg.Weights) {
+		excluded = nil
 	}
 
-	// 6. Calculate risk scores
-	scores := risk.Calculate(newsData, connData, aviationData, tankerData, weatherData, polyData, pentagonData)
+	// Scheduled modifier for nearby sensitive dates, on top of the signals
+	p.mu.Lock()
+	cal := p.calendar
+	p.mu.Unlock()
+	sensitiveDates, calendarModifier := cal.Upcoming(time.Now())
+	if calendarModifier > 0 {
+		slog.Info("risk: calendar modifier", "points", calendarModifier)
+		scores.TotalRisk = min(100, scores.TotalRisk+calendarModifier)
+	}
 
-	// 7. Update signal histories and build final snapshot
-	rawResults := model.RawResults{
-		News:         newsRaw,
-		Connectivity: connRaw,
-		Flight:       aviationRaw,
-		Tanker:       tankerRaw,
-		Weather:      weatherRaw,
-		Polymarket:   polyRaw,
-		Pentagon:     pentagonRaw,
+	// 5. Update signal histories and build final snapshot
+	rawResults := make(model.RawResults, len(results))
+	for name, r := range results {
+		rawResults[name] = r.raw
 	}
+	scores.Uncertainty = risk.Uncertainty(p.cfg.Weights, quality.Signals)
 	snapshot := risk.UpdateHistory(currentData, scores, rawResults)
+	snapshot.TotalRisk.Forecast = p.forecast(ctx, scores.TotalRisk)
+	snapshot.TotalRisk.Comparisons = p.comparisons(ctx, time.Now(), scores.TotalRisk)
+	snapshot.TotalRisk.CalendarModifier = calendarModifier
+	snapshot.TotalRisk.Degraded = excluded != nil
+	snapshot.TotalRisk.ExcludedSignals = excluded
+	snapshot.SensitiveDates = sensitiveDates
+	snapshot.DataQuality = quality
+	markStaleness(&snapshot, quality, currentData, time.Now())
+	snapshot.Meta = p.cfg.SnapshotMeta
+	rec.TotalRisk = scores.TotalRisk
+	rec.FetchErrors = make(map[string]FetchFailure)
+	for name, err := range fetchErrs {
+		if err != nil {
+			kind := fetcher.Classify(err)
+			fetchFailures.WithLabelValues(name, string(kind)).Inc()
+			rec.FetchErrors[name] = FetchFailure{Kind: kind, Error: err.Error()}
+		}
+	}
 
-	// 8. Serialize
+	// 6. Serialize
 	data, err := json.Marshal(snapshot)
+	if err == nil {
+		data, err = withEpochs(data)
+	}
 	if err != nil {
 		slog.Error("failed to serialize snapshot", "error", err)
 		return err
 	}
 
-	// 9. Write to DB
+	// 7. Write to DB
 	if err := p.store.SaveSnapshot(ctx, data); err != nil {
 		slog.Error("failed to save snapshot to DB", "error", err)
 		return err
 	}
 
-	// 10. Update in-memory cache
+	// Per-signal rows for alerting and analytics (non-fatal)
+	if err := p.store.SaveSignalScores(ctx, runID, scores.Signals); err != nil {
+		slog.Warn("failed to save signal scores", "error", err)
+	}
+
+	// Several signals spiking together is kept for post-hoc review (non-fatal)
+	if inc, ok := risk.DetectIncident(runID, time.Now().UTC(), snapshot.ChangesSinceLast, news, scores.TotalRisk); ok {
+		slog.Info("incident detected", "run_id", runID, "signals", len(inc.Signals))
+		if err := p.store.SaveIncident(ctx, inc); err != nil {
+			slog.Warn("failed to save incident", "error", err)
+		}
+	}
+
+	// 8. Update in-memory cache
 	p.cache.Set(data)
+	p.mu.Lock()
+	notifiers := p.notifiers
+	p.mu.Unlock()
+	for _, n := range notifiers {
+		n.SnapshotUpdated(data)
+	}
 
-	slog.Info("pipeline run complete", "total_risk", scores.TotalRisk, "bytes", len(data))
+	slog.Info("pipeline run complete", "run_id", runID, "total_risk", scores.TotalRisk, "bytes", len(data))
 	return nil
 }
 
-// Extraction helpers: convert raw_data maps back to typed structs for risk calculation fallbacks.
+// applyAircraftBaseline persists the current aircraft count and annotates the
+// aviation data with the same-hour historical baseline used for scoring.
+func (p *Pipeline) applyAircraftBaseline(ctx context.Context, data *model.AviationData, raw map[string]any) {
+	now := time.Now().UTC()
+	if err := p.store.SaveAircraftCount(ctx, data.AircraftCount, now); err != nil {
+		slog.Warn("failed to save aircraft count", "error", err)
+	}
+
+	baseline, samples, err := p.store.AircraftBaseline(ctx, now.Hour(), aircraftBaselineDays)
+	if err != nil {
+		slog.Warn("failed to load aircraft baseline", "error", err)
+		return
+	}
+	slog.Info("aircraft baseline", "hour", now.Hour(), "baseline", baseline, "samples", samples)
 
-func extractPolymarket(m map[string]any) model.PolymarketData {
-	return model.PolymarketData{
-		Odds:      intFromAny(m["odds"]),
-		Market:    strFromAny(m["market"]),
-		Timestamp: strFromAny(m["timestamp"]),
+	data.Baseline = math.Round(baseline*10) / 10
+	data.BaselineSamples = samples
+	if raw != nil {
+		raw["baseline"] = data.Baseline
+		raw["baseline_samples"] = samples
 	}
 }
 
-func extractNews(m map[string]any) model.NewsData {
-	return model.NewsData{
-		TotalCount: intFromAny(m["total_count"]),
-		AlertCount: intFromAny(m["alert_count"]),
-		Timestamp:  strFromAny(m["timestamp"]),
+// applyTankerBaseline persists the current tanker count and annotates the
+// tanker data with the same hour-of-week baseline used for scoring.
+func (p *Pipeline) applyTankerBaseline(ctx context.Context, data *model.TankerData, raw map[string]any) {
+	now := time.Now().UTC()
+	if err := p.store.SaveTankerCount(ctx, data.TankerCount, now); err != nil {
+		slog.Warn("failed to save tanker count", "error", err)
+	}
+
+	baseline, samples, err := p.store.TankerBaseline(ctx, now.Hour(), now.Weekday(), tankerBaselineWeeks)
+	if err != nil {
+		slog.Warn("failed to load tanker baseline", "error", err)
+		return
+	}
+	slog.Info("tanker baseline", "hour", now.Hour(), "weekday", now.Weekday(), "baseline", baseline, "samples", samples)
+
+	data.Baseline = math.Round(baseline*10) / 10
+	data.BaselineSamples = samples
+	if raw != nil {
+		raw["baseline"] = data.Baseline
+		raw["baseline_samples"] = samples
 	}
 }
 
-func extractAviation(m map[string]any) model.AviationData {
-	return model.AviationData{
-		AircraftCount: intFromAny(m["aircraft_count"]),
-		AirlineCount:  intFromAny(m["airline_count"]),
-		Timestamp:     strFromAny(m["timestamp"]),
+// applyShippingBaseline persists the current Hormuz vessel counts and
+// annotates the shipping data with their same-hour baselines.
+func (p *Pipeline) applyShippingBaseline(ctx context.Context, data *model.ShippingData, raw map[string]any) {
+	now := time.Now().UTC()
+	if err := p.store.SaveVesselCounts(ctx, data.VesselCount, data.TankerCount, now); err != nil {
+		slog.Warn("failed to save vessel counts", "error", err)
+	}
+
+	vessels, tankers, samples, err := p.store.VesselBaseline(ctx, now.Hour(), shippingBaselineDays)
+	if err != nil {
+		slog.Warn("failed to load vessel baseline", "error", err)
+		return
+	}
+	slog.Info("vessel baseline", "hour", now.Hour(), "baseline", vessels, "tanker_baseline", tankers, "samples", samples)
+
+	data.Baseline = math.Round(vessels*10) / 10
+	data.TankerBaseline = math.Round(tankers*10) / 10
+	data.BaselineSamples = samples
+	if raw != nil {
+		raw["baseline"] = data.Baseline
+		raw["tanker_baseline"] = data.TankerBaseline
+		raw["baseline_samples"] = samples
 	}
 }
 
-func extractWeather(m map[string]any) model.WeatherData {
-	return model.WeatherData{
-		Temp:        intFromAny(m["temp"]),
-		Visibility:  intFromAny(m["visibility"]),
-		Clouds:      intFromAny(m["clouds"]),
-		Description: strFromAny(m["description"]),
-		Condition:   strFromAny(m["condition"]),
-		Timestamp:   strFromAny(m["timestamp"]),
+// saveTracks persists this run's positions and drops those past the
+// retention window. Failures only cost replay data.
+func (p *Pipeline) saveTracks(ctx context.Context, aircraft, tankers []model.Position) {
+	now := time.Now()
+	for kind, positions := range map[string][]model.Position{"aircraft": aircraft, "tanker": tankers} {
+		if len(positions) == 0 {
+			continue
+		}
+		if err := p.store.SaveTrackPoints(ctx, kind, positions, now); err != nil {
+			slog.Warn("failed to save track points", "kind", kind, "error", err)
+		}
+	}
+	pruned, err := p.store.PruneTrackPoints(ctx, now.Add(-p.cfg.Tracks.Retention))
+	if err != nil {
+		slog.Warn("failed to prune track points", "error", err)
+	} else if pruned > 0 {
+		slog.Info("pruned track points", "rows", pruned)
 	}
 }
 
-func extractConnectivity(m map[string]any) model.ConnectivityData {
-	return model.ConnectivityData{
-		Status:    strFromAny(m["status"]),
-		Risk:      floatFromAny(m["risk"]),
-		Trend:     floatFromAny(m["trend"]),
-		Timestamp: strFromAny(m["timestamp"]),
+// forecast projects total risk from stored runs plus the current score.
+// Failures only drop the forecast from this snapshot.
+func (p *Pipeline) forecast(ctx context.Context, totalRisk int) []model.ForecastPoint {
+	now := time.Now()
+	series, err := p.store.TotalRiskSeries(ctx, now.AddDate(0, 0, -forecastLookbackDays))
+	if err != nil {
+		slog.Warn("failed to load total risk series", "error", err)
+		return nil
 	}
+	series = append(series, model.TotalRiskPoint{Timestamp: now.UnixMilli(), Risk: totalRisk})
+	return risk.Forecast(series)
 }
 
-func extractTanker(m map[string]any) model.TankerData {
-	return model.TankerData{
-		TankerCount: intFromAny(m["tanker_count"]),
-		Timestamp:   strFromAny(m["timestamp"]),
+// applyCarrierAvoidance persists the major carriers seen this run and
+// records those that usually cross Iranian airspace at this hour but were
+// not seen.
+func (p *Pipeline) applyCarrierAvoidance(ctx context.Context, data *model.AviationData, raw map[string]any) {
+	now := time.Now().UTC()
+	if err := p.store.SaveCarrierSightings(ctx, data.MajorCarriers, now); err != nil {
+		slog.Warn("failed to save carrier sightings", "error", err)
+	}
+
+	sightings, err := p.store.CarrierSightings(ctx, now.Hour(), carrierBaselineDays)
+	if err != nil {
+		slog.Warn("failed to load carrier baseline", "error", err)
+		return
+	}
+	missing := missingCarriers(data.MajorCarriers, sightings)
+	if len(missing) == 0 {
+		return
+	}
+
+	slog.Info("major carriers missing from airspace", "carriers", missing, "samples", len(sightings))
+	data.MissingCarriers = missing
+	if raw != nil {
+		raw["missing_carriers"] = missing
 	}
 }
 
-func intFromAny(v any) int {
-	switch n := v.(type) {
-	case float64:
-		return int(n)
-	case int:
-		return n
+// missingCarriers returns the carriers seen in at least half of the
+// baseline runs that are absent from seen, sorted. A carrier skipping one
+// run is noise, so nothing is missing until there are
+// carrierBaselineMinSamples runs to compare against.
+func missingCarriers(seen []string, sightings [][]string) []string {
+	if len(sightings) < carrierBaselineMinSamples {
+		return nil
 	}
-	return 0
+	runs := make(map[string]int)
+	for _, carriers := range sightings {
+		for _, code := range carriers {
+			runs[code]++
+		}
+	}
+	var missing []string
+	for code, n := range runs {
+		if 2*n >= len(sightings) && !slices.Contains(seen, code) {
+			missing = append(missing, code)
+		}
+	}
+	slices.Sort(missing)
+	return missing
 }
 
 func strFromAny(v any) string {
@@ -262,13 +562,3 @@ func strFromAny(v any) string {
 	}
 	return ""
 }
-
-func floatFromAny(v any) float64 {
-	switch n := v.(type) {
-	case float64:
-		return n
-	case int:
-		return float64(n)
-	}
-	return 0
-}
@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// staleAfter is the age at which a fallback signal stops contributing to
+// the data-quality score.
+const staleAfter = 6 * time.Hour
+
+// signalSourceLag is how far behind real time a signal's upstream data runs
+// even when freshly fetched. Cloudflare Radar publishes hourly aggregates.
+var signalSourceLag = map[string]time.Duration{
+	"connectivity": time.Hour,
+}
+
+// expectedMaxAge is the oldest a signal's data should be while the pipeline
+// runs on schedule: one run interval plus the upstream's own lag.
+func expectedMaxAge(name string, interval time.Duration) int64 {
+	return int64((interval + signalSourceLag[name]).Seconds())
+}
+
+// dataQuality scores the provenance of each signal in this run. fetchErrs is
+// keyed by snapshot signal name; a nil error means the signal was fetched
+// fresh. Fallback ages are tracked through the previous snapshot's
+// data_quality so repeated failures keep ageing rather than resetting.
+// interval is the pipeline schedule that freshness expectations derive from.
+func dataQuality(now time.Time, interval time.Duration, current map[string]any, fetchErrs map[string]error) *model.DataQuality {
+	prevSignals := previousFetchTimes(current)
+
+	q := &model.DataQuality{
+		Total:   len(fetchErrs),
+		Signals: make(map[string]model.SignalQuality, len(fetchErrs)),
+	}
+	var scoreSum float64
+	var ageSum int64
+	for name, err := range fetchErrs {
+		sq := model.SignalQuality{Status: "ok", FetchedAt: now.Format(time.RFC3339)}
+		if err != nil {
+			fetchedAt, ok := prevSignals[name]
+			if !ok {
+				sq = model.SignalQuality{Status: "missing"}
+			} else {
+				age := now.Sub(fetchedAt)
+				sq = model.SignalQuality{
+					Status:     "fallback",
+					FetchedAt:  fetchedAt.Format(time.RFC3339),
+					AgeSeconds: int64(age.Seconds()),
+				}
+				sq.Confidence = math.Max(0, 1-float64(age)/float64(staleAfter))
+				scoreSum += sq.Confidence
+			}
+			sq.ErrorKind = string(fetcher.Classify(err))
+		} else {
+			q.Fresh++
+			sq.Confidence = 1
+			scoreSum++
+		}
+		sq.ExpectedMaxAgeSeconds = expectedMaxAge(name, interval)
+		ageSum += sq.AgeSeconds
+		q.Signals[name] = sq
+	}
+
+	if q.Total > 0 {
+		q.Score = math.Round(scoreSum/float64(q.Total)*100) / 100
+		q.AverageAgeSeconds = ageSum / int64(q.Total)
+	}
+	return q
+}
+
+// previousFetchTimes returns when each signal in the previous snapshot was
+// last fetched. Snapshots written before data_quality existed fall back to
+// their last_updated time for every signal present.
+func previousFetchTimes(current map[string]any) map[string]time.Time {
+	times := map[string]time.Time{}
+	if current == nil {
+		return times
+	}
+
+	if dq, ok := current["data_quality"].(map[string]any); ok {
+		if signals, ok := dq["signals"].(map[string]any); ok {
+			for name, v := range signals {
+				sq, ok := v.(map[string]any)
+				if !ok {
+					continue
+				}
+				if t, err := time.Parse(time.RFC3339, strFromAny(sq["fetched_at"])); err == nil {
+					times[name] = t
+				}
+			}
+			return times
+		}
+	}
+
+	lastUpdated, err := time.Parse(time.RFC3339, strFromAny(current["last_updated"]))
+	if err != nil {
+		return times
+	}
+	for name, v := range current {
+		if sig, ok := v.(map[string]any); ok {
+			if _, ok := sig["raw_data"]; ok {
+				times[name] = lastUpdated
+			}
+		}
+	}
+	return times
+}
+
+// markStaleness copies each signal's provenance from quality onto the
+// signal itself. A signal that was already stale in the previous snapshot
+// keeps its stale_since, so it records when the outage began.
+func markStaleness(snap *model.Snapshot, quality *model.DataQuality, current map[string]any, now time.Time) {
+	for name, sq := range quality.Signals {
+		sig := snap.Signal(name)
+		if sig == nil {
+			continue
+		}
+		sig.FetchedAt = sq.FetchedAt
+		if sq.Status == "ok" {
+			continue
+		}
+		sig.IsStale = true
+		sig.StaleSince = now.Format(time.RFC3339)
+		if prev, ok := current[name].(map[string]any); ok && prev["is_stale"] == true {
+			if since := strFromAny(prev["stale_since"]); since != "" {
+				sig.StaleSince = since
+			}
+		}
+	}
+}
+
+// degradedSignals returns the stale signals to leave out of total risk, in
+// name order, or nil while no more than limit are stale. A signal held by
+// an operator override counts as healthy whatever its fetch did.
+func degradedSignals(quality *model.DataQuality, scores *model.RiskScores, limit int) []string {
+	var stale []string
+	for name, sq := range quality.Signals {
+		if sq.Status == "ok" {
+			continue
+		}
+		if s := scores.Score(name); s != nil && s.Override == nil {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) <= limit {
+		return nil
+	}
+	sort.Strings(stale)
+	return stale
+}
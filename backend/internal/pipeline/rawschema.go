@@ -0,0 +1,47 @@
+package pipeline
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+)
+
+var rawSchemaViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "aegis",
+	Subsystem: "pipeline",
+	Name:      "raw_data_schema_violations_total",
+	Help:      "Signal raw_data maps rejected for not matching their published schema, by signal.",
+}, []string{"signal"})
+
+// checkRawData validates a fetched signal's raw_data against its schema.
+// A map that fails is treated as a failed fetch: the run falls back to the
+// previous snapshot's raw_data for the signal and scores that, so the
+// stored score and raw_data agree, and the signal is marked stale.
+func checkRawData(sig fetcher.Signal, r *signalResult) {
+	err := sig.Check(r.raw)
+	if err == nil {
+		return
+	}
+	name := sig.Name()
+	rawSchemaViolations.WithLabelValues(name).Inc()
+	slog.Error("raw_data does not match its schema, falling back", "signal", name, "error", err)
+	if r.err == nil {
+		r.err = &fetcher.FetchError{Kind: fetcher.KindParse, Err: fmt.Errorf("raw_data does not match its schema: %w", err)}
+	}
+	r.data, r.raw = nil, nil
+}
+
+// previousRawData returns a signal's raw_data from the previous snapshot,
+// or nil if it had none.
+func previousRawData(current map[string]any, name string) map[string]any {
+	sig, ok := current[name].(map[string]any)
+	if !ok {
+		return nil
+	}
+	rd, _ := sig["raw_data"].(map[string]any)
+	return rd
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"slices"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+)
+
+// maxRunSummaries is how many recent runs are kept for operators.
+const maxRunSummaries = 20
+
+// RunSummary is the outcome of one pipeline run.
+type RunSummary struct {
+	ID        string        `json:"id"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	TotalRisk int           `json:"total_risk"`
+	// FetchErrors maps each signal whose fetch failed to why.
+	FetchErrors map[string]FetchFailure `json:"fetch_errors,omitempty"`
+	// Error is set when the run failed before storing a snapshot.
+	Error string `json:"error,omitempty"`
+}
+
+// FetchFailure is one signal's failed fetch.
+type FetchFailure struct {
+	Kind  fetcher.ErrorKind `json:"kind"`
+	Error string            `json:"error"`
+}
+
+// Runs returns summaries of recent runs, newest first.
+func (p *Pipeline) Runs() []RunSummary {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	runs := slices.Clone(p.runs)
+	slices.Reverse(runs)
+	return runs
+}
+
+// Pause stops scheduled runs until Resume is called. Manual triggers still
+// run while paused.
+func (p *Pipeline) Pause() {
+	p.mu.Lock()
+	p.paused = true
+	p.mu.Unlock()
+}
+
+// Resume re-enables scheduled runs.
+func (p *Pipeline) Resume() {
+	p.mu.Lock()
+	p.paused = false
+	p.mu.Unlock()
+}
+
+// Paused reports whether scheduled runs are paused.
+func (p *Pipeline) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
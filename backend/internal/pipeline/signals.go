@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"context"
+	"sort"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// signalResult is one signal's fetch outcome within a run.
+type signalResult struct {
+	data any
+	raw  map[string]any
+	err  error
+}
+
+// signalResults holds a run's results by signal name.
+type signalResults map[string]*signalResult
+
+// dataOf returns the data of the signal called name, or T's zero value if
+// the signal has none of that type.
+func dataOf[T any](rs signalResults, name string) T {
+	var d T
+	if r := rs[name]; r != nil {
+		d, _ = r.data.(T)
+	}
+	return d
+}
+
+func (rs signalResults) errs() map[string]error {
+	out := make(map[string]error, len(rs))
+	for name, r := range rs {
+		out[name] = r.err
+	}
+	return out
+}
+
+// fetchStages groups signals by stage, lowest first, keeping registry order
+// within a stage.
+func fetchStages(signals []fetcher.Signal) [][]fetcher.Signal {
+	byStage := make(map[int][]fetcher.Signal)
+	var stages []int
+	for _, sig := range signals {
+		if _, ok := byStage[sig.Stage()]; !ok {
+			stages = append(stages, sig.Stage())
+		}
+		byStage[sig.Stage()] = append(byStage[sig.Stage()], sig)
+	}
+	sort.Ints(stages)
+	out := make([][]fetcher.Signal, 0, len(stages))
+	for _, s := range stages {
+		out = append(out, byStage[s])
+	}
+	return out
+}
+
+// afterFetch applies the history-backed adjustments some signals get once
+// fetched, before any fallback. Feed health is recorded even for a failed
+// news fetch; the baselines only take fresh counts.
+func (p *Pipeline) afterFetch(ctx context.Context, r *signalResult) {
+	switch d := r.data.(type) {
+	case model.NewsData:
+		p.recordFeedHealth(ctx, &d, r.raw)
+		r.data = d
+	case model.AviationData:
+		if r.err == nil {
+			p.applyAircraftBaseline(ctx, &d, r.raw)
+			p.applyCarrierAvoidance(ctx, &d, r.raw)
+			r.data = d
+		}
+	case model.TankerData:
+		if r.err == nil {
+			p.applyTankerBaseline(ctx, &d, r.raw)
+			r.data = d
+		}
+	case model.ShippingData:
+		if r.err == nil {
+			p.applyShippingBaseline(ctx, &d, r.raw)
+			r.data = d
+		}
+	}
+}
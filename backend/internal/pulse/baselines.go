@@ -0,0 +1,128 @@
+package pulse
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// Baselines are the expected visitors per 10-minute window: by country,
+// and in total for the activity multiplier.
+type Baselines struct {
+	Countries map[string]int `json:"countries"`
+	BaseTotal int            `json:"base_total"`
+}
+
+// maxBaseline bounds each configured baseline.
+const maxBaseline = 1_000_000
+
+// Validate checks that every country is a two-letter uppercase code and
+// every baseline is between 1 and 1,000,000.
+func (b Baselines) Validate() error {
+	if b.BaseTotal < 1 || b.BaseTotal > maxBaseline {
+		return fmt.Errorf("base_total must be 1-%d", maxBaseline)
+	}
+	for cc, n := range b.Countries {
+		if normalizeCountry(cc) != cc || cc == "XX" {
+			return fmt.Errorf("%q is not a two-letter country code", cc)
+		}
+		if n < 1 || n > maxBaseline {
+			return fmt.Errorf("baseline for %s must be 1-%d", cc, maxBaseline)
+		}
+	}
+	return nil
+}
+
+// BaselineReport compares configured baselines with the mean visitors per
+// window actually recorded since Since.
+type BaselineReport struct {
+	Since     time.Time          `json:"since"`
+	Windows   int                `json:"windows"`
+	BaseTotal BaselineComparison `json:"base_total"`
+	Countries []CountryBaseline  `json:"countries"`
+}
+
+// BaselineComparison is one configured baseline against its observed mean.
+// Suggested is the observed mean rounded, at least 1; Ratio is observed
+// over configured, so above 1 means the baseline is set too low.
+type BaselineComparison struct {
+	Configured int     `json:"configured"`
+	Observed   float64 `json:"observed"`
+	Suggested  int     `json:"suggested"`
+	Ratio      float64 `json:"ratio"`
+}
+
+// CountryBaseline is one country's comparison. Fallback marks a country
+// with no configured baseline, compared against the fallback instead.
+type CountryBaseline struct {
+	CC       string `json:"cc"`
+	Flag     string `json:"flag"`
+	Fallback bool   `json:"fallback,omitempty"`
+	BaselineComparison
+}
+
+// Observe builds a report from the windows recorded since since, covering
+// every country that was configured or seen, busiest first.
+func Observe(since time.Time, windows []model.PulseWindow, b Baselines) BaselineReport {
+	totals := make(map[string]int)
+	total := 0
+	for _, w := range windows {
+		for cc, n := range w.Counts {
+			totals[cc] += n
+			total += n
+		}
+	}
+	for cc := range b.Countries {
+		if _, ok := totals[cc]; !ok {
+			totals[cc] = 0
+		}
+	}
+
+	mean := func(n int) float64 {
+		if len(windows) == 0 {
+			return 0
+		}
+		return float64(n) / float64(len(windows))
+	}
+	report := BaselineReport{
+		Since:     since,
+		Windows:   len(windows),
+		BaseTotal: compare(b.BaseTotal, mean(total)),
+		Countries: make([]CountryBaseline, 0, len(totals)),
+	}
+	for cc, n := range totals {
+		configured, ok := b.Countries[cc]
+		if !ok {
+			configured = fallbackBaseline
+		}
+		report.Countries = append(report.Countries, CountryBaseline{
+			CC:                 cc,
+			Flag:               getFlag(cc),
+			Fallback:           !ok,
+			BaselineComparison: compare(configured, mean(n)),
+		})
+	}
+	sort.Slice(report.Countries, func(i, j int) bool {
+		a, b := report.Countries[i], report.Countries[j]
+		if a.Observed != b.Observed {
+			return a.Observed > b.Observed
+		}
+		return a.CC < b.CC
+	})
+	return report
+}
+
+func compare(configured int, observed float64) BaselineComparison {
+	c := BaselineComparison{
+		Configured: configured,
+		Observed:   math.Round(observed*100) / 100,
+		Suggested:  max(1, int(math.Round(observed))),
+	}
+	if configured > 0 {
+		c.Ratio = math.Round(observed/float64(configured)*100) / 100
+	}
+	return c
+}
@@ -0,0 +1,58 @@
+package pulse
+
+import "strconv"
+
+// Privacy controls how per-country visitor counts are published, so a
+// country with only a handful of visitors doesn't give them away.
+// watching_now is then the sum of the published counts, since an exact
+// total less the published ones would recover what was withheld. The
+// activity multiplier stays exact; it is too coarse to give a count away.
+type Privacy struct {
+	// MinCount withholds counts below it, which are published as 0 with
+	// count_withheld set and no surge. 0 publishes every count.
+	MinCount int
+	// RoundTo rounds published counts to the nearest multiple of it, never
+	// down to zero. 0 or 1 leaves them exact.
+	RoundTo int
+}
+
+// Count is a visitor count as published. A withheld count has Below set
+// and encodes as 0; the stats carrying it flag it in count_withheld, so
+// count stays a number for existing clients.
+type Count struct {
+	N     int
+	Below int
+}
+
+// Withheld reports whether the count is hidden for privacy.
+func (c Count) Withheld() bool {
+	return c.Below > 0
+}
+
+// MarshalJSON encodes c as its published number.
+func (c Count) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, int64(c.N), 10), nil
+}
+
+// publish returns the count published for n visitors.
+func (p Privacy) publish(n int) Count {
+	if n > 0 && n < p.MinCount {
+		return Count{Below: p.MinCount}
+	}
+	if p.RoundTo > 1 && n > 0 {
+		n = (n + p.RoundTo/2) / p.RoundTo * p.RoundTo
+		n = max(n, p.RoundTo)
+	}
+	return Count{N: n}
+}
+
+// surgeOf returns the published count's ratio to baseline, rounded down
+// to 2 decimals. A withheld count has none, as it would give the count
+// away.
+func surgeOf(c Count, baseline float64) float64 {
+	if c.Withheld() {
+		return 0
+	}
+	surge := float64(c.N) / baseline
+	return float64(int(surge*100)) / 100
+}
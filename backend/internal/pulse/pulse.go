@@ -1,28 +1,43 @@
 package pulse
 
 import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
-// Visit represents a single page visit.
-type Visit struct {
-	Timestamp   time.Time
-	CountryCode string
-}
+var droppedVisits = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "aegis",
+	Subsystem: "pulse",
+	Name:      "dropped_visits_total",
+	Help:      "Visits not counted because the pulse queue was full.",
+})
 
 // CountryStats holds statistics for a single country.
 type CountryStats struct {
 	CC    string `json:"cc"`
 	Flag  string `json:"flag"`
-	Count int    `json:"count"`
-	Surge float64 `json:"surge"`
+	Count Count  `json:"count"`
+	// CountWithheld is set when the count is below the privacy minimum
+	// and published as 0.
+	CountWithheld bool    `json:"count_withheld,omitempty"`
+	Surge         float64 `json:"surge"`
 }
 
 // IsraelStats holds Israel-specific statistics.
 type IsraelStats struct {
-	Count int     `json:"count"`
-	Surge float64 `json:"surge"`
+	Count         Count   `json:"count"`
+	CountWithheld bool    `json:"count_withheld,omitempty"`
+	Surge         float64 `json:"surge"`
 }
 
 // Stats is the pulse data returned to the frontend.
@@ -36,15 +51,65 @@ type Stats struct {
 }
 
 // Tracker tracks visitor activity with a sliding time window.
+//
+// Visits are counted into a fixed ring of per-minute, per-country slots, so
+// memory stays bounded regardless of traffic and expiring old minutes is
+// O(1): a slot is reset when it is reused for a new minute. No individual
+// visit is ever retained, which PULSE_AGGREGATE_ONLY used to opt into and
+// is now the only mode.
+//
+// Requests never count visits or compute stats themselves: LogVisit queues
+// the visit for the worker run by Start, which adds queued visits in
+// batches and recomputes the stats once per batch. Requests are answered
+// from those cached stats, so a surge costs a channel send per visit.
+//
+// As each 10-minute window is completed the worker also records its
+// per-country counts, so configured baselines can be compared with what
+// was actually observed.
 type Tracker struct {
-	mu         sync.RWMutex
-	visits     []Visit
-	window     time.Duration
-	maxVisits  int
-	baselines  map[string]int
-	baseTotal  int
+	mu        sync.RWMutex
+	slots     [windowMinutes]minuteSlot
+	baselines map[string]int
+	baseTotal int
+	privacy   Privacy
+
+	visits chan string
+	stats  atomic.Pointer[Stats]
+	stop   chan struct{}
+
+	// windows receives completed windows; nil discards them. Only the
+	// worker touches window and started.
+	windows WindowStore
+	window  int64
+	started time.Time
 }
 
+// WindowStore records completed pulse windows. store.Store implements it.
+type WindowStore interface {
+	SavePulseWindow(ctx context.Context, w model.PulseWindow) error
+}
+
+// minuteSlot holds per-country visit counts for one wall-clock minute.
+type minuteSlot struct {
+	minute int64
+	counts map[string]int
+}
+
+// windowMinutes is the length of the sliding window in minutes.
+const windowMinutes = 10
+
+const (
+	// queueSize is how many visits may wait for the worker; beyond it
+	// visits are dropped rather than making requests wait.
+	queueSize = 8192
+	// refreshInterval is how often cached stats are recomputed when no
+	// visits arrive, so minutes still age out of the window.
+	refreshInterval = 5 * time.Second
+	// fallbackBaseline is the expected visitors per window for countries
+	// without a configured baseline.
+	fallbackBaseline = 5
+)
+
 // Country code to flag emoji mapping.
 var countryFlags = map[string]string{
 	"IL": "🇮🇱", "US": "🇺🇸", "DE": "🇩🇪", "GB": "🇬🇧", "IR": "🇮🇷",
@@ -69,15 +134,67 @@ var defaultBaselines = map[string]int{
 
 const defaultBaseTotal = 100
 
-// NewTracker creates a new pulse tracker.
-func NewTracker() *Tracker {
-	return &Tracker{
-		visits:    make([]Visit, 0, 1000),
-		window:    10 * time.Minute,
-		maxVisits: 10000,
+// NewTracker creates a new pulse tracker recording completed windows to
+// windows, which may be nil.
+func NewTracker(windows WindowStore) *Tracker {
+	now := time.Now()
+	t := &Tracker{
 		baselines: defaultBaselines,
 		baseTotal: defaultBaseTotal,
+		visits:    make(chan string, queueSize),
+		stop:      make(chan struct{}),
+		windows:   windows,
+		window:    now.Unix() / 60 / windowMinutes,
+		started:   now,
+	}
+	for i := range t.slots {
+		t.slots[i].counts = make(map[string]int)
 	}
+	t.refresh()
+	return t
+}
+
+// Baselines returns the baselines stats are computed against.
+func (t *Tracker) Baselines() Baselines {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	b := Baselines{Countries: make(map[string]int, len(t.baselines)), BaseTotal: t.baseTotal}
+	for cc, n := range t.baselines {
+		b.Countries[cc] = n
+	}
+	return b
+}
+
+// SetBaselines replaces the baselines, which must be valid, and refreshes
+// the cached stats.
+func (t *Tracker) SetBaselines(b Baselines) {
+	countries := make(map[string]int, len(b.Countries))
+	for cc, n := range b.Countries {
+		countries[cc] = n
+	}
+	t.mu.Lock()
+	t.baselines = countries
+	t.baseTotal = b.BaseTotal
+	t.mu.Unlock()
+	t.refresh()
+}
+
+// SetPrivacy sets how per-country counts are published and refreshes the
+// cached stats.
+func (t *Tracker) SetPrivacy(p Privacy) {
+	t.mu.Lock()
+	t.privacy = p
+	t.mu.Unlock()
+	t.refresh()
+}
+
+// baseline returns a country's expected visitors per window. Must be
+// called with lock held.
+func (t *Tracker) baseline(cc string) int {
+	if n := t.baselines[cc]; n > 0 {
+		return n
+	}
+	return fallbackBaseline
 }
 
 // getFlag returns the flag emoji for a country code.
@@ -88,89 +205,133 @@ func getFlag(cc string) string {
 	return "🌍"
 }
 
-// LogVisit records a visit and returns current stats.
+// LogVisit queues a visit and returns the cached stats, which include it
+// once the worker has caught up. It never blocks.
 func (t *Tracker) LogVisit(countryCode string) Stats {
-	now := time.Now()
-
-	if countryCode == "" {
-		countryCode = "XX"
+	select {
+	case t.visits <- normalizeCountry(countryCode):
+	default:
+		droppedVisits.Inc()
 	}
+	return t.GetStats()
+}
 
-	t.mu.Lock()
-	// Trim old visits
-	t.trimOldVisits(now)
-
-	// Add new visit
-	t.visits = append(t.visits, Visit{
-		Timestamp:   now,
-		CountryCode: countryCode,
-	})
+// GetStats returns the cached stats without logging a visit.
+func (t *Tracker) GetStats() Stats {
+	return *t.stats.Load()
+}
 
-	// Enforce max visits limit
-	if len(t.visits) > t.maxVisits {
-		t.visits = t.visits[len(t.visits)-t.maxVisits:]
+// Start counts queued visits until Stop is called.
+func (t *Tracker) Start(ctx context.Context) {
+	slog.Info("pulse worker started")
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case cc := <-t.visits:
+			t.closeWindow(ctx, time.Now())
+			t.record(cc)
+		case <-ticker.C:
+			t.closeWindow(ctx, time.Now())
+			t.refresh()
+		case <-t.stop:
+			slog.Info("pulse worker stopped")
+			return
+		case <-ctx.Done():
+			return
+		}
 	}
+}
 
-	// Calculate stats while holding lock
-	stats := t.calculateStats(now)
-	t.mu.Unlock()
-
-	return stats
+// Stop signals the worker to stop.
+func (t *Tracker) Stop() {
+	close(t.stop)
 }
 
-// GetStats returns current stats without logging a visit.
-func (t *Tracker) GetStats() Stats {
+// record adds first and every other visit already queued to the window
+// under one lock, then refreshes the cached stats.
+func (t *Tracker) record(first string) {
 	now := time.Now()
+	minute := now.Unix() / 60
 
 	t.mu.Lock()
-	t.trimOldVisits(now)
-	stats := t.calculateStats(now)
+	slot := &t.slots[minute%windowMinutes]
+	if slot.minute != minute {
+		// Reusing a slot from an expired minute
+		slot.minute = minute
+		clear(slot.counts)
+	}
+	slot.counts[first]++
+	for n := len(t.visits); n > 0; n-- {
+		slot.counts[<-t.visits]++
+	}
 	t.mu.Unlock()
 
-	return stats
+	t.refresh()
 }
 
-// trimOldVisits removes visits outside the time window.
-// Must be called with lock held.
-func (t *Tracker) trimOldVisits(now time.Time) {
-	cutoff := now.Add(-t.window)
-	idx := 0
-	for i, v := range t.visits {
-		if v.Timestamp.After(cutoff) {
-			idx = i
-			break
-		}
-		if i == len(t.visits)-1 {
-			idx = len(t.visits)
-		}
+// closeWindow records the previous window once now has moved past it, and
+// before any visit in the new window reuses its slots. A window the tracker
+// wasn't running for all of, or that has already left the ring, is skipped
+// rather than recorded short.
+func (t *Tracker) closeWindow(ctx context.Context, now time.Time) {
+	window := now.Unix() / 60 / windowMinutes
+	if window == t.window {
+		return
 	}
-	if idx > 0 {
-		t.visits = t.visits[idx:]
+	prev := t.window
+	t.window = window
+	first := prev * windowMinutes
+	if t.windows == nil || window != prev+1 || t.started.Unix()/60 > first {
+		return
+	}
+
+	t.mu.RLock()
+	counts := t.countsIn(first, first+windowMinutes-1)
+	t.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	w := model.PulseWindow{EndedAt: time.Unix((first+windowMinutes)*60, 0).UTC(), Counts: counts}
+	if err := t.windows.SavePulseWindow(ctx, w); err != nil {
+		slog.Warn("pulse: failed to record window", "ended_at", w.EndedAt, "error", err)
+	}
+}
+
+// refresh recomputes the cached stats.
+func (t *Tracker) refresh() {
+	t.mu.RLock()
+	stats := t.calculateStats(time.Now())
+	t.mu.RUnlock()
+	t.stats.Store(&stats)
+}
+
+// normalizeCountry maps a country header value to a two-letter uppercase
+// code, or "XX" if it is missing or malformed. This bounds the number of
+// distinct keys a client can create.
+func normalizeCountry(cc string) string {
+	cc = strings.ToUpper(strings.TrimSpace(cc))
+	if len(cc) != 2 || cc[0] < 'A' || cc[0] > 'Z' || cc[1] < 'A' || cc[1] > 'Z' {
+		return "XX"
 	}
+	return cc
 }
 
-// calculateStats computes pulse statistics from current visits.
+// calculateStats computes pulse statistics from the live minute slots.
 // Must be called with lock held.
 func (t *Tracker) calculateStats(now time.Time) Stats {
-	cutoff := now.Add(-t.window)
-
 	// Count visits by country
-	countryCounts := make(map[string]int)
-	for _, v := range t.visits {
-		if v.Timestamp.After(cutoff) {
-			countryCounts[v.CountryCode]++
-		}
-	}
+	countryCounts := t.countsSince(now, windowMinutes)
 
-	watchingNow := 0
+	visits := 0
 	for _, count := range countryCounts {
-		watchingNow += count
+		visits += count
 	}
 
 	// Calculate activity multiplier
 	var activityMultiplier float64
 	if t.baseTotal > 0 {
-		activityMultiplier = float64(watchingNow) / float64(t.baseTotal)
+		activityMultiplier = float64(visits) / float64(t.baseTotal)
 		// Round to 1 decimal
 		activityMultiplier = float64(int(activityMultiplier*10)) / 10
 	} else {
@@ -193,19 +354,18 @@ func (t *Tracker) calculateStats(now time.Time) Stats {
 	// Calculate country stats with surge
 	type countryData struct {
 		cc    string
-		count int
+		count Count
 		surge float64
 	}
 	var countries []countryData
 
-	for cc, count := range countryCounts {
-		baseline := t.baselines[cc]
-		if baseline == 0 {
-			baseline = 5 // Default baseline
-		}
-		surge := float64(count) / float64(baseline)
-		// Round to 2 decimals
-		surge = float64(int(surge*100)) / 100
+	watchingNow := 0
+	for cc, n := range countryCounts {
+		// Ranked, filtered and totalled on published counts, so none of
+		// them gives away a withheld one
+		count := t.privacy.publish(n)
+		surge := surgeOf(count, float64(t.baseline(cc)))
+		watchingNow += count.N
 
 		countries = append(countries, countryData{
 			cc:    cc,
@@ -217,17 +377,18 @@ func (t *Tracker) calculateStats(now time.Time) Stats {
 	// Sort by count descending
 	for i := 0; i < len(countries); i++ {
 		for j := i + 1; j < len(countries); j++ {
-			if countries[j].count > countries[i].count {
+			if countries[j].count.N > countries[i].count.N {
 				countries[i], countries[j] = countries[j], countries[i]
 			}
 		}
 	}
 
 	// Israel stats (always include)
-	israel := IsraelStats{Count: 0, Surge: 0}
+	israel := IsraelStats{}
 	for _, c := range countries {
 		if c.cc == "IL" {
 			israel.Count = c.count
+			israel.CountWithheld = c.count.Withheld()
 			israel.Surge = c.surge
 			break
 		}
@@ -255,10 +416,11 @@ func (t *Tracker) calculateStats(now time.Time) Stats {
 		for _, c := range otherCountries {
 			if c.surge >= 1.5 && len(displayCountries) < 6 {
 				displayCountries = append(displayCountries, CountryStats{
-					CC:    c.cc,
-					Flag:  getFlag(c.cc),
-					Count: c.count,
-					Surge: c.surge,
+					CC:            c.cc,
+					Flag:          getFlag(c.cc),
+					Count:         c.count,
+					CountWithheld: c.count.Withheld(),
+					Surge:         c.surge,
 				})
 			}
 		}
@@ -269,10 +431,11 @@ func (t *Tracker) calculateStats(now time.Time) Stats {
 				break
 			}
 			displayCountries = append(displayCountries, CountryStats{
-				CC:    c.cc,
-				Flag:  getFlag(c.cc),
-				Count: c.count,
-				Surge: c.surge,
+				CC:            c.cc,
+				Flag:          getFlag(c.cc),
+				Count:         c.count,
+				CountWithheld: c.count.Withheld(),
+				Surge:         c.surge,
 			})
 		}
 	}
@@ -286,3 +449,71 @@ func (t *Tracker) calculateStats(now time.Time) Stats {
 		TotalCountries:     len(countryCounts),
 	}
 }
+
+// countsSince sums per-country visits over the last `minutes` minutes.
+// Must be called with lock held.
+func (t *Tracker) countsSince(now time.Time, minutes int) map[string]int {
+	newest := now.Unix() / 60
+	return t.countsIn(newest-int64(minutes)+1, newest)
+}
+
+// countsIn sums per-country visits over minutes oldest through newest.
+// Must be called with lock held.
+func (t *Tracker) countsIn(oldest, newest int64) map[string]int {
+	counts := make(map[string]int)
+	for _, slot := range t.slots {
+		if slot.minute < oldest || slot.minute > newest {
+			continue
+		}
+		for cc, n := range slot.counts {
+			counts[cc] += n
+		}
+	}
+	return counts
+}
+
+// CountryRanking is the full ranked country list.
+type CountryRanking struct {
+	WindowMinutes int            `json:"window_minutes"`
+	WatchingNow   int            `json:"watching_now"`
+	Countries     []CountryStats `json:"countries"`
+}
+
+// Countries returns every country seen in the last `minutes` minutes, ranked
+// by published count. minutes is clamped to the tracker window; surge ratios
+// scale the 10-minute baselines to the requested window.
+func (t *Tracker) Countries(minutes int) CountryRanking {
+	if minutes <= 0 || minutes > windowMinutes {
+		minutes = windowMinutes
+	}
+	now := time.Now()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	counts := t.countsSince(now, minutes)
+
+	scale := float64(minutes) / windowMinutes
+	ranking := CountryRanking{WindowMinutes: minutes, Countries: []CountryStats{}}
+	for cc, n := range counts {
+		count := t.privacy.publish(n)
+		surge := surgeOf(count, float64(t.baseline(cc))*scale)
+
+		ranking.WatchingNow += count.N
+		ranking.Countries = append(ranking.Countries, CountryStats{
+			CC:            cc,
+			Flag:          getFlag(cc),
+			Count:         count,
+			CountWithheld: count.Withheld(),
+			Surge:         surge,
+		})
+	}
+
+	sort.Slice(ranking.Countries, func(i, j int) bool {
+		a, b := ranking.Countries[i], ranking.Countries[j]
+		if a.Count.N != b.Count.N {
+			return a.Count.N > b.Count.N
+		}
+		return a.CC < b.CC
+	})
+	return ranking
+}
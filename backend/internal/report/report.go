@@ -0,0 +1,252 @@
+// Package report computes periodic model diagnostics from stored
+// per-signal scores.
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+)
+
+// windowDays is how much signal history each report covers.
+const windowDays = 7
+
+// reportInterval is the minimum age of the latest report before a new one
+// is generated; checkInterval is how often that is checked.
+const (
+	reportInterval = 24 * time.Hour
+	checkInterval  = time.Hour
+)
+
+// Reporter generates a model report once a day.
+type Reporter struct {
+	store store.Store
+	cfg   *config.Config
+	stop  chan struct{}
+}
+
+func New(st store.Store, cfg *config.Config) *Reporter {
+	return &Reporter{store: st, cfg: cfg, stop: make(chan struct{})}
+}
+
+// Start generates a report whenever the latest one is a day old, checking
+// hourly. Blocks until Stop is called.
+func (r *Reporter) Start(ctx context.Context) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	slog.Info("model reporter started")
+	r.maybeGenerate(ctx)
+
+	for {
+		select {
+		case <-ticker.C:
+			r.maybeGenerate(ctx)
+		case <-r.stop:
+			slog.Info("model reporter stopped")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop signals the reporter to stop.
+func (r *Reporter) Stop() {
+	close(r.stop)
+}
+
+func (r *Reporter) maybeGenerate(ctx context.Context) {
+	latest, err := r.store.LatestModelReport(ctx)
+	if err != nil {
+		slog.Error("model report: failed to load latest", "error", err)
+		return
+	}
+	if latest != nil {
+		var prev model.ModelReport
+		if err := json.Unmarshal(latest, &prev); err == nil {
+			if t, err := time.Parse(time.RFC3339, prev.GeneratedAt); err == nil && time.Since(t) < reportInterval {
+				return
+			}
+		}
+	}
+	if _, err := Generate(ctx, r.store, r.cfg); err != nil {
+		slog.Error("model report: failed to generate", "error", err)
+	}
+}
+
+// Generate computes a report over the last windowDays days for the signals
+// cfg enables, attributing contributions with its weights, stores it and
+// returns it.
+func Generate(ctx context.Context, st store.Store, cfg *config.Config) (model.ModelReport, error) {
+	now := time.Now().UTC()
+	rows, err := st.SignalScoresSince(ctx, now.AddDate(0, 0, -windowDays))
+	if err != nil {
+		return model.ModelReport{}, err
+	}
+
+	rep := Build(rows, signalsOf(cfg))
+	rep.GeneratedAt = now.Format(time.RFC3339)
+
+	data, err := json.Marshal(rep)
+	if err != nil {
+		return model.ModelReport{}, err
+	}
+	if err := st.SaveModelReport(ctx, data); err != nil {
+		return model.ModelReport{}, err
+	}
+	slog.Info("model report generated", "runs", rep.Runs)
+	return rep, nil
+}
+
+// signalsOf returns the signals cfg enables, at its weights. Disabled
+// signals record no scores, so a report including them would find no run
+// complete.
+func signalsOf(cfg *config.Config) []model.SignalMeta {
+	var meta []model.SignalMeta
+	for _, d := range fetcher.Enabled(cfg) {
+		meta = append(meta, d.Meta())
+	}
+	return risk.Meta(meta, cfg.Weights).Signals
+}
+
+// Build computes per-signal statistics, weighted contribution shares and
+// the Pearson correlation between every pair of signals. Only runs that
+// recorded every signal are used, so all series line up.
+func Build(rows []model.SignalScoreRow, signals []model.SignalMeta) model.ModelReport {
+	byRun := map[string]map[string]int{}
+	var order []string
+	for _, r := range rows {
+		run, ok := byRun[r.RunID]
+		if !ok {
+			run = map[string]int{}
+			byRun[r.RunID] = run
+			order = append(order, r.RunID)
+		}
+		run[r.Signal] = r.Risk
+	}
+
+	series := make(map[string][]float64, len(signals))
+	for _, id := range order {
+		run := byRun[id]
+		if !recordsAll(run, signals) {
+			continue
+		}
+		for _, s := range signals {
+			series[s.Name] = append(series[s.Name], float64(run[s.Name]))
+		}
+	}
+
+	rep := model.ModelReport{
+		WindowDays:   windowDays,
+		Signals:      make(map[string]model.SignalStats, len(signals)),
+		Correlations: make(map[string]map[string]float64, len(signals)),
+	}
+	if len(signals) > 0 {
+		rep.Runs = len(series[signals[0].Name])
+	}
+	if rep.Runs == 0 {
+		return rep
+	}
+
+	var weightedTotal float64
+	for _, s := range signals {
+		weightedTotal += s.Weight * meanOf(series[s.Name])
+	}
+	for _, s := range signals {
+		xs := series[s.Name]
+		m := meanOf(xs)
+		v := varianceOf(xs, m)
+		lo, hi := minMax(xs)
+		stats := model.SignalStats{
+			Mean:     round(m),
+			Variance: round(v),
+			StdDev:   round(math.Sqrt(v)),
+			Min:      int(lo),
+			Max:      int(hi),
+		}
+		if weightedTotal > 0 {
+			stats.Contribution = round(s.Weight * m / weightedTotal)
+		}
+		rep.Signals[s.Name] = stats
+	}
+
+	for _, a := range signals {
+		row := make(map[string]float64, len(signals))
+		for _, b := range signals {
+			row[b.Name] = round(correlation(series[a.Name], series[b.Name]))
+		}
+		rep.Correlations[a.Name] = row
+	}
+	return rep
+}
+
+// recordsAll reports whether run has a score for each of signals.
+func recordsAll(run map[string]int, signals []model.SignalMeta) bool {
+	for _, s := range signals {
+		if _, ok := run[s.Name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func meanOf(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+func varianceOf(xs []float64, mean float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, x := range xs {
+		sum += (x - mean) * (x - mean)
+	}
+	return sum / float64(len(xs))
+}
+
+// correlation is the Pearson coefficient. It is undefined when either series
+// is constant and reported as 0.
+func correlation(xs, ys []float64) float64 {
+	mx, my := meanOf(xs), meanOf(ys)
+	var cov, vx, vy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+func minMax(xs []float64) (float64, float64) {
+	lo, hi := xs[0], xs[0]
+	for _, x := range xs[1:] {
+		lo = math.Min(lo, x)
+		hi = math.Max(hi, x)
+	}
+	return lo, hi
+}
+
+func round(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
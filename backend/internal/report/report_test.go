@@ -0,0 +1,39 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+func TestBuildSkipsDisabledSignals(t *testing.T) {
+	cfg := &config.Config{
+		Weights:  config.DefaultWeights(fetcher.ConfigSignals()),
+		Disabled: map[string]bool{"shipping": true},
+	}
+
+	// Disabled signals aren't scored, so no run stores a row for shipping
+	var rows []model.SignalScoreRow
+	for _, run := range []string{"run-1", "run-2"} {
+		for _, name := range fetcher.Names() {
+			if name != "shipping" {
+				rows = append(rows, model.SignalScoreRow{RunID: run, Signal: name, Risk: 20})
+			}
+		}
+	}
+	// A run missing an enabled signal is left out
+	rows = append(rows, model.SignalScoreRow{RunID: "run-3", Signal: "news", Risk: 90})
+
+	rep := Build(rows, signalsOf(cfg))
+	if rep.Runs != 2 {
+		t.Errorf("Runs = %d, want 2", rep.Runs)
+	}
+	if _, ok := rep.Signals["shipping"]; ok {
+		t.Error("report includes disabled signal shipping")
+	}
+	if got := rep.Signals["news"].Mean; got != 20 {
+		t.Errorf("news mean = %g, want 20", got)
+	}
+}
@@ -4,163 +4,457 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"strings"
 
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
-// Calculate computes risk scores for all signals and returns a RiskScores struct.
-func Calculate(
-	news model.NewsData,
-	connectivity model.ConnectivityData,
-	aviation model.AviationData,
-	tanker model.TankerData,
-	weather model.WeatherData,
-	polymarket model.PolymarketData,
-	pentagon model.PentagonData,
-) model.RiskScores {
-	slog.Info("calculating risk scores")
+// minFlightBaselineSamples is the number of same-hour observations required
+// before the flight signal is scored against its historical baseline.
+const minFlightBaselineSamples = 3
 
-	// NEWS (20% weight)
-	articles := news.TotalCount
-	alertCount := news.AlertCount
-	alertRatio := 0.0
+// minTankerBaselineSamples is the number of same hour-of-week observations
+// required before the tanker signal is scored as an anomaly ratio.
+const minTankerBaselineSamples = 3
+
+// tankerCountRisk scores a tanker count with no baseline to compare it
+// against, on a curve that reaches 90 at saturation tankers and flattens
+// toward 100 beyond it. Each tanker adds less than the one before, so a
+// surge past saturation can't score more than 100. It replaced a linear
+// 10 points per tanker, so at the default saturation of 20 a count of 10
+// scores 68 where it used to score 100.
+func tankerCountRisk(count, saturation int) int {
+	risk := 100 * (1 - math.Pow(10, -float64(count)/float64(saturation)))
+	return int(math.Max(0, math.Min(100, math.Round(risk))))
+}
+
+// carrierAvoidancePenalty is the flight risk added per major carrier that
+// usually crosses Iranian airspace at this hour but was not seen this run.
+const carrierAvoidancePenalty = 10
+
+// Band maps a total risk score to the status band used by the frontend.
+func Band(risk int) string {
+	for _, b := range bands {
+		if risk >= b.Min {
+			return b.Name
+		}
+	}
+	return bands[len(bands)-1].Name
+}
+
+// deescalationMaxAdjustment is how many points news risk drops when every
+// article reports de-escalation.
+const deescalationMaxAdjustment = 30
+
+// NewsRisk scores the news signal from the article, alert and de-escalation
+// counts. De-escalation coverage subtracts in proportion to its share of
+// articles, so volume alone can't only push risk up.
+func NewsRisk(articles, alertCount, deescalationCount int) int {
+	alertRatio, deescalationRatio := 0.0, 0.0
 	if articles > 0 {
 		alertRatio = float64(alertCount) / float64(articles)
+		deescalationRatio = float64(deescalationCount) / float64(articles)
 	}
-	newsDisplayRisk := int(math.Max(3, math.Round(math.Pow(alertRatio, 2)*85)))
-	newsDetail := fmt.Sprintf("%d articles, %d critical", articles, alertCount)
-	slog.Info("risk: news", "risk", newsDisplayRisk, "detail", newsDetail)
-
-	// DIGITAL CONNECTIVITY (20% weight)
-	connStatus := connectivity.Status
-	if connStatus == "" {
-		connStatus = "STABLE"
-	}
-	connRisk := connectivity.Risk
-	connTrend := connectivity.Trend
-	connDisplayRisk := int(math.Min(95, math.Round(connRisk*3.8)))
-	var connDetail string
-	if connStatus == "STALE" {
-		connDetail = "Data unavailable"
-	} else {
-		connDetail = fmt.Sprintf("%s (%+.1f%%)", connStatus, connTrend)
+	return int(math.Max(3, math.Round(math.Pow(alertRatio, 2)*85-deescalationRatio*deescalationMaxAdjustment)))
+}
+
+// Polymarket spread bounds: odds from a book at or under tightSpread count in
+// full, while at wideSpread and beyond only minSpreadConfidence of their
+// distance from polyBaselineRisk (the score shown with no odds) does.
+const (
+	tightSpread         = 0.02
+	wideSpread          = 0.15
+	minSpreadConfidence = 0.5
+	polyBaselineRisk    = 10
+)
+
+// SpreadConfidence is how far to trust a market's odds given its bid/ask
+// spread in probability units, falling linearly from 1 to
+// minSpreadConfidence between tightSpread and wideSpread.
+func SpreadConfidence(spread float64) float64 {
+	if spread <= tightSpread {
+		return 1
+	}
+	if spread >= wideSpread {
+		return minSpreadConfidence
 	}
-	slog.Info("risk: connectivity", "risk", connDisplayRisk, "detail", connDetail)
+	return 1 - (spread-tightSpread)/(wideSpread-tightSpread)*(1-minSpreadConfidence)
+}
 
-	// FLIGHT (15% weight)
-	aircraftCount := aviation.AircraftCount
-	flightRisk := int(math.Max(3, 95-math.Round(float64(aircraftCount)*0.8)))
-	flightDetail := fmt.Sprintf("%d aircraft over Iran", aircraftCount)
-	slog.Info("risk: flight", "risk", flightRisk, "detail", flightDetail)
+// attentionQuietRisk is the attention score when every source reads at its
+// usual level; each 1× above normal adds attentionRiskPerRatio points.
+const (
+	attentionQuietRisk    = 10
+	attentionRiskPerRatio = 45
+)
 
-	// TANKER (15% weight)
-	tankerCount := tanker.TankerCount
-	tankerRisk := int(math.Round(float64(tankerCount) / 10 * 100))
-	tankerDisplayCount := int(math.Round(float64(tankerCount) / 4))
-	tankerDetail := fmt.Sprintf("%d detected in region", tankerDisplayCount)
-	slog.Info("risk: tanker", "risk", tankerRisk, "detail", tankerDetail)
-
-	// WEATHER (5% weight)
-	clouds := weather.Clouds
-	weatherRisk := int(math.Max(0, math.Min(100, float64(100-(int(math.Max(0, float64(clouds-6)))*10)))))
-	weatherDetail := weather.Description
-	if weatherDetail == "" {
-		weatherDetail = "clear"
-	}
-	slog.Info("risk: weather", "risk", weatherRisk, "detail", weatherDetail)
-
-	// POLYMARKET (15% weight)
-	polyOdds := polymarket.Odds
-	if polyOdds < 0 {
-		polyOdds = 0
-	}
-	if polyOdds > 100 {
-		polyOdds = 100
-	}
-	if polyOdds > 95 {
-		polyOdds = 0
-	}
-	polyDisplayRisk := polyOdds
-	if polyOdds == 0 {
-		polyDisplayRisk = 10
-	}
-	var polyDetail string
-	if polyOdds > 0 {
-		polyDetail = fmt.Sprintf("%d%% odds", polyOdds)
-	} else {
-		polyDetail = "Awaiting data..."
+// ScoreAttention combines the attention sources into one score: the mean of
+// each source's ratio-to-normal mapped onto 0–100.
+func ScoreAttention(data model.AttentionData, cfg *config.Config) (int, string) {
+	if len(data.Components) == 0 {
+		return attentionQuietRisk, "No sources"
+	}
+	sum := 0.0
+	parts := make([]string, 0, len(data.Components))
+	for _, c := range data.Components {
+		sum += math.Min(100, math.Max(0, attentionQuietRisk+(c.Ratio-1)*attentionRiskPerRatio))
+		parts = append(parts, fmt.Sprintf("%s %.1f×", c.Source, c.Ratio))
 	}
-	slog.Info("risk: polymarket", "risk", polyDisplayRisk, "detail", polyDetail)
+	return int(math.Round(sum / float64(len(data.Components)))), strings.Join(parts, ", ")
+}
 
-	// PENTAGON (10% weight)
-	pentagonContrib := pentagon.RiskContribution
-	pentagonDisplayRisk := int(math.Round(float64(pentagonContrib) / 10 * 100))
-	pentagonStatus := pentagon.Status
-	if pentagonStatus == "" {
-		pentagonStatus = "Normal"
+// Geopolitics scoring: coverage at its usual rate and tone scores
+// geopoliticsQuietRisk. Each 1× above the usual rate adds
+// geopoliticsRiskPerRatio points, and each point of tone more hostile than
+// the week before adds geopoliticsRiskPerTone, up to geopoliticsMaxToneRisk.
+const (
+	geopoliticsQuietRisk    = 15
+	geopoliticsRiskPerRatio = 35
+	geopoliticsRiskPerTone  = 10
+	geopoliticsMaxToneRisk  = 30
+)
+
+// ScoreGeopolitics scores GDELT coverage on how far its volume and tone have
+// moved from the week before.
+func ScoreGeopolitics(data model.GeopoliticsData, cfg *config.Config) (int, string) {
+	if data.BaselinePerDay == 0 {
+		return geopoliticsQuietRisk, "No coverage baseline"
 	}
-	pentagonDetail := pentagonStatus
-	if pentagon.IsLateNight {
-		pentagonDetail += " (late night)"
+	volume := math.Max(0, (data.Ratio-1)*geopoliticsRiskPerRatio)
+	tone := math.Min(geopoliticsMaxToneRisk, math.Max(0, (data.BaselineTone-data.AverageTone)*geopoliticsRiskPerTone))
+	risk := int(math.Round(math.Min(100, geopoliticsQuietRisk+volume+tone)))
+	return risk, fmt.Sprintf("%d articles/24h (%.1f× usual), tone %.1f vs %.1f", data.ArticleCount, data.Ratio, data.AverageTone, data.BaselineTone)
+}
+
+// minShippingBaselineSamples is the number of same-hour observations
+// required before Hormuz traffic is scored against its baseline.
+const minShippingBaselineSamples = 3
+
+// Shipping scoring: the risk is the percent that vessels, or tankers alone,
+// have fallen below their baselines, between shippingQuietRisk and
+// shippingMaxRisk. The tanker baseline is floored at one like the tanker
+// signal's, so a quiet hour's single tanker leaving doesn't read as a full
+// drop.
+const (
+	shippingQuietRisk = 5
+	shippingMaxRisk   = 95
+)
+
+// ScoreShipping scores Hormuz traffic on the larger of its overall and
+// tanker drops from the same hour's baseline. Busier traffic than usual
+// scores as quiet: it is a drop ahead of conflict that is the warning.
+func ScoreShipping(data model.ShippingData, cfg *config.Config) (int, string) {
+	if data.BaselineSamples < minShippingBaselineSamples || data.Baseline == 0 {
+		return shippingQuietRisk, fmt.Sprintf("%d vessels in Hormuz (%d tankers), no baseline yet", data.VesselCount, data.TankerCount)
 	}
-	if pentagon.IsWeekend {
-		pentagonDetail += " (weekend)"
+	ratio := float64(data.VesselCount) / data.Baseline
+	tankerRatio := float64(data.TankerCount) / math.Max(1, data.TankerBaseline)
+	drop := math.Max(1-ratio, 1-tankerRatio) * 100
+	risk := int(math.Max(shippingQuietRisk, math.Min(shippingMaxRisk, math.Round(drop))))
+	return risk, fmt.Sprintf("%d vessels in Hormuz (%d%% of normal), %d tankers (%d%%)",
+		data.VesselCount, int(math.Round(ratio*100)), data.TankerCount, int(math.Round(tankerRatio*100)))
+}
+
+// Airspace scoring: with nothing in force the signal scores
+// airspaceQuietRisk. A closure at one of keyAirspace adds
+// airspaceKeyClosureRisk, any other FIR closure airspaceClosureRisk and
+// any other aerodrome closure airspaceAerodromeRisk. Activated restricted
+// areas and military warnings add smaller amounts, each capped, as a few
+// of both are in force on a quiet day.
+const (
+	airspaceQuietRisk          = 5
+	airspaceKeyClosureRisk     = 60
+	airspaceClosureRisk        = 25
+	airspaceAerodromeRisk      = 10
+	airspaceRiskPerRestriction = 2
+	airspaceMaxRestrictionRisk = 10
+	airspaceRiskPerWarning     = 5
+	airspaceMaxWarningRisk     = 25
+)
+
+// keyAirspace are the locations whose closure most directly precedes a
+// strike: the Tehran and Tel Aviv FIRs and their main airports.
+var keyAirspace = map[string]bool{"OIIX": true, "LLLL": true, "OIIE": true, "LLBG": true}
+
+// ScoreAirspace scores the NOTAMs in force on the closures they announce,
+// then on activated restrictions and military warnings.
+func ScoreAirspace(data model.AirspaceData, cfg *config.Config) (int, string) {
+	risk := float64(airspaceQuietRisk)
+	closed := make([]string, 0, len(data.Closures))
+	for _, c := range data.Closures {
+		switch {
+		case keyAirspace[c.Location]:
+			risk += airspaceKeyClosureRisk
+		case c.Kind == "fir":
+			risk += airspaceClosureRisk
+		default:
+			risk += airspaceAerodromeRisk
+		}
+		closed = append(closed, c.Location)
 	}
-	slog.Info("risk: pentagon", "risk", pentagonDisplayRisk, "detail", pentagonDetail)
+	risk += math.Min(airspaceMaxRestrictionRisk, float64(data.Restrictions*airspaceRiskPerRestriction))
+	risk += math.Min(airspaceMaxWarningRisk, float64(data.Warnings*airspaceRiskPerWarning))
 
-	// Weighted contributions
-	newsWeighted := float64(newsDisplayRisk) * 0.20
-	connWeighted := float64(connDisplayRisk) * 0.20
-	flightWeighted := float64(flightRisk) * 0.15
-	tankerWeighted := float64(tankerRisk) * 0.15
-	polyWeighted := float64(polyDisplayRisk) * 0.15
-	pentagonWeighted := float64(pentagonDisplayRisk) * 0.10
-	weatherWeighted := float64(weatherRisk) * 0.05
+	detail := "No closures"
+	if len(closed) > 0 {
+		detail = "Closed: " + strings.Join(closed, ", ")
+	}
+	detail += fmt.Sprintf(", %d restrictions, %d warnings", data.Restrictions, data.Warnings)
+	return int(math.Round(math.Min(100, risk))), detail
+}
 
-	totalRisk := newsWeighted + connWeighted + flightWeighted + tankerWeighted +
-		polyWeighted + pentagonWeighted + weatherWeighted
+// Seismic scoring: a day with no suspicious events scores seismicQuietRisk,
+// and each suspicious one adds seismicRiskPerEvent. Small shallow quakes do
+// happen naturally, so it takes two in a day to elevate the signal.
+const (
+	seismicQuietRisk    = 5
+	seismicRiskPerEvent = 20
+)
 
-	// Escalation multiplier
-	elevatedCount := 0
-	if newsDisplayRisk > 30 {
-		elevatedCount++
+// ScoreSeismic scores the day's events inconsistent with natural
+// seismicity, naming the largest.
+func ScoreSeismic(data model.SeismicData, cfg *config.Config) (int, string) {
+	if data.Suspicious == 0 {
+		return seismicQuietRisk, fmt.Sprintf("%d events, none suspicious", data.EventCount)
 	}
-	if connRisk >= 10 {
-		elevatedCount++
+	var largest model.SeismicEvent
+	for _, e := range data.Events {
+		if e.Suspicious && (largest.ID == "" || e.Magnitude > largest.Magnitude) {
+			largest = e
+		}
 	}
-	if flightRisk > 50 {
-		elevatedCount++
+	risk := math.Min(100, float64(seismicQuietRisk+data.Suspicious*seismicRiskPerEvent))
+	detail := fmt.Sprintf("%d of %d events suspicious", data.Suspicious, data.EventCount)
+	if largest.ID != "" {
+		detail += fmt.Sprintf(", largest M%.1f at %.0f km, %s", largest.Magnitude, largest.DepthKm, largest.Place)
 	}
-	if tankerRisk > 30 {
-		elevatedCount++
+	return int(risk), detail
+}
+
+// GPS scoring follows gpsjam.org's levels: a zone with at least
+// gpsMinAircraft reporting has medium interference once gpsMediumRatio of
+// them are degraded, and high from gpsHighRatio. Each zone adds to
+// gpsQuietRisk by its level. Israel has been jammed most days since late
+// 2023, so one high zone alone doesn't elevate the signal; widespread
+// jamming does.
+const (
+	gpsQuietRisk      = 5
+	gpsMinAircraft    = 5
+	gpsMediumRatio    = 0.02
+	gpsHighRatio      = 0.10
+	gpsMediumZoneRisk = 15
+	gpsHighZoneRisk   = 30
+)
+
+// ScoreGPS scores GPS interference on how many zones are jammed and how
+// badly.
+func ScoreGPS(data model.GPSData, cfg *config.Config) (int, string) {
+	risk := gpsQuietRisk
+	var high, medium []string
+	for _, z := range data.Zones {
+		if z.Aircraft < gpsMinAircraft {
+			continue
+		}
+		switch {
+		case z.Ratio >= gpsHighRatio:
+			risk += gpsHighZoneRisk
+			high = append(high, fmt.Sprintf("%s %d%%", z.Name, int(math.Round(z.Ratio*100))))
+		case z.Ratio >= gpsMediumRatio:
+			risk += gpsMediumZoneRisk
+			medium = append(medium, fmt.Sprintf("%s %d%%", z.Name, int(math.Round(z.Ratio*100))))
+		}
 	}
-	if polyDisplayRisk > 30 {
-		elevatedCount++
+	if len(high)+len(medium) == 0 {
+		return risk, fmt.Sprintf("No interference in %d zones", len(data.Zones))
 	}
-	if pentagonDisplayRisk > 50 {
-		elevatedCount++
+	var parts []string
+	if len(high) > 0 {
+		parts = append(parts, "High: "+strings.Join(high, ", "))
 	}
-	if weatherRisk > 70 {
-		elevatedCount++
+	if len(medium) > 0 {
+		parts = append(parts, "Medium: "+strings.Join(medium, ", "))
 	}
+	return min(100, risk), strings.Join(parts, "; ")
+}
 
-	if elevatedCount >= 3 {
-		slog.Info("escalation triggered", "elevated_signals", elevatedCount)
-		totalRisk = math.Min(100, totalRisk*1.15)
+// The signal scorers below share one form: each rates one signal's data
+// and returns its displayed risk and a detail line. cfg carries the
+// settings a few are scored against.
+
+// ScoreNews scores the news signal on its article counts; see NewsRisk.
+func ScoreNews(news model.NewsData, cfg *config.Config) (int, string) {
+	articles := news.TotalCount
+	alertCount := news.AlertCount
+	detail := fmt.Sprintf("%d articles, %d critical", articles, alertCount)
+	if news.Lookback != "" {
+		detail = fmt.Sprintf("%d articles in %s (%.1f/h), %d critical", articles, news.Lookback, news.ArticlesPerHour, alertCount)
+	}
+	if news.DeescalationCount > 0 {
+		detail += fmt.Sprintf(", %d de-escalation", news.DeescalationCount)
+	}
+	if news.Feeds.OK < news.Feeds.Live {
+		detail += fmt.Sprintf(" (%d/%d feeds)", news.Feeds.OK, news.Feeds.Live)
+	}
+	return NewsRisk(articles, alertCount, news.DeescalationCount), detail
+}
+
+// ScoreConnectivity scales the traffic drop onto the displayed range.
+func ScoreConnectivity(connectivity model.ConnectivityData, cfg *config.Config) (int, string) {
+	status := connectivity.Status
+	if status == "" {
+		status = "STABLE"
+	}
+	risk := int(math.Min(95, math.Round(connectivity.Risk*3.8)))
+	if status == "STALE" {
+		return risk, "Data unavailable"
+	}
+	return risk, fmt.Sprintf("%s (%+.1f%%)", status, connectivity.Trend)
+}
+
+// ScoreFlight scores the drop in civil traffic over Iran, against the
+// same hour's baseline once there is one, plus the major carriers that
+// have pulled out.
+func ScoreFlight(aviation model.AviationData, cfg *config.Config) (int, string) {
+	aircraftCount := aviation.AircraftCount
+	var risk int
+	var detail string
+	if aviation.BaselineSamples >= minFlightBaselineSamples && aviation.Baseline > 0 {
+		// Score the drop relative to what is normal for this hour of day
+		ratio := float64(aircraftCount) / aviation.Baseline
+		risk = int(math.Max(3, math.Min(95, math.Round((1-ratio)*100))))
+		detail = fmt.Sprintf("%d aircraft over Iran (%d%% of normal)", aircraftCount, int(math.Round(ratio*100)))
+	} else {
+		risk = int(math.Max(3, 95-math.Round(float64(aircraftCount)*0.8)))
+		detail = fmt.Sprintf("%d aircraft over Iran", aircraftCount)
+	}
+	if len(aviation.MissingCarriers) > 0 {
+		// Major carriers pulling out of Iranian airspace is an avoidance signal
+		risk = int(math.Min(95, float64(risk+carrierAvoidancePenalty*len(aviation.MissingCarriers))))
+		names := make([]string, len(aviation.MissingCarriers))
+		for i, code := range aviation.MissingCarriers {
+			names[i] = model.CarrierName(code)
+		}
+		detail += fmt.Sprintf(", %s avoiding", strings.Join(names, ", "))
+	}
+	return risk, detail
+}
+
+// ScoreTanker scores tanker activity against the same hour of the week
+// once there is a baseline, and on the count alone until then.
+func ScoreTanker(tanker model.TankerData, cfg *config.Config) (int, string) {
+	tankerCount := tanker.TankerCount
+	if tanker.BaselineSamples >= minTankerBaselineSamples {
+		// Normalize against what is routine for this hour of the week; a floor
+		// of one tanker keeps quiet hours from producing huge ratios.
+		ratio := float64(tankerCount) / math.Max(1, tanker.Baseline)
+		risk := int(math.Max(0, math.Min(100, math.Round((ratio-1)*50))))
+		return risk, fmt.Sprintf("%.1fx normal (%d tracked)", ratio, tankerCount)
+	}
+	displayCount := int(math.Round(float64(tankerCount) / 4))
+	return tankerCountRisk(tankerCount, cfg.TankerSaturation), fmt.Sprintf("%d detected in region", displayCount)
+}
+
+// ScoreWeather scores how favorable conditions are for a strike under the
+// configured thresholds.
+func ScoreWeather(weather model.WeatherData, cfg *config.Config) (int, string) {
+	detail := weather.Description
+	if detail == "" {
+		detail = "clear"
+	}
+	return weatherRisk(weather, cfg.Weather), detail
+}
+
+// ScorePolymarket scores the strike odds, pulled towards the baseline for
+// thinly traded markets. Odds above 95% are taken as a resolved or broken
+// market and ignored.
+func ScorePolymarket(polymarket model.PolymarketData, cfg *config.Config) (int, string) {
+	odds := polymarket.Odds
+	if odds < 0 {
+		odds = 0
+	}
+	if odds > 100 {
+		odds = 100
+	}
+	if odds > 95 {
+		odds = 0
+	}
+	if odds == 0 {
+		return polyBaselineRisk, "Awaiting data..."
+	}
+	risk := odds
+	detail := fmt.Sprintf("%d%% odds", odds)
+	// Thinly traded markets are pulled towards the baseline
+	if book := polymarket.OrderBook; book != nil {
+		conf := SpreadConfidence(book.Spread)
+		risk = int(math.Round(polyBaselineRisk + float64(odds-polyBaselineRisk)*conf))
+		detail += fmt.Sprintf(" (%.0f¢ spread)", book.Spread*100)
+	}
+	return risk, detail
+}
+
+// ScorePentagon scales the pizza index's contribution onto 0-100.
+func ScorePentagon(pentagon model.PentagonData, cfg *config.Config) (int, string) {
+	risk := int(math.Round(float64(pentagon.RiskContribution) / 10 * 100))
+	detail := pentagon.Status
+	if detail == "" {
+		detail = "Normal"
+	}
+	if len(pentagon.Clusters) > 1 && pentagon.Cluster != "" {
+		detail += " at " + pentagon.Cluster
+	}
+	if pentagon.IsLateNight {
+		detail += " (late night)"
+	}
+	if pentagon.IsWeekend {
+		detail += " (weekend)"
+	}
+	return risk, detail
+}
+
+// Calculate combines the signal scores, in scoring order, into total risk
+// under weights.
+func Calculate(signals []model.NamedSignalScore, weights config.Weights) model.RiskScores {
+	slog.Info("calculating risk scores")
+	scores := model.RiskScores{Signals: signals}
+	combine(&scores, weights, nil)
+	return scores
+}
+
+// combine sets the total risk and elevated count from the signal scores:
+// the weighted sum of signal risks, multiplied up when enough signals are
+// elevated at once. Signals in excluded are left out, with the remaining
+// weights scaled up to sum to what the full set did.
+func combine(scores *model.RiskScores, weights config.Weights, excluded map[string]bool) {
+	var all, kept float64
+	for name, w := range weights {
+		all += w
+		if !excluded[name] {
+			kept += w
+		}
 	}
 
-	totalRiskInt := int(math.Min(100, math.Max(0, math.Round(totalRisk))))
-	slog.Info("total risk", "risk", totalRiskInt, "elevated", elevatedCount)
+	var totalRisk float64
+	elevatedCount := 0
+	for _, s := range scores.Signals {
+		if excluded[s.Name] {
+			continue
+		}
+		totalRisk += float64(s.Risk) * weights[s.Name]
+		if s.Elevated {
+			elevatedCount++
+		}
+	}
+	if kept > 0 {
+		totalRisk *= all / kept
+	}
 
-	return model.RiskScores{
-		News:         model.SignalScore{Risk: newsDisplayRisk, Detail: newsDetail},
-		Connectivity: model.SignalScore{Risk: connDisplayRisk, Detail: connDetail},
-		Flight:       model.SignalScore{Risk: flightRisk, Detail: flightDetail},
-		Tanker:       model.SignalScore{Risk: tankerRisk, Detail: tankerDetail},
-		Weather:      model.SignalScore{Risk: weatherRisk, Detail: weatherDetail},
-		Polymarket:   model.SignalScore{Risk: polyDisplayRisk, Detail: polyDetail},
-		Pentagon:     model.SignalScore{Risk: pentagonDisplayRisk, Detail: pentagonDetail},
-		TotalRisk:    totalRiskInt,
-		ElevatedCount: elevatedCount,
+	if elevatedCount >= escalationSignals {
+		slog.Info("escalation triggered", "elevated_signals", elevatedCount)
+		totalRisk = math.Min(100, totalRisk*escalationMultiplier)
 	}
+
+	scores.TotalRisk = int(math.Min(100, math.Max(0, math.Round(totalRisk))))
+	scores.ElevatedCount = elevatedCount
+	slog.Info("total risk", "risk", scores.TotalRisk, "elevated", elevatedCount)
 }
@@ -0,0 +1,33 @@
+package risk
+
+import "testing"
+
+func TestTankerCountRisk(t *testing.T) {
+	// The TANKER_SATURATION default
+	const saturation = 20
+
+	prev := -1
+	for count := 0; count <= 40; count++ {
+		got := tankerCountRisk(count, saturation)
+		if got > 100 {
+			t.Errorf("tankerCountRisk(%d) = %d, above 100", count, got)
+		}
+		if got < prev {
+			t.Errorf("tankerCountRisk(%d) = %d, below %d for %d tankers", count, got, prev, count-1)
+		}
+		prev = got
+	}
+
+	for _, tc := range []struct {
+		count, want int
+	}{
+		{0, 0},
+		{10, 68},
+		{saturation, 90},
+		{40, 99},
+	} {
+		if got := tankerCountRisk(tc.count, saturation); got != tc.want {
+			t.Errorf("tankerCountRisk(%d) = %d, want %d", tc.count, got, tc.want)
+		}
+	}
+}
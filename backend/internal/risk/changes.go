@@ -0,0 +1,67 @@
+package risk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// changeThreshold is the minimum risk movement (in points) since the previous
+// run for a signal to appear in changes_since_last.
+const changeThreshold = 10
+
+// changesSinceLast lists signals whose risk moved by at least changeThreshold
+// points compared to the previous snapshot.
+func changesSinceLast(current map[string]any, scores model.RiskScores) []model.SignalChange {
+	changes := []model.SignalChange{}
+	if current == nil {
+		return changes
+	}
+
+	for _, s := range scores.Signals {
+		sigData, ok := current[s.Name].(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := sigData["risk"]; !ok {
+			continue
+		}
+		if c, ok := Change(s.Name, s.Label, getIntVal(sigData, "risk"), s.Risk, s.Detail); ok {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// Change describes a signal's move from prev to cur, or reports false when
+// it moved by less than changeThreshold points.
+func Change(name, label string, prev, cur int, detail string) (model.SignalChange, bool) {
+	delta := cur - prev
+	if delta < changeThreshold && delta > -changeThreshold {
+		return model.SignalChange{}, false
+	}
+
+	direction := "up"
+	if delta < 0 {
+		direction = "down"
+	}
+	desc := fmt.Sprintf("%s risk %s %d (%d → %d)", label, direction, abs(delta), prev, cur)
+	if detail != "" {
+		desc += ": " + detail
+	}
+	return model.SignalChange{
+		Signal:      name,
+		From:        prev,
+		To:          cur,
+		Delta:       delta,
+		Description: strings.TrimSpace(desc),
+	}, true
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,27 @@
+package risk
+
+import (
+	"log/slog"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// Degrade recombines total risk without the excluded signals, so that when
+// most upstreams are down the score reflects what was actually fetched
+// rather than a blend with day-old fallbacks, and a reading suspected of
+// being a glitch doesn't move it. It does nothing if every signal would be
+// excluded, leaving no score to fall back on.
+func Degrade(scores *model.RiskScores, excluded []string, weights config.Weights) bool {
+	if len(excluded) == 0 || len(excluded) >= len(scores.Signals) {
+		return false
+	}
+	skip := make(map[string]bool, len(excluded))
+	for _, name := range excluded {
+		skip[name] = true
+	}
+	computed := scores.TotalRisk
+	combine(scores, weights, skip)
+	slog.Warn("risk: scoring without signals", "excluded", excluded, "risk", scores.TotalRisk, "computed", computed)
+	return true
+}
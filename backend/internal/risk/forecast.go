@@ -0,0 +1,107 @@
+package risk
+
+import (
+	"math"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// Holt's linear smoothing parameters for the hourly total risk series. The
+// level reacts quickly to new runs; the trend is damped so a single spike
+// does not project straight to 100.
+const (
+	forecastAlpha = 0.5
+	forecastBeta  = 0.1
+	forecastPhi   = 0.9
+)
+
+// minForecastHours is the number of hourly points needed before a forecast
+// is produced.
+const minForecastHours = 12
+
+// forecastHorizons are the hours ahead projected in total_risk.forecast.
+var forecastHorizons = []int{12, 24}
+
+// Forecast projects the total risk series forward using damped Holt
+// smoothing over hourly means. The interval is ±1.96 standard deviations of
+// the one-step-ahead errors, widened with the square root of the horizon.
+// It returns nil when the series is too short.
+func Forecast(series []model.TotalRiskPoint) []model.ForecastPoint {
+	hourly := hourlyMeans(series)
+	if len(hourly) < minForecastHours {
+		return nil
+	}
+
+	level, trend := hourly[0], hourly[1]-hourly[0]
+	var sumSq float64
+	for _, y := range hourly[1:] {
+		predicted := level + forecastPhi*trend
+		sumSq += (y - predicted) * (y - predicted)
+
+		prevLevel := level
+		level = forecastAlpha*y + (1-forecastAlpha)*predicted
+		trend = forecastBeta*(level-prevLevel) + (1-forecastBeta)*forecastPhi*trend
+	}
+	sigma := math.Sqrt(sumSq / float64(len(hourly)-1))
+
+	points := make([]model.ForecastPoint, 0, len(forecastHorizons))
+	for _, h := range forecastHorizons {
+		// Sum of phi^1..phi^h for the damped trend.
+		damp := 0.0
+		for i, f := 1, forecastPhi; i <= h; i, f = i+1, f*forecastPhi {
+			damp += f
+		}
+		mid := level + damp*trend
+		spread := 1.96 * sigma * math.Sqrt(float64(h))
+
+		r := clampRisk(mid)
+		points = append(points, model.ForecastPoint{
+			Hours: h,
+			Risk:  r,
+			Low:   clampRisk(mid - spread),
+			High:  clampRisk(mid + spread),
+			Band:  Band(r),
+		})
+	}
+	return points
+}
+
+// hourlyMeans buckets the series into consecutive UTC hours and averages
+// each bucket. Hours with no runs repeat the previous value so gaps don't
+// read as trend.
+func hourlyMeans(series []model.TotalRiskPoint) []float64 {
+	if len(series) == 0 {
+		return nil
+	}
+
+	start := time.UnixMilli(series[0].Timestamp).UTC().Truncate(time.Hour)
+	end := time.UnixMilli(series[len(series)-1].Timestamp).UTC().Truncate(time.Hour)
+	n := int(end.Sub(start)/time.Hour) + 1
+
+	sums := make([]float64, n)
+	counts := make([]int, n)
+	for _, p := range series {
+		i := int(time.UnixMilli(p.Timestamp).UTC().Sub(start) / time.Hour)
+		if i < 0 || i >= n {
+			continue
+		}
+		sums[i] += float64(p.Risk)
+		counts[i]++
+	}
+
+	means := make([]float64, n)
+	for i := range means {
+		switch {
+		case counts[i] > 0:
+			means[i] = sums[i] / float64(counts[i])
+		case i > 0:
+			means[i] = means[i-1]
+		}
+	}
+	return means
+}
+
+func clampRisk(v float64) int {
+	return int(math.Round(math.Max(0, math.Min(100, v))))
+}
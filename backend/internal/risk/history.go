@@ -2,6 +2,7 @@ package risk
 
 import (
 	"log/slog"
+	"sort"
 	"time"
 
 	"github.com/backyonatan-alt/aegis/backend/internal/model"
@@ -13,9 +14,9 @@ func UpdateHistory(current map[string]any, scores model.RiskScores, raw model.Ra
 	now := time.Now()
 
 	// Extract existing signal histories
-	signalHistory := map[string][]int{
-		"news": {}, "connectivity": {}, "flight": {}, "tanker": {},
-		"pentagon": {}, "polymarket": {}, "weather": {},
+	signalHistory := make(map[string][]int, len(scores.Signals))
+	for _, s := range scores.Signals {
+		signalHistory[s.Name] = []int{}
 	}
 
 	// Extract existing total risk history
@@ -28,9 +29,10 @@ func UpdateHistory(current map[string]any, scores model.RiskScores, raw model.Ra
 				for _, item := range hist {
 					if mp, ok := item.(map[string]any); ok {
 						point := model.TotalRiskPoint{
-							Timestamp: int64(getFloat64(mp, "timestamp")),
-							Risk:      getIntVal(mp, "risk"),
-							Pinned:    getBoolVal(mp, "pinned"),
+							Timestamp:   int64(getFloat64(mp, "timestamp")),
+							Risk:        getIntVal(mp, "risk"),
+							Uncertainty: getIntVal(mp, "uncertainty"),
+							Pinned:      getBoolVal(mp, "pinned"),
 						}
 						totalRiskHistory = append(totalRiskHistory, point)
 					}
@@ -69,20 +71,10 @@ func UpdateHistory(current map[string]any, scores model.RiskScores, raw model.Ra
 	}
 
 	// Append current scores to signal histories
-	signalScores := map[string]int{
-		"news":         scores.News.Risk,
-		"connectivity": scores.Connectivity.Risk,
-		"flight":       scores.Flight.Risk,
-		"tanker":       scores.Tanker.Risk,
-		"pentagon":     scores.Pentagon.Risk,
-		"polymarket":   scores.Polymarket.Risk,
-		"weather":      scores.Weather.Risk,
-	}
-
-	for sig, risk := range signalScores {
-		signalHistory[sig] = append(signalHistory[sig], risk)
-		if len(signalHistory[sig]) > 20 {
-			signalHistory[sig] = signalHistory[sig][len(signalHistory[sig])-20:]
+	for _, s := range scores.Signals {
+		signalHistory[s.Name] = append(signalHistory[s.Name], s.Risk)
+		if len(signalHistory[s.Name]) > 20 {
+			signalHistory[s.Name] = signalHistory[s.Name][len(signalHistory[s.Name])-20:]
 		}
 	}
 
@@ -90,13 +82,7 @@ func UpdateHistory(current map[string]any, scores model.RiskScores, raw model.Ra
 	currentTimestamp := now.UnixMilli()
 	totalRisk := scores.TotalRisk
 
-	var currentBoundary time.Time
-	if now.Hour() >= 12 {
-		currentBoundary = time.Date(now.Year(), now.Month(), now.Day(), 12, 0, 0, 0, now.Location())
-	} else {
-		currentBoundary = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	}
-	currentBoundaryTS := currentBoundary.UnixMilli()
+	currentBoundaryTS := halfDayBoundary(now).UnixMilli()
 
 	if len(totalRiskHistory) > 0 {
 		lastPoint := totalRiskHistory[len(totalRiskHistory)-1]
@@ -109,82 +95,107 @@ func UpdateHistory(current map[string]any, scores model.RiskScores, raw model.Ra
 			}
 			if len(totalRiskHistory) > 0 {
 				totalRiskHistory[len(totalRiskHistory)-1] = model.TotalRiskPoint{
-					Timestamp: currentBoundaryTS,
-					Risk:      lastPoint.Risk,
-					Pinned:    true,
+					Timestamp:   currentBoundaryTS,
+					Risk:        lastPoint.Risk,
+					Uncertainty: lastPoint.Uncertainty,
+					Pinned:      true,
 				}
 			}
 			totalRiskHistory = append(totalRiskHistory, model.TotalRiskPoint{
-				Timestamp: currentTimestamp,
-				Risk:      totalRisk,
+				Timestamp:   currentTimestamp,
+				Risk:        totalRisk,
+				Uncertainty: scores.Uncertainty,
 			})
 		} else {
 			slog.Info("history: updating last point in-place")
 			totalRiskHistory[len(totalRiskHistory)-1] = model.TotalRiskPoint{
-				Timestamp: currentTimestamp,
-				Risk:      totalRisk,
+				Timestamp:   currentTimestamp,
+				Risk:        totalRisk,
+				Uncertainty: scores.Uncertainty,
 			}
 		}
 	} else {
 		slog.Info("history: starting fresh with single point")
 		totalRiskHistory = []model.TotalRiskPoint{
-			{Timestamp: currentTimestamp, Risk: totalRisk},
+			{Timestamp: currentTimestamp, Risk: totalRisk, Uncertainty: scores.Uncertainty},
 		}
 	}
 
 	slog.Info("history points", "count", len(totalRiskHistory))
 
 	// Build final snapshot
-	return model.Snapshot{
-		News: model.Signal{
-			Risk:    scores.News.Risk,
-			Detail:  scores.News.Detail,
-			History: signalHistory["news"],
-			RawData: ensureMap(raw.News),
-		},
-		Connectivity: model.Signal{
-			Risk:    scores.Connectivity.Risk,
-			Detail:  scores.Connectivity.Detail,
-			History: signalHistory["connectivity"],
-			RawData: ensureMap(raw.Connectivity),
-		},
-		Flight: model.Signal{
-			Risk:    scores.Flight.Risk,
-			Detail:  scores.Flight.Detail,
-			History: signalHistory["flight"],
-			RawData: ensureMap(raw.Flight),
-		},
-		Tanker: model.Signal{
-			Risk:    scores.Tanker.Risk,
-			Detail:  scores.Tanker.Detail,
-			History: signalHistory["tanker"],
-			RawData: ensureMap(raw.Tanker),
-		},
-		Weather: model.Signal{
-			Risk:    scores.Weather.Risk,
-			Detail:  scores.Weather.Detail,
-			History: signalHistory["weather"],
-			RawData: ensureMap(raw.Weather),
-		},
-		Polymarket: model.Signal{
-			Risk:    scores.Polymarket.Risk,
-			Detail:  scores.Polymarket.Detail,
-			History: signalHistory["polymarket"],
-			RawData: ensureMap(raw.Polymarket),
-		},
-		Pentagon: model.Signal{
-			Risk:    scores.Pentagon.Risk,
-			Detail:  scores.Pentagon.Detail,
-			History: signalHistory["pentagon"],
-			RawData: ensureMap(raw.Pentagon),
-		},
+	snap := model.Snapshot{
+		Signals: make(map[string]*model.Signal, len(scores.Signals)),
 		TotalRisk: model.TotalRisk{
 			Risk:          totalRisk,
 			History:       totalRiskHistory,
 			ElevatedCount: scores.ElevatedCount,
+			Uncertainty:   scores.Uncertainty,
 		},
-		LastUpdated: now.Format(time.RFC3339),
+		LastUpdated:      now.Format(time.RFC3339),
+		ServerTimezone:   now.Location().String(),
+		ChangesSinceLast: changesSinceLast(current, scores),
+	}
+	for _, s := range scores.Signals {
+		snap.Signals[s.Name] = &model.Signal{
+			Risk:       s.Risk,
+			Detail:     s.Detail,
+			Elevated:   s.Elevated,
+			History:    signalHistory[s.Name],
+			RawData:    ensureMap(raw[s.Name]),
+			Overridden: s.Override != nil,
+			Override:   s.Override,
+		}
+	}
+	return snap
+}
+
+// halfDayBoundary returns the most recent midnight or noon at or before t,
+// in t's location. Total risk history is pinned at these boundaries.
+func halfDayBoundary(t time.Time) time.Time {
+	hour := 0
+	if t.Hour() >= 12 {
+		hour = 12
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location())
+}
+
+// PinBoundary returns the boundary in loc that the last pinned point of a
+// history repinned at t falls on. Repinning the same runs at two instants
+// with one boundary gives the same history.
+func PinBoundary(t time.Time, loc *time.Location) time.Time {
+	return halfDayBoundary(t.In(loc))
+}
+
+// RepinHistory rebuilds a total risk history of the given length with its
+// pinned points on the 12-hour boundaries of loc instead of the server's.
+// series is the per-run total risk, oldest first; each pinned point takes
+// the last run at or before its boundary, and the final point is the latest
+// run. Boundaries before the first run are omitted.
+func RepinHistory(series []model.TotalRiskPoint, now time.Time, loc *time.Location, points int) []model.TotalRiskPoint {
+	if len(series) == 0 || points < 1 {
+		return []model.TotalRiskPoint{}
+	}
+	latest := series[len(series)-1]
+
+	boundary := halfDayBoundary(now.In(loc))
+	boundaries := make([]time.Time, 0, points-1)
+	for i := 0; i < points-1; i++ {
+		boundaries = append(boundaries, boundary)
+		// Step back via the previous instant so DST shifts can't skip a boundary
+		boundary = halfDayBoundary(boundary.Add(-time.Minute))
+	}
+
+	history := make([]model.TotalRiskPoint, 0, points)
+	for i := len(boundaries) - 1; i >= 0; i-- {
+		ts := boundaries[i].UnixMilli()
+		j := sort.Search(len(series), func(k int) bool { return series[k].Timestamp > ts })
+		if j == 0 {
+			continue
+		}
+		history = append(history, model.TotalRiskPoint{Timestamp: ts, Risk: series[j-1].Risk, Uncertainty: series[j-1].Uncertainty, Pinned: true})
 	}
+	return append(history, latest)
 }
 
 func getFloat64(m map[string]any, key string) float64 {
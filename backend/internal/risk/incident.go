@@ -0,0 +1,52 @@
+package risk
+
+import (
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	// incidentMinSignals is how many signals must spike in one run for the
+	// run to be recorded as an incident.
+	incidentMinSignals = 3
+	// incidentHeadlines caps the headlines kept with an incident.
+	incidentHeadlines = 5
+)
+
+// DetectIncident reports an incident when at least incidentMinSignals
+// signals rose by changeThreshold points or more since the previous run.
+// The incident keeps those signals' moves and the run's leading headlines,
+// alert articles first.
+func DetectIncident(runID string, now time.Time, changes []model.SignalChange, news model.NewsData, totalRisk int) (model.Incident, bool) {
+	var spikes []model.SignalChange
+	for _, c := range changes {
+		if c.Delta >= changeThreshold {
+			spikes = append(spikes, c)
+		}
+	}
+	if len(spikes) < incidentMinSignals {
+		return model.Incident{}, false
+	}
+
+	headlines := []string{}
+	for _, alerts := range []bool{true, false} {
+		for _, a := range news.Articles {
+			if len(headlines) == incidentHeadlines {
+				break
+			}
+			title, _ := a["title"].(string)
+			if title != "" && getBoolVal(a, "is_alert") == alerts {
+				headlines = append(headlines, title)
+			}
+		}
+	}
+
+	return model.Incident{
+		RunID:      runID,
+		DetectedAt: now,
+		TotalRisk:  totalRisk,
+		Signals:    spikes,
+		Headlines:  headlines,
+	}, true
+}
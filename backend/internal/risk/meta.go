@@ -0,0 +1,37 @@
+package risk
+
+import (
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// Escalation: when at least escalationSignals signals are elevated, the
+// weighted total is multiplied by escalationMultiplier.
+const (
+	escalationSignals    = 3
+	escalationMultiplier = 1.15
+)
+
+// bands are the total risk status bands, highest first.
+var bands = []model.BandMeta{
+	{Name: "imminent", Min: 86},
+	{Name: "high", Min: 61},
+	{Name: "elevated", Min: 31},
+	{Name: "low", Min: 0},
+}
+
+// Meta describes the scoring rules for signals under weights w so clients
+// can render elevated badges and bands the same way the backend does.
+func Meta(signals []model.SignalMeta, w config.Weights) model.Meta {
+	out := make([]model.SignalMeta, len(signals))
+	for i, m := range signals {
+		m.Weight = w[m.Name]
+		out[i] = m
+	}
+	return model.Meta{
+		Signals:              out,
+		EscalationSignals:    escalationSignals,
+		EscalationMultiplier: escalationMultiplier,
+		Bands:                bands,
+	}
+}
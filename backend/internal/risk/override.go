@@ -0,0 +1,36 @@
+package risk
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// ApplyOverrides puts each operator override in place of its signal's
+// computed score and recombines the total. The computed risk stays visible
+// in the detail. Overrides naming an unknown signal are ignored.
+func ApplyOverrides(scores *model.RiskScores, overrides []model.SignalOverride, weights config.Weights) {
+	applied := 0
+	for i := range overrides {
+		o := &overrides[i]
+		for j := range scores.Signals {
+			s := &scores.Signals[j]
+			if s.Name != o.Signal {
+				continue
+			}
+			slog.Info("risk: signal overridden", "signal", o.Signal, "risk", o.Risk, "computed", s.Risk, "expires_at", o.ExpiresAt)
+			s.SignalScore = model.SignalScore{
+				Risk:     o.Risk,
+				Detail:   fmt.Sprintf("Held at %d by operator: %s (computed %d)", o.Risk, o.Reason, s.Risk),
+				Elevated: o.Risk >= s.ElevatedMin,
+				Override: o,
+			}
+			applied++
+		}
+	}
+	if applied > 0 {
+		combine(scores, weights, nil)
+	}
+}
@@ -0,0 +1,25 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// unknownSpread is how far, in points, a signal with no trustworthy data
+// may sit from the value it was scored at: half the scale either way.
+const unknownSpread = 50
+
+// Uncertainty is the half-width of the band around total risk left by the
+// signals that aren't fully trusted. Each contributes its weight times its
+// missing confidence times unknownSpread. Contributions add rather than
+// combine in quadrature, since signals tend to go stale together during
+// one outage.
+func Uncertainty(weights config.Weights, signals map[string]model.SignalQuality) int {
+	var band float64
+	for name, sq := range signals {
+		band += weights[name] * (1 - sq.Confidence) * unknownSpread
+	}
+	return int(math.Round(band))
+}
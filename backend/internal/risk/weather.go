@@ -0,0 +1,57 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// weatherRisk scores how favorable conditions over Tehran are for an air
+// strike (0-100, higher = more favorable). Visibility and cloud cover carry
+// most of the weight; wind, haze and airborne dust degrade the result.
+func weatherRisk(w model.WeatherData, t config.WeatherThresholds) int {
+	// Visibility: linear between the prohibitive and ideal thresholds
+	visScore := 100.0
+	if w.Visibility < t.ClearVisibility {
+		span := float64(t.ClearVisibility - t.MinVisibility)
+		visScore = math.Max(0, float64(w.Visibility-t.MinVisibility)/span*100)
+	}
+
+	// Cloud cover bands: clear, scattered, broken, overcast
+	scattered := (t.ClearClouds + t.MaxClouds) / 2
+	var cloudScore float64
+	switch {
+	case w.Clouds <= t.ClearClouds:
+		cloudScore = 100
+	case w.Clouds <= scattered:
+		cloudScore = 70
+	case w.Clouds < t.MaxClouds:
+		cloudScore = 40
+	default:
+		cloudScore = 10
+	}
+
+	// Wind: calm is ideal, above the limit is marginal for most operations
+	var windScore float64
+	switch {
+	case w.WindSpeed <= t.MaxWind/2:
+		windScore = 100
+	case w.WindSpeed <= t.MaxWind:
+		windScore = 60
+	default:
+		windScore = 20
+	}
+
+	score := visScore*0.4 + cloudScore*0.4 + windScore*0.2
+	// Dust and sand foul optics and engines beyond what visibility shows;
+	// haze mostly costs targeting range
+	switch {
+	case w.Dust:
+		score *= 0.5
+	case w.Haze:
+		score *= 0.8
+	}
+
+	return int(math.Max(0, math.Min(100, math.Round(score))))
+}
@@ -2,41 +2,102 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"time"
 
 	"github.com/backyonatan-alt/aegis/backend/internal/pipeline"
 )
 
-// Scheduler runs the pipeline on a fixed interval.
+// ScoreFunc reports the latest total risk, or false if there is none yet.
+type ScoreFunc func() (risk int, ok bool)
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithIntervals bounds the interval: min while risk is at or above the
+// threshold given to WithScore, max otherwise.
+func WithIntervals(min, max time.Duration) Option {
+	return func(s *Scheduler) {
+		s.min, s.max = min, max
+	}
+}
+
+// WithScore makes the interval follow risk: score is read after each run,
+// and the next run comes after the min interval if it is at or above
+// threshold, or the max interval if below.
+func WithScore(threshold int, score ScoreFunc) Option {
+	return func(s *Scheduler) {
+		s.threshold, s.score = threshold, score
+	}
+}
+
+// Scheduler runs the pipeline on an interval, fixed unless WithScore is
+// given.
 type Scheduler struct {
-	pipeline *pipeline.Pipeline
+	pipeline  *pipeline.Pipeline
+	min, max  time.Duration
+	threshold int
+	score     ScoreFunc
+	stop      chan struct{}
+
+	// interval is the current wait between runs; only Start touches it.
 	interval time.Duration
-	stop     chan struct{}
 }
 
-func New(p *pipeline.Pipeline, interval time.Duration) *Scheduler {
-	return &Scheduler{
+// New returns a scheduler running p every interval, which WithIntervals
+// replaces with a min and max.
+func New(p *pipeline.Pipeline, interval time.Duration, opts ...Option) *Scheduler {
+	s := &Scheduler{
 		pipeline: p,
-		interval: interval,
+		min:      interval,
+		max:      interval,
 		stop:     make(chan struct{}),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.interval = s.max
+	return s
 }
 
 // Start begins the periodic pipeline runs. Blocks until Stop is called.
 func (s *Scheduler) Start(ctx context.Context) {
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	s.adjust()
+	timer := time.NewTimer(s.interval)
+	defer timer.Stop()
+
+	slog.Info("scheduler started", "interval", s.interval, "min", s.min, "max", s.max)
 
-	slog.Info("scheduler started", "interval", s.interval)
+	// Stop also cancels a run in progress, abandoning its fetches
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
 
 	for {
 		select {
-		case <-ticker.C:
-			slog.Info("scheduler: triggering pipeline run")
-			if err := s.pipeline.Run(ctx); err != nil {
-				slog.Error("scheduler: pipeline run failed", "error", err)
+		case <-timer.C:
+			if s.pipeline.Paused() {
+				slog.Info("scheduler: skipping tick, pipeline paused")
+			} else {
+				slog.Info("scheduler: triggering pipeline run")
+				var inProgress *pipeline.RunInProgressError
+				if err := s.pipeline.Run(ctx); errors.As(err, &inProgress) {
+					slog.Warn("scheduler: skipping tick, run already in progress", "run_id", inProgress.RunID)
+				} else if errors.Is(err, pipeline.ErrStopped) {
+					slog.Info("scheduler: skipping tick, pipeline stopped")
+				} else if err != nil {
+					slog.Error("scheduler: pipeline run failed", "error", err)
+				}
 			}
+			s.adjust()
+			timer.Reset(s.interval)
 		case <-s.stop:
 			slog.Info("scheduler stopped")
 			return
@@ -47,6 +108,31 @@ func (s *Scheduler) Start(ctx context.Context) {
 	}
 }
 
+// adjust picks the next interval from the latest score. With no score
+// yet the interval is left as it is.
+func (s *Scheduler) adjust() {
+	if s.score == nil {
+		return
+	}
+	risk, ok := s.score()
+	if !ok {
+		return
+	}
+	next := s.max
+	if risk >= s.threshold {
+		next = s.min
+	}
+	if next == s.interval {
+		return
+	}
+	if next < s.interval {
+		slog.Info("scheduler: tightening interval", "interval", next, "risk", risk, "threshold", s.threshold)
+	} else {
+		slog.Info("scheduler: relaxing interval", "interval", next, "risk", risk, "threshold", s.threshold)
+	}
+	s.interval = next
+}
+
 // Stop signals the scheduler to stop.
 func (s *Scheduler) Stop() {
 	close(s.stop)
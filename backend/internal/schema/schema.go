@@ -0,0 +1,162 @@
+// Package schema holds the JSON Schemas each signal's raw_data follows and
+// checks raw_data against them before it is stored.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect the schemas are written in.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is the subset of JSON Schema the raw_data schemas use. Objects
+// allow properties beyond those listed, so a signal can add fields
+// without breaking consumers; listed ones must have the declared type.
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	ID          string             `json:"$id,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        Types              `json:"type,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	// MaxProperties caps how many members an object may have.
+	MaxProperties *int `json:"maxProperties,omitempty"`
+	// AnyOf lists alternatives, at least one of which must match.
+	AnyOf []*Schema `json:"anyOf,omitempty"`
+}
+
+// Types is a schema's allowed JSON types. One type is written as a plain
+// string, as most schemas do.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Validate checks v, after a JSON round trip so Go values are seen as the
+// JSON they encode to, against s. The error lists every violation found.
+func (s *Schema) Validate(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding: %w", err)
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("decoding: %w", err)
+	}
+	var problems []string
+	s.check("$", doc, &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (s *Schema) check(path string, v any, problems *[]string) {
+	if len(s.AnyOf) > 0 {
+		// Report the last alternative's problems, the most specific one
+		var last []string
+		for _, alt := range s.AnyOf {
+			last = nil
+			if alt.check(path, v, &last); len(last) == 0 {
+				break
+			}
+		}
+		*problems = append(*problems, last...)
+	}
+	if len(s.Type) > 0 && !s.hasType(v) {
+		*problems = append(*problems, fmt.Sprintf("%s: want %s, got %s", path, strings.Join(s.Type, " or "), typeOf(v)))
+		return
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		if s.MaxProperties != nil && len(v) > *s.MaxProperties {
+			*problems = append(*problems, fmt.Sprintf("%s: more than %d members", path, *s.MaxProperties))
+		}
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*problems = append(*problems, fmt.Sprintf("%s: missing %q", path, name))
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if pv, ok := v[name]; ok {
+				s.Properties[name].check(path+"."+name, pv, problems)
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.check(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case string:
+		if len(s.Enum) > 0 && !contains(s.Enum, v) {
+			*problems = append(*problems, fmt.Sprintf("%s: %q is not one of %s", path, v, strings.Join(s.Enum, ", ")))
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			*problems = append(*problems, fmt.Sprintf("%s: %g is below %g", path, v, *s.Minimum))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			*problems = append(*problems, fmt.Sprintf("%s: %g is above %g", path, v, *s.Maximum))
+		}
+	}
+}
+
+func (s *Schema) hasType(v any) bool {
+	got := typeOf(v)
+	for _, t := range s.Type {
+		if t == got || (t == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// typeOf names v's JSON type, telling integers from other numbers.
+func typeOf(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
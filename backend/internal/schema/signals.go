@@ -0,0 +1,279 @@
+package schema
+
+// The schema of each signal's raw_data, named after the signal. A signal's
+// descriptor names its schema, which its snapshots are checked against.
+var (
+	News = object("Matched news articles and feed coverage.",
+		map[string]*Schema{
+			"articles": nullable(array(object("",
+				map[string]*Schema{
+					"title":                 str(),
+					"is_alert":              boolean(),
+					"is_deescalation":       boolean(),
+					"keywords":              nullable(array(str())),
+					"alert_keywords":        nullable(array(str())),
+					"deescalation_keywords": nullable(array(str())),
+				}, "title", "is_alert"))),
+			"total_count":        count(),
+			"alert_count":        count(),
+			"deescalation_count": count(),
+			"timestamp":          str(),
+			"feeds": object("",
+				map[string]*Schema{
+					"ok":   count(),
+					"live": count(),
+					"results": nullable(array(object("",
+						map[string]*Schema{
+							"url":     str(),
+							"fetched": boolean(),
+							"parsed":  boolean(),
+							"items":   count(),
+							"matched": count(),
+							"error":   str(),
+							"dead":    boolean(),
+						}, "url", "fetched", "parsed"))),
+				}, "ok", "live"),
+			"lookback":          str(),
+			"articles_per_hour": number(),
+		}, "articles", "total_count", "alert_count", "deescalation_count", "timestamp", "feeds")
+
+	Connectivity = object("Cloudflare Radar traffic for Iran and neighbouring countries.",
+		map[string]*Schema{
+			"status":   str(),
+			"risk":     number(),
+			"trend":    number(),
+			"values":   nullable(array(number())),
+			"baseline": str(),
+			"networks": nullable(array(object("",
+				map[string]*Schema{
+					"name":     str(),
+					"query":    str(),
+					"domestic": boolean(),
+					"status":   str(),
+					"trend":    number(),
+				}, "name", "status"))),
+			"countries": nullable(array(object("",
+				map[string]*Schema{
+					"code":   str(),
+					"name":   str(),
+					"status": str(),
+					"trend":  number(),
+					"values": nullable(array(number())),
+				}, "code", "status"))),
+			"timestamp": str(),
+			"error":     str(),
+		}, "status", "risk", "trend", "timestamp")
+
+	Flight = object("Civil aviation over the region from OpenSky.",
+		map[string]*Schema{
+			"aircraft_count":   count(),
+			"airline_count":    count(),
+			"airlines":         nullable(array(str())),
+			"major_carriers":   nullable(array(str())),
+			"missing_carriers": nullable(array(str())),
+			"baseline":         number(),
+			"baseline_samples": count(),
+			"timestamp":        str(),
+		}, "aircraft_count", "airline_count", "timestamp")
+
+	Tanker = object("Aerial refuelling tankers over the region from OpenSky.",
+		map[string]*Schema{
+			"tanker_count":     count(),
+			"callsigns":        nullable(array(str())),
+			"baseline":         number(),
+			"baseline_samples": count(),
+			"timestamp":        str(),
+		}, "tanker_count", "timestamp")
+
+	Weather = object("Current weather over Tehran.",
+		map[string]*Schema{
+			"temp":         integer(),
+			"visibility":   count(),
+			"clouds":       percent(),
+			"wind_speed":   number(),
+			"condition_id": integer(),
+			"dust":         boolean(),
+			"haze":         boolean(),
+			"description":  str(),
+			"condition":    enum("Favorable", "Marginal", "Poor"),
+			"provider":     str(),
+			"source":       str(),
+			"timestamp":    str(),
+			"imagery": object("",
+				map[string]*Schema{
+					"layers":      object("", nil),
+					"bounds":      array(number()),
+					"min_zoom":    integer(),
+					"max_zoom":    integer(),
+					"attribution": str(),
+				}, "layers", "bounds"),
+		}, "temp", "visibility", "clouds", "wind_speed", "condition_id", "condition", "timestamp")
+
+	Polymarket = object("Polymarket odds of a strike.",
+		map[string]*Schema{
+			"odds":     percent(),
+			"market":   str(),
+			"token_id": str(),
+			"order_book": object("",
+				map[string]*Schema{
+					"best_bid":   number(),
+					"best_ask":   number(),
+					"spread":     number(),
+					"bid_depth":  number(),
+					"ask_depth":  number(),
+					"depth_band": number(),
+				}, "best_bid", "best_ask"),
+			"timestamp": str(),
+		}, "odds", "market", "timestamp")
+
+	Pentagon = object("Late-night activity at pizza places near the Pentagon and other watched facilities.",
+		map[string]*Schema{
+			"score":             integer(),
+			"risk_contribution": integer(),
+			"status":            str(),
+			"places":            nullable(array(object("", nil))),
+			"timestamp":         str(),
+			"is_late_night":     boolean(),
+			"is_weekend":        boolean(),
+			"cluster":           str(),
+			"clusters": array(object("",
+				map[string]*Schema{
+					"name":          str(),
+					"score":         integer(),
+					"status":        str(),
+					"places":        nullable(array(object("", nil))),
+					"is_late_night": boolean(),
+					"is_weekend":    boolean(),
+				}, "name", "score", "status")),
+		}, "score", "risk_contribution", "status", "timestamp")
+
+	Attention = object("Public attention relative to baseline, by source.",
+		map[string]*Schema{
+			"components": nullable(array(object("",
+				map[string]*Schema{
+					"source": str(),
+					"ratio":  number(),
+					"detail": str(),
+				}, "source", "ratio"))),
+			"timestamp": str(),
+		}, "components", "timestamp")
+
+	Geopolitics = object("GDELT coverage of conflict between Iran, Israel and the US.",
+		map[string]*Schema{
+			"article_count":    count(),
+			"baseline_per_day": number(),
+			"ratio":            number(),
+			"average_tone":     number(),
+			"baseline_tone":    number(),
+			"timestamp":        str(),
+		}, "article_count", "baseline_per_day", "ratio", "average_tone", "timestamp")
+
+	Shipping = object("Vessels under way in the Strait of Hormuz, from AISHub.",
+		map[string]*Schema{
+			"vessel_count":     count(),
+			"tanker_count":     count(),
+			"baseline":         number(),
+			"tanker_baseline":  number(),
+			"baseline_samples": count(),
+			"timestamp":        str(),
+		}, "vessel_count", "tanker_count", "timestamp")
+
+	Airspace = object("NOTAMs in force over Iran, Israel and the Gulf, from the FAA NOTAM API.",
+		map[string]*Schema{
+			"notam_count": count(),
+			"closures": array(object("",
+				map[string]*Schema{
+					"location": str(),
+					"kind":     enum("fir", "aerodrome"),
+					"number":   str(),
+				}, "location", "kind")),
+			"restrictions": count(),
+			"warnings":     count(),
+			"unavailable":  array(str()),
+			"timestamp":    str(),
+		}, "notam_count", "closures", "restrictions", "warnings", "timestamp")
+
+	Seismic = object("USGS seismic events of the last day over Iran, with those unlike natural seismicity marked suspicious.",
+		map[string]*Schema{
+			"event_count": count(),
+			"suspicious":  count(),
+			"events": array(object("",
+				map[string]*Schema{
+					"id":         str(),
+					"place":      str(),
+					"magnitude":  number(),
+					"depth_km":   number(),
+					"lat":        number(),
+					"lon":        number(),
+					"time":       str(),
+					"type":       str(),
+					"suspicious": boolean(),
+				}, "id", "magnitude", "depth_km", "time", "suspicious")),
+			"timestamp": str(),
+		}, "event_count", "suspicious", "events", "timestamp")
+
+	GPS = object("Aircraft reporting degraded GPS integrity over Israel, Iran and the Gulf, from adsb.lol.",
+		map[string]*Schema{
+			"zones": array(object("",
+				map[string]*Schema{
+					"name":     str(),
+					"aircraft": count(),
+					"degraded": count(),
+					"ratio":    number(),
+				}, "name", "aircraft", "degraded", "ratio")),
+			"unavailable": array(str()),
+			"timestamp":   str(),
+		}, "zones", "timestamp")
+)
+
+// Publish returns a signal's raw_data schema as served, marked with its
+// dialect and a title. It also allows the empty object a signal stores
+// until its first successful fetch.
+func Publish(signal string, s *Schema) *Schema {
+	none := 0
+	empty := &Schema{Type: Types{"object"}, Description: "No data yet.", MaxProperties: &none}
+	return &Schema{Schema: Draft, Title: signal + " raw_data", AnyOf: []*Schema{empty, s}}
+}
+
+// Check validates one signal's raw_data against s. An empty map means the
+// signal has no data yet and is always accepted, as the published schemas
+// allow.
+func Check(s *Schema, raw map[string]any) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return s.Validate(raw)
+}
+
+func object(description string, properties map[string]*Schema, required ...string) *Schema {
+	return &Schema{Type: Types{"object"}, Description: description, Properties: properties, Required: required}
+}
+
+func array(items *Schema) *Schema { return &Schema{Type: Types{"array"}, Items: items} }
+
+// nullable allows null as well, which is how Go encodes a nil slice.
+func nullable(s *Schema) *Schema {
+	s.Type = append(s.Type, "null")
+	return s
+}
+
+func str() *Schema     { return &Schema{Type: Types{"string"}} }
+func boolean() *Schema { return &Schema{Type: Types{"boolean"}} }
+func number() *Schema  { return &Schema{Type: Types{"number"}} }
+func integer() *Schema { return &Schema{Type: Types{"integer"}} }
+
+func enum(values ...string) *Schema {
+	return &Schema{Type: Types{"string"}, Enum: values}
+}
+
+// count is a non-negative integer.
+func count() *Schema {
+	zero := 0.0
+	return &Schema{Type: Types{"integer"}, Minimum: &zero}
+}
+
+// percent is an integer from 0 to 100.
+func percent() *Schema {
+	zero, hundred := 0.0, 100.0
+	return &Schema{Type: Types{"integer"}, Minimum: &zero, Maximum: &hundred}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/pipeline"
+	"github.com/backyonatan-alt/aegis/backend/internal/report"
+)
+
+// requireAdmin checks the bearer token against the configured admin token.
+// Admin routes respond 404 when no token is configured.
+func (s *Server) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.cfg.AdminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+	if !s.adminAuthorized(r, false) {
+		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// adminAuthorized reports whether the request carries the admin token as a
+// bearer token or, with basic, as the password of HTTP Basic credentials.
+// Only dashboard routes accept Basic: browsers resend it on cross-site
+// requests, and those routes reject cross-site posts.
+func (s *Server) adminAuthorized(r *http.Request, basic bool) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		if !basic {
+			return false
+		}
+		_, token, _ = r.BasicAuth()
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AdminToken)) == 1
+}
+
+func (s *Server) handleAdminRun(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+	// GET reports the active run, if any
+	if r.Method != http.MethodPost {
+		runID := s.pipeline.ActiveRun()
+		json.NewEncoder(w).Encode(map[string]any{
+			"running": runID != "",
+			"run_id":  runID,
+		})
+		return
+	}
+
+	runID, err := s.pipeline.Trigger()
+	var inProgress *pipeline.RunInProgressError
+	if errors.As(err, &inProgress) {
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]any{
+			"error":  "run already in progress",
+			"run_id": inProgress.RunID,
+		})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to trigger pipeline run", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	slog.Info("manual pipeline run triggered", "run_id", runID)
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]any{"run_id": runID})
+}
+
+// handleAdminModelReport serves the latest model diagnostics report. POST
+// generates a fresh one first.
+func (s *Server) handleAdminModelReport(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+	if r.Method == http.MethodPost {
+		rep, err := report.Generate(r.Context(), s.store, s.cfg)
+		if err != nil {
+			slog.Error("failed to generate model report", "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(rep)
+		return
+	}
+
+	data, err := s.store.LatestModelReport(r.Context())
+	if err != nil {
+		slog.Error("failed to load model report", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, `{"error":"no report available"}`, http.StatusNotFound)
+		return
+	}
+	w.Write(data)
+}
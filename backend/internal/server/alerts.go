@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// AlertChannel is a notification channel that can send a test alert for
+// the crossing from snapshot prev to cur, reporting one result per
+// destination.
+type AlertChannel interface {
+	TestAlert(ctx context.Context, prev, cur []byte) []model.AlertTestResult
+}
+
+// SetAlertChannels attaches the channels test alerts are sent through.
+func (s *Server) SetAlertChannels(channels ...AlertChannel) {
+	s.alerts = channels
+}
+
+type alertTestRequest struct {
+	Previous *int `json:"previous"`
+	Current  *int `json:"current"`
+}
+
+// handleAdminAlertTest sends a synthetic threshold crossing, marked as a
+// test, through every configured notification channel and reports how each
+// fared. The crossing defaults to low (20) to high (70); a body such as
+// {"previous":50,"current":90} picks another. The rest of the event is the
+// current snapshot.
+func (s *Server) handleAdminAlertTest(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	var req alertTestRequest
+	if r.ContentLength != 0 && !decodeJSON(w, r, &req, maxBodyBytes) {
+		return
+	}
+	previous, current := 20, 70
+	if req.Previous != nil {
+		previous = *req.Previous
+	}
+	if req.Current != nil {
+		current = *req.Current
+	}
+	if previous < 0 || previous > 100 || current < 0 || current > 100 || risk.Band(previous) == risk.Band(current) {
+		http.Error(w, `{"error":"previous and current must be 0-100 and in different bands"}`, http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.snapshot(r.Context())
+	if err != nil {
+		slog.Error("failed to load snapshot for test alert", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	prev, err := withTotalRisk(data, previous)
+	if err == nil {
+		data, err = withTotalRisk(data, current)
+	}
+	if err != nil {
+		slog.Error("failed to build test alert", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	results := []model.AlertTestResult{}
+	for _, ch := range s.alerts {
+		results = append(results, ch.TestAlert(r.Context(), prev, data)...)
+	}
+	failed := 0
+	for _, res := range results {
+		if !res.OK {
+			failed++
+		}
+	}
+	slog.Info("test alert sent", "previous", previous, "current", current, "destinations", len(results), "failed", failed)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]any{
+		"previous": previous,
+		"current":  current,
+		"results":  results,
+	})
+}
+
+// withTotalRisk returns the snapshot with its total risk replaced, keeping
+// every other field as served. A nil snapshot gives one with only total
+// risk.
+func withTotalRisk(data []byte, total int) ([]byte, error) {
+	snap := map[string]any{}
+	if data != nil {
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return nil, err
+		}
+	}
+	tr, _ := snap["total_risk"].(map[string]any)
+	if tr == nil {
+		tr = map[string]any{}
+	}
+	tr["risk"] = total
+	snap["total_risk"] = tr
+	return json.Marshal(snap)
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hotState remembers whether the snapshot with a given hash is above the
+// hot-risk threshold, so the snapshot is decoded once per version rather
+// than on every request.
+type hotState struct {
+	mu   sync.Mutex
+	hash string
+	hot  bool
+}
+
+// dataCacheControl returns the Cache-Control value for serving data, a
+// snapshot with the given content hash.
+func (s *Server) dataCacheControl(data []byte, hash string) string {
+	c := s.cfg.DataCache
+	if s.isHot(data, hash) {
+		return cacheControl(c.HotMaxAge, c.HotSMaxAge)
+	}
+	return cacheControl(c.MaxAge, c.SMaxAge)
+}
+
+func (s *Server) isHot(data []byte, hash string) bool {
+	s.hot.mu.Lock()
+	defer s.hot.mu.Unlock()
+	if s.hot.hash != hash {
+		var snap struct {
+			TotalRisk struct {
+				Risk int `json:"risk"`
+			} `json:"total_risk"`
+		}
+		json.Unmarshal(data, &snap)
+		s.hot.hash = hash
+		s.hot.hot = snap.TotalRisk.Risk >= s.cfg.DataCache.HotMinRisk
+	}
+	return s.hot.hot
+}
+
+func cacheControl(maxAge, sMaxAge time.Duration) string {
+	return fmt.Sprintf("public, max-age=%d, s-maxage=%d", int(maxAge.Seconds()), int(sMaxAge.Seconds()))
+}
+
+// notModified reports whether r's conditional headers say the client
+// already holds the response with etag, last modified at modified. As in
+// RFC 9110, If-Modified-Since is ignored when If-None-Match is present.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if modified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+type cacheMetaResponse struct {
+	Empty      bool   `json:"empty"`
+	Hash       string `json:"hash,omitempty"`
+	SizeBytes  int    `json:"size_bytes"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+	AgeSeconds int    `json:"age_seconds"`
+	// Entries are the snapshot versions held for /api/data/delta, keyed by
+	// hash, oldest first.
+	Entries []cacheEntry `json:"entries"`
+	Deltas  int          `json:"deltas"`
+	// Compressed lists stored compressed variants. Responses are gzipped as
+	// they are written, so there are none yet.
+	Compressed []string `json:"compressed"`
+}
+
+type cacheEntry struct {
+	Key       string `json:"key"`
+	SizeBytes int    `json:"size_bytes"`
+	Current   bool   `json:"current"`
+}
+
+// handleAdminCache describes the in-memory snapshot cache (GET) or purges
+// it (DELETE), so stale data stuck in memory can be found and cleared
+// without a restart. After a purge the next read reloads the latest
+// snapshot from the database.
+func (s *Server) handleAdminCache(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if r.Method == http.MethodDelete {
+		st := s.cache.Stats()
+		s.cache.Purge()
+		s.deltas.reset()
+		s.repins.reset()
+		slog.Info("snapshot cache purged", "hash", st.Hash, "versions", len(st.Versions))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	st := s.cache.Stats()
+	resp := cacheMetaResponse{
+		Empty:      st.UpdatedAt.IsZero(),
+		Hash:       st.Hash,
+		SizeBytes:  st.Size,
+		Entries:    make([]cacheEntry, len(st.Versions)),
+		Deltas:     s.deltas.size(),
+		Compressed: []string{},
+	}
+	if !resp.Empty {
+		resp.UpdatedAt = st.UpdatedAt.UTC().Format(time.RFC3339)
+		resp.AgeSeconds = int(time.Since(st.UpdatedAt).Seconds())
+	}
+	for i, v := range st.Versions {
+		resp.Entries[i] = cacheEntry{Key: v.Hash, SizeBytes: v.Size, Current: v.Hash == st.Hash}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressibleTypes are the media types worth compressing. Images, tiles
+// and event streams are left alone: the first two are compressed already
+// and a stream must reach the client as each event is written.
+var compressibleTypes = map[string]bool{
+	"application/json":       true,
+	"application/javascript": true,
+	"image/svg+xml":          true,
+	"text/css":               true,
+	"text/html":              true,
+	"text/plain":             true,
+	"text/csv":               true,
+}
+
+var gzipWriters = sync.Pool{
+	New: func() any {
+		gz, _ := gzip.NewWriterLevel(nil, gzip.BestSpeed)
+		return gz
+	},
+}
+
+// compressMiddleware gzips responses for clients that accept it, when the
+// content type is compressible and the body reaches Compression.MinBytes.
+// Smaller bodies are buffered until the handler finishes and sent as they
+// are, since gzip would barely shrink them.
+func (s *Server) compressMiddleware(next http.Handler) http.Handler {
+	if !s.cfg.Compression.Enabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsGzip(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		cw := &compressWriter{ResponseWriter: w, minBytes: s.cfg.Compression.MinBytes}
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptsGzip reports whether r's Accept-Encoding allows gzip.
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(enc, ";")
+		if strings.TrimSpace(name) != "gzip" {
+			continue
+		}
+		// gzip;q=0 explicitly refuses it
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(v, 64)
+			return err == nil && q > 0
+		}
+		return true
+	}
+	return false
+}
+
+// compressWriter decides on the first write whether to compress. Until a
+// compressible body reaches minBytes it is held in buf; after that it goes
+// through gz, or straight to the client if it isn't being compressed.
+type compressWriter struct {
+	http.ResponseWriter
+	minBytes int
+
+	status  int
+	decided bool
+	// pending holds a compressible body until it is known to be big enough.
+	pending bool
+	buf     []byte
+	gz      *gzip.Writer
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	if c.status != 0 {
+		return
+	}
+	c.status = status
+	h := c.Header()
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified ||
+		h.Get("Content-Encoding") != "" || !compressible(h.Get("Content-Type")) {
+		c.decided = true
+		c.ResponseWriter.WriteHeader(status)
+		return
+	}
+	h.Add("Vary", "Accept-Encoding")
+	c.pending = true
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		if c.Header().Get("Content-Type") == "" {
+			c.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		c.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case c.gz != nil:
+		return c.gz.Write(p)
+	case c.decided:
+		return c.ResponseWriter.Write(p)
+	}
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= c.minBytes {
+		if err := c.startGzip(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// startGzip switches a pending response to gzip and compresses what has
+// been buffered.
+func (c *compressWriter) startGzip() error {
+	h := c.Header()
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	// The encoded bytes differ from those the ETag was computed over
+	if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		h.Set("ETag", "W/"+etag)
+	}
+	c.ResponseWriter.WriteHeader(c.status)
+	c.pending, c.decided = false, true
+
+	c.gz = gzipWriters.Get().(*gzip.Writer)
+	c.gz.Reset(c.ResponseWriter)
+	buf := c.buf
+	c.buf = nil
+	_, err := c.gz.Write(buf)
+	return err
+}
+
+// sendPending sends a body that stayed under minBytes uncompressed.
+func (c *compressWriter) sendPending() error {
+	c.pending, c.decided = false, true
+	c.ResponseWriter.WriteHeader(c.status)
+	buf := c.buf
+	c.buf = nil
+	_, err := c.ResponseWriter.Write(buf)
+	return err
+}
+
+// Flush sends whatever has been written so far, compressed if the body
+// already is.
+func (c *compressWriter) Flush() {
+	if c.pending {
+		c.sendPending()
+	}
+	if c.gz != nil {
+		c.gz.Flush()
+	}
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close finishes the response: it sends a body left under minBytes, or
+// ends the gzip stream and returns its writer to the pool.
+func (c *compressWriter) Close() error {
+	if c.pending {
+		return c.sendPending()
+	}
+	if c.gz == nil {
+		return nil
+	}
+	err := c.gz.Close()
+	c.gz.Reset(nil)
+	gzipWriters.Put(c.gz)
+	c.gz = nil
+	return err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, for
+// deadlines.
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// compressible reports whether a Content-Type value is one worth gzipping.
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && compressibleTypes[mediaType]
+}
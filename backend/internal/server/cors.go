@@ -2,28 +2,82 @@ package server
 
 import (
 	"net/http"
+	"slices"
 	"strings"
 )
 
+// publicPaths are served with a wildcard origin.
+var publicPaths = map[string]bool{
+	"/api/embed":        true,
+	"/api/v1/indicator": true,
+}
+
+// corsMiddleware applies one of three policies by route group:
+//   - admin routes answer only the exact CORS.AdminOrigins, with credentials;
+//   - public reads (GET/HEAD) answer CORS.PublicOrigins, without credentials;
+//   - anything else, such as pulse beacons, keeps the site's AllowedOrigins.
 func (s *Server) corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
-		allowedOrigin := s.getAllowedOrigin(origin)
+		h := w.Header()
+		h.Add("Vary", "Origin")
 
-		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-		w.Header().Set("Access-Control-Max-Age", "86400")
-
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
+		switch {
+		case isAdminPath(r.URL.Path):
+			if origin != "" && slices.Contains(s.cfg.CORS.AdminOrigins, origin) {
+				h.Set("Access-Control-Allow-Origin", origin)
+				h.Set("Access-Control-Allow-Credentials", "true")
+				h.Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				h.Set("Access-Control-Max-Age", "600")
+			}
+		case publicPaths[r.URL.Path]:
+			// Public routes (embeds) may be loaded from any site
+			h.Set("Access-Control-Allow-Origin", "*")
+			s.setPublicHeaders(h)
+		case isReadRequest(r):
+			h.Set("Access-Control-Allow-Origin", s.publicReadOrigin(origin))
+			s.setPublicHeaders(h)
+		default:
+			h.Set("Access-Control-Allow-Origin", s.getAllowedOrigin(origin))
+			s.setPublicHeaders(h)
 		}
 
+		// OPTIONS preflights are answered per route with the allowed methods
 		next.ServeHTTP(w, r)
 	})
 }
 
+func (s *Server) setPublicHeaders(h http.Header) {
+	h.Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key")
+	h.Set("Access-Control-Expose-Headers", "X-Snapshot-Hash")
+	h.Set("Access-Control-Max-Age", "86400")
+}
+
+func isAdminPath(path string) bool {
+	return path == "/admin" || strings.HasPrefix(path, "/admin/") || strings.HasPrefix(path, "/api/admin/")
+}
+
+// isReadRequest reports whether r is a GET or HEAD, or a preflight for one.
+func isReadRequest(r *http.Request) bool {
+	method := r.Method
+	if method == http.MethodOptions {
+		method = r.Header.Get("Access-Control-Request-Method")
+	}
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// publicReadOrigin picks the Allow-Origin for a public read: "*" when any
+// site may read, the origin itself when listed, else the site policy.
+func (s *Server) publicReadOrigin(origin string) string {
+	if slices.Contains(s.cfg.CORS.PublicOrigins, "*") {
+		return "*"
+	}
+	if origin != "" && slices.Contains(s.cfg.CORS.PublicOrigins, origin) {
+		return origin
+	}
+	return s.getAllowedOrigin(origin)
+}
+
 func (s *Server) getAllowedOrigin(origin string) string {
 	if origin == "" {
 		return s.cfg.AllowedOrigins[0]
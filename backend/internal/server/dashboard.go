@@ -0,0 +1,388 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/pipeline"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+)
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Aegis operator dashboard</title>
+<style>
+body { font: 14px/1.4 system-ui, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 4px 12px 4px 0; border-bottom: 1px solid #ddd; vertical-align: top; }
+.ok { color: #1a7f37; } .fallback { color: #9a6700; } .missing, .error { color: #cf222e; }
+form { display: inline; margin-right: 8px; }
+.flash { background: #fff8c5; padding: 6px 10px; display: inline-block; }
+</style>
+</head>
+<body>
+<h1>Aegis operator dashboard</h1>
+{{with .Flash}}<p class="flash">{{.}}</p>{{end}}
+<p>
+{{if .ActiveRun}}Run <code>{{.ActiveRun}}</code> in progress.{{else}}Idle.{{end}}
+Scheduled runs are <strong>{{if .Paused}}paused{{else}}active{{end}}</strong>.
+Cache age: {{.CacheAge}}.
+</p>
+<form method="post" action="/admin/refresh"><button>Refresh now</button></form>
+{{if .Paused}}<form method="post" action="/admin/resume"><button>Resume schedule</button></form>
+{{else}}<form method="post" action="/admin/pause"><button>Pause schedule</button></form>{{end}}
+
+<h2>Fetcher health</h2>
+{{if .Signals}}<table>
+<tr><th>Signal</th><th>Status</th><th>Data age</th><th>Last error</th></tr>
+{{range .Signals}}<tr><td>{{.Name}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Age}}</td><td class="error">{{.Error}}</td></tr>
+{{end}}</table>{{else}}<p>No snapshot cached yet.</p>{{end}}
+
+<h2>Recent runs</h2>
+{{if .Runs}}<table>
+<tr><th>Run</th><th>Started</th><th>Duration</th><th>Total risk</th><th>Failed fetches</th><th>Error</th></tr>
+{{range .Runs}}<tr><td><code>{{.ID}}</code></td><td>{{.StartedAt.UTC.Format "2006-01-02 15:04:05"}}</td><td>{{.Duration}}</td><td>{{.TotalRisk}}</td><td>{{.Failed}}</td><td class="error">{{.Error}}</td></tr>
+{{end}}</table>{{else}}<p>No runs since startup.</p>{{end}}
+
+<h2>Configuration</h2>
+<table>
+{{range .Config}}<tr><th>{{.Key}}</th><td>{{.Value}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+type dashboardPage struct {
+	Flash     string
+	ActiveRun string
+	Paused    bool
+	CacheAge  string
+	Signals   []dashboardSignal
+	Runs      []dashboardRun
+	Config    []dashboardSetting
+}
+
+type dashboardSignal struct {
+	Name   string
+	Status string
+	Age    string
+	Error  string
+}
+
+type dashboardRun struct {
+	pipeline.RunSummary
+	Failed string
+}
+
+type dashboardSetting struct {
+	Key   string
+	Value string
+}
+
+// requireDashboard is requireAdmin for browser routes: it challenges for
+// Basic credentials (any username, the admin token as password) and rejects
+// cross-site form posts, since browsers resend those credentials on their own.
+func (s *Server) requireDashboard(w http.ResponseWriter, r *http.Request) bool {
+	if s.cfg.AdminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+	if !s.adminAuthorized(r, true) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="aegis admin", charset="UTF-8"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	if r.Method == http.MethodPost && !sameOrigin(r) {
+		http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// sameOrigin reports whether a browser request came from this host. Requests
+// without an Origin header (non-browser clients) are allowed.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return r.Header.Get("Sec-Fetch-Site") != "cross-site"
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if !s.requireDashboard(w, r) {
+		return
+	}
+
+	page := dashboardPage{
+		Flash:     r.URL.Query().Get("flash"),
+		ActiveRun: s.pipeline.ActiveRun(),
+		Paused:    s.pipeline.Paused(),
+		CacheAge:  "empty",
+		Config:    s.dashboardConfig(),
+	}
+	if updatedAt := s.cache.UpdatedAt(); !updatedAt.IsZero() {
+		page.CacheAge = time.Since(updatedAt).Round(time.Second).String()
+	}
+
+	runs := s.pipeline.Runs()
+	var lastErrors map[string]pipeline.FetchFailure
+	if len(runs) > 0 {
+		lastErrors = runs[0].FetchErrors
+	}
+	for _, run := range runs {
+		failed := make([]string, 0, len(run.FetchErrors))
+		for name, f := range run.FetchErrors {
+			failed = append(failed, name+" ("+string(f.Kind)+")")
+		}
+		sort.Strings(failed)
+		page.Runs = append(page.Runs, dashboardRun{RunSummary: run, Failed: strings.Join(failed, ", ")})
+	}
+
+	if data := s.cache.Get(); data != nil {
+		var snap struct {
+			DataQuality *model.DataQuality `json:"data_quality"`
+		}
+		if err := json.Unmarshal(data, &snap); err != nil {
+			slog.Warn("failed to parse cached snapshot for dashboard", "error", err)
+		} else if snap.DataQuality != nil {
+			for name, q := range snap.DataQuality.Signals {
+				page.Signals = append(page.Signals, dashboardSignal{
+					Name:   name,
+					Status: q.Status,
+					Age:    (time.Duration(q.AgeSeconds) * time.Second).String(),
+				})
+				if f, ok := lastErrors[name]; ok {
+					page.Signals[len(page.Signals)-1].Error = string(f.Kind) + ": " + f.Error
+				}
+			}
+			sort.Slice(page.Signals, func(i, j int) bool { return page.Signals[i].Name < page.Signals[j].Name })
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("X-Frame-Options", "DENY")
+	if err := dashboardTemplate.Execute(w, page); err != nil {
+		slog.Error("failed to render dashboard", "error", err)
+	}
+}
+
+// dashboardConfig lists the effective settings worth checking at a glance.
+// Secrets are reported only as set or unset.
+func (s *Server) dashboardConfig() []dashboardSetting {
+	cfg := s.cfg
+	set := func(v string) string {
+		if v == "" {
+			return "unset"
+		}
+		return "set"
+	}
+	dbPool := strconv.Itoa(int(cfg.DBPool.MinConns)) + "–" + strconv.Itoa(int(cfg.DBPool.MaxConns)) +
+		" conns, statement timeout " + cfg.DBPool.StatementTimeout.String() + ", scans " + cfg.DBPool.ScanTimeout.String()
+	if store.IsSQLiteURL(cfg.DatabaseURL) {
+		dbPool = "sqlite (local file)"
+	}
+	runInterval := cfg.RunInterval.String()
+	if cfg.Schedule.MinInterval < cfg.RunInterval {
+		runInterval += ", " + cfg.Schedule.MinInterval.String() + " at risk ≥ " + strconv.Itoa(cfg.Schedule.RiskThreshold)
+	}
+	weather := "open-meteo"
+	if cfg.OpenWeatherAPIKey != "" {
+		weather = "openweather (open-meteo fallback)"
+	}
+	archive := "disabled"
+	if cfg.Archive.Enabled() {
+		archive = cfg.Archive.Endpoint + "/" + cfg.Archive.Bucket + "/" + cfg.Archive.Prefix
+	}
+	mirror := "disabled"
+	if cfg.Mirror.Enabled() {
+		mirror = cfg.Mirror.PublicURL + "/" + cfg.Mirror.Prefix + " (" + cfg.Mirror.CacheControl + ")"
+	}
+	telegram := "disabled"
+	if cfg.Telegram.Enabled() {
+		telegram = "chat " + cfg.Telegram.ChatID + " on band changes"
+	}
+	var privacy []string
+	if cfg.PulsePrivacy.MinCount > 0 {
+		privacy = append(privacy, "counts under "+strconv.Itoa(cfg.PulsePrivacy.MinCount)+" withheld")
+	}
+	if cfg.PulsePrivacy.RoundTo > 1 {
+		privacy = append(privacy, "rounded to "+strconv.Itoa(cfg.PulsePrivacy.RoundTo))
+	}
+	pulsePrivacy := "exact counts"
+	if len(privacy) > 0 {
+		pulsePrivacy = strings.Join(privacy, ", ")
+	}
+	degraded := "never"
+	if cfg.DegradedAfter < len(cfg.Weights)-len(cfg.Disabled) {
+		degraded = "with more than " + strconv.Itoa(cfg.DegradedAfter) + " stale signals"
+	}
+	compression := "disabled"
+	if cfg.Compression.Enabled {
+		compression = "gzip from " + strconv.Itoa(cfg.Compression.MinBytes) + " bytes"
+	}
+	tracks := "disabled"
+	if cfg.Tracks.Enabled {
+		tracks = "retained " + cfg.Tracks.Retention.String()
+	}
+	attention := "pulse"
+	if cfg.Attention.Wikipedia {
+		attention += ", wikipedia (" + strings.Join(cfg.Attention.WikipediaArticles, ", ") + ")"
+	}
+	dates := "built-in"
+	if cfg.SensitiveDatesFile != "" {
+		dates += " + " + cfg.SensitiveDatesFile
+	}
+	meta := "none"
+	if len(cfg.SnapshotMeta) > 0 {
+		keys := make([]string, 0, len(cfg.SnapshotMeta))
+		for k := range cfg.SnapshotMeta {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		meta = strings.Join(keys, ", ")
+	}
+	tls := "reverse proxy"
+	if cfg.TLS.Enabled() {
+		tls = "autocert for " + strings.Join(cfg.TLS.Domains, ", ") + " on :" + cfg.TLS.Port
+	}
+	metrics := "/metrics on " + cfg.MetricsAddr
+	signals := risk.Meta(fetcher.Meta(), cfg.Weights).Signals
+	weights := make([]string, 0, len(signals))
+	for _, m := range signals {
+		weights = append(weights, m.Name+" "+strconv.FormatFloat(m.Weight, 'f', 2, 64))
+	}
+	lookback := "whole feeds"
+	if cfg.NewsLookback > 0 {
+		lookback = "last " + cfg.NewsLookback.String()
+	}
+	adminOrigins := "same-origin only"
+	if len(cfg.CORS.AdminOrigins) > 0 {
+		adminOrigins = strings.Join(cfg.CORS.AdminOrigins, ", ")
+	}
+	rateLimits := "none"
+	if len(cfg.RateLimits) > 0 {
+		parts := make([]string, 0, len(cfg.RateLimits))
+		for _, rl := range cfg.RateLimits {
+			parts = append(parts, rl.Host+" 1/"+rl.Interval.String()+" (burst "+strconv.Itoa(rl.Burst)+")")
+		}
+		rateLimits = strings.Join(parts, ", ")
+	}
+	openSky := "anonymous"
+	if cfg.OpenSky.Authenticated() {
+		openSky = "client " + cfg.OpenSky.ClientID
+	}
+	pizzaClusters := make([]string, 0, len(cfg.PizzaClusters))
+	for _, c := range cfg.PizzaClusters {
+		pizzaClusters = append(pizzaClusters, c.Name+" ("+strconv.Itoa(len(c.Places))+" places, "+c.Location.String()+")")
+	}
+	notam := "disabled, no FAA credentials"
+	if cfg.Notam.ClientID != "" {
+		notam = "client " + cfg.Notam.ClientID
+	}
+	shipping := "disabled, no AISHub username"
+	if cfg.Shipping.AISHubUsername != "" {
+		shipping = "AISHub user " + cfg.Shipping.AISHubUsername
+	}
+	retryStatuses := make([]string, 0, len(cfg.Retry.Statuses))
+	for _, code := range cfg.Retry.Statuses {
+		retryStatuses = append(retryStatuses, strconv.Itoa(code))
+	}
+	return []dashboardSetting{
+		{"Run interval", runInterval + " (budget " + cfg.RunBudget.String() + ", " +
+			strconv.Itoa(cfg.FetchConcurrency) + " concurrent fetches)"},
+		{"Fetch retries", strconv.Itoa(cfg.Retry.Attempts) + " attempts, backoff " + cfg.Retry.Backoff.String() +
+			"–" + cfg.Retry.MaxBackoff.String() + ", on network errors and " + strings.Join(retryStatuses, ", ")},
+		{"Rate limits", rateLimits},
+		{"Circuit breakers", "open after " + strconv.Itoa(cfg.Breaker.Threshold) + " consecutive failures for " + cfg.Breaker.Cooldown.String()},
+		{"Public URL", cfg.PublicURL},
+		{"TLS", tls},
+		{"Metrics", metrics},
+		{"HTTP", "write timeout " + cfg.HTTP.WriteTimeout.String() + ", streams " + cfg.HTTP.StreamTimeout.String() +
+			" (keep-alive " + cfg.HTTP.StreamKeepAlive.String() + "), h2c " + strconv.FormatBool(cfg.HTTP.H2C)},
+		{"Allowed origins", strings.Join(cfg.AllowedOrigins, ", ")},
+		{"Public read origins", strings.Join(cfg.CORS.PublicOrigins, ", ")},
+		{"Admin origins", adminOrigins},
+		{"Weather provider", weather},
+		{"Signal weights", strings.Join(weights, ", ")},
+		{"Degraded scoring", degraded},
+		{"Tanker saturation", "scores 90 at " + strconv.Itoa(cfg.TankerSaturation) + " tankers until baselined"},
+		{"Cloudflare Radar token", set(cfg.CloudflareRadarToken)},
+		{"OpenSky", openSky + "; aviation box " + cfg.OpenSky.AviationBox.String() + ", tanker box " + cfg.OpenSky.TankerBox.String()},
+		{"DB pool", dbPool},
+		{"Data cache", "max-age " + cfg.DataCache.MaxAge.String() + ", s-maxage " + cfg.DataCache.SMaxAge.String() +
+			"; at risk ≥ " + strconv.Itoa(cfg.DataCache.HotMinRisk) + ": " + cfg.DataCache.HotMaxAge.String() +
+			" / " + cfg.DataCache.HotSMaxAge.String()},
+		{"Pulse honors DNT", strconv.FormatBool(cfg.PulseHonorDNT)},
+		{"Pulse privacy", pulsePrivacy},
+		{"Compression", compression},
+		{"Tracks", tracks},
+		{"Attention sources", attention},
+		{"GDELT query", cfg.GDELTQuery},
+		{"Shipping", shipping + "; Hormuz box " + cfg.Shipping.Box.String()},
+		{"Indicator feed", cfg.Indicator.Attribution + " (" + cfg.Indicator.License + ")"},
+		{"Pizza clusters", strings.Join(pizzaClusters, ", ")},
+		{"NOTAMs", notam + "; " + strings.Join(cfg.Notam.Locations, ", ")},
+		{"Seismic box", cfg.SeismicBox.String()},
+		{"News", "counts " + lookback + ", feeds dead after " + cfg.NewsFeedDeadAfter.String()},
+		{"Connectivity countries", strings.Join(cfg.Connectivity.Locations, ", ")},
+		{"Sensitive dates", dates},
+		{"Snapshot meta", meta},
+		{"Dataset archive", archive},
+		{"Snapshot mirror", mirror},
+		{"Telegram alerts", telegram},
+	}
+}
+
+// handleDashboardAction runs one dashboard button and redirects back to the
+// dashboard with a short status message.
+func (s *Server) handleDashboardAction(w http.ResponseWriter, r *http.Request) {
+	if !s.requireDashboard(w, r) {
+		return
+	}
+
+	var flash string
+	switch r.PathValue("action") {
+	case "refresh":
+		runID, err := s.pipeline.Trigger()
+		var inProgress *pipeline.RunInProgressError
+		switch {
+		case errors.As(err, &inProgress):
+			flash = "Run " + inProgress.RunID + " is already in progress."
+		case err != nil:
+			slog.Error("failed to trigger pipeline run", "error", err)
+			flash = "Failed to trigger run: " + err.Error()
+		default:
+			slog.Info("manual pipeline run triggered", "run_id", runID, "source", "dashboard")
+			flash = "Started run " + runID + "."
+		}
+	case "pause":
+		s.pipeline.Pause()
+		slog.Info("scheduled pipeline runs paused")
+		flash = "Scheduled runs paused."
+	case "resume":
+		s.pipeline.Resume()
+		slog.Info("scheduled pipeline runs resumed")
+		flash = "Scheduled runs resumed."
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	http.Redirect(w, r, "/admin?flash="+url.QueryEscape(flash), http.StatusSeeOther)
+}
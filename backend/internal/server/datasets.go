@@ -0,0 +1,48 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// datasetEntry is one day in the public dataset index.
+type datasetEntry struct {
+	Day         string `json:"day"`
+	Hours       int    `json:"hours"`
+	JSON        string `json:"json"`
+	CSV         string `json:"csv"`
+	PublishedAt string `json:"published_at"`
+}
+
+// handleDatasets lists the published daily datasets with download links
+// into the archive bucket.
+func (s *Server) handleDatasets(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.Archive.Enabled() {
+		http.NotFound(w, r)
+		return
+	}
+
+	exports, err := s.store.DatasetExports(r.Context())
+	if err != nil {
+		slog.Error("failed to list dataset exports", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	entries := make([]datasetEntry, 0, len(exports))
+	for _, e := range exports {
+		entries = append(entries, datasetEntry{
+			Day:         e.Day,
+			Hours:       e.Hours,
+			JSON:        s.cfg.Archive.PublicURL + "/" + e.JSONKey,
+			CSV:         s.cfg.Archive.PublicURL + "/" + e.CSVKey,
+			PublishedAt: e.PublishedAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(map[string]any{"datasets": entries})
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+const (
+	// maxBodyBytes caps every request body. Routes that decode JSON may set
+	// a tighter limit of their own.
+	maxBodyBytes = 64 << 10
+	// maxIdeaBytes fits a 1000-character idea even with every character
+	// escaped.
+	maxIdeaBytes = 8 << 10
+)
+
+// limitBody caps r.Body at maxBodyBytes; reading past it fails with
+// *http.MaxBytesError and closes the connection after the response.
+func limitBody(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+		h(w, r)
+	}
+}
+
+// decodeJSON strictly decodes a request body of at most limit bytes into
+// v: unknown fields and trailing data are refused. On failure it writes a
+// 413 or 400 JSON error and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any, limit int64) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, limit))
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		// A second value, or garbage, after the first is refused too
+		var extra json.RawMessage
+		if err = dec.Decode(&extra); err == io.EOF {
+			return true
+		} else if err == nil {
+			err = errors.New("unexpected data after JSON value")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		json.NewEncoder(w).Encode(map[string]any{"error": "request body too large", "limit_bytes": tooLarge.Limit})
+		return false
+	}
+	msg := "invalid request"
+	if errors.Is(err, io.EOF) {
+		msg += ": empty body"
+	} else {
+		msg += ": " + err.Error()
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+	return false
+}
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/jsonpatch"
+)
+
+// deltaCache memoizes serialized patches against the current snapshot so
+// polling clients on the same version share one diff. Patches are kept only
+// from versions the cache still holds; every other hash shares the one
+// full replace. It is reset whenever the current snapshot changes.
+type deltaCache struct {
+	mu      sync.Mutex
+	current string
+	patches map[string][]byte
+	full    []byte
+}
+
+func (d *deltaCache) get(from, to string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.current != to {
+		return nil, false
+	}
+	p, ok := d.patches[from]
+	return p, ok
+}
+
+func (d *deltaCache) put(from, to string, patch []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.advance(to)
+	d.patches[from] = patch
+}
+
+// fullReplace returns the memoized full replace for to, if any.
+func (d *deltaCache) fullReplace(to string) ([]byte, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.current != to || d.full == nil {
+		return nil, false
+	}
+	return d.full, true
+}
+
+func (d *deltaCache) putFullReplace(to string, patch []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.advance(to)
+	d.full = patch
+}
+
+// advance drops everything memoized against a snapshot other than to.
+// d.mu must be held.
+func (d *deltaCache) advance(to string) {
+	if d.current != to {
+		d.current = to
+		d.patches = map[string][]byte{}
+		d.full = nil
+	}
+}
+
+// size returns how many patches are memoized.
+func (d *deltaCache) size() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.patches)
+}
+
+// reset drops every memoized patch.
+func (d *deltaCache) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.current = ""
+	d.patches = nil
+	d.full = nil
+}
+
+// handleDataDelta returns an RFC 6902 JSON Patch from the client's snapshot,
+// identified by the X-Snapshot-Hash it last received, to the current one.
+// Unknown or expired hashes get a single root replace carrying the whole
+// snapshot, so clients always have one code path.
+func (s *Server) handleDataDelta(w http.ResponseWriter, r *http.Request) {
+	since := r.URL.Query().Get("since")
+	if since == "" {
+		http.Error(w, `{"error":"since is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.snapshot(r.Context()); err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	current, hash := s.cache.Current()
+	if current == nil {
+		http.Error(w, `{"error":"no data available"}`, http.StatusNotFound)
+		return
+	}
+
+	patch, err := s.delta(since, hash, current)
+	if err != nil {
+		slog.Error("failed to build snapshot delta", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json-patch+json")
+	w.Header().Set("Cache-Control", s.dataCacheControl(current, hash))
+	w.Header().Set("X-Snapshot-Hash", hash)
+	w.Write(patch)
+}
+
+// delta returns the patch from since to the current snapshot. Only hashes
+// of held versions are memoized under their own key, since since comes
+// from the client and would otherwise grow the memo without bound.
+func (s *Server) delta(since, hash string, current []byte) ([]byte, error) {
+	if patch, ok := s.deltas.get(since, hash); ok {
+		return patch, nil
+	}
+	from := s.cache.Version(since)
+	if from == nil {
+		if patch, ok := s.deltas.fullReplace(hash); ok {
+			return patch, nil
+		}
+		patch, err := buildPatch(nil, current)
+		if err != nil {
+			return nil, err
+		}
+		s.deltas.putFullReplace(hash, patch)
+		return patch, nil
+	}
+	patch, err := buildPatch(from, current)
+	if err != nil {
+		return nil, err
+	}
+	s.deltas.put(since, hash, patch)
+	return patch, nil
+}
+
+func buildPatch(from, to []byte) ([]byte, error) {
+	var toDoc any
+	if err := json.Unmarshal(to, &toDoc); err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return json.Marshal([]jsonpatch.Operation{{Op: "replace", Path: "", Value: toDoc}})
+	}
+	var fromDoc any
+	if err := json.Unmarshal(from, &fromDoc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonpatch.Diff(fromDoc, toDoc))
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// embedSignal is a single signal entry in the embed payload.
+type embedSignal struct {
+	Name   string `json:"name"`
+	Risk   int    `json:"risk"`
+	Detail string `json:"detail"`
+}
+
+// embedPayload is the minimal widget payload served to third-party sites.
+type embedPayload struct {
+	Risk        int           `json:"risk"`
+	Band        string        `json:"band"`
+	Trend       string        `json:"trend"`
+	LastUpdated string        `json:"last_updated"`
+	TopSignals  []embedSignal `json:"top_signals"`
+}
+
+func (s *Server) handleEmbed(w http.ResponseWriter, r *http.Request) {
+	data, err := s.snapshot(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, `{"error":"no data available"}`, http.StatusNotFound)
+		return
+	}
+
+	var snap model.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		slog.Error("failed to parse snapshot for embed", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300, s-maxage=900")
+	json.NewEncoder(w).Encode(buildEmbed(snap))
+}
+
+func buildEmbed(snap model.Snapshot) embedPayload {
+	signals := []embedSignal{}
+	for _, name := range fetcher.Names() {
+		if sig := snap.Signal(name); sig != nil {
+			signals = append(signals, embedSignal{Name: name, Risk: sig.Risk, Detail: sig.Detail})
+		}
+	}
+	sort.SliceStable(signals, func(i, j int) bool { return signals[i].Risk > signals[j].Risk })
+
+	return embedPayload{
+		Risk:        snap.TotalRisk.Risk,
+		Band:        risk.Band(snap.TotalRisk.Risk),
+		Trend:       riskTrend(snap.TotalRisk.History),
+		LastUpdated: snap.LastUpdated,
+		TopSignals:  signals[:min(3, len(signals))],
+	}
+}
+
+// riskTrend compares the latest total risk point to the one before it.
+func riskTrend(history []model.TotalRiskPoint) string {
+	if len(history) < 2 {
+		return "flat"
+	}
+	last := history[len(history)-1].Risk
+	prev := history[len(history)-2].Risk
+	switch {
+	case last > prev:
+		return "up"
+	case last < prev:
+		return "down"
+	default:
+		return "flat"
+	}
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/geo"
+)
+
+// defaultMapPrecision keeps full OpenSky precision (about 1 m) unless the
+// client asks for coarser coordinates.
+const defaultMapPrecision = 5
+
+func (s *Server) handleMap(w http.ResponseWriter, r *http.Request) {
+	precision := defaultMapPrecision
+	if v := r.URL.Query().Get("precision"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > defaultMapPrecision {
+			http.Error(w, `{"error":"precision must be between 0 and 5"}`, http.StatusBadRequest)
+			return
+		}
+		precision = n
+	}
+	var layers []string
+	if v := r.URL.Query().Get("layers"); v != "" {
+		layers = strings.Split(v, ",")
+	}
+
+	m := s.pipeline.Map()
+	if m == nil {
+		http.Error(w, `{"error":"no data available"}`, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=300")
+	json.NewEncoder(w).Encode(geo.Filter(*m, layers, precision))
+}
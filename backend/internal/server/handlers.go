@@ -1,46 +1,165 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/cache"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+	"github.com/backyonatan-alt/aegis/backend/internal/schema"
+	"github.com/backyonatan-alt/aegis/backend/internal/spam"
 )
 
 func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusNoContent)
+	loc, err := viewerLocation(r)
+	if err != nil {
+		http.Error(w, `{"error":"tz must be an IANA time zone"}`, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := s.snapshot(r.Context()); err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
 		return
 	}
+	// The cache hashed the snapshot once when it was stored
+	data, hash := s.cache.Current()
+	if data == nil {
+		http.Error(w, `{"error":"no data available"}`, http.StatusNotFound)
+		return
+	}
+
+	cacheCtl := s.dataCacheControl(data, hash)
+	// The ETag covers the bytes served, which repinning changes
+	etag := `"` + hash + `"`
+	if loc != nil {
+		rp, err := s.repinned(r.Context(), data, hash, loc, time.Now())
+		if err != nil {
+			slog.Error("failed to repin history", "tz", loc, "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		data, etag = rp.data, rp.etag
+	}
+
+	modified := s.cache.UpdatedAt()
+	w.Header().Set("Cache-Control", cacheCtl)
+	w.Header().Set("X-Snapshot-Hash", hash)
+	w.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// net/http only infers the length of bodies that fit its write buffer,
+	// so HEAD probes of a full snapshot would get none; gzip drops it again
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
 
-	if r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+func (s *Server) handleDataAt(w http.ResponseWriter, r *http.Request) {
+	t, err := time.Parse(time.RFC3339, r.URL.Query().Get("t"))
+	if err != nil {
+		http.Error(w, `{"error":"t must be an RFC 3339 timestamp"}`, http.StatusBadRequest)
 		return
 	}
+	loc, err := viewerLocation(r)
+	if err != nil {
+		http.Error(w, `{"error":"tz must be an IANA time zone"}`, http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.store.SnapshotAt(r.Context(), t)
+	if err != nil {
+		slog.Error("failed to load historical snapshot", "t", t, "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, `{"error":"no snapshot at or before t"}`, http.StatusNotFound)
+		return
+	}
+
+	hash := cache.Hash(data)
+	w.Header().Set("Content-Type", "application/json")
+	// Past snapshots never change once stored
+	if time.Since(t) > time.Hour {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+	} else {
+		w.Header().Set("Cache-Control", s.dataCacheControl(data, hash))
+	}
+	if loc != nil {
+		rp, err := s.repinned(r.Context(), data, hash, loc, t)
+		if err != nil {
+			slog.Error("failed to repin history", "tz", loc, "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		data = rp.data
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Write(data)
+}
 
+// snapshot returns the latest serialized snapshot, trying the in-memory cache
+// first and falling back to the database on a cold start. It returns nil
+// bytes and no error when no snapshot exists yet.
+func (s *Server) snapshot(ctx context.Context) ([]byte, error) {
 	// Try in-memory cache first
 	data := s.cache.Get()
+	if data != nil {
+		return data, nil
+	}
 
-	// Cold start: load from DB
-	if data == nil {
+	// Cold start: load from DB. Concurrent misses share one query, detached
+	// from any single caller's cancellation since others are waiting on it.
+	v, err, _ := s.coldStart.Do("latest", func() (any, error) {
+		if data := s.cache.Get(); data != nil {
+			return data, nil
+		}
 		slog.Info("cache miss, loading from database")
-		var err error
-		data, err = s.store.LatestSnapshot(r.Context())
+		data, err := s.store.LatestSnapshot(context.WithoutCancel(ctx))
 		if err != nil {
 			slog.Error("failed to load snapshot from DB", "error", err)
-			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
-			return
+			return nil, err
 		}
-		if data == nil {
-			http.Error(w, `{"error":"no data available"}`, http.StatusNotFound)
-			return
+		if data != nil {
+			// Populate cache for next request
+			s.cache.Set(data)
 		}
-		// Populate cache for next request
-		s.cache.Set(data)
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	data, _ = v.([]byte)
+	return data, nil
+}
 
+func (s *Server) handleMeta(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=300")
-	w.Write(data)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	json.NewEncoder(w).Encode(risk.Meta(fetcher.Meta(), s.cfg.Weights))
+}
+
+// handleMetaSchemas serves the JSON Schema of every signal's raw_data,
+// keyed by signal. Stored snapshots are checked against them, so a
+// signal's raw_data is either {} or valid under its schema.
+func (s *Server) handleMetaSchemas(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	schemas := make(map[string]*schema.Schema)
+	for _, d := range fetcher.Descriptors() {
+		schemas[d.Name] = schema.Publish(d.Name, d.Schema)
+	}
+	json.NewEncoder(w).Encode(schemas)
 }
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -52,22 +171,30 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if !updatedAt.IsZero() {
 		resp["last_update"] = updatedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
+	if feeds := s.pipeline.FeedHealth(); feeds != nil {
+		resp["news_feeds"] = feeds
+	}
+	if upstreams := s.fetcher.Breakers(); len(upstreams) > 0 {
+		resp["upstreams"] = upstreams
+		for _, u := range upstreams {
+			if u.State != "closed" {
+				resp["status"] = "degraded"
+			}
+		}
+	}
+	if s.jobs != nil {
+		// Still 200: a restarting job is degraded service, not an outage
+		resp["jobs"] = s.jobs.Status()
+		if !s.jobs.Healthy() {
+			resp["status"] = "degraded"
+		}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
 
 func (s *Server) handlePulse(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusNoContent)
-		return
-	}
-
-	if r.Method != http.MethodPost && r.Method != http.MethodGet {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
 	// Extract country code from headers
 	// Cloudflare: CF-IPCountry, other proxies may use X-Country
 	countryCode := r.Header.Get("CF-IPCountry")
@@ -79,7 +206,10 @@ func (s *Server) handlePulse(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var stats interface{}
-	if r.Method == http.MethodPost {
+	if r.Method == http.MethodPost && s.cfg.PulseHonorDNT && optedOut(r) {
+		// Visitor asked not to be tracked: return stats without logging
+		stats = s.pulse.GetStats()
+	} else if r.Method == http.MethodPost {
 		// POST logs a visit and returns stats
 		stats = s.pulse.LogVisit(countryCode)
 		slog.Debug("pulse visit logged", "country", countryCode)
@@ -93,24 +223,36 @@ func (s *Server) handlePulse(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(stats)
 }
 
-func (s *Server) handleRadarIdea(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusNoContent)
-		return
+func (s *Server) handlePulseCountries(w http.ResponseWriter, r *http.Request) {
+	minutes := 0
+	if v := r.URL.Query().Get("minutes"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			http.Error(w, `{"error":"minutes must be a positive integer"}`, http.StatusBadRequest)
+			return
+		}
+		minutes = n
 	}
 
-	// POST only - no GET to retrieve ideas
-	if r.Method != http.MethodPost {
-		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	json.NewEncoder(w).Encode(s.pulse.Countries(minutes))
+}
+
+// optedOut reports whether the request carries a Do-Not-Track or Global
+// Privacy Control signal.
+func optedOut(r *http.Request) bool {
+	return r.Header.Get("DNT") == "1" || r.Header.Get("Sec-GPC") == "1"
+}
+
+func (s *Server) handleRadarIdea(w http.ResponseWriter, r *http.Request) {
+	// POST only - no GET to retrieve ideas (enforced by the router)
 
 	// Parse request body
 	var req struct {
 		Idea string `json:"idea"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+	if !decodeJSON(w, r, &req, maxIdeaBytes) {
 		return
 	}
 
@@ -133,6 +275,19 @@ func (s *Server) handleRadarIdea(w http.ResponseWriter, r *http.Request) {
 		countryCode = "XX"
 	}
 
+	// Spam filtering: rejected ideas go to a shadow table and the client
+	// still gets a success response so spammers have nothing to tune against
+	if check := spam.Check(idea); !check.OK {
+		if err := s.store.SaveRejectedRadarIdea(r.Context(), idea, countryCode, check.Reason, check.Language); err != nil {
+			slog.Error("failed to save rejected radar idea", "error", err)
+		}
+		slog.Info("radar idea rejected", "country", countryCode, "reason", check.Reason, "language", check.Language)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+		w.Write([]byte(`{"success":true}`))
+		return
+	}
+
 	// Save to database
 	if err := s.store.SaveRadarIdea(r.Context(), idea, countryCode); err != nil {
 		slog.Error("failed to save radar idea", "error", err)
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	defaultHistoryRange = 7 * 24 * time.Hour
+	maxHistoryRange     = 365 * 24 * time.Hour
+	// maxRawHistoryRange bounds resolution=raw, one point per run.
+	maxRawHistoryRange = 7 * 24 * time.Hour
+)
+
+// riskBucket summarizes the runs that fell in one bucket. Timestamp is the
+// bucket start; Risk and Uncertainty are means, rounded.
+type riskBucket struct {
+	Timestamp   int64 `json:"timestamp"`
+	Risk        int   `json:"risk"`
+	Uncertainty int   `json:"uncertainty"`
+	Min         int   `json:"min"`
+	Max         int   `json:"max"`
+	Count       int   `json:"count"`
+}
+
+// handleHistory returns total risk between ?from= and ?to= (RFC 3339,
+// default the last 7 days, at most a year) at ?resolution=raw, hour or day.
+// The default picks raw for up to two days, hourly up to 30 and daily
+// beyond. Day buckets follow ?tz= midnights, UTC without it.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	loc, err := viewerLocation(r)
+	if err != nil {
+		http.Error(w, `{"error":"tz must be an IANA time zone"}`, http.StatusBadRequest)
+		return
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	q := r.URL.Query()
+	now := time.Now()
+	from, to, msg := parseTimeRange(q, now, defaultHistoryRange, maxHistoryRange)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	resolution := q.Get("resolution")
+	switch resolution {
+	case "":
+		resolution = autoResolution(to.Sub(from))
+	case "raw":
+		if to.Sub(from) > maxRawHistoryRange {
+			http.Error(w, `{"error":"resolution=raw covers at most 7 days"}`, http.StatusBadRequest)
+			return
+		}
+	case "hour", "day":
+	default:
+		http.Error(w, `{"error":"resolution must be raw, hour or day"}`, http.StatusBadRequest)
+		return
+	}
+
+	series, err := s.store.TotalRiskBetween(r.Context(), from, to)
+	if err != nil {
+		slog.Error("failed to load total risk history", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Ranges that ended well in the past can't gain runs
+	if now.Sub(to) > time.Hour {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=300")
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"from":       from.UTC().Format(time.RFC3339),
+		"to":         to.UTC().Format(time.RFC3339),
+		"resolution": resolution,
+		"points":     downsample(series, resolution, loc),
+	})
+}
+
+func autoResolution(span time.Duration) string {
+	switch {
+	case span <= 2*24*time.Hour:
+		return "raw"
+	case span <= 30*24*time.Hour:
+		return "hour"
+	default:
+		return "day"
+	}
+}
+
+// downsample groups series, oldest first, into consecutive buckets of the
+// given resolution. Buckets without runs are omitted rather than zero
+// filled, so gaps in collection show as gaps.
+func downsample(series []model.TotalRiskPoint, resolution string, loc *time.Location) []riskBucket {
+	buckets := make([]riskBucket, 0)
+	sum, uncertaintySum := 0, 0
+	for _, p := range series {
+		start := bucketStart(time.UnixMilli(p.Timestamp).In(loc), resolution).UnixMilli()
+		if n := len(buckets); n > 0 && buckets[n-1].Timestamp == start {
+			b := &buckets[n-1]
+			sum += p.Risk
+			uncertaintySum += p.Uncertainty
+			b.Count++
+			b.Min = min(b.Min, p.Risk)
+			b.Max = max(b.Max, p.Risk)
+			b.Risk = int(math.Round(float64(sum) / float64(b.Count)))
+			b.Uncertainty = int(math.Round(float64(uncertaintySum) / float64(b.Count)))
+			continue
+		}
+		sum, uncertaintySum = p.Risk, p.Uncertainty
+		buckets = append(buckets, riskBucket{Timestamp: start, Risk: p.Risk, Uncertainty: p.Uncertainty, Min: p.Risk, Max: p.Risk, Count: 1})
+	}
+	return buckets
+}
+
+func bucketStart(t time.Time, resolution string) time.Time {
+	switch resolution {
+	case "hour":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return t
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+)
+
+// NewHTTPServer returns a listener for h with the configured timeouts.
+func NewHTTPServer(addr string, h http.Handler, cfg config.HTTP) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           h,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// ConfigureHTTP2 enables HTTP/2 on hs with the configured stream limit. Set
+// TLSConfig first: over TLS HTTP/2 is negotiated via ALPN, and without TLS
+// it is only accepted when cfg.H2C is set.
+func ConfigureHTTP2(hs *http.Server, cfg config.HTTP) error {
+	h2 := &http2.Server{
+		MaxConcurrentStreams: cfg.MaxConcurrentStreams,
+		IdleTimeout:          cfg.IdleTimeout,
+	}
+	if err := http2.ConfigureServer(hs, h2); err != nil {
+		return err
+	}
+	if cfg.H2C && hs.TLSConfig == nil {
+		hs.Handler = h2c.NewHandler(hs.Handler, h2)
+	}
+	return nil
+}
+
+// beginStream prepares w for a long-lived response such as an event stream.
+// The server's read and write timeouts would otherwise end it (an expired
+// HTTP/1 read deadline cancels the request context), so both deadlines are
+// moved out to the stream timeout. The returned func flushes buffered
+// output to the client.
+func (s *Server) beginStream(w http.ResponseWriter) (flush func() error, err error) {
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(s.cfg.HTTP.StreamTimeout)
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if err := rc.SetReadDeadline(deadline); err != nil && err != http.ErrNotSupported {
+		return nil, err
+	}
+	return rc.Flush, nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	defaultIncidentHours = 24 * 7
+	maxIncidentHours     = 24 * 90
+)
+
+// handleIncidents lists the runs in which several signals spiked together,
+// newest first, over the last ?hours= (default a week).
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	hours := defaultIncidentHours
+	if v := r.URL.Query().Get("hours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxIncidentHours {
+			http.Error(w, `{"error":"hours must be between 1 and 2160"}`, http.StatusBadRequest)
+			return
+		}
+		hours = n
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-time.Duration(hours) * time.Hour)
+	incidents, err := s.store.IncidentsBetween(r.Context(), from, to)
+	if err != nil {
+		slog.Error("failed to load incidents", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if incidents == nil {
+		incidents = []model.Incident{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=300")
+	json.NewEncoder(w).Encode(map[string]any{
+		"from":      from,
+		"to":        to,
+		"incidents": incidents,
+	})
+}
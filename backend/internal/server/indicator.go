@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/cache"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// indicatorPayload is the /api/v1/indicator body, the feed for aggregator
+// sites. Its schema is a published contract: fields may be added, but none
+// is renamed, retyped or removed within v1, whatever happens to the
+// snapshot it is built from.
+//
+//	value        integer 0-100, total strike risk
+//	band         "low", "elevated", "high" or "imminent"
+//	updated      RFC 3339 UTC time the value was computed
+//	attribution  credit line to show with the value
+//	license      SPDX identifier of the license the value is offered under
+type indicatorPayload struct {
+	Value       int    `json:"value"`
+	Band        string `json:"band"`
+	Updated     string `json:"updated"`
+	Attribution string `json:"attribution"`
+	License     string `json:"license"`
+}
+
+func (s *Server) handleIndicator(w http.ResponseWriter, r *http.Request) {
+	data, err := s.snapshot(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if data == nil {
+		http.Error(w, `{"error":"no data available"}`, http.StatusNotFound)
+		return
+	}
+
+	var snap model.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		slog.Error("failed to parse snapshot for indicator", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	body, err := json.Marshal(s.buildIndicator(snap))
+	if err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	etag := `"` + cache.Hash(body) + `"`
+	modified := s.cache.UpdatedAt()
+	w.Header().Set("Cache-Control", "public, max-age=300, s-maxage=900")
+	w.Header().Set("ETag", etag)
+	if !modified.IsZero() {
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, etag, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func (s *Server) buildIndicator(snap model.Snapshot) indicatorPayload {
+	updated := snap.LastUpdated
+	if t, err := time.Parse(time.RFC3339, updated); err == nil {
+		updated = t.UTC().Format(time.RFC3339)
+	}
+	return indicatorPayload{
+		Value:       snap.TotalRisk.Risk,
+		Band:        risk.Band(snap.TotalRisk.Risk),
+		Updated:     updated,
+		Attribution: s.cfg.Indicator.Attribution,
+		License:     s.cfg.Indicator.License,
+	}
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// handleAdminKeywords returns (GET) or replaces (PUT) the news keyword lists.
+// A PUT is persisted and hot-applied to the fetcher for the next run.
+func (s *Server) handleAdminKeywords(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+	if r.Method != http.MethodPut {
+		json.NewEncoder(w).Encode(s.fetcher.Keywords())
+		return
+	}
+
+	kw, ok := decodeKeywords(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := json.Marshal(kw)
+	if err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := s.store.SaveKeywords(r.Context(), data); err != nil {
+		slog.Error("failed to save news keywords", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	s.fetcher.SetKeywords(kw)
+
+	slog.Info("news keywords updated", "alert", len(kw.Alert), "iran", len(kw.Iran), "negative", len(kw.Negative), "deescalation", len(kw.Deescalation))
+	json.NewEncoder(w).Encode(kw)
+}
+
+// keywordTestArticle shows how one article from the last run is classified
+// by the current and candidate keyword sets.
+type keywordTestArticle struct {
+	Title             string `json:"title"`
+	CurrentRelevant   bool   `json:"current_relevant"`
+	CurrentAlert      bool   `json:"current_alert"`
+	CandidateRelevant bool   `json:"candidate_relevant"`
+	CandidateAlert    bool   `json:"candidate_alert"`
+	CandidateDeesc    bool   `json:"candidate_deescalation"`
+}
+
+type keywordTestScore struct {
+	TotalCount        int `json:"total_count"`
+	AlertCount        int `json:"alert_count"`
+	DeescalationCount int `json:"deescalation_count"`
+	Risk              int `json:"risk"`
+}
+
+// add counts one item as a run would: alerts and de-escalations only among
+// the Iran-related items it keeps.
+func (t *keywordTestScore) add(relevant, alert, deescalation bool) {
+	if !relevant {
+		return
+	}
+	t.TotalCount++
+	if alert {
+		t.AlertCount++
+	}
+	if deescalation {
+		t.DeescalationCount++
+	}
+}
+
+// handleAdminKeywordsTest re-scores the last run's news with a candidate
+// keyword set without applying it. Every feed item the run read within its
+// lookback is classified on its title and description, as a run does, by
+// both the keywords the run used and the candidate, so a candidate can
+// widen the article set as well as narrow it.
+func (s *Server) handleAdminKeywordsTest(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	kw, ok := decodeKeywords(w, r)
+	if !ok {
+		return
+	}
+
+	items, used, ok := s.fetcher.LastNews()
+	if !ok {
+		http.Error(w, `{"error":"no news run since startup"}`, http.StatusNotFound)
+		return
+	}
+
+	var current, candidate keywordTestScore
+	results := []keywordTestArticle{}
+	for _, item := range items {
+		isRelevant, isAlert := used.Classify(item.Text)
+		isDeescalation := len(used.MatchDeescalation(item.Text)) > 0
+		current.add(isRelevant, isAlert, isDeescalation)
+
+		relevant, alert := kw.Classify(item.Text)
+		deescalation := len(kw.MatchDeescalation(item.Text)) > 0
+		candidate.add(relevant, alert, deescalation)
+
+		if !isRelevant && !relevant {
+			continue
+		}
+		results = append(results, keywordTestArticle{
+			Title:             item.Title,
+			CurrentRelevant:   isRelevant,
+			CurrentAlert:      isAlert,
+			CandidateRelevant: relevant,
+			CandidateAlert:    alert,
+			CandidateDeesc:    deescalation,
+		})
+	}
+	current.Risk = risk.NewsRisk(current.TotalCount, current.AlertCount, current.DeescalationCount)
+	candidate.Risk = risk.NewsRisk(candidate.TotalCount, candidate.AlertCount, candidate.DeescalationCount)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	json.NewEncoder(w).Encode(map[string]any{
+		"current":   current,
+		"candidate": candidate,
+		"articles":  results,
+	})
+}
+
+func decodeKeywords(w http.ResponseWriter, r *http.Request) (fetcher.Keywords, bool) {
+	var kw fetcher.Keywords
+	if !decodeJSON(w, r, &kw, maxBodyBytes) {
+		return kw, false
+	}
+	kw = kw.Normalize()
+	if err := kw.Validate(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return kw, false
+	}
+	return kw, true
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/logtail"
+)
+
+// handleAdminLogs streams log records as Server-Sent Events: the buffered
+// backlog first, then each new record. ?level= (debug, info, warn, error;
+// default info) sets the lowest level sent. Event IDs are record sequence
+// numbers, so a reconnecting client resumes after its Last-Event-ID
+// instead of replaying the backlog.
+func (s *Server) handleAdminLogs(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+	if s.logs == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	level := slog.LevelInfo
+	if v := r.URL.Query().Get("level"); v != "" {
+		if err := level.UnmarshalText([]byte(v)); err != nil {
+			http.Error(w, `{"error":"level must be debug, info, warn or error"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	var lastSeq uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		lastSeq, _ = strconv.ParseUint(v, 10, 64)
+	}
+
+	flush, err := s.beginStream(w)
+	if err != nil {
+		slog.Error("log tail: failed to start stream", "error", err)
+		http.Error(w, `{"error":"streaming unsupported"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before reading the backlog so no record falls between them;
+	// the sequence check drops the overlap.
+	records, unsubscribe := s.logs.Subscribe(level)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(rec logtail.Record) error {
+		if rec.Seq <= lastSeq {
+			return nil
+		}
+		lastSeq = rec.Seq
+		data, err := json.Marshal(rec)
+		if err != nil {
+			// An attribute that can't be marshaled; keep the record itself
+			rec.Attrs = map[string]any{"marshal_error": err.Error()}
+			data, _ = json.Marshal(rec)
+		}
+		_, err = fmt.Fprintf(w, "event: log\nid: %d\ndata: %s\n\n", rec.Seq, data)
+		return err
+	}
+
+	for _, rec := range s.logs.Recent(level) {
+		if err := send(rec); err != nil {
+			return
+		}
+	}
+	if err := flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(s.cfg.HTTP.StreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.shutdown:
+			return
+		case rec := <-records:
+			if err := send(rec); err != nil {
+				return
+			}
+			// Flush once the burst is drained rather than per record
+			if len(records) == 0 {
+				if err := flush(); err != nil {
+					return
+				}
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := flush(); err != nil {
+				return
+			}
+		}
+	}
+}
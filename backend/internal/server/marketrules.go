@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+)
+
+// handleAdminMarketRules returns (GET) or replaces (PUT) the Polymarket
+// query and matching rules. A PUT is persisted and hot-applied to the
+// fetcher for the next run.
+func (s *Server) handleAdminMarketRules(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+
+	if r.Method != http.MethodPut {
+		json.NewEncoder(w).Encode(s.fetcher.MarketRules())
+		return
+	}
+
+	rules, ok := decodeMarketRules(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := s.store.SaveMarketRules(r.Context(), data); err != nil {
+		slog.Error("failed to save polymarket rules", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	s.fetcher.SetMarketRules(rules)
+
+	slog.Info("polymarket rules updated", "query", rules.Query, "topic", len(rules.Topic), "strike", len(rules.Strike), "event_titles", len(rules.EventTitles))
+	json.NewEncoder(w).Encode(rules)
+}
+
+// handleAdminMarketRulesTest runs a live Polymarket search with candidate
+// rules, without applying them, and lists every market they match. An empty
+// body tests the current rules.
+func (s *Server) handleAdminMarketRulesTest(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	rules := s.fetcher.MarketRules()
+	if r.ContentLength != 0 {
+		var ok bool
+		if rules, ok = decodeMarketRules(w, r); !ok {
+			return
+		}
+	}
+
+	matches, result, err := s.fetcher.PolymarketDryRun(r.Context(), rules)
+	if err != nil {
+		slog.Warn("polymarket dry run failed", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error(), "kind": string(fetcher.Classify(err))})
+		return
+	}
+	if matches == nil {
+		matches = []fetcher.MarketMatch{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	json.NewEncoder(w).Encode(map[string]any{
+		"rules":   rules,
+		"odds":    result.Odds,
+		"market":  result.Market,
+		"matches": matches,
+	})
+}
+
+func decodeMarketRules(w http.ResponseWriter, r *http.Request) (fetcher.MarketRules, bool) {
+	var rules fetcher.MarketRules
+	if !decodeJSON(w, r, &rules, maxBodyBytes) {
+		return rules, false
+	}
+	rules = rules.Normalize()
+	if err := rules.Validate(); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return rules, false
+	}
+	return rules, true
+}
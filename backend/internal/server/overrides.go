@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// secretMask stands in for a stored credential's value in responses.
+const secretMask = "********"
+
+type configOverrideRequest struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	UpdatedBy string `json:"updated_by"`
+}
+
+// handleAdminConfigOverrides lists (GET), sets (PUT) or removes (DELETE
+// ?key=) the stored settings layered over the environment. A PUT is
+// checked by loading the whole configuration with it applied, so a value
+// that would fail at startup is refused. Credentials are masked in every
+// response. Changes apply at the next start or reload.
+func (s *Server) handleAdminConfigOverrides(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	overrides, err := s.store.ConfigOverrides(r.Context())
+	if err != nil {
+		slog.Error("failed to load config overrides", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if overrides == nil {
+			overrides = []model.ConfigOverride{}
+		}
+		for i, o := range overrides {
+			overrides[i].Value = maskedValue(o.Key, o.Value)
+		}
+		json.NewEncoder(w).Encode(overrides)
+
+	case http.MethodPut:
+		var req configOverrideRequest
+		if !decodeJSON(w, r, &req, maxBodyBytes) {
+			return
+		}
+		if err := config.CheckOverrideKey(fetcher.ConfigSignals(), req.Key); err != nil {
+			badOverride(w, err)
+			return
+		}
+		candidate := overrideMap(overrides)
+		candidate[req.Key] = req.Value
+		if _, err := config.LoadWithOverrides(fetcher.ConfigSignals(), candidate); err != nil {
+			badOverride(w, err)
+			return
+		}
+		// Basic credentials name the operator; bearer tokens are anonymous
+		updatedBy, _, _ := r.BasicAuth()
+		if req.UpdatedBy != "" {
+			updatedBy = req.UpdatedBy
+		}
+		if updatedBy == "" {
+			updatedBy = "admin"
+		}
+		if err := s.store.SetConfigOverride(r.Context(), req.Key, req.Value, updatedBy); err != nil {
+			slog.Error("failed to save config override", "key", req.Key, "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		slog.Info("config override set", "key", req.Key, "updated_by", updatedBy)
+		json.NewEncoder(w).Encode(map[string]any{"key": req.Key, "value": maskedValue(req.Key, req.Value), "updated_by": updatedBy})
+
+	case http.MethodDelete:
+		key := r.URL.Query().Get("key")
+		if key == "" {
+			http.Error(w, `{"error":"key is required"}`, http.StatusBadRequest)
+			return
+		}
+		if _, ok := overrideMap(overrides)[key]; ok {
+			candidate := overrideMap(overrides)
+			delete(candidate, key)
+			if _, err := config.LoadWithOverrides(fetcher.ConfigSignals(), candidate); err != nil {
+				badOverride(w, err)
+				return
+			}
+		}
+		deleted, err := s.store.DeleteConfigOverride(r.Context(), key)
+		if err != nil {
+			slog.Error("failed to delete config override", "key", key, "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			http.Error(w, `{"error":"no override for key"}`, http.StatusNotFound)
+			return
+		}
+		slog.Info("config override removed", "key", key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleAdminConfigReload restarts the app's components with the
+// environment and stored overrides read afresh. The response is sent
+// before the restart begins.
+func (s *Server) handleAdminConfigReload(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+	if s.reload == nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(`{"status":"reloading"}`))
+	s.reload()
+}
+
+// overrideMap keys stored overrides by environment variable, the form
+// config.LoadWithOverrides takes.
+func overrideMap(overrides []model.ConfigOverride) map[string]string {
+	m := make(map[string]string, len(overrides))
+	for _, o := range overrides {
+		m[o.Key] = o.Value
+	}
+	return m
+}
+
+// maskedValue returns value as it may be shown for key: credentials are
+// masked, though an empty one (standing for unset) is shown as such.
+func maskedValue(key, value string) string {
+	if config.SecretKey(key) && value != "" {
+		return secretMask
+	}
+	return value
+}
+
+func badOverride(w http.ResponseWriter, err error) {
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
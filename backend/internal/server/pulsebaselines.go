@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/pulse"
+)
+
+// baselineReportWindow is how far back observed baselines are averaged.
+const baselineReportWindow = 7 * 24 * time.Hour
+
+// handleAdminPulseBaselines returns (GET) or replaces (PUT) the pulse
+// baselines, e.g. {"countries":{"US":35,"IL":15},"base_total":100}. A PUT
+// is persisted and applied to the live stats at once.
+func (s *Server) handleAdminPulseBaselines(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if r.Method != http.MethodPut {
+		json.NewEncoder(w).Encode(s.pulse.Baselines())
+		return
+	}
+
+	var b pulse.Baselines
+	if !decodeJSON(w, r, &b, maxBodyBytes) {
+		return
+	}
+	if err := b.Validate(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if err := s.store.SavePulseBaselines(r.Context(), data); err != nil {
+		slog.Error("failed to save pulse baselines", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	s.pulse.SetBaselines(b)
+
+	slog.Info("pulse baselines updated", "countries", len(b.Countries), "base_total", b.BaseTotal)
+	json.NewEncoder(w).Encode(b)
+}
+
+// handleAdminPulseBaselinesObserved compares the configured baselines with
+// the mean visitors per 10-minute window over the last week, with a
+// suggested value for each.
+func (s *Server) handleAdminPulseBaselinesObserved(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	since := time.Now().UTC().Add(-baselineReportWindow)
+	windows, err := s.store.PulseWindowsSince(r.Context(), since)
+	if err != nil {
+		slog.Error("failed to load pulse windows", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(pulse.Observe(since, windows, s.pulse.Baselines()))
+}
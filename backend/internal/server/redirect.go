@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net"
+	"net/http"
+)
+
+// RedirectHTTPS answers plain-HTTP requests with a permanent redirect to the
+// same host and path over HTTPS on tlsPort.
+func RedirectHTTPS(tlsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if tlsPort != "443" {
+			host = net.JoinHostPort(host, tlsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
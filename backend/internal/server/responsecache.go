@@ -0,0 +1,51 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// responseCache holds encoded responses that are costly to build, keyed by
+// their parameters, so a burst of requests costs one build per key and
+// ttl. Callers bound the keys, as entries are only replaced.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+	builds  singleflight.Group
+}
+
+type cachedResponse struct {
+	data  []byte
+	built time.Time
+}
+
+// get returns the response cached under key, building it with build when
+// it is missing or older than ttl. Concurrent misses share one build.
+func (c *responseCache) get(key string, ttl time.Duration, build func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Since(e.built) < ttl {
+		return e.data, nil
+	}
+
+	v, err, _ := c.builds.Do(key, func() (any, error) {
+		data, err := build()
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		if c.entries == nil {
+			c.entries = make(map[string]cachedResponse)
+		}
+		c.entries[key] = cachedResponse{data: data, built: time.Now()}
+		c.mu.Unlock()
+		return data, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]byte), nil
+}
@@ -2,36 +2,166 @@ package server
 
 import (
 	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/backyonatan-alt/aegis/backend/internal/cache"
 	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/logtail"
+	"github.com/backyonatan-alt/aegis/backend/internal/pipeline"
 	"github.com/backyonatan-alt/aegis/backend/internal/pulse"
 	"github.com/backyonatan-alt/aegis/backend/internal/store"
+	"github.com/backyonatan-alt/aegis/backend/internal/supervisor"
+	"github.com/backyonatan-alt/aegis/backend/internal/tenant"
 )
 
 // Server holds dependencies for HTTP handlers.
 type Server struct {
-	cfg   *config.Config
-	cache *cache.Cache
-	store store.Store
-	pulse *pulse.Tracker
+	cfg      *config.Config
+	cache    *cache.Cache
+	store    store.Store
+	pulse    *pulse.Tracker
+	pipeline *pipeline.Pipeline
+	fetcher  *fetcher.Fetcher
+	tenants  *tenant.Registry
+
+	// coldStart coalesces concurrent cache-miss loads of the latest snapshot.
+	coldStart singleflight.Group
+	deltas    deltaCache
+	repins    repinCache
+	tiles     tileCache
+	timelines responseCache
+	tracks    responseCache
+	hot       hotState
+	streams   streamHub
+
+	logs   *logtail.Buffer
+	jobs   *supervisor.Supervisor
+	reload func()
+	alerts []AlertChannel
+	// shutdown is closed by CloseStreams to end long-lived responses.
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
 }
 
-func New(cfg *config.Config, cache *cache.Cache, store store.Store) *Server {
+func New(cfg *config.Config, cache *cache.Cache, store store.Store, pipeline *pipeline.Pipeline, fetcher *fetcher.Fetcher, tenants *tenant.Registry) *Server {
+	tracker := pulse.NewTracker(store)
+	tracker.SetPrivacy(pulse.Privacy{MinCount: cfg.PulsePrivacy.MinCount, RoundTo: cfg.PulsePrivacy.RoundTo})
 	return &Server{
-		cfg:   cfg,
-		cache: cache,
-		store: store,
-		pulse: pulse.NewTracker(),
+		cfg:      cfg,
+		cache:    cache,
+		store:    store,
+		pulse:    tracker,
+		pipeline: pipeline,
+		fetcher:  fetcher,
+		tenants:  tenants,
+		shutdown: make(chan struct{}),
 	}
 }
 
+// SetLogTail attaches the buffer behind the admin log stream, which is
+// unavailable until it is set.
+func (s *Server) SetLogTail(b *logtail.Buffer) {
+	s.logs = b
+}
+
+// SetJobs attaches the supervisor whose job states /healthz reports.
+func (s *Server) SetJobs(j *supervisor.Supervisor) {
+	s.jobs = j
+}
+
+// SetReload attaches the func that restarts the app with freshly loaded
+// configuration. Without one the admin reload route is unavailable.
+func (s *Server) SetReload(fn func()) {
+	s.reload = fn
+}
+
+// Pulse returns the visitor tracker behind /api/pulse.
+func (s *Server) Pulse() *pulse.Tracker {
+	return s.pulse
+}
+
 // Router returns the HTTP handler with all routes registered.
 func (s *Server) Router() http.Handler {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/data", s.handleData)
-	mux.HandleFunc("/api/pulse", s.handlePulse)
-	mux.HandleFunc("/api/radar-ideas", s.handleRadarIdea)
-	mux.HandleFunc("/healthz", s.handleHealth)
-	return s.corsMiddleware(mux)
+	handle(mux, "/api/data", s.handleData, http.MethodGet)
+	handle(mux, "/api/data/at", s.handleDataAt, http.MethodGet)
+	handle(mux, "/api/data/delta", s.handleDataDelta, http.MethodGet)
+	handle(mux, "/api/stream", s.handleStream, http.MethodGet)
+	handle(mux, "/api/embed", s.handleEmbed, http.MethodGet)
+	handle(mux, "/api/v1/indicator", s.handleIndicator, http.MethodGet)
+	handle(mux, "/api/meta", s.handleMeta, http.MethodGet)
+	handle(mux, "/api/meta/schemas", s.handleMetaSchemas, http.MethodGet)
+	handle(mux, "/api/map", s.handleMap, http.MethodGet)
+	handle(mux, "/api/tracks", s.handleTracks, http.MethodGet)
+	handle(mux, "/api/datasets", s.handleDatasets, http.MethodGet)
+	handle(mux, "/api/weather/tiles/{layer}/{z}/{x}/{y}", s.handleWeatherTile, http.MethodGet)
+	handle(mux, "/api/pulse", s.handlePulse, http.MethodGet, http.MethodPost)
+	handle(mux, "/api/pulse/countries", s.handlePulseCountries, http.MethodGet)
+	handle(mux, "/api/timeline", s.handleTimeline, http.MethodGet)
+	handle(mux, "/api/incidents", s.handleIncidents, http.MethodGet)
+	handle(mux, "/api/webhooks", s.handleWebhooks, http.MethodGet, http.MethodPost, http.MethodDelete)
+	handle(mux, "/api/history", s.handleHistory, http.MethodGet)
+	handle(mux, "/api/signals/{name}/history", s.handleSignalHistory, http.MethodGet)
+	handle(mux, "/api/radar-ideas", s.handleRadarIdea, http.MethodPost)
+	handle(mux, "/api/admin/run", s.handleAdminRun, http.MethodGet, http.MethodPost)
+	handle(mux, "/api/admin/keywords", s.handleAdminKeywords, http.MethodGet, http.MethodPut)
+	handle(mux, "/api/admin/keywords/test", s.handleAdminKeywordsTest, http.MethodPost)
+	handle(mux, "/api/admin/polymarket-rules", s.handleAdminMarketRules, http.MethodGet, http.MethodPut)
+	handle(mux, "/api/admin/polymarket-rules/test", s.handleAdminMarketRulesTest, http.MethodPost)
+	handle(mux, "/api/admin/model-report", s.handleAdminModelReport, http.MethodGet, http.MethodPost)
+	handle(mux, "/api/admin/tenants", s.handleAdminTenants, http.MethodPost)
+	handle(mux, "/api/admin/tenants/usage", s.handleAdminTenantUsage, http.MethodGet)
+	handle(mux, "/api/admin/annotations", s.handleAdminAnnotation, http.MethodPost)
+	handle(mux, "/api/admin/logs", s.handleAdminLogs, http.MethodGet)
+	handle(mux, "/api/admin/cache", s.handleAdminCache, http.MethodGet, http.MethodDelete)
+	handle(mux, "/api/admin/config/overrides", s.handleAdminConfigOverrides, http.MethodGet, http.MethodPut, http.MethodDelete)
+	handle(mux, "/api/admin/config/reload", s.handleAdminConfigReload, http.MethodPost)
+	handle(mux, "/api/admin/alerts/test", s.handleAdminAlertTest, http.MethodPost)
+	handle(mux, "/api/admin/signal-overrides", s.handleAdminSignalOverrides, http.MethodGet, http.MethodPut, http.MethodDelete)
+	handle(mux, "/api/admin/pulse/baselines", s.handleAdminPulseBaselines, http.MethodGet, http.MethodPut)
+	handle(mux, "/api/admin/pulse/baselines/observed", s.handleAdminPulseBaselinesObserved, http.MethodGet)
+	handle(mux, "/admin", s.handleDashboard, http.MethodGet)
+	handle(mux, "/admin/{action}", s.handleDashboardAction, http.MethodPost)
+	handle(mux, "/healthz", s.handleHealth, http.MethodGet)
+	return s.compressMiddleware(s.corsMiddleware(s.tenantMiddleware(mux)))
+}
+
+// MetricsRouter serves /metrics alone, for the listener on MetricsAddr.
+func MetricsRouter() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.Handler())
+	return mux
+}
+
+// handle registers h on path for the given methods. GET routes also answer
+// HEAD (the ServeMux matches HEAD against GET patterns and net/http discards
+// the body), and OPTIONS is answered centrally with the allowed methods.
+// Any other method gets a 405 with an Allow header from the ServeMux.
+// Bodies of non-GET requests are capped at maxBodyBytes.
+func handle(mux *http.ServeMux, path string, h http.HandlerFunc, methods ...string) {
+	allowed := append([]string{}, methods...)
+	if slices.Contains(methods, http.MethodGet) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	allowed = append(allowed, http.MethodOptions)
+	allow := strings.Join(allowed, ", ")
+
+	for _, m := range methods {
+		if m == http.MethodGet {
+			mux.HandleFunc(m+" "+path, h)
+		} else {
+			mux.HandleFunc(m+" "+path, limitBody(h))
+		}
+	}
+	mux.HandleFunc(http.MethodOptions+" "+path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		w.Header().Set("Access-Control-Allow-Methods", allow)
+		w.WriteHeader(http.StatusNoContent)
+	})
 }
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	defaultSignalHistoryRange = 7 * 24 * time.Hour
+	maxSignalHistoryRange     = 90 * 24 * time.Hour
+)
+
+// historyPoint is one stored reading. Value is the risk after
+// normalization, or the risk itself when none was requested.
+type historyPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Risk      int     `json:"risk"`
+	Value     float64 `json:"value"`
+}
+
+// historyStats describes the raw series the normalization was derived from.
+type historyStats struct {
+	Count  int     `json:"count"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+}
+
+// handleSignalHistory returns one signal's per-run risk between ?from= and
+// ?to= (RFC 3339, default the last 7 days, at most 90), optionally
+// normalized with ?normalize=zscore or minmax so series on different scales
+// can be overlaid.
+func (s *Server) handleSignalHistory(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, ok := fetcher.Lookup(name); !ok {
+		http.Error(w, `{"error":"unknown signal"}`, http.StatusNotFound)
+		return
+	}
+
+	q := r.URL.Query()
+	now := time.Now()
+	from, to, msg := parseTimeRange(q, now, defaultSignalHistoryRange, maxSignalHistoryRange)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	normalize := q.Get("normalize")
+	switch normalize {
+	case "", "zscore", "minmax":
+	default:
+		http.Error(w, `{"error":"normalize must be zscore or minmax"}`, http.StatusBadRequest)
+		return
+	}
+
+	rows, err := s.store.SignalScoresBetween(r.Context(), name, from, to)
+	if err != nil {
+		slog.Error("failed to load signal history", "signal", name, "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	points, stats := normalizeHistory(rows, normalize)
+
+	w.Header().Set("Content-Type", "application/json")
+	// Ranges that ended well in the past can't gain readings
+	if now.Sub(to) > time.Hour {
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=300")
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"signal":    name,
+		"from":      from.UTC().Format(time.RFC3339),
+		"to":        to.UTC().Format(time.RFC3339),
+		"normalize": normalize,
+		"stats":     stats,
+		"points":    points,
+	})
+}
+
+// parseTimeRange reads ?from= and ?to= as RFC 3339 timestamps. to defaults
+// to now and from to def before to; the range may span at most limit. On bad
+// input it returns the JSON error body to send.
+func parseTimeRange(q url.Values, now time.Time, def, limit time.Duration) (from, to time.Time, msg string) {
+	to = now
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, `{"error":"to must be an RFC 3339 timestamp"}`
+		}
+		to = t
+	}
+	from = to.Add(-def)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return from, to, `{"error":"from must be an RFC 3339 timestamp"}`
+		}
+		from = t
+	}
+	if !from.Before(to) || to.Sub(from) > limit {
+		days := int(limit / (24 * time.Hour))
+		return from, to, fmt.Sprintf(`{"error":"from must be before to and at most %d days earlier"}`, days)
+	}
+	return from, to, ""
+}
+
+// normalizeHistory maps rows onto the requested scale. zscore uses the
+// population standard deviation; a flat series normalizes to all zeros
+// under either method rather than dividing by zero.
+func normalizeHistory(rows []model.SignalScoreRow, method string) ([]historyPoint, historyStats) {
+	points := make([]historyPoint, 0, len(rows))
+	stats := historyStats{Count: len(rows)}
+	if len(rows) == 0 {
+		return points, stats
+	}
+
+	stats.Min, stats.Max = rows[0].Risk, rows[0].Risk
+	sum := 0.0
+	for _, r := range rows {
+		sum += float64(r.Risk)
+		stats.Min = min(stats.Min, r.Risk)
+		stats.Max = max(stats.Max, r.Risk)
+	}
+	stats.Mean = sum / float64(len(rows))
+	variance := 0.0
+	for _, r := range rows {
+		d := float64(r.Risk) - stats.Mean
+		variance += d * d
+	}
+	stats.StdDev = math.Sqrt(variance / float64(len(rows)))
+
+	for _, r := range rows {
+		v := float64(r.Risk)
+		switch method {
+		case "zscore":
+			v = 0
+			if stats.StdDev > 0 {
+				v = (float64(r.Risk) - stats.Mean) / stats.StdDev
+			}
+		case "minmax":
+			v = 0
+			if stats.Max > stats.Min {
+				v = float64(r.Risk-stats.Min) / float64(stats.Max-stats.Min)
+			}
+		}
+		points = append(points, historyPoint{
+			Timestamp: r.CreatedAt.UnixMilli(),
+			Risk:      r.Risk,
+			Value:     math.Round(v*10000) / 10000,
+		})
+	}
+	stats.Mean = math.Round(stats.Mean*100) / 100
+	stats.StdDev = math.Round(stats.StdDev*100) / 100
+	return points, stats
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	// maxSignalOverride bounds how long a hold may last, so a forgotten one
+	// can't freeze a signal indefinitely.
+	maxSignalOverride     = 7 * 24 * time.Hour
+	maxOverrideReasonSize = 500
+)
+
+type signalOverrideRequest struct {
+	Signal string `json:"signal"`
+	// Risk is the value to hold at; omitted, the signal is pinned at its
+	// risk in the current snapshot.
+	Risk      *int   `json:"risk"`
+	Reason    string `json:"reason"`
+	Duration  string `json:"duration"`
+	CreatedBy string `json:"created_by"`
+}
+
+// handleAdminSignalOverrides lists (GET), sets (PUT) or removes (DELETE
+// ?signal=) operator holds on signal risk, e.g. {"signal":"flight",
+// "risk":40,"reason":"OpenSky broken","duration":"6h"}. A held signal is
+// marked "overridden" in snapshots from the next run until the hold
+// expires.
+func (s *Server) handleAdminSignalOverrides(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	switch r.Method {
+	case http.MethodGet:
+		overrides, err := s.store.SignalOverrides(r.Context(), time.Now())
+		if err != nil {
+			slog.Error("failed to load signal overrides", "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if overrides == nil {
+			overrides = []model.SignalOverride{}
+		}
+		json.NewEncoder(w).Encode(overrides)
+
+	case http.MethodPut:
+		var req signalOverrideRequest
+		if !decodeJSON(w, r, &req, maxBodyBytes) {
+			return
+		}
+		if _, ok := fetcher.Lookup(req.Signal); !ok {
+			http.Error(w, `{"error":"unknown signal"}`, http.StatusBadRequest)
+			return
+		}
+		req.Reason = strings.TrimSpace(req.Reason)
+		if req.Reason == "" || len(req.Reason) > maxOverrideReasonSize {
+			http.Error(w, `{"error":"reason must be 1-500 characters"}`, http.StatusBadRequest)
+			return
+		}
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 || d > maxSignalOverride {
+			http.Error(w, `{"error":"duration must be between 0 and 168h"}`, http.StatusBadRequest)
+			return
+		}
+
+		var risk int
+		if req.Risk != nil {
+			risk = *req.Risk
+		} else {
+			current, ok := s.currentSignalRisk(r, req.Signal)
+			if !ok {
+				http.Error(w, `{"error":"no current value to pin; give risk"}`, http.StatusConflict)
+				return
+			}
+			risk = current
+		}
+		if risk < 0 || risk > 100 {
+			http.Error(w, `{"error":"risk must be 0-100"}`, http.StatusBadRequest)
+			return
+		}
+
+		// Basic credentials name the operator; bearer tokens are anonymous
+		createdBy, _, _ := r.BasicAuth()
+		if req.CreatedBy != "" {
+			createdBy = req.CreatedBy
+		}
+		if createdBy == "" {
+			createdBy = "admin"
+		}
+		now := time.Now().UTC()
+		o := model.SignalOverride{
+			Signal:    req.Signal,
+			Risk:      risk,
+			Reason:    req.Reason,
+			CreatedBy: createdBy,
+			CreatedAt: now,
+			ExpiresAt: now.Add(d),
+		}
+		if err := s.store.SetSignalOverride(r.Context(), o); err != nil {
+			slog.Error("failed to save signal override", "signal", o.Signal, "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		slog.Info("signal override set", "signal", o.Signal, "risk", o.Risk, "expires_at", o.ExpiresAt, "created_by", createdBy)
+		json.NewEncoder(w).Encode(o)
+
+	case http.MethodDelete:
+		signal := r.URL.Query().Get("signal")
+		if signal == "" {
+			http.Error(w, `{"error":"signal is required"}`, http.StatusBadRequest)
+			return
+		}
+		deleted, err := s.store.DeleteSignalOverride(r.Context(), signal)
+		if err != nil {
+			slog.Error("failed to delete signal override", "signal", signal, "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			http.Error(w, `{"error":"no override for signal"}`, http.StatusNotFound)
+			return
+		}
+		slog.Info("signal override removed", "signal", signal)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// currentSignalRisk reads a signal's risk from the latest snapshot.
+func (s *Server) currentSignalRisk(r *http.Request, name string) (int, bool) {
+	data, err := s.snapshot(r.Context())
+	if err != nil || data == nil {
+		return 0, false
+	}
+	var snap model.Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return 0, false
+	}
+	sig := snap.Signal(name)
+	if sig == nil {
+		return 0, false
+	}
+	return sig.Risk, true
+}
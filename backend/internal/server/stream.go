@@ -0,0 +1,161 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/cache"
+)
+
+// maxStreamClients caps concurrent /api/stream connections; each holds a
+// goroutine and a socket for up to the stream timeout.
+const maxStreamClients = 1000
+
+// streamRetry is the reconnect delay suggested to EventSource clients.
+const streamRetry = 10 * time.Second
+
+// streamHub fans newly cached snapshots out to the connected streams.
+// Each subscriber channel holds at most the latest snapshot: a slow client
+// skips versions instead of holding up the pipeline.
+type streamHub struct {
+	mu     sync.Mutex
+	subs   map[chan []byte]struct{}
+	closed bool
+}
+
+func (h *streamHub) subscribe() (chan []byte, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed || len(h.subs) >= maxStreamClients {
+		return nil, false
+	}
+	if h.subs == nil {
+		h.subs = make(map[chan []byte]struct{})
+	}
+	ch := make(chan []byte, 1)
+	h.subs[ch] = struct{}{}
+	return ch, true
+}
+
+func (h *streamHub) unsubscribe(ch chan []byte) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+}
+
+// close ends every open stream and refuses new ones.
+func (h *streamHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for ch := range h.subs {
+		close(ch)
+		delete(h.subs, ch)
+	}
+}
+
+func (h *streamHub) publish(data []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		// Replace an undelivered snapshot with the newer one
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- data
+	}
+}
+
+// SnapshotUpdated pushes a newly cached snapshot to every open stream. It
+// implements pipeline.Notifier.
+func (s *Server) SnapshotUpdated(data []byte) {
+	s.streams.publish(data)
+}
+
+// CloseStreams ends the open event and log streams so a graceful shutdown need not
+// wait out their timeout. Register it with http.Server.RegisterOnShutdown.
+func (s *Server) CloseStreams() {
+	s.streams.close()
+	s.shutdownOnce.Do(func() { close(s.shutdown) })
+}
+
+// handleStream serves the latest snapshot as Server-Sent Events: the
+// current one on connect, then each new one as the pipeline caches it.
+// Event IDs are snapshot hashes, so a reconnecting client that already
+// holds the current snapshot (Last-Event-ID) is not sent it again.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	ch, ok := s.streams.subscribe()
+	if !ok {
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, `{"error":"too many streams"}`, http.StatusServiceUnavailable)
+		return
+	}
+	defer s.streams.unsubscribe(ch)
+
+	flush, err := s.beginStream(w)
+	if err != nil {
+		slog.Error("stream: failed to start", "error", err)
+		http.Error(w, `{"error":"streaming unsupported"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keep nginx-style proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+
+	lastID := r.Header.Get("Last-Event-ID")
+	send := func(data []byte) error {
+		hash := cache.Hash(data)
+		if hash == lastID {
+			return nil
+		}
+		lastID = hash
+		// Snapshots are compact JSON, so they fit on a single data line
+		if _, err := fmt.Fprintf(w, "event: snapshot\nid: %s\ndata: %s\n\n", hash, data); err != nil {
+			return err
+		}
+		return flush()
+	}
+
+	data, err := s.snapshot(r.Context())
+	if err != nil {
+		slog.Error("stream: failed to load snapshot", "error", err)
+	}
+	if data != nil {
+		if err := send(data); err != nil {
+			return
+		}
+	} else if err := flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(s.cfg.HTTP.StreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := send(data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			if err := flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/tenant"
+)
+
+// apiKeyHeader carries a tenant's API key. Requests without one are served
+// anonymously as before.
+const apiKeyHeader = "X-API-Key"
+
+// tenantMiddleware authenticates keyed requests, applies the tenant's rate
+// limit and records the request and response size against its usage.
+func (s *Server) tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(apiKeyHeader)
+		if key == "" || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		t, err := s.tenants.Lookup(r.Context(), key)
+		if err != nil {
+			slog.Error("failed to look up tenant", "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if t == nil {
+			http.Error(w, `{"error":"invalid API key"}`, http.StatusUnauthorized)
+			return
+		}
+
+		now := time.Now()
+		if ok, wait := s.tenants.Allow(t, now); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, `{"error":"rate limit exceeded"}`, http.StatusTooManyRequests)
+			return
+		}
+
+		cw := &countingWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+		s.tenants.Record(t.ID, cw.n, now)
+	})
+}
+
+// countingWriter counts response body bytes.
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (c *countingWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// handleAdminTenants creates a tenant and returns its API key. The key is
+// only stored hashed, so this response is the one chance to read it.
+func (s *Server) handleAdminTenants(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	var req struct {
+		Name      string `json:"name"`
+		RateLimit int    `json:"rate_limit"`
+	}
+	if !decodeJSON(w, r, &req, maxBodyBytes) {
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, `{"error":"name is required"}`, http.StatusBadRequest)
+		return
+	}
+	if req.RateLimit < 0 {
+		http.Error(w, `{"error":"rate_limit must not be negative"}`, http.StatusBadRequest)
+		return
+	}
+
+	key, err := tenant.GenerateKey()
+	if err != nil {
+		slog.Error("failed to generate API key", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	t, err := s.store.CreateTenant(r.Context(), req.Name, tenant.HashKey(key), req.RateLimit)
+	if err != nil {
+		slog.Error("failed to create tenant", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	slog.Info("tenant created", "tenant_id", t.ID, "name", t.Name, "rate_limit", t.RateLimit)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{
+		"tenant":  t,
+		"api_key": key,
+	})
+}
+
+// tenantUsageReport is one tenant's totals over the report window plus the
+// daily rows they sum.
+type tenantUsageReport struct {
+	TenantID int64               `json:"tenant_id"`
+	Name     string              `json:"name"`
+	Requests int64               `json:"requests"`
+	Bytes    int64               `json:"bytes"`
+	Days     []model.TenantUsage `json:"days"`
+}
+
+// handleAdminTenantUsage reports per-tenant usage for the last ?days= UTC
+// days (default 30, max 366), including today. Counts from the last minute
+// may not be flushed yet.
+func (s *Server) handleAdminTenantUsage(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	days := 30
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 366 {
+			http.Error(w, `{"error":"days must be between 1 and 366"}`, http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1))
+
+	rows, err := s.store.TenantUsageSince(r.Context(), since)
+	if err != nil {
+		slog.Error("failed to load tenant usage", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	reports := []tenantUsageReport{}
+	for _, u := range rows {
+		if n := len(reports); n == 0 || reports[n-1].TenantID != u.TenantID {
+			reports = append(reports, tenantUsageReport{TenantID: u.TenantID, Name: u.Name})
+		}
+		rep := &reports[len(reports)-1]
+		rep.Requests += u.Requests
+		rep.Bytes += u.Bytes
+		u.Name = ""
+		rep.Days = append(rep.Days, u)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	json.NewEncoder(w).Encode(map[string]any{
+		"since":   since.Format("2006-01-02"),
+		"tenants": reports,
+	})
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/timeline"
+)
+
+const (
+	defaultTimelineHours = 72
+	maxTimelineHours     = 24 * 7
+	maxAnnotationLength  = 2000
+	// timelineTTL is how long a window's timeline is served from memory,
+	// matching its max-age.
+	timelineTTL = time.Minute
+)
+
+func (s *Server) handleTimeline(w http.ResponseWriter, r *http.Request) {
+	hours := defaultTimelineHours
+	if v := r.URL.Query().Get("hours"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTimelineHours {
+			http.Error(w, `{"error":"hours must be between 1 and 168"}`, http.StatusBadRequest)
+			return
+		}
+		hours = n
+	}
+
+	data, err := s.timelines.get(strconv.Itoa(hours), timelineTTL, func() ([]byte, error) {
+		// Detached from the request, since other requests may wait on it
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		to := time.Now().UTC()
+		from := to.Add(-time.Duration(hours) * time.Hour)
+		events, err := timeline.Build(ctx, s.store, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{
+			"from":   from,
+			"to":     to,
+			"events": events,
+		})
+	})
+	if err != nil {
+		slog.Error("failed to build timeline", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=300")
+	w.Write(data)
+}
+
+func (s *Server) handleAdminAnnotation(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	var body struct {
+		Body       string     `json:"body"`
+		OccurredAt *time.Time `json:"occurred_at"`
+	}
+	if !decodeJSON(w, r, &body, maxBodyBytes) {
+		return
+	}
+	body.Body = strings.TrimSpace(body.Body)
+	if body.Body == "" || len(body.Body) > maxAnnotationLength {
+		http.Error(w, `{"error":"body must be 1-2000 characters"}`, http.StatusBadRequest)
+		return
+	}
+	occurredAt := time.Now().UTC()
+	if body.OccurredAt != nil {
+		occurredAt = body.OccurredAt.UTC()
+	}
+
+	if err := s.store.SaveAnnotation(r.Context(), body.Body, occurredAt); err != nil {
+		slog.Error("failed to save annotation", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{"success": true})
+}
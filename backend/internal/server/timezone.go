@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/cache"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// viewerLocation parses the optional ?tz= IANA zone. It returns nil when
+// the parameter is absent.
+func viewerLocation(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	if tz == "" {
+		return nil, nil
+	}
+	return time.LoadLocation(tz)
+}
+
+// maxRepinned bounds the memoized repinned snapshots. Viewers cluster in a
+// handful of zones, so the memo is simply dropped when it fills.
+const maxRepinned = 256
+
+type repinKey struct {
+	hash     string
+	zone     string
+	boundary int64
+}
+
+type repinEntry struct {
+	data []byte
+	etag string
+}
+
+// repinCache memoizes repinned snapshots by source snapshot, zone and pin
+// boundary, so polling viewers in one zone share a single history query
+// and re-encode until the snapshot changes or the next boundary passes.
+type repinCache struct {
+	mu      sync.Mutex
+	entries map[repinKey]repinEntry
+}
+
+func (c *repinCache) get(k repinKey) (repinEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[k]
+	return e, ok
+}
+
+func (c *repinCache) put(k repinKey, e repinEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil || len(c.entries) >= maxRepinned {
+		c.entries = map[repinKey]repinEntry{}
+	}
+	c.entries[k] = e
+}
+
+// reset drops every memoized snapshot.
+func (c *repinCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// repinned returns the snapshot with the given hash repinned to loc at at,
+// and the ETag of the repinned bytes, from the memo when it can.
+func (s *Server) repinned(ctx context.Context, data []byte, hash string, loc *time.Location, at time.Time) (repinEntry, error) {
+	k := repinKey{hash: hash, zone: loc.String(), boundary: risk.PinBoundary(at, loc).UnixMilli()}
+	if e, ok := s.repins.get(k); ok {
+		return e, nil
+	}
+	out, err := s.repinSnapshot(ctx, data, loc, at)
+	if err != nil {
+		return repinEntry{}, err
+	}
+	e := repinEntry{data: out, etag: `"` + cache.Hash(out) + `"`}
+	s.repins.put(k, e)
+	return e, nil
+}
+
+// repinSnapshot rewrites total_risk.history in a serialized snapshot so its
+// pinned points fall on loc's midnight and noon, rebuilt from the stored
+// runs up to at. The history keeps its original length.
+func (s *Server) repinSnapshot(ctx context.Context, data []byte, loc *time.Location, at time.Time) ([]byte, error) {
+	var snap map[string]any
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	totalRisk, ok := snap["total_risk"].(map[string]any)
+	if !ok {
+		return data, nil
+	}
+	hist, _ := totalRisk["history"].([]any)
+	points := max(len(hist), 1)
+
+	since := at.Add(-time.Duration(points+1) * 12 * time.Hour)
+	series, err := s.store.TotalRiskSeries(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := at.UnixMilli()
+	for len(series) > 0 && series[len(series)-1].Timestamp > cutoff {
+		series = series[:len(series)-1]
+	}
+	if len(series) == 0 {
+		return data, nil
+	}
+
+	totalRisk["history"] = risk.RepinHistory(series, at, loc, points)
+	snap["history_timezone"] = loc.String()
+	return json.Marshal(snap)
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+const (
+	defaultTrackWindow = 6 * time.Hour
+	// maxTrackSamples is the most points a track gets in any window; longer
+	// windows keep each aircraft's latest position per window/maxTrackSamples.
+	maxTrackSamples = 120
+	// maxTrackPoints bounds a response, keeping the most recent points.
+	maxTrackPoints = 20000
+)
+
+// trackPoint is one replay sample; the aircraft identity is on the track.
+type trackPoint struct {
+	Timestamp int64   `json:"timestamp"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	Altitude  float64 `json:"altitude"`
+	Heading   float64 `json:"heading"`
+}
+
+type track struct {
+	ICAO     string       `json:"icao"`
+	Callsign string       `json:"callsign"`
+	Kind     string       `json:"kind"`
+	Points   []trackPoint `json:"points"`
+}
+
+func (s *Server) handleTracks(w http.ResponseWriter, r *http.Request) {
+	if !s.cfg.Tracks.Enabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	window := defaultTrackWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > s.cfg.Tracks.Retention {
+			http.Error(w, `{"error":"window must be a positive duration within the retention period"}`, http.StatusBadRequest)
+			return
+		}
+		// Whole hours, so the cache holds one entry per hour of retention
+		window = min(max(d.Round(time.Hour), time.Hour), s.cfg.Tracks.Retention)
+	}
+
+	// Points are stored once per run, so a response is good for a run
+	data, err := s.tracks.get(window.String(), s.cfg.RunInterval, func() ([]byte, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		points, err := s.store.TrackPointsSince(ctx, time.Now().Add(-window), window/maxTrackSamples, maxTrackPoints)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{
+			"window": window.String(),
+			"tracks": groupTracks(points),
+		})
+	})
+	if err != nil {
+		slog.Error("failed to load track points", "error", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=60, s-maxage=300")
+	w.Write(data)
+}
+
+// groupTracks folds points, already ordered by aircraft and time, into one
+// track per aircraft.
+func groupTracks(points []model.TrackPoint) []track {
+	tracks := []track{}
+	for _, p := range points {
+		if n := len(tracks); n == 0 || tracks[n-1].ICAO != p.ICAO {
+			tracks = append(tracks, track{ICAO: p.ICAO, Callsign: p.Callsign, Kind: p.Kind})
+		}
+		t := &tracks[len(tracks)-1]
+		if p.Callsign != "" {
+			t.Callsign = p.Callsign
+		}
+		t.Points = append(t.Points, trackPoint{
+			Timestamp: p.ObservedAt.UnixMilli(),
+			Lat:       p.Lat,
+			Lon:       p.Lon,
+			Altitude:  p.Altitude,
+			Heading:   p.Heading,
+		})
+	}
+	return tracks
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+)
+
+const (
+	// maxCachedTiles bounds the tile cache. The imagery region spans a few
+	// hundred tiles across every layer and zoom.
+	maxCachedTiles = 512
+	// tileTTL matches OpenWeather's own refresh interval.
+	tileTTL = 10 * time.Minute
+)
+
+// tileCache is a small LRU of weather tiles keyed by layer/z/x/y, so each
+// tile costs one OpenWeather call per refresh however many viewers load it
+// and the key's quota isn't spent on repeats.
+type tileCache struct {
+	mu      sync.Mutex
+	order   *list.List // of *cachedTile, most recently used first
+	entries map[string]*list.Element
+}
+
+type cachedTile struct {
+	key     string
+	data    []byte
+	fetched time.Time
+}
+
+func (c *tileCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	t := el.Value.(*cachedTile)
+	if time.Since(t.fetched) > tileTTL {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return t.data, true
+}
+
+func (c *tileCache) put(key string, data []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.order = list.New()
+		c.entries = make(map[string]*list.Element)
+	}
+	if el, ok := c.entries[key]; ok {
+		el.Value = &cachedTile{key: key, data: data, fetched: time.Now()}
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cachedTile{key: key, data: data, fetched: time.Now()})
+	if c.order.Len() > maxCachedTiles {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedTile).key)
+	}
+}
+
+// handleWeatherTile proxies OpenWeather map tiles so the API key stays
+// server-side. Tiles are cached in memory and at the edge for ten minutes,
+// matching OpenWeather's own refresh interval.
+func (s *Server) handleWeatherTile(w http.ResponseWriter, r *http.Request) {
+	z, errZ := strconv.Atoi(r.PathValue("z"))
+	x, errX := strconv.Atoi(r.PathValue("x"))
+	y, errY := strconv.Atoi(strings.TrimSuffix(r.PathValue("y"), ".png"))
+	if errZ != nil || errX != nil || errY != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	layer := r.PathValue("layer")
+	key := fmt.Sprintf("%s/%d/%d/%d", layer, z, x, y)
+	tile, ok := s.tiles.get(key)
+	if !ok {
+		var err error
+		tile, err = s.fetcher.WeatherTile(r.Context(), layer, z, x, y)
+		if errors.Is(err, fetcher.ErrTileOutOfRange) {
+			http.NotFound(w, r)
+			return
+		}
+		if err != nil {
+			slog.Warn("failed to fetch weather tile", "error", err)
+			http.Error(w, `{"error":"upstream unavailable"}`, http.StatusBadGateway)
+			return
+		}
+		s.tiles.put(key, tile)
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=600")
+	w.Write(tile)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/webhook"
+)
+
+const maxWebhookURLSize = 2048
+
+type webhookRequest struct {
+	URL       string `json:"url"`
+	Threshold int    `json:"threshold"`
+	// Direction is "up" (the default), "down" or "both".
+	Direction string `json:"direction"`
+	Payload   string `json:"payload"`
+}
+
+// handleWebhooks lists (GET), registers (POST) or removes (DELETE ?id=)
+// webhooks called when total risk crosses a threshold between runs, e.g.
+// {"url":"https://example.com/hook","threshold":60,"direction":"up",
+// "payload":"diff"}. The signing secret is returned once, on registration.
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	switch r.Method {
+	case http.MethodGet:
+		hooks, err := s.store.Webhooks(r.Context())
+		if err != nil {
+			slog.Error("failed to load webhooks", "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if hooks == nil {
+			hooks = []model.Webhook{}
+		}
+		for i := range hooks {
+			hooks[i].Secret = ""
+		}
+		json.NewEncoder(w).Encode(hooks)
+
+	case http.MethodPost:
+		var req webhookRequest
+		if !decodeJSON(w, r, &req, maxBodyBytes) {
+			return
+		}
+		u, err := url.Parse(req.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(req.URL) > maxWebhookURLSize {
+			http.Error(w, `{"error":"url must be an absolute http or https URL"}`, http.StatusBadRequest)
+			return
+		}
+		if req.Threshold < 1 || req.Threshold > 100 {
+			http.Error(w, `{"error":"threshold must be 1-100"}`, http.StatusBadRequest)
+			return
+		}
+		switch req.Direction {
+		case "":
+			req.Direction = webhook.DirectionUp
+		case webhook.DirectionUp, webhook.DirectionDown, webhook.DirectionBoth:
+		default:
+			http.Error(w, `{"error":"direction must be up, down or both"}`, http.StatusBadRequest)
+			return
+		}
+		mode, err := webhook.ParsePayloadMode(req.Payload)
+		if err != nil {
+			http.Error(w, `{"error":"payload must be full or diff"}`, http.StatusBadRequest)
+			return
+		}
+
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			slog.Error("failed to generate webhook secret", "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		hook, err := s.store.CreateWebhook(r.Context(), model.Webhook{
+			URL:         req.URL,
+			Threshold:   req.Threshold,
+			Direction:   req.Direction,
+			PayloadMode: string(mode),
+			Secret:      hex.EncodeToString(secret),
+		})
+		if err != nil {
+			slog.Error("failed to save webhook", "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		slog.Info("webhook registered", "id", hook.ID, "host", u.Host, "threshold", hook.Threshold, "direction", hook.Direction)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(hook)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			http.Error(w, `{"error":"id is required"}`, http.StatusBadRequest)
+			return
+		}
+		deleted, err := s.store.DeleteWebhook(r.Context(), id)
+		if err != nil {
+			slog.Error("failed to delete webhook", "id", id, "error", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if !deleted {
+			http.Error(w, `{"error":"webhook not found"}`, http.StatusNotFound)
+			return
+		}
+		slog.Info("webhook removed", "id", id)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,169 @@
+// Package spam implements lightweight heuristics for filtering user-submitted
+// text (radar ideas) before it reaches the review queue.
+package spam
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var urlPattern = regexp.MustCompile(`(?i)(https?://|www\.|\b[a-z0-9-]+\.(com|net|org|io|ru|xyz|top|info|biz|link|click)\b)`)
+
+// blockedWords is a short list of profanity and common spam terms. Matching
+// is done on whole lowercase words.
+var blockedWords = map[string]bool{
+	"fuck": true, "shit": true, "cunt": true, "nigger": true, "faggot": true,
+	"casino": true, "viagra": true, "airdrop": true, "porn": true, "escort": true,
+	"betting": true, "payday": true,
+}
+
+const (
+	// minEntropy rejects repetitive text such as "aaaaaaa" or "!!!!!!".
+	minEntropy = 2.0
+	// minLetterRatio rejects text that is mostly digits, symbols or emoji.
+	minLetterRatio = 0.5
+	// maxURLs is the number of links tolerated before text is rejected.
+	maxURLs = 0
+)
+
+// Result is the outcome of checking a piece of text.
+type Result struct {
+	OK       bool
+	Reason   string
+	Language string
+}
+
+// Check runs all heuristics against text and reports the first failure.
+func Check(text string) Result {
+	lang := DetectScript(text)
+
+	if len(urlPattern.FindAllString(text, -1)) > maxURLs {
+		return Result{Reason: "url", Language: lang}
+	}
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		if blockedWords[word] {
+			return Result{Reason: "blocked_word", Language: lang}
+		}
+	}
+	if lang == "unknown" {
+		return Result{Reason: "language", Language: lang}
+	}
+	if mixedScript(text) {
+		return Result{Reason: "mixed_script", Language: lang}
+	}
+	if len([]rune(text)) >= 8 && Entropy(text) < minEntropy {
+		return Result{Reason: "low_entropy", Language: lang}
+	}
+	return Result{OK: true, Language: lang}
+}
+
+// DetectScript returns the dominant script of text as a lowercase Unicode
+// script name such as "latin", "arabic" (including Persian) or "han", or
+// "unknown" when letters make up too little of the text to tell. Any
+// script is accepted; only words mixing scripts are treated as suspect
+// (see mixedScript).
+func DetectScript(text string) string {
+	counts := map[string]int{}
+	letters, total := 0, 0
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		counts[scriptOf(r)]++
+	}
+	if total == 0 || float64(letters)/float64(total) < minLetterRatio {
+		return "unknown"
+	}
+
+	best, bestCount := "unknown", 0
+	for script, n := range counts {
+		if n > bestCount || (n == bestCount && script < best) {
+			best, bestCount = script, n
+		}
+	}
+	return best
+}
+
+// commonScripts are checked before the full unicode.Scripts table, which
+// covers the rest.
+var commonScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"latin", unicode.Latin},
+	{"hebrew", unicode.Hebrew},
+	{"arabic", unicode.Arabic},
+	{"cyrillic", unicode.Cyrillic},
+}
+
+// cjkScripts are written together within one word, as in Japanese, and so
+// don't count as mixing.
+var cjkScripts = map[string]bool{"han": true, "hiragana": true, "katakana": true, "hangul": true}
+
+// scriptOf returns the lowercase name of the script letter r belongs to.
+func scriptOf(r rune) string {
+	for _, s := range commonScripts {
+		if unicode.Is(s.table, r) {
+			return s.name
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return strings.ToLower(name)
+		}
+	}
+	return "unknown"
+}
+
+// mixedScript reports whether any word of text mixes letters of different
+// scripts, such as a Cyrillic "а" inside "pаypal". Text in any one script,
+// and sentences switching script between words, pass.
+func mixedScript(text string) bool {
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	}) {
+		first := ""
+		for _, r := range word {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			script := scriptOf(r)
+			switch {
+			case script == "common" || script == "inherited":
+			case first == "":
+				first = script
+			case script != first && !(cjkScripts[script] && cjkScripts[first]):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Entropy returns the Shannon entropy of text in bits per character.
+func Entropy(text string) float64 {
+	freq := map[rune]int{}
+	n := 0
+	for _, r := range strings.ToLower(text) {
+		freq[r]++
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	var h float64
+	for _, c := range freq {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,413 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "aegis",
+	Subsystem: "store",
+	Name:      "query_duration_seconds",
+	Help:      "Duration of Store method calls.",
+	Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"method", "status"})
+
+// Instrumented wraps a Store, recording per-method latency histograms and
+// logging a warning for any call slower than the configured threshold.
+type Instrumented struct {
+	next Store
+	slow time.Duration
+}
+
+func NewInstrumented(next Store, slow time.Duration) *Instrumented {
+	return &Instrumented{next: next, slow: slow}
+}
+
+func (s *Instrumented) observe(method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	queryDuration.WithLabelValues(method, status).Observe(elapsed.Seconds())
+	if s.slow > 0 && elapsed >= s.slow {
+		slog.Warn("slow store query", "method", method, "duration", elapsed, "error", err)
+	}
+}
+
+func (s *Instrumented) SaveSnapshot(ctx context.Context, response []byte) (err error) {
+	defer func(start time.Time) { s.observe("SaveSnapshot", start, err) }(time.Now())
+	return s.next.SaveSnapshot(ctx, response)
+}
+
+func (s *Instrumented) ImportSnapshot(ctx context.Context, response []byte, createdAt time.Time) (_ bool, err error) {
+	defer func(start time.Time) { s.observe("ImportSnapshot", start, err) }(time.Now())
+	return s.next.ImportSnapshot(ctx, response, createdAt)
+}
+
+func (s *Instrumented) LatestSnapshot(ctx context.Context) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("LatestSnapshot", start, err) }(time.Now())
+	return s.next.LatestSnapshot(ctx)
+}
+
+func (s *Instrumented) SnapshotAt(ctx context.Context, t time.Time) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("SnapshotAt", start, err) }(time.Now())
+	return s.next.SnapshotAt(ctx, t)
+}
+
+func (s *Instrumented) SnapshotsBetween(ctx context.Context, from, to time.Time, fn SnapshotFunc) (err error) {
+	defer func(start time.Time) { s.observe("SnapshotsBetween", start, err) }(time.Now())
+	return s.next.SnapshotsBetween(ctx, from, to, fn)
+}
+
+func (s *Instrumented) TotalRiskSeries(ctx context.Context, since time.Time) (_ []model.TotalRiskPoint, err error) {
+	defer func(start time.Time) { s.observe("TotalRiskSeries", start, err) }(time.Now())
+	return s.next.TotalRiskSeries(ctx, since)
+}
+
+func (s *Instrumented) TotalRiskBetween(ctx context.Context, from, to time.Time) (_ []model.TotalRiskPoint, err error) {
+	defer func(start time.Time) { s.observe("TotalRiskBetween", start, err) }(time.Now())
+	return s.next.TotalRiskBetween(ctx, from, to)
+}
+
+func (s *Instrumented) Migrate(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("Migrate", start, err) }(time.Now())
+	return s.next.Migrate(ctx)
+}
+
+func (s *Instrumented) SaveRadarIdea(ctx context.Context, idea, countryCode string) (err error) {
+	defer func(start time.Time) { s.observe("SaveRadarIdea", start, err) }(time.Now())
+	return s.next.SaveRadarIdea(ctx, idea, countryCode)
+}
+
+func (s *Instrumented) SaveRejectedRadarIdea(ctx context.Context, idea, countryCode, reason, language string) (err error) {
+	defer func(start time.Time) { s.observe("SaveRejectedRadarIdea", start, err) }(time.Now())
+	return s.next.SaveRejectedRadarIdea(ctx, idea, countryCode, reason, language)
+}
+
+func (s *Instrumented) MigrateRadarIdeas(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateRadarIdeas", start, err) }(time.Now())
+	return s.next.MigrateRadarIdeas(ctx)
+}
+
+func (s *Instrumented) SaveAircraftCount(ctx context.Context, count int, observedAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SaveAircraftCount", start, err) }(time.Now())
+	return s.next.SaveAircraftCount(ctx, count, observedAt)
+}
+
+func (s *Instrumented) AircraftBaseline(ctx context.Context, hour, days int) (_ float64, _ int, err error) {
+	defer func(start time.Time) { s.observe("AircraftBaseline", start, err) }(time.Now())
+	return s.next.AircraftBaseline(ctx, hour, days)
+}
+
+func (s *Instrumented) MigrateAircraftCounts(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateAircraftCounts", start, err) }(time.Now())
+	return s.next.MigrateAircraftCounts(ctx)
+}
+
+func (s *Instrumented) SaveTankerCount(ctx context.Context, count int, observedAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SaveTankerCount", start, err) }(time.Now())
+	return s.next.SaveTankerCount(ctx, count, observedAt)
+}
+
+func (s *Instrumented) TankerBaseline(ctx context.Context, hour int, weekday time.Weekday, weeks int) (_ float64, _ int, err error) {
+	defer func(start time.Time) { s.observe("TankerBaseline", start, err) }(time.Now())
+	return s.next.TankerBaseline(ctx, hour, weekday, weeks)
+}
+
+func (s *Instrumented) MigrateTankerCounts(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateTankerCounts", start, err) }(time.Now())
+	return s.next.MigrateTankerCounts(ctx)
+}
+
+func (s *Instrumented) SaveVesselCounts(ctx context.Context, vessels, tankers int, observedAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SaveVesselCounts", start, err) }(time.Now())
+	return s.next.SaveVesselCounts(ctx, vessels, tankers, observedAt)
+}
+
+func (s *Instrumented) VesselBaseline(ctx context.Context, hour, days int) (_, _ float64, _ int, err error) {
+	defer func(start time.Time) { s.observe("VesselBaseline", start, err) }(time.Now())
+	return s.next.VesselBaseline(ctx, hour, days)
+}
+
+func (s *Instrumented) SaveCarrierSightings(ctx context.Context, carriers []string, observedAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SaveCarrierSightings", start, err) }(time.Now())
+	return s.next.SaveCarrierSightings(ctx, carriers, observedAt)
+}
+
+func (s *Instrumented) CarrierSightings(ctx context.Context, hour, days int) (_ [][]string, err error) {
+	defer func(start time.Time) { s.observe("CarrierSightings", start, err) }(time.Now())
+	return s.next.CarrierSightings(ctx, hour, days)
+}
+
+func (s *Instrumented) MigrateCarrierSightings(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateCarrierSightings", start, err) }(time.Now())
+	return s.next.MigrateCarrierSightings(ctx)
+}
+
+func (s *Instrumented) MigrateVesselCounts(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateVesselCounts", start, err) }(time.Now())
+	return s.next.MigrateVesselCounts(ctx)
+}
+
+func (s *Instrumented) SaveTrackPoints(ctx context.Context, kind string, positions []model.Position, observedAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SaveTrackPoints", start, err) }(time.Now())
+	return s.next.SaveTrackPoints(ctx, kind, positions, observedAt)
+}
+
+func (s *Instrumented) TrackPointsSince(ctx context.Context, since time.Time, step time.Duration, limit int) (_ []model.TrackPoint, err error) {
+	defer func(start time.Time) { s.observe("TrackPointsSince", start, err) }(time.Now())
+	return s.next.TrackPointsSince(ctx, since, step, limit)
+}
+
+func (s *Instrumented) PruneTrackPoints(ctx context.Context, cutoff time.Time) (_ int64, err error) {
+	defer func(start time.Time) { s.observe("PruneTrackPoints", start, err) }(time.Now())
+	return s.next.PruneTrackPoints(ctx, cutoff)
+}
+
+func (s *Instrumented) MigrateTracks(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateTracks", start, err) }(time.Now())
+	return s.next.MigrateTracks(ctx)
+}
+
+func (s *Instrumented) SaveSignalScores(ctx context.Context, runID string, scores []model.NamedSignalScore) (err error) {
+	defer func(start time.Time) { s.observe("SaveSignalScores", start, err) }(time.Now())
+	return s.next.SaveSignalScores(ctx, runID, scores)
+}
+
+func (s *Instrumented) SignalScoresBetween(ctx context.Context, signal string, from, to time.Time) (_ []model.SignalScoreRow, err error) {
+	defer func(start time.Time) { s.observe("SignalScoresBetween", start, err) }(time.Now())
+	return s.next.SignalScoresBetween(ctx, signal, from, to)
+}
+
+func (s *Instrumented) SignalScoresSince(ctx context.Context, since time.Time) (_ []model.SignalScoreRow, err error) {
+	defer func(start time.Time) { s.observe("SignalScoresSince", start, err) }(time.Now())
+	return s.next.SignalScoresSince(ctx, since)
+}
+
+func (s *Instrumented) MigrateSignalScores(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateSignalScores", start, err) }(time.Now())
+	return s.next.MigrateSignalScores(ctx)
+}
+
+func (s *Instrumented) SaveKeywords(ctx context.Context, keywords []byte) (err error) {
+	defer func(start time.Time) { s.observe("SaveKeywords", start, err) }(time.Now())
+	return s.next.SaveKeywords(ctx, keywords)
+}
+
+func (s *Instrumented) LatestKeywords(ctx context.Context) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("LatestKeywords", start, err) }(time.Now())
+	return s.next.LatestKeywords(ctx)
+}
+
+func (s *Instrumented) MigrateKeywords(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateKeywords", start, err) }(time.Now())
+	return s.next.MigrateKeywords(ctx)
+}
+
+func (s *Instrumented) SaveMarketRules(ctx context.Context, rules []byte) (err error) {
+	defer func(start time.Time) { s.observe("SaveMarketRules", start, err) }(time.Now())
+	return s.next.SaveMarketRules(ctx, rules)
+}
+
+func (s *Instrumented) LatestMarketRules(ctx context.Context) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("LatestMarketRules", start, err) }(time.Now())
+	return s.next.LatestMarketRules(ctx)
+}
+
+func (s *Instrumented) MigrateMarketRules(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateMarketRules", start, err) }(time.Now())
+	return s.next.MigrateMarketRules(ctx)
+}
+
+func (s *Instrumented) SaveDatasetExport(ctx context.Context, export model.DatasetExport) (err error) {
+	defer func(start time.Time) { s.observe("SaveDatasetExport", start, err) }(time.Now())
+	return s.next.SaveDatasetExport(ctx, export)
+}
+
+func (s *Instrumented) DatasetExports(ctx context.Context) (_ []model.DatasetExport, err error) {
+	defer func(start time.Time) { s.observe("DatasetExports", start, err) }(time.Now())
+	return s.next.DatasetExports(ctx)
+}
+
+func (s *Instrumented) MigrateDatasetExports(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateDatasetExports", start, err) }(time.Now())
+	return s.next.MigrateDatasetExports(ctx)
+}
+
+func (s *Instrumented) SaveModelReport(ctx context.Context, report []byte) (err error) {
+	defer func(start time.Time) { s.observe("SaveModelReport", start, err) }(time.Now())
+	return s.next.SaveModelReport(ctx, report)
+}
+
+func (s *Instrumented) LatestModelReport(ctx context.Context) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("LatestModelReport", start, err) }(time.Now())
+	return s.next.LatestModelReport(ctx)
+}
+
+func (s *Instrumented) MigrateModelReports(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateModelReports", start, err) }(time.Now())
+	return s.next.MigrateModelReports(ctx)
+}
+
+func (s *Instrumented) SaveAnnotation(ctx context.Context, body string, occurredAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SaveAnnotation", start, err) }(time.Now())
+	return s.next.SaveAnnotation(ctx, body, occurredAt)
+}
+
+func (s *Instrumented) AnnotationsBetween(ctx context.Context, from, to time.Time) (_ []model.Annotation, err error) {
+	defer func(start time.Time) { s.observe("AnnotationsBetween", start, err) }(time.Now())
+	return s.next.AnnotationsBetween(ctx, from, to)
+}
+
+func (s *Instrumented) MigrateAnnotations(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateAnnotations", start, err) }(time.Now())
+	return s.next.MigrateAnnotations(ctx)
+}
+
+func (s *Instrumented) CreateTenant(ctx context.Context, name, keyHash string, rateLimit int) (_ model.Tenant, err error) {
+	defer func(start time.Time) { s.observe("CreateTenant", start, err) }(time.Now())
+	return s.next.CreateTenant(ctx, name, keyHash, rateLimit)
+}
+
+func (s *Instrumented) TenantByKeyHash(ctx context.Context, keyHash string) (_ *model.Tenant, err error) {
+	defer func(start time.Time) { s.observe("TenantByKeyHash", start, err) }(time.Now())
+	return s.next.TenantByKeyHash(ctx, keyHash)
+}
+
+func (s *Instrumented) RecordTenantUsage(ctx context.Context, usage []model.TenantUsage) (err error) {
+	defer func(start time.Time) { s.observe("RecordTenantUsage", start, err) }(time.Now())
+	return s.next.RecordTenantUsage(ctx, usage)
+}
+
+func (s *Instrumented) TenantUsageSince(ctx context.Context, since time.Time) (_ []model.TenantUsage, err error) {
+	defer func(start time.Time) { s.observe("TenantUsageSince", start, err) }(time.Now())
+	return s.next.TenantUsageSince(ctx, since)
+}
+
+func (s *Instrumented) MigrateTenants(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateTenants", start, err) }(time.Now())
+	return s.next.MigrateTenants(ctx)
+}
+
+func (s *Instrumented) SaveFeedChecks(ctx context.Context, results []model.FeedResult, checkedAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SaveFeedChecks", start, err) }(time.Now())
+	return s.next.SaveFeedChecks(ctx, results, checkedAt)
+}
+
+func (s *Instrumented) FeedHealth(ctx context.Context, since time.Time) (_ []model.FeedHealth, err error) {
+	defer func(start time.Time) { s.observe("FeedHealth", start, err) }(time.Now())
+	return s.next.FeedHealth(ctx, since)
+}
+
+func (s *Instrumented) MigrateFeedChecks(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateFeedChecks", start, err) }(time.Now())
+	return s.next.MigrateFeedChecks(ctx)
+}
+
+func (s *Instrumented) ConfigOverrides(ctx context.Context) (_ []model.ConfigOverride, err error) {
+	defer func(start time.Time) { s.observe("ConfigOverrides", start, err) }(time.Now())
+	return s.next.ConfigOverrides(ctx)
+}
+
+func (s *Instrumented) SetConfigOverride(ctx context.Context, key, value, updatedBy string) (err error) {
+	defer func(start time.Time) { s.observe("SetConfigOverride", start, err) }(time.Now())
+	return s.next.SetConfigOverride(ctx, key, value, updatedBy)
+}
+
+func (s *Instrumented) DeleteConfigOverride(ctx context.Context, key string) (_ bool, err error) {
+	defer func(start time.Time) { s.observe("DeleteConfigOverride", start, err) }(time.Now())
+	return s.next.DeleteConfigOverride(ctx, key)
+}
+
+func (s *Instrumented) MigrateConfigOverrides(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateConfigOverrides", start, err) }(time.Now())
+	return s.next.MigrateConfigOverrides(ctx)
+}
+
+func (s *Instrumented) SignalOverrides(ctx context.Context, now time.Time) (_ []model.SignalOverride, err error) {
+	defer func(start time.Time) { s.observe("SignalOverrides", start, err) }(time.Now())
+	return s.next.SignalOverrides(ctx, now)
+}
+
+func (s *Instrumented) SetSignalOverride(ctx context.Context, o model.SignalOverride) (err error) {
+	defer func(start time.Time) { s.observe("SetSignalOverride", start, err) }(time.Now())
+	return s.next.SetSignalOverride(ctx, o)
+}
+
+func (s *Instrumented) DeleteSignalOverride(ctx context.Context, signal string) (_ bool, err error) {
+	defer func(start time.Time) { s.observe("DeleteSignalOverride", start, err) }(time.Now())
+	return s.next.DeleteSignalOverride(ctx, signal)
+}
+
+func (s *Instrumented) MigrateSignalOverrides(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateSignalOverrides", start, err) }(time.Now())
+	return s.next.MigrateSignalOverrides(ctx)
+}
+
+func (s *Instrumented) SaveIncident(ctx context.Context, inc model.Incident) (err error) {
+	defer func(start time.Time) { s.observe("SaveIncident", start, err) }(time.Now())
+	return s.next.SaveIncident(ctx, inc)
+}
+
+func (s *Instrumented) IncidentsBetween(ctx context.Context, from, to time.Time) (_ []model.Incident, err error) {
+	defer func(start time.Time) { s.observe("IncidentsBetween", start, err) }(time.Now())
+	return s.next.IncidentsBetween(ctx, from, to)
+}
+
+func (s *Instrumented) MigrateIncidents(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateIncidents", start, err) }(time.Now())
+	return s.next.MigrateIncidents(ctx)
+}
+
+func (s *Instrumented) CreateWebhook(ctx context.Context, w model.Webhook) (_ model.Webhook, err error) {
+	defer func(start time.Time) { s.observe("CreateWebhook", start, err) }(time.Now())
+	return s.next.CreateWebhook(ctx, w)
+}
+
+func (s *Instrumented) Webhooks(ctx context.Context) (_ []model.Webhook, err error) {
+	defer func(start time.Time) { s.observe("Webhooks", start, err) }(time.Now())
+	return s.next.Webhooks(ctx)
+}
+
+func (s *Instrumented) DeleteWebhook(ctx context.Context, id int64) (_ bool, err error) {
+	defer func(start time.Time) { s.observe("DeleteWebhook", start, err) }(time.Now())
+	return s.next.DeleteWebhook(ctx, id)
+}
+
+func (s *Instrumented) MigrateWebhooks(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigrateWebhooks", start, err) }(time.Now())
+	return s.next.MigrateWebhooks(ctx)
+}
+
+func (s *Instrumented) SavePulseBaselines(ctx context.Context, baselines []byte) (err error) {
+	defer func(start time.Time) { s.observe("SavePulseBaselines", start, err) }(time.Now())
+	return s.next.SavePulseBaselines(ctx, baselines)
+}
+
+func (s *Instrumented) LatestPulseBaselines(ctx context.Context) (_ []byte, err error) {
+	defer func(start time.Time) { s.observe("LatestPulseBaselines", start, err) }(time.Now())
+	return s.next.LatestPulseBaselines(ctx)
+}
+
+func (s *Instrumented) SavePulseWindow(ctx context.Context, w model.PulseWindow) (err error) {
+	defer func(start time.Time) { s.observe("SavePulseWindow", start, err) }(time.Now())
+	return s.next.SavePulseWindow(ctx, w)
+}
+
+func (s *Instrumented) PulseWindowsSince(ctx context.Context, since time.Time) (_ []model.PulseWindow, err error) {
+	defer func(start time.Time) { s.observe("PulseWindowsSince", start, err) }(time.Now())
+	return s.next.PulseWindowsSince(ctx, since)
+}
+
+func (s *Instrumented) MigratePulseBaselines(ctx context.Context) (err error) {
+	defer func(start time.Time) { s.observe("MigratePulseBaselines", start, err) }(time.Now())
+	return s.next.MigratePulseBaselines(ctx)
+}
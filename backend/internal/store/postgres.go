@@ -2,15 +2,114 @@ package store
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/config"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
 )
 
 type Postgres struct {
-	db *sql.DB
+	pool *pgxpool.Pool
+	// scanTimeout replaces the pool's statement_timeout for scans over a
+	// time range.
+	scanTimeout time.Duration
+}
+
+// NewPostgres wraps pool. Range scans may run for scanTimeout, 0 meaning
+// no limit, rather than the statement_timeout that bounds other queries.
+func NewPostgres(pool *pgxpool.Pool, scanTimeout time.Duration) *Postgres {
+	return &Postgres{pool: pool, scanTimeout: scanTimeout}
+}
+
+// scan runs query in a read-only transaction with scanTimeout as its
+// statement_timeout, passing the rows to fn. Rows stream while fn reads
+// them, so a scan bound by the pool's timeout would fail partway through
+// a long range.
+func (p *Postgres) scan(ctx context.Context, fn func(pgx.Rows) error, query string, args ...any) error {
+	tx, err := p.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if _, err := tx.Exec(ctx, "SELECT set_config('statement_timeout', $1, true)", strconv.FormatInt(p.scanTimeout.Milliseconds(), 10)); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	err = fn(rows)
+	rows.Close()
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// NewPool opens a pgx connection pool sized and timed from cfg. Statements
+// are prepared and cached per connection (pgx's default exec mode), and the
+// server-side statement_timeout bounds every query.
+func NewPool(ctx context.Context, dsn string, cfg config.DBPool) (*pgxpool.Pool, error) {
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+
+	poolCfg.MaxConns = cfg.MaxConns
+	poolCfg.MinConns = cfg.MinConns
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	poolCfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	if cfg.StatementTimeout > 0 {
+		poolCfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	return pgxpool.NewWithConfig(ctx, poolCfg)
 }
 
-func NewPostgres(db *sql.DB) *Postgres {
-	return &Postgres{db: db}
+// MigrateAll runs every table migration in order, stopping at the first
+// failure.
+func (p *Postgres) MigrateAll(ctx context.Context) error {
+	steps := []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{"snapshots", p.Migrate},
+		{"radar ideas", p.MigrateRadarIdeas},
+		{"aircraft counts", p.MigrateAircraftCounts},
+		{"tanker counts", p.MigrateTankerCounts},
+		{"signal scores", p.MigrateSignalScores},
+		{"news keywords", p.MigrateKeywords},
+		{"polymarket rules", p.MigrateMarketRules},
+		{"tenants", p.MigrateTenants},
+		{"annotations", p.MigrateAnnotations},
+		{"tracks", p.MigrateTracks},
+		{"dataset exports", p.MigrateDatasetExports},
+		{"model reports", p.MigrateModelReports},
+		{"feed checks", p.MigrateFeedChecks},
+		{"config overrides", p.MigrateConfigOverrides},
+		{"signal overrides", p.MigrateSignalOverrides},
+		{"incidents", p.MigrateIncidents},
+		{"webhooks", p.MigrateWebhooks},
+		{"pulse baselines", p.MigratePulseBaselines},
+		{"vessel counts", p.MigrateVesselCounts},
+		{"carrier sightings", p.MigrateCarrierSightings},
+	}
+	for _, step := range steps {
+		if err := step.fn(ctx); err != nil {
+			return fmt.Errorf("%s migration: %w", step.name, err)
+		}
+	}
+	return nil
 }
 
 func (p *Postgres) Migrate(ctx context.Context) error {
@@ -22,37 +121,125 @@ func (p *Postgres) Migrate(ctx context.Context) error {
 		);
 		CREATE INDEX IF NOT EXISTS idx_snapshots_created_at ON snapshots (created_at DESC);
 	`
-	_, err := p.db.ExecContext(ctx, query)
+	_, err := p.pool.Exec(ctx, query)
 	return err
 }
 
 func (p *Postgres) SaveSnapshot(ctx context.Context, response []byte) error {
-	_, err := p.db.ExecContext(ctx,
+	_, err := p.pool.Exec(ctx,
 		"INSERT INTO snapshots (response) VALUES ($1)",
 		response,
 	)
 	return err
 }
 
+func (p *Postgres) ImportSnapshot(ctx context.Context, response []byte, createdAt time.Time) (bool, error) {
+	tag, err := p.pool.Exec(ctx,
+		"INSERT INTO snapshots (response, created_at) SELECT $1, $2 WHERE NOT EXISTS (SELECT 1 FROM snapshots WHERE created_at = $2)",
+		response, createdAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 func (p *Postgres) LatestSnapshot(ctx context.Context) ([]byte, error) {
 	var response []byte
-	err := p.db.QueryRowContext(ctx,
+	err := p.pool.QueryRow(ctx,
 		"SELECT response FROM snapshots ORDER BY created_at DESC LIMIT 1",
 	).Scan(&response)
-	if err == sql.ErrNoRows {
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
 	return response, err
 }
 
+func (p *Postgres) SnapshotAt(ctx context.Context, t time.Time) ([]byte, error) {
+	var response []byte
+	err := p.pool.QueryRow(ctx,
+		"SELECT response FROM snapshots WHERE created_at <= $1 ORDER BY created_at DESC LIMIT 1",
+		t,
+	).Scan(&response)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return response, err
+}
+
+func (p *Postgres) SnapshotsBetween(ctx context.Context, from, to time.Time, fn SnapshotFunc) error {
+	return p.scan(ctx, func(rows pgx.Rows) error {
+		for rows.Next() {
+			var createdAt time.Time
+			var response []byte
+			if err := rows.Scan(&createdAt, &response); err != nil {
+				return err
+			}
+			if err := fn(createdAt, response); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	},
+		"SELECT created_at, response FROM snapshots WHERE created_at >= $1 AND created_at < $2 ORDER BY created_at ASC",
+		from, to,
+	)
+}
+
+func (p *Postgres) TotalRiskSeries(ctx context.Context, since time.Time) ([]model.TotalRiskPoint, error) {
+	var series []model.TotalRiskPoint
+	err := p.scan(ctx, func(rows pgx.Rows) (err error) {
+		series, err = scanTotalRisk(rows)
+		return err
+	},
+		"SELECT created_at, COALESCE((response->'total_risk'->>'risk')::int, 0), COALESCE((response->'total_risk'->>'uncertainty')::int, 0) FROM snapshots WHERE created_at >= $1 ORDER BY created_at ASC",
+		since,
+	)
+	return series, err
+}
+
+func (p *Postgres) TotalRiskBetween(ctx context.Context, from, to time.Time) ([]model.TotalRiskPoint, error) {
+	var series []model.TotalRiskPoint
+	err := p.scan(ctx, func(rows pgx.Rows) (err error) {
+		series, err = scanTotalRisk(rows)
+		return err
+	},
+		"SELECT created_at, COALESCE((response->'total_risk'->>'risk')::int, 0), COALESCE((response->'total_risk'->>'uncertainty')::int, 0) FROM snapshots WHERE created_at >= $1 AND created_at < $2 ORDER BY created_at ASC",
+		from, to,
+	)
+	return series, err
+}
+
+func scanTotalRisk(rows pgx.Rows) ([]model.TotalRiskPoint, error) {
+	var series []model.TotalRiskPoint
+	for rows.Next() {
+		var createdAt time.Time
+		var point model.TotalRiskPoint
+		if err := rows.Scan(&createdAt, &point.Risk, &point.Uncertainty); err != nil {
+			return nil, err
+		}
+		point.Timestamp = createdAt.UnixMilli()
+		series = append(series, point)
+	}
+	return series, rows.Err()
+}
+
 func (p *Postgres) SaveRadarIdea(ctx context.Context, idea, countryCode string) error {
-	_, err := p.db.ExecContext(ctx,
+	_, err := p.pool.Exec(ctx,
 		"INSERT INTO radar_ideas (idea, country_code) VALUES ($1, $2)",
 		idea, countryCode,
 	)
 	return err
 }
 
+func (p *Postgres) SaveRejectedRadarIdea(ctx context.Context, idea, countryCode, reason, language string) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO rejected_radar_ideas (idea, country_code, reason, language) VALUES ($1, $2, $3, $4)",
+		idea, countryCode, reason, language,
+	)
+	return err
+}
+
 func (p *Postgres) MigrateRadarIdeas(ctx context.Context) error {
 	query := `
 		CREATE TABLE IF NOT EXISTS radar_ideas (
@@ -62,7 +249,964 @@ func (p *Postgres) MigrateRadarIdeas(ctx context.Context) error {
 			created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
 		);
 		CREATE INDEX IF NOT EXISTS idx_radar_ideas_created_at ON radar_ideas (created_at DESC);
+
+		CREATE TABLE IF NOT EXISTS rejected_radar_ideas (
+			id           BIGSERIAL PRIMARY KEY,
+			idea         TEXT NOT NULL,
+			country_code VARCHAR(10) NOT NULL DEFAULT 'XX',
+			reason       TEXT NOT NULL,
+			language     TEXT NOT NULL DEFAULT '',
+			created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_rejected_radar_ideas_created_at ON rejected_radar_ideas (created_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveAircraftCount(ctx context.Context, count int, observedAt time.Time) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO aircraft_counts (aircraft_count, observed_at) VALUES ($1, $2)",
+		count, observedAt,
+	)
+	return err
+}
+
+func (p *Postgres) AircraftBaseline(ctx context.Context, hour, days int) (float64, int, error) {
+	var avg float64
+	var samples int
+	// Exclude the last 12 hours so the current run never baselines against itself
+	err := p.pool.QueryRow(ctx, `
+		SELECT COALESCE(AVG(aircraft_count), 0), COUNT(*)
+		FROM aircraft_counts
+		WHERE EXTRACT(HOUR FROM observed_at AT TIME ZONE 'UTC') = $1
+		  AND observed_at > NOW() - make_interval(days => $2)
+		  AND observed_at < NOW() - INTERVAL '12 hours'`,
+		hour, days,
+	).Scan(&avg, &samples)
+	return avg, samples, err
+}
+
+func (p *Postgres) MigrateAircraftCounts(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS aircraft_counts (
+			id             BIGSERIAL PRIMARY KEY,
+			aircraft_count INTEGER NOT NULL,
+			observed_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_aircraft_counts_observed_at ON aircraft_counts (observed_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveTankerCount(ctx context.Context, count int, observedAt time.Time) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO tanker_counts (tanker_count, observed_at) VALUES ($1, $2)",
+		count, observedAt,
+	)
+	return err
+}
+
+func (p *Postgres) TankerBaseline(ctx context.Context, hour int, weekday time.Weekday, weeks int) (float64, int, error) {
+	var avg float64
+	var samples int
+	// EXTRACT(DOW) uses Sunday=0, matching time.Weekday
+	err := p.pool.QueryRow(ctx, `
+		SELECT COALESCE(AVG(tanker_count), 0), COUNT(*)
+		FROM tanker_counts
+		WHERE EXTRACT(HOUR FROM observed_at AT TIME ZONE 'UTC') = $1
+		  AND EXTRACT(DOW FROM observed_at AT TIME ZONE 'UTC') = $2
+		  AND observed_at > NOW() - make_interval(weeks => $3)
+		  AND observed_at < NOW() - INTERVAL '12 hours'`,
+		hour, int(weekday), weeks,
+	).Scan(&avg, &samples)
+	return avg, samples, err
+}
+
+func (p *Postgres) MigrateTankerCounts(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS tanker_counts (
+			id           BIGSERIAL PRIMARY KEY,
+			tanker_count INTEGER NOT NULL,
+			observed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_tanker_counts_observed_at ON tanker_counts (observed_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveVesselCounts(ctx context.Context, vessels, tankers int, observedAt time.Time) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO vessel_counts (vessel_count, tanker_count, observed_at) VALUES ($1, $2, $3)",
+		vessels, tankers, observedAt,
+	)
+	return err
+}
+
+func (p *Postgres) VesselBaseline(ctx context.Context, hour, days int) (float64, float64, int, error) {
+	var vessels, tankers float64
+	var samples int
+	err := p.pool.QueryRow(ctx, `
+		SELECT COALESCE(AVG(vessel_count), 0), COALESCE(AVG(tanker_count), 0), COUNT(*)
+		FROM vessel_counts
+		WHERE EXTRACT(HOUR FROM observed_at AT TIME ZONE 'UTC') = $1
+		  AND observed_at > NOW() - make_interval(days => $2)
+		  AND observed_at < NOW() - INTERVAL '12 hours'`,
+		hour, days,
+	).Scan(&vessels, &tankers, &samples)
+	return vessels, tankers, samples, err
+}
+
+func (p *Postgres) MigrateVesselCounts(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS vessel_counts (
+			id           BIGSERIAL PRIMARY KEY,
+			vessel_count INTEGER NOT NULL,
+			tanker_count INTEGER NOT NULL,
+			observed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_vessel_counts_observed_at ON vessel_counts (observed_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveCarrierSightings(ctx context.Context, carriers []string, observedAt time.Time) error {
+	data, err := json.Marshal(carriers)
+	if err != nil {
+		return err
+	}
+	_, err = p.pool.Exec(ctx,
+		"INSERT INTO carrier_sightings (carriers, observed_at) VALUES ($1, $2)",
+		data, observedAt,
+	)
+	return err
+}
+
+func (p *Postgres) CarrierSightings(ctx context.Context, hour, days int) ([][]string, error) {
+	// Exclude the last 12 hours so the current run never baselines against itself
+	rows, err := p.pool.Query(ctx, `
+		SELECT carriers
+		FROM carrier_sightings
+		WHERE EXTRACT(HOUR FROM observed_at AT TIME ZONE 'UTC') = $1
+		  AND observed_at > NOW() - make_interval(days => $2)
+		  AND observed_at < NOW() - INTERVAL '12 hours'`,
+		hour, days,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sightings [][]string
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var carriers []string
+		if err := json.Unmarshal(data, &carriers); err != nil {
+			return nil, fmt.Errorf("carrier sightings: %w", err)
+		}
+		sightings = append(sightings, carriers)
+	}
+	return sightings, rows.Err()
+}
+
+func (p *Postgres) MigrateCarrierSightings(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS carrier_sightings (
+			id          BIGSERIAL PRIMARY KEY,
+			carriers    JSONB NOT NULL,
+			observed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_carrier_sightings_observed_at ON carrier_sightings (observed_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveTrackPoints(ctx context.Context, kind string, positions []model.Position, observedAt time.Time) error {
+	rows := make([][]any, len(positions))
+	for i, pos := range positions {
+		rows[i] = []any{kind, pos.ICAO, pos.Callsign, pos.Lat, pos.Lon, pos.Altitude, pos.Heading, observedAt}
+	}
+	_, err := p.pool.CopyFrom(ctx,
+		pgx.Identifier{"track_points"},
+		[]string{"kind", "icao", "callsign", "lat", "lon", "altitude", "heading", "observed_at"},
+		pgx.CopyFromRows(rows),
+	)
+	return err
+}
+
+func (p *Postgres) TrackPointsSince(ctx context.Context, since time.Time, step time.Duration, limit int) ([]model.TrackPoint, error) {
+	var points []model.TrackPoint
+	err := p.scan(ctx, func(rows pgx.Rows) error {
+		for rows.Next() {
+			var t model.TrackPoint
+			if err := rows.Scan(&t.Kind, &t.ICAO, &t.Callsign, &t.Lat, &t.Lon, &t.Altitude, &t.Heading, &t.ObservedAt); err != nil {
+				return err
+			}
+			points = append(points, t)
+		}
+		return rows.Err()
+	}, `
+		SELECT kind, icao, callsign, lat, lon, altitude, heading, observed_at FROM (
+			SELECT * FROM (
+				SELECT DISTINCT ON (icao, floor(extract(epoch FROM observed_at)::float8 / $2::float8))
+				       kind, icao, callsign, lat, lon, altitude, heading, observed_at
+				FROM track_points
+				WHERE observed_at >= $1
+				ORDER BY icao, floor(extract(epoch FROM observed_at)::float8 / $2::float8), observed_at DESC
+			) sampled
+			ORDER BY observed_at DESC
+			LIMIT $3
+		) recent
+		ORDER BY icao, observed_at`,
+		since, max(step.Seconds(), 1), limit,
+	)
+	return points, err
+}
+
+func (p *Postgres) PruneTrackPoints(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM track_points WHERE observed_at < $1", cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (p *Postgres) MigrateTracks(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS track_points (
+			id          BIGSERIAL PRIMARY KEY,
+			kind        TEXT NOT NULL,
+			icao        TEXT NOT NULL,
+			callsign    TEXT NOT NULL DEFAULT '',
+			lat         DOUBLE PRECISION NOT NULL,
+			lon         DOUBLE PRECISION NOT NULL,
+			altitude    DOUBLE PRECISION NOT NULL DEFAULT 0,
+			heading     DOUBLE PRECISION NOT NULL DEFAULT 0,
+			observed_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_track_points_observed_at ON track_points (observed_at);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveSignalScores(ctx context.Context, runID string, scores []model.NamedSignalScore) error {
+	// A batch is sent as a single implicit transaction
+	batch := &pgx.Batch{}
+	for _, s := range scores {
+		batch.Queue(
+			"INSERT INTO signal_scores (run_id, signal, risk, detail, elevated) VALUES ($1, $2, $3, $4, $5)",
+			runID, s.Name, s.Risk, s.Detail, s.Elevated,
+		)
+	}
+	return p.pool.SendBatch(ctx, batch).Close()
+}
+
+func (p *Postgres) SignalScoresSince(ctx context.Context, since time.Time) ([]model.SignalScoreRow, error) {
+	var scores []model.SignalScoreRow
+	err := p.scan(ctx, func(rows pgx.Rows) (err error) {
+		scores, err = scanSignalScores(rows)
+		return err
+	},
+		"SELECT run_id, signal, risk, detail, elevated, created_at FROM signal_scores WHERE created_at >= $1 ORDER BY created_at",
+		since,
+	)
+	return scores, err
+}
+
+func (p *Postgres) SignalScoresBetween(ctx context.Context, signal string, from, to time.Time) ([]model.SignalScoreRow, error) {
+	var scores []model.SignalScoreRow
+	err := p.scan(ctx, func(rows pgx.Rows) (err error) {
+		scores, err = scanSignalScores(rows)
+		return err
+	},
+		"SELECT run_id, signal, risk, detail, elevated, created_at FROM signal_scores WHERE signal = $1 AND created_at BETWEEN $2 AND $3 ORDER BY created_at",
+		signal, from, to,
+	)
+	return scores, err
+}
+
+func scanSignalScores(rows pgx.Rows) ([]model.SignalScoreRow, error) {
+	var scores []model.SignalScoreRow
+	for rows.Next() {
+		var r model.SignalScoreRow
+		if err := rows.Scan(&r.RunID, &r.Signal, &r.Risk, &r.Detail, &r.Elevated, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		scores = append(scores, r)
+	}
+	return scores, rows.Err()
+}
+
+func (p *Postgres) MigrateSignalScores(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS signal_scores (
+			id          BIGSERIAL PRIMARY KEY,
+			run_id      TEXT NOT NULL,
+			signal      TEXT NOT NULL,
+			risk        INTEGER NOT NULL,
+			detail      TEXT NOT NULL DEFAULT '',
+			elevated    BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_signal_scores_signal_created_at ON signal_scores (signal, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_signal_scores_run_id ON signal_scores (run_id);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveKeywords(ctx context.Context, keywords []byte) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO news_keywords (keywords) VALUES ($1)",
+		keywords,
+	)
+	return err
+}
+
+func (p *Postgres) LatestKeywords(ctx context.Context) ([]byte, error) {
+	var keywords []byte
+	err := p.pool.QueryRow(ctx,
+		"SELECT keywords FROM news_keywords ORDER BY created_at DESC LIMIT 1",
+	).Scan(&keywords)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return keywords, err
+}
+
+func (p *Postgres) MigrateKeywords(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS news_keywords (
+			id          BIGSERIAL PRIMARY KEY,
+			keywords    JSONB NOT NULL,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_news_keywords_created_at ON news_keywords (created_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveMarketRules(ctx context.Context, rules []byte) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO polymarket_rules (rules) VALUES ($1)",
+		rules,
+	)
+	return err
+}
+
+func (p *Postgres) LatestMarketRules(ctx context.Context) ([]byte, error) {
+	var rules []byte
+	err := p.pool.QueryRow(ctx,
+		"SELECT rules FROM polymarket_rules ORDER BY created_at DESC LIMIT 1",
+	).Scan(&rules)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rules, err
+}
+
+func (p *Postgres) MigrateMarketRules(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS polymarket_rules (
+			id          BIGSERIAL PRIMARY KEY,
+			rules       JSONB NOT NULL,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_polymarket_rules_created_at ON polymarket_rules (created_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveDatasetExport(ctx context.Context, export model.DatasetExport) error {
+	_, err := p.pool.Exec(ctx, `
+		INSERT INTO dataset_exports (day, json_key, csv_key, hours, published_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (day) DO UPDATE SET
+			json_key = EXCLUDED.json_key,
+			csv_key = EXCLUDED.csv_key,
+			hours = EXCLUDED.hours,
+			published_at = EXCLUDED.published_at`,
+		export.Day, export.JSONKey, export.CSVKey, export.Hours, export.PublishedAt,
+	)
+	return err
+}
+
+func (p *Postgres) DatasetExports(ctx context.Context) ([]model.DatasetExport, error) {
+	rows, err := p.pool.Query(ctx,
+		"SELECT to_char(day, 'YYYY-MM-DD'), json_key, csv_key, hours, published_at FROM dataset_exports ORDER BY day DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []model.DatasetExport
+	for rows.Next() {
+		var e model.DatasetExport
+		if err := rows.Scan(&e.Day, &e.JSONKey, &e.CSVKey, &e.Hours, &e.PublishedAt); err != nil {
+			return nil, err
+		}
+		exports = append(exports, e)
+	}
+	return exports, rows.Err()
+}
+
+func (p *Postgres) MigrateDatasetExports(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS dataset_exports (
+			day          DATE PRIMARY KEY,
+			json_key     TEXT NOT NULL,
+			csv_key      TEXT NOT NULL,
+			hours        INTEGER NOT NULL,
+			published_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveModelReport(ctx context.Context, report []byte) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO model_reports (report) VALUES ($1)",
+		report,
+	)
+	return err
+}
+
+func (p *Postgres) LatestModelReport(ctx context.Context) ([]byte, error) {
+	var report []byte
+	err := p.pool.QueryRow(ctx,
+		"SELECT report FROM model_reports ORDER BY created_at DESC LIMIT 1",
+	).Scan(&report)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return report, err
+}
+
+func (p *Postgres) MigrateModelReports(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS model_reports (
+			id          BIGSERIAL PRIMARY KEY,
+			report      JSONB NOT NULL,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_model_reports_created_at ON model_reports (created_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveAnnotation(ctx context.Context, body string, occurredAt time.Time) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO annotations (body, occurred_at) VALUES ($1, $2)",
+		body, occurredAt,
+	)
+	return err
+}
+
+func (p *Postgres) AnnotationsBetween(ctx context.Context, from, to time.Time) ([]model.Annotation, error) {
+	rows, err := p.pool.Query(ctx,
+		"SELECT id, body, occurred_at FROM annotations WHERE occurred_at >= $1 AND occurred_at < $2 ORDER BY occurred_at ASC",
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var annotations []model.Annotation
+	for rows.Next() {
+		var a model.Annotation
+		if err := rows.Scan(&a.ID, &a.Body, &a.OccurredAt); err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, rows.Err()
+}
+
+func (p *Postgres) MigrateAnnotations(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS annotations (
+			id          BIGSERIAL PRIMARY KEY,
+			body        TEXT NOT NULL,
+			occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_annotations_occurred_at ON annotations (occurred_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) CreateTenant(ctx context.Context, name, keyHash string, rateLimit int) (model.Tenant, error) {
+	t := model.Tenant{Name: name, RateLimit: rateLimit}
+	err := p.pool.QueryRow(ctx,
+		"INSERT INTO tenants (name, key_hash, rate_limit) VALUES ($1, $2, $3) RETURNING id, created_at",
+		name, keyHash, rateLimit,
+	).Scan(&t.ID, &t.CreatedAt)
+	return t, err
+}
+
+func (p *Postgres) TenantByKeyHash(ctx context.Context, keyHash string) (*model.Tenant, error) {
+	var t model.Tenant
+	err := p.pool.QueryRow(ctx,
+		"SELECT id, name, rate_limit, disabled, created_at FROM tenants WHERE key_hash = $1",
+		keyHash,
+	).Scan(&t.ID, &t.Name, &t.RateLimit, &t.Disabled, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (p *Postgres) RecordTenantUsage(ctx context.Context, usage []model.TenantUsage) error {
+	batch := &pgx.Batch{}
+	for _, u := range usage {
+		day, err := time.Parse("2006-01-02", u.Day)
+		if err != nil {
+			return fmt.Errorf("tenant usage day %q: %w", u.Day, err)
+		}
+		batch.Queue(`
+			INSERT INTO tenant_usage (tenant_id, day, requests, bytes)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (tenant_id, day) DO UPDATE SET
+				requests = tenant_usage.requests + EXCLUDED.requests,
+				bytes = tenant_usage.bytes + EXCLUDED.bytes`,
+			u.TenantID, day, u.Requests, u.Bytes,
+		)
+	}
+	return p.pool.SendBatch(ctx, batch).Close()
+}
+
+func (p *Postgres) TenantUsageSince(ctx context.Context, since time.Time) ([]model.TenantUsage, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT u.tenant_id, t.name, to_char(u.day, 'YYYY-MM-DD'), u.requests, u.bytes
+		FROM tenant_usage u JOIN tenants t ON t.id = u.tenant_id
+		WHERE u.day >= $1
+		ORDER BY u.tenant_id, u.day`,
+		since.UTC().Truncate(24*time.Hour),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []model.TenantUsage
+	for rows.Next() {
+		var u model.TenantUsage
+		if err := rows.Scan(&u.TenantID, &u.Name, &u.Day, &u.Requests, &u.Bytes); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+func (p *Postgres) MigrateTenants(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS tenants (
+			id          BIGSERIAL PRIMARY KEY,
+			name        TEXT NOT NULL,
+			key_hash    TEXT NOT NULL UNIQUE,
+			rate_limit  INTEGER NOT NULL DEFAULT 0,
+			disabled    BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE TABLE IF NOT EXISTS tenant_usage (
+			tenant_id   BIGINT NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+			day         DATE NOT NULL,
+			requests    BIGINT NOT NULL DEFAULT 0,
+			bytes       BIGINT NOT NULL DEFAULT 0,
+			PRIMARY KEY (tenant_id, day)
+		);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveFeedChecks(ctx context.Context, results []model.FeedResult, checkedAt time.Time) error {
+	rows := make([][]any, len(results))
+	for i, r := range results {
+		rows[i] = []any{r.URL, r.Fetched, r.Parsed, r.Items, r.Matched, r.Error, checkedAt}
+	}
+	_, err := p.pool.CopyFrom(ctx,
+		pgx.Identifier{"feed_checks"},
+		[]string{"feed_url", "fetched", "parsed", "items", "matched", "error", "checked_at"},
+		pgx.CopyFromRows(rows),
+	)
+	return err
+}
+
+func (p *Postgres) FeedHealth(ctx context.Context, since time.Time) ([]model.FeedHealth, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT feed_url,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE fetched),
+		       COUNT(*) FILTER (WHERE parsed),
+		       COALESCE(ROUND(AVG(items), 1), 0)::float8,
+		       MIN(checked_at),
+		       MAX(checked_at),
+		       MAX(checked_at) FILTER (WHERE parsed),
+		       COALESCE((SELECT e.error FROM feed_checks e
+		                 WHERE e.feed_url = c.feed_url AND e.error <> '' AND e.checked_at >= $1
+		                 ORDER BY e.checked_at DESC LIMIT 1), '')
+		FROM feed_checks c
+		WHERE checked_at >= $1
+		GROUP BY feed_url
+		ORDER BY feed_url`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var health []model.FeedHealth
+	for rows.Next() {
+		var h model.FeedHealth
+		if err := rows.Scan(&h.URL, &h.Checks, &h.FetchOK, &h.ParseOK, &h.AvgItems,
+			&h.FirstCheckedAt, &h.LastCheckedAt, &h.LastSuccessAt, &h.LastError); err != nil {
+			return nil, err
+		}
+		health = append(health, h)
+	}
+	return health, rows.Err()
+}
+
+func (p *Postgres) MigrateFeedChecks(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS feed_checks (
+			id          BIGSERIAL PRIMARY KEY,
+			feed_url    TEXT NOT NULL,
+			fetched     BOOLEAN NOT NULL,
+			parsed      BOOLEAN NOT NULL,
+			items       INTEGER NOT NULL DEFAULT 0,
+			matched     INTEGER NOT NULL DEFAULT 0,
+			error       TEXT NOT NULL DEFAULT '',
+			checked_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_feed_checks_url_checked_at ON feed_checks (feed_url, checked_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) ConfigOverrides(ctx context.Context) ([]model.ConfigOverride, error) {
+	rows, err := p.pool.Query(ctx,
+		"SELECT key, value, updated_by, updated_at FROM config_overrides ORDER BY key",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []model.ConfigOverride
+	for rows.Next() {
+		var o model.ConfigOverride
+		if err := rows.Scan(&o.Key, &o.Value, &o.UpdatedBy, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+func (p *Postgres) SetConfigOverride(ctx context.Context, key, value, updatedBy string) error {
+	_, err := p.pool.Exec(ctx, `
+		INSERT INTO config_overrides (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			value = EXCLUDED.value,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at`,
+		key, value, updatedBy,
+	)
+	return err
+}
+
+func (p *Postgres) DeleteConfigOverride(ctx context.Context, key string) (bool, error) {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM config_overrides WHERE key = $1", key)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *Postgres) MigrateConfigOverrides(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS config_overrides (
+			key         TEXT PRIMARY KEY,
+			value       TEXT NOT NULL,
+			updated_by  TEXT NOT NULL,
+			updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SignalOverrides(ctx context.Context, now time.Time) ([]model.SignalOverride, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT signal, risk, reason, created_by, created_at, expires_at
+		FROM signal_overrides
+		WHERE expires_at > $1
+		ORDER BY signal`,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []model.SignalOverride
+	for rows.Next() {
+		var o model.SignalOverride
+		if err := rows.Scan(&o.Signal, &o.Risk, &o.Reason, &o.CreatedBy, &o.CreatedAt, &o.ExpiresAt); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+func (p *Postgres) SetSignalOverride(ctx context.Context, o model.SignalOverride) error {
+	_, err := p.pool.Exec(ctx, `
+		INSERT INTO signal_overrides (signal, risk, reason, created_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (signal) DO UPDATE SET
+			risk = EXCLUDED.risk,
+			reason = EXCLUDED.reason,
+			created_by = EXCLUDED.created_by,
+			created_at = EXCLUDED.created_at,
+			expires_at = EXCLUDED.expires_at`,
+		o.Signal, o.Risk, o.Reason, o.CreatedBy, o.CreatedAt, o.ExpiresAt,
+	)
+	return err
+}
+
+func (p *Postgres) DeleteSignalOverride(ctx context.Context, signal string) (bool, error) {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM signal_overrides WHERE signal = $1", signal)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *Postgres) MigrateSignalOverrides(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS signal_overrides (
+			signal      TEXT PRIMARY KEY,
+			risk        INTEGER NOT NULL,
+			reason      TEXT NOT NULL,
+			created_by  TEXT NOT NULL,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			expires_at  TIMESTAMPTZ NOT NULL
+		);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SaveIncident(ctx context.Context, inc model.Incident) error {
+	signals, err := json.Marshal(inc.Signals)
+	if err != nil {
+		return err
+	}
+	headlines, err := json.Marshal(inc.Headlines)
+	if err != nil {
+		return err
+	}
+	_, err = p.pool.Exec(ctx,
+		"INSERT INTO incidents (run_id, detected_at, total_risk, signals, headlines) VALUES ($1, $2, $3, $4, $5)",
+		inc.RunID, inc.DetectedAt, inc.TotalRisk, signals, headlines,
+	)
+	return err
+}
+
+func (p *Postgres) IncidentsBetween(ctx context.Context, from, to time.Time) ([]model.Incident, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT id, run_id, detected_at, total_risk, signals, headlines
+		FROM incidents
+		WHERE detected_at >= $1 AND detected_at < $2
+		ORDER BY detected_at DESC`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []model.Incident
+	for rows.Next() {
+		var inc model.Incident
+		var signals, headlines []byte
+		if err := rows.Scan(&inc.ID, &inc.RunID, &inc.DetectedAt, &inc.TotalRisk, &signals, &headlines); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(signals, &inc.Signals); err != nil {
+			return nil, fmt.Errorf("incident %d signals: %w", inc.ID, err)
+		}
+		if err := json.Unmarshal(headlines, &inc.Headlines); err != nil {
+			return nil, fmt.Errorf("incident %d headlines: %w", inc.ID, err)
+		}
+		incidents = append(incidents, inc)
+	}
+	return incidents, rows.Err()
+}
+
+func (p *Postgres) MigrateIncidents(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS incidents (
+			id          BIGSERIAL PRIMARY KEY,
+			run_id      TEXT NOT NULL,
+			detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			total_risk  INTEGER NOT NULL,
+			signals     JSONB NOT NULL,
+			headlines   JSONB NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_incidents_detected_at ON incidents (detected_at DESC);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) CreateWebhook(ctx context.Context, w model.Webhook) (model.Webhook, error) {
+	err := p.pool.QueryRow(ctx,
+		"INSERT INTO webhooks (url, threshold, direction, payload_mode, secret) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at",
+		w.URL, w.Threshold, w.Direction, w.PayloadMode, w.Secret,
+	).Scan(&w.ID, &w.CreatedAt)
+	return w, err
+}
+
+func (p *Postgres) Webhooks(ctx context.Context) ([]model.Webhook, error) {
+	rows, err := p.pool.Query(ctx,
+		"SELECT id, url, threshold, direction, payload_mode, secret, created_at FROM webhooks ORDER BY id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []model.Webhook
+	for rows.Next() {
+		var w model.Webhook
+		if err := rows.Scan(&w.ID, &w.URL, &w.Threshold, &w.Direction, &w.PayloadMode, &w.Secret, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, rows.Err()
+}
+
+func (p *Postgres) DeleteWebhook(ctx context.Context, id int64) (bool, error) {
+	tag, err := p.pool.Exec(ctx, "DELETE FROM webhooks WHERE id = $1", id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (p *Postgres) MigrateWebhooks(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id           BIGSERIAL PRIMARY KEY,
+			url          TEXT NOT NULL,
+			threshold    INTEGER NOT NULL,
+			direction    TEXT NOT NULL,
+			payload_mode TEXT NOT NULL DEFAULT 'full',
+			secret       TEXT NOT NULL,
+			created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+	`
+	_, err := p.pool.Exec(ctx, query)
+	return err
+}
+
+func (p *Postgres) SavePulseBaselines(ctx context.Context, baselines []byte) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO pulse_baselines (baselines) VALUES ($1)",
+		baselines,
+	)
+	return err
+}
+
+func (p *Postgres) LatestPulseBaselines(ctx context.Context) ([]byte, error) {
+	var baselines []byte
+	err := p.pool.QueryRow(ctx,
+		"SELECT baselines FROM pulse_baselines ORDER BY created_at DESC LIMIT 1",
+	).Scan(&baselines)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return baselines, err
+}
+
+func (p *Postgres) SavePulseWindow(ctx context.Context, w model.PulseWindow) error {
+	counts, err := json.Marshal(w.Counts)
+	if err != nil {
+		return err
+	}
+	_, err = p.pool.Exec(ctx,
+		"INSERT INTO pulse_windows (ended_at, counts) VALUES ($1, $2) ON CONFLICT (ended_at) DO UPDATE SET counts = EXCLUDED.counts",
+		w.EndedAt, counts,
+	)
+	return err
+}
+
+func (p *Postgres) PulseWindowsSince(ctx context.Context, since time.Time) ([]model.PulseWindow, error) {
+	rows, err := p.pool.Query(ctx,
+		"SELECT ended_at, counts FROM pulse_windows WHERE ended_at >= $1 ORDER BY ended_at ASC",
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []model.PulseWindow
+	for rows.Next() {
+		var w model.PulseWindow
+		var counts []byte
+		if err := rows.Scan(&w.EndedAt, &counts); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(counts, &w.Counts); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+func (p *Postgres) MigratePulseBaselines(ctx context.Context) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS pulse_baselines (
+			id          BIGSERIAL PRIMARY KEY,
+			baselines   JSONB NOT NULL,
+			created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		);
+		CREATE INDEX IF NOT EXISTS idx_pulse_baselines_created_at ON pulse_baselines (created_at DESC);
+
+		CREATE TABLE IF NOT EXISTS pulse_windows (
+			ended_at    TIMESTAMPTZ PRIMARY KEY,
+			counts      JSONB NOT NULL
+		);
 	`
-	_, err := p.db.ExecContext(ctx, query)
+	_, err := p.pool.Exec(ctx, query)
 	return err
 }
@@ -0,0 +1,1216 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// sqliteScheme prefixes DATABASE_URLs that select the SQLite store, e.g.
+// sqlite://aegis.db or sqlite:///var/lib/aegis/aegis.db.
+const sqliteScheme = "sqlite://"
+
+// sqliteTimeFormat stores timestamps as fixed-width UTC text, so they
+// compare and sort correctly as strings and SQLite's date functions can
+// still read them.
+const sqliteTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// SQLite is a Store in a single SQLite file, for running the app locally
+// without a Postgres server. It keeps the same tables as Postgres, with
+// JSON held as text and timestamps in sqliteTimeFormat.
+type SQLite struct {
+	db *sql.DB
+}
+
+// IsSQLiteURL reports whether dsn selects the SQLite store.
+func IsSQLiteURL(dsn string) bool {
+	return strings.HasPrefix(dsn, sqliteScheme)
+}
+
+// OpenSQLite opens, creating if needed, the database file a sqlite:// URL
+// names. The file is put in WAL mode so the server can read while the
+// pipeline writes, and a busy timeout makes concurrent writers wait their
+// turn instead of failing.
+func OpenSQLite(ctx context.Context, dsn string) (*SQLite, error) {
+	path := strings.TrimPrefix(dsn, sqliteScheme)
+	if path == "" {
+		return nil, fmt.Errorf("parse database url: sqlite:// needs a file path")
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite", "file:"+path+sep+"_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	return &SQLite{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SQLite) Close() error {
+	return s.db.Close()
+}
+
+// MigrateAll runs every table migration in order, stopping at the first
+// failure.
+func (s *SQLite) MigrateAll(ctx context.Context) error {
+	steps := []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{"snapshots", s.Migrate},
+		{"radar ideas", s.MigrateRadarIdeas},
+		{"aircraft counts", s.MigrateAircraftCounts},
+		{"tanker counts", s.MigrateTankerCounts},
+		{"signal scores", s.MigrateSignalScores},
+		{"news keywords", s.MigrateKeywords},
+		{"polymarket rules", s.MigrateMarketRules},
+		{"tenants", s.MigrateTenants},
+		{"annotations", s.MigrateAnnotations},
+		{"tracks", s.MigrateTracks},
+		{"dataset exports", s.MigrateDatasetExports},
+		{"model reports", s.MigrateModelReports},
+		{"feed checks", s.MigrateFeedChecks},
+		{"config overrides", s.MigrateConfigOverrides},
+		{"signal overrides", s.MigrateSignalOverrides},
+		{"incidents", s.MigrateIncidents},
+		{"webhooks", s.MigrateWebhooks},
+		{"pulse baselines", s.MigratePulseBaselines},
+		{"vessel counts", s.MigrateVesselCounts},
+		{"carrier sightings", s.MigrateCarrierSightings},
+	}
+	for _, step := range steps {
+		if err := step.fn(ctx); err != nil {
+			return fmt.Errorf("%s migration: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// sqliteTS formats t for storage or comparison.
+func sqliteTS(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
+
+// sqliteTime scans a stored timestamp into t. A NULL leaves t zero.
+type sqliteTime struct{ t *time.Time }
+
+func (s sqliteTime) Scan(src any) error {
+	var v string
+	switch src := src.(type) {
+	case nil:
+		*s.t = time.Time{}
+		return nil
+	case time.Time:
+		*s.t = src.UTC()
+		return nil
+	case string:
+		v = src
+	case []byte:
+		v = string(src)
+	default:
+		return fmt.Errorf("sqlite: cannot scan %T into time", src)
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return fmt.Errorf("sqlite: parse time %q: %w", v, err)
+	}
+	*s.t = t
+	return nil
+}
+
+// sqliteNullTime scans a stored timestamp into *t, leaving it nil for NULL.
+type sqliteNullTime struct{ t **time.Time }
+
+func (s sqliteNullTime) Scan(src any) error {
+	if src == nil {
+		*s.t = nil
+		return nil
+	}
+	var t time.Time
+	if err := (sqliteTime{&t}).Scan(src); err != nil {
+		return err
+	}
+	*s.t = &t
+	return nil
+}
+
+// exec runs a schema script or single write.
+func (s *SQLite) exec(ctx context.Context, query string, args ...any) error {
+	_, err := s.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// inTx runs fn in a transaction, committing if it returns nil.
+func (s *SQLite) inTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// queryBlob returns the single blob a query selects, or nil if none.
+func (s *SQLite) queryBlob(ctx context.Context, query string, args ...any) ([]byte, error) {
+	var blob []byte
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&blob)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return blob, err
+}
+
+func (s *SQLite) Migrate(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS snapshots (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			response    TEXT NOT NULL,
+			created_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_snapshots_created_at ON snapshots (created_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveSnapshot(ctx context.Context, response []byte) error {
+	return s.exec(ctx,
+		"INSERT INTO snapshots (response, created_at) VALUES (?, ?)",
+		string(response), sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) ImportSnapshot(ctx context.Context, response []byte, createdAt time.Time) (bool, error) {
+	at := sqliteTS(createdAt)
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO snapshots (response, created_at) SELECT ?, ? WHERE NOT EXISTS (SELECT 1 FROM snapshots WHERE created_at = ?)",
+		string(response), at, at,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *SQLite) LatestSnapshot(ctx context.Context) ([]byte, error) {
+	return s.queryBlob(ctx, "SELECT response FROM snapshots ORDER BY created_at DESC LIMIT 1")
+}
+
+func (s *SQLite) SnapshotAt(ctx context.Context, t time.Time) ([]byte, error) {
+	return s.queryBlob(ctx,
+		"SELECT response FROM snapshots WHERE created_at <= ? ORDER BY created_at DESC LIMIT 1",
+		sqliteTS(t),
+	)
+}
+
+func (s *SQLite) SnapshotsBetween(ctx context.Context, from, to time.Time, fn SnapshotFunc) error {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT created_at, response FROM snapshots WHERE created_at >= ? AND created_at < ? ORDER BY created_at ASC",
+		sqliteTS(from), sqliteTS(to),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var createdAt time.Time
+		var response []byte
+		if err := rows.Scan(sqliteTime{&createdAt}, &response); err != nil {
+			return err
+		}
+		if err := fn(createdAt, response); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLite) TotalRiskSeries(ctx context.Context, since time.Time) ([]model.TotalRiskPoint, error) {
+	return s.TotalRiskBetween(ctx, since, time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func (s *SQLite) TotalRiskBetween(ctx context.Context, from, to time.Time) ([]model.TotalRiskPoint, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT created_at,
+		       COALESCE(CAST(json_extract(response, '$.total_risk.risk') AS INTEGER), 0),
+		       COALESCE(CAST(json_extract(response, '$.total_risk.uncertainty') AS INTEGER), 0)
+		FROM snapshots
+		WHERE created_at >= ? AND created_at < ?
+		ORDER BY created_at ASC`,
+		sqliteTS(from), sqliteTS(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var series []model.TotalRiskPoint
+	for rows.Next() {
+		var createdAt time.Time
+		var point model.TotalRiskPoint
+		if err := rows.Scan(sqliteTime{&createdAt}, &point.Risk, &point.Uncertainty); err != nil {
+			return nil, err
+		}
+		point.Timestamp = createdAt.UnixMilli()
+		series = append(series, point)
+	}
+	return series, rows.Err()
+}
+
+func (s *SQLite) SaveRadarIdea(ctx context.Context, idea, countryCode string) error {
+	return s.exec(ctx,
+		"INSERT INTO radar_ideas (idea, country_code, created_at) VALUES (?, ?, ?)",
+		idea, countryCode, sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) SaveRejectedRadarIdea(ctx context.Context, idea, countryCode, reason, language string) error {
+	return s.exec(ctx,
+		"INSERT INTO rejected_radar_ideas (idea, country_code, reason, language, created_at) VALUES (?, ?, ?, ?, ?)",
+		idea, countryCode, reason, language, sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) MigrateRadarIdeas(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS radar_ideas (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			idea         TEXT NOT NULL,
+			country_code TEXT NOT NULL DEFAULT 'XX',
+			created_at   TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_radar_ideas_created_at ON radar_ideas (created_at DESC);
+
+		CREATE TABLE IF NOT EXISTS rejected_radar_ideas (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			idea         TEXT NOT NULL,
+			country_code TEXT NOT NULL DEFAULT 'XX',
+			reason       TEXT NOT NULL,
+			language     TEXT NOT NULL DEFAULT '',
+			created_at   TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_rejected_radar_ideas_created_at ON rejected_radar_ideas (created_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveAircraftCount(ctx context.Context, count int, observedAt time.Time) error {
+	return s.exec(ctx,
+		"INSERT INTO aircraft_counts (aircraft_count, observed_at) VALUES (?, ?)",
+		count, sqliteTS(observedAt),
+	)
+}
+
+func (s *SQLite) AircraftBaseline(ctx context.Context, hour, days int) (float64, int, error) {
+	var avg float64
+	var samples int
+	// Exclude the last 12 hours so the current run never baselines against itself
+	now := time.Now()
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(AVG(aircraft_count), 0), COUNT(*)
+		FROM aircraft_counts
+		WHERE CAST(strftime('%H', observed_at) AS INTEGER) = ?
+		  AND observed_at > ?
+		  AND observed_at < ?`,
+		hour, sqliteTS(now.AddDate(0, 0, -days)), sqliteTS(now.Add(-12*time.Hour)),
+	).Scan(&avg, &samples)
+	return avg, samples, err
+}
+
+func (s *SQLite) MigrateAircraftCounts(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS aircraft_counts (
+			id             INTEGER PRIMARY KEY AUTOINCREMENT,
+			aircraft_count INTEGER NOT NULL,
+			observed_at    TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_aircraft_counts_observed_at ON aircraft_counts (observed_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveTankerCount(ctx context.Context, count int, observedAt time.Time) error {
+	return s.exec(ctx,
+		"INSERT INTO tanker_counts (tanker_count, observed_at) VALUES (?, ?)",
+		count, sqliteTS(observedAt),
+	)
+}
+
+func (s *SQLite) TankerBaseline(ctx context.Context, hour int, weekday time.Weekday, weeks int) (float64, int, error) {
+	var avg float64
+	var samples int
+	// strftime('%w') uses Sunday=0, matching time.Weekday
+	now := time.Now()
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(AVG(tanker_count), 0), COUNT(*)
+		FROM tanker_counts
+		WHERE CAST(strftime('%H', observed_at) AS INTEGER) = ?
+		  AND CAST(strftime('%w', observed_at) AS INTEGER) = ?
+		  AND observed_at > ?
+		  AND observed_at < ?`,
+		hour, int(weekday), sqliteTS(now.AddDate(0, 0, -7*weeks)), sqliteTS(now.Add(-12*time.Hour)),
+	).Scan(&avg, &samples)
+	return avg, samples, err
+}
+
+func (s *SQLite) MigrateTankerCounts(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS tanker_counts (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			tanker_count INTEGER NOT NULL,
+			observed_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_tanker_counts_observed_at ON tanker_counts (observed_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveVesselCounts(ctx context.Context, vessels, tankers int, observedAt time.Time) error {
+	return s.exec(ctx,
+		"INSERT INTO vessel_counts (vessel_count, tanker_count, observed_at) VALUES (?, ?, ?)",
+		vessels, tankers, sqliteTS(observedAt),
+	)
+}
+
+func (s *SQLite) VesselBaseline(ctx context.Context, hour, days int) (float64, float64, int, error) {
+	var vessels, tankers float64
+	var samples int
+	now := time.Now()
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(AVG(vessel_count), 0), COALESCE(AVG(tanker_count), 0), COUNT(*)
+		FROM vessel_counts
+		WHERE CAST(strftime('%H', observed_at) AS INTEGER) = ?
+		  AND observed_at > ?
+		  AND observed_at < ?`,
+		hour, sqliteTS(now.AddDate(0, 0, -days)), sqliteTS(now.Add(-12*time.Hour)),
+	).Scan(&vessels, &tankers, &samples)
+	return vessels, tankers, samples, err
+}
+
+func (s *SQLite) MigrateVesselCounts(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS vessel_counts (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			vessel_count INTEGER NOT NULL,
+			tanker_count INTEGER NOT NULL,
+			observed_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_vessel_counts_observed_at ON vessel_counts (observed_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveCarrierSightings(ctx context.Context, carriers []string, observedAt time.Time) error {
+	data, err := json.Marshal(carriers)
+	if err != nil {
+		return err
+	}
+	return s.exec(ctx,
+		"INSERT INTO carrier_sightings (carriers, observed_at) VALUES (?, ?)",
+		string(data), sqliteTS(observedAt),
+	)
+}
+
+func (s *SQLite) CarrierSightings(ctx context.Context, hour, days int) ([][]string, error) {
+	// Exclude the last 12 hours so the current run never baselines against itself
+	now := time.Now()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT carriers
+		FROM carrier_sightings
+		WHERE CAST(strftime('%H', observed_at) AS INTEGER) = ?
+		  AND observed_at > ?
+		  AND observed_at < ?`,
+		hour, sqliteTS(now.AddDate(0, 0, -days)), sqliteTS(now.Add(-12*time.Hour)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sightings [][]string
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var carriers []string
+		if err := json.Unmarshal(data, &carriers); err != nil {
+			return nil, fmt.Errorf("carrier sightings: %w", err)
+		}
+		sightings = append(sightings, carriers)
+	}
+	return sightings, rows.Err()
+}
+
+func (s *SQLite) MigrateCarrierSightings(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS carrier_sightings (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			carriers    TEXT NOT NULL,
+			observed_at TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_carrier_sightings_observed_at ON carrier_sightings (observed_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveTrackPoints(ctx context.Context, kind string, positions []model.Position, observedAt time.Time) error {
+	at := sqliteTS(observedAt)
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx,
+			"INSERT INTO track_points (kind, icao, callsign, lat, lon, altitude, heading, observed_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, pos := range positions {
+			if _, err := stmt.ExecContext(ctx, kind, pos.ICAO, pos.Callsign, pos.Lat, pos.Lon, pos.Altitude, pos.Heading, at); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLite) TrackPointsSince(ctx context.Context, since time.Time, step time.Duration, limit int) ([]model.TrackPoint, error) {
+	// The other columns of a MAX() group come from its latest row
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT kind, icao, callsign, lat, lon, altitude, heading, last_seen FROM (
+			SELECT kind, icao, callsign, lat, lon, altitude, heading, MAX(observed_at) AS last_seen
+			FROM track_points
+			WHERE observed_at >= ?
+			GROUP BY icao, CAST(strftime('%s', observed_at) AS INTEGER) / ?
+			ORDER BY last_seen DESC
+			LIMIT ?
+		)
+		ORDER BY icao, last_seen`,
+		sqliteTS(since), max(int64(step.Seconds()), 1), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []model.TrackPoint
+	for rows.Next() {
+		var t model.TrackPoint
+		if err := rows.Scan(&t.Kind, &t.ICAO, &t.Callsign, &t.Lat, &t.Lon, &t.Altitude, &t.Heading, sqliteTime{&t.ObservedAt}); err != nil {
+			return nil, err
+		}
+		points = append(points, t)
+	}
+	return points, rows.Err()
+}
+
+func (s *SQLite) PruneTrackPoints(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM track_points WHERE observed_at < ?", sqliteTS(cutoff))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLite) MigrateTracks(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS track_points (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			kind        TEXT NOT NULL,
+			icao        TEXT NOT NULL,
+			callsign    TEXT NOT NULL DEFAULT '',
+			lat         REAL NOT NULL,
+			lon         REAL NOT NULL,
+			altitude    REAL NOT NULL DEFAULT 0,
+			heading     REAL NOT NULL DEFAULT 0,
+			observed_at TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_track_points_observed_at ON track_points (observed_at);
+	`)
+}
+
+func (s *SQLite) SaveSignalScores(ctx context.Context, runID string, scores []model.NamedSignalScore) error {
+	at := sqliteTS(time.Now())
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, sc := range scores {
+			if _, err := tx.ExecContext(ctx,
+				"INSERT INTO signal_scores (run_id, signal, risk, detail, elevated, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+				runID, sc.Name, sc.Risk, sc.Detail, sc.Elevated, at,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLite) SignalScoresSince(ctx context.Context, since time.Time) ([]model.SignalScoreRow, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT run_id, signal, risk, detail, elevated, created_at FROM signal_scores WHERE created_at >= ? ORDER BY created_at",
+		sqliteTS(since),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSQLiteSignalScores(rows)
+}
+
+func (s *SQLite) SignalScoresBetween(ctx context.Context, signal string, from, to time.Time) ([]model.SignalScoreRow, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT run_id, signal, risk, detail, elevated, created_at FROM signal_scores WHERE signal = ? AND created_at BETWEEN ? AND ? ORDER BY created_at",
+		signal, sqliteTS(from), sqliteTS(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanSQLiteSignalScores(rows)
+}
+
+func scanSQLiteSignalScores(rows *sql.Rows) ([]model.SignalScoreRow, error) {
+	defer rows.Close()
+
+	var scores []model.SignalScoreRow
+	for rows.Next() {
+		var r model.SignalScoreRow
+		if err := rows.Scan(&r.RunID, &r.Signal, &r.Risk, &r.Detail, &r.Elevated, sqliteTime{&r.CreatedAt}); err != nil {
+			return nil, err
+		}
+		scores = append(scores, r)
+	}
+	return scores, rows.Err()
+}
+
+func (s *SQLite) MigrateSignalScores(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS signal_scores (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id      TEXT NOT NULL,
+			signal      TEXT NOT NULL,
+			risk        INTEGER NOT NULL,
+			detail      TEXT NOT NULL DEFAULT '',
+			elevated    INTEGER NOT NULL DEFAULT 0,
+			created_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_signal_scores_signal_created_at ON signal_scores (signal, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_signal_scores_run_id ON signal_scores (run_id);
+	`)
+}
+
+func (s *SQLite) SaveKeywords(ctx context.Context, keywords []byte) error {
+	return s.exec(ctx,
+		"INSERT INTO news_keywords (keywords, created_at) VALUES (?, ?)",
+		string(keywords), sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) LatestKeywords(ctx context.Context) ([]byte, error) {
+	return s.queryBlob(ctx, "SELECT keywords FROM news_keywords ORDER BY created_at DESC LIMIT 1")
+}
+
+func (s *SQLite) MigrateKeywords(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS news_keywords (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			keywords    TEXT NOT NULL,
+			created_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_news_keywords_created_at ON news_keywords (created_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveMarketRules(ctx context.Context, rules []byte) error {
+	return s.exec(ctx,
+		"INSERT INTO polymarket_rules (rules, created_at) VALUES (?, ?)",
+		string(rules), sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) LatestMarketRules(ctx context.Context) ([]byte, error) {
+	return s.queryBlob(ctx, "SELECT rules FROM polymarket_rules ORDER BY created_at DESC LIMIT 1")
+}
+
+func (s *SQLite) MigrateMarketRules(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS polymarket_rules (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			rules       TEXT NOT NULL,
+			created_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_polymarket_rules_created_at ON polymarket_rules (created_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveDatasetExport(ctx context.Context, export model.DatasetExport) error {
+	return s.exec(ctx, `
+		INSERT INTO dataset_exports (day, json_key, csv_key, hours, published_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (day) DO UPDATE SET
+			json_key = excluded.json_key,
+			csv_key = excluded.csv_key,
+			hours = excluded.hours,
+			published_at = excluded.published_at`,
+		export.Day, export.JSONKey, export.CSVKey, export.Hours, sqliteTS(export.PublishedAt),
+	)
+}
+
+func (s *SQLite) DatasetExports(ctx context.Context) ([]model.DatasetExport, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT day, json_key, csv_key, hours, published_at FROM dataset_exports ORDER BY day DESC",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []model.DatasetExport
+	for rows.Next() {
+		var e model.DatasetExport
+		if err := rows.Scan(&e.Day, &e.JSONKey, &e.CSVKey, &e.Hours, sqliteTime{&e.PublishedAt}); err != nil {
+			return nil, err
+		}
+		exports = append(exports, e)
+	}
+	return exports, rows.Err()
+}
+
+func (s *SQLite) MigrateDatasetExports(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS dataset_exports (
+			day          TEXT PRIMARY KEY,
+			json_key     TEXT NOT NULL,
+			csv_key      TEXT NOT NULL,
+			hours        INTEGER NOT NULL,
+			published_at TEXT NOT NULL
+		);
+	`)
+}
+
+func (s *SQLite) SaveModelReport(ctx context.Context, report []byte) error {
+	return s.exec(ctx,
+		"INSERT INTO model_reports (report, created_at) VALUES (?, ?)",
+		string(report), sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) LatestModelReport(ctx context.Context) ([]byte, error) {
+	return s.queryBlob(ctx, "SELECT report FROM model_reports ORDER BY created_at DESC LIMIT 1")
+}
+
+func (s *SQLite) MigrateModelReports(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS model_reports (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			report      TEXT NOT NULL,
+			created_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_model_reports_created_at ON model_reports (created_at DESC);
+	`)
+}
+
+func (s *SQLite) SaveAnnotation(ctx context.Context, body string, occurredAt time.Time) error {
+	return s.exec(ctx,
+		"INSERT INTO annotations (body, occurred_at, created_at) VALUES (?, ?, ?)",
+		body, sqliteTS(occurredAt), sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) AnnotationsBetween(ctx context.Context, from, to time.Time) ([]model.Annotation, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, body, occurred_at FROM annotations WHERE occurred_at >= ? AND occurred_at < ? ORDER BY occurred_at ASC",
+		sqliteTS(from), sqliteTS(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var annotations []model.Annotation
+	for rows.Next() {
+		var a model.Annotation
+		if err := rows.Scan(&a.ID, &a.Body, sqliteTime{&a.OccurredAt}); err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, rows.Err()
+}
+
+func (s *SQLite) MigrateAnnotations(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS annotations (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			body        TEXT NOT NULL,
+			occurred_at TEXT NOT NULL,
+			created_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_annotations_occurred_at ON annotations (occurred_at DESC);
+	`)
+}
+
+func (s *SQLite) CreateTenant(ctx context.Context, name, keyHash string, rateLimit int) (model.Tenant, error) {
+	t := model.Tenant{Name: name, RateLimit: rateLimit, CreatedAt: time.Now().UTC()}
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO tenants (name, key_hash, rate_limit, created_at) VALUES (?, ?, ?, ?)",
+		name, keyHash, rateLimit, sqliteTS(t.CreatedAt),
+	)
+	if err != nil {
+		return t, err
+	}
+	t.ID, err = res.LastInsertId()
+	return t, err
+}
+
+func (s *SQLite) TenantByKeyHash(ctx context.Context, keyHash string) (*model.Tenant, error) {
+	var t model.Tenant
+	err := s.db.QueryRowContext(ctx,
+		"SELECT id, name, rate_limit, disabled, created_at FROM tenants WHERE key_hash = ?",
+		keyHash,
+	).Scan(&t.ID, &t.Name, &t.RateLimit, &t.Disabled, sqliteTime{&t.CreatedAt})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *SQLite) RecordTenantUsage(ctx context.Context, usage []model.TenantUsage) error {
+	for _, u := range usage {
+		if _, err := time.Parse("2006-01-02", u.Day); err != nil {
+			return fmt.Errorf("tenant usage day %q: %w", u.Day, err)
+		}
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, u := range usage {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO tenant_usage (tenant_id, day, requests, bytes)
+				VALUES (?, ?, ?, ?)
+				ON CONFLICT (tenant_id, day) DO UPDATE SET
+					requests = tenant_usage.requests + excluded.requests,
+					bytes = tenant_usage.bytes + excluded.bytes`,
+				u.TenantID, u.Day, u.Requests, u.Bytes,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLite) TenantUsageSince(ctx context.Context, since time.Time) ([]model.TenantUsage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT u.tenant_id, t.name, u.day, u.requests, u.bytes
+		FROM tenant_usage u JOIN tenants t ON t.id = u.tenant_id
+		WHERE u.day >= ?
+		ORDER BY u.tenant_id, u.day`,
+		since.UTC().Format("2006-01-02"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []model.TenantUsage
+	for rows.Next() {
+		var u model.TenantUsage
+		if err := rows.Scan(&u.TenantID, &u.Name, &u.Day, &u.Requests, &u.Bytes); err != nil {
+			return nil, err
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+func (s *SQLite) MigrateTenants(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS tenants (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			name        TEXT NOT NULL,
+			key_hash    TEXT NOT NULL UNIQUE,
+			rate_limit  INTEGER NOT NULL DEFAULT 0,
+			disabled    INTEGER NOT NULL DEFAULT 0,
+			created_at  TEXT NOT NULL
+		);
+		CREATE TABLE IF NOT EXISTS tenant_usage (
+			tenant_id   INTEGER NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+			day         TEXT NOT NULL,
+			requests    INTEGER NOT NULL DEFAULT 0,
+			bytes       INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (tenant_id, day)
+		);
+	`)
+}
+
+func (s *SQLite) SaveFeedChecks(ctx context.Context, results []model.FeedResult, checkedAt time.Time) error {
+	at := sqliteTS(checkedAt)
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx,
+			"INSERT INTO feed_checks (feed_url, fetched, parsed, items, matched, error, checked_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, r := range results {
+			if _, err := stmt.ExecContext(ctx, r.URL, r.Fetched, r.Parsed, r.Items, r.Matched, r.Error, at); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *SQLite) FeedHealth(ctx context.Context, since time.Time) ([]model.FeedHealth, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT feed_url,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE fetched),
+		       COUNT(*) FILTER (WHERE parsed),
+		       COALESCE(ROUND(AVG(items), 1), 0.0),
+		       MIN(checked_at),
+		       MAX(checked_at),
+		       MAX(checked_at) FILTER (WHERE parsed),
+		       COALESCE((SELECT e.error FROM feed_checks e
+		                 WHERE e.feed_url = c.feed_url AND e.error <> '' AND e.checked_at >= ?1
+		                 ORDER BY e.checked_at DESC LIMIT 1), '')
+		FROM feed_checks c
+		WHERE checked_at >= ?1
+		GROUP BY feed_url
+		ORDER BY feed_url`,
+		sqliteTS(since),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var health []model.FeedHealth
+	for rows.Next() {
+		var h model.FeedHealth
+		if err := rows.Scan(&h.URL, &h.Checks, &h.FetchOK, &h.ParseOK, &h.AvgItems,
+			sqliteTime{&h.FirstCheckedAt}, sqliteTime{&h.LastCheckedAt}, sqliteNullTime{&h.LastSuccessAt}, &h.LastError); err != nil {
+			return nil, err
+		}
+		health = append(health, h)
+	}
+	return health, rows.Err()
+}
+
+func (s *SQLite) MigrateFeedChecks(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS feed_checks (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			feed_url    TEXT NOT NULL,
+			fetched     INTEGER NOT NULL,
+			parsed      INTEGER NOT NULL,
+			items       INTEGER NOT NULL DEFAULT 0,
+			matched     INTEGER NOT NULL DEFAULT 0,
+			error       TEXT NOT NULL DEFAULT '',
+			checked_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_feed_checks_url_checked_at ON feed_checks (feed_url, checked_at DESC);
+	`)
+}
+
+func (s *SQLite) ConfigOverrides(ctx context.Context) ([]model.ConfigOverride, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT key, value, updated_by, updated_at FROM config_overrides ORDER BY key",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []model.ConfigOverride
+	for rows.Next() {
+		var o model.ConfigOverride
+		if err := rows.Scan(&o.Key, &o.Value, &o.UpdatedBy, sqliteTime{&o.UpdatedAt}); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+func (s *SQLite) SetConfigOverride(ctx context.Context, key, value, updatedBy string) error {
+	return s.exec(ctx, `
+		INSERT INTO config_overrides (key, value, updated_by, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			value = excluded.value,
+			updated_by = excluded.updated_by,
+			updated_at = excluded.updated_at`,
+		key, value, updatedBy, sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) DeleteConfigOverride(ctx context.Context, key string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM config_overrides WHERE key = ?", key)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *SQLite) MigrateConfigOverrides(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS config_overrides (
+			key         TEXT PRIMARY KEY,
+			value       TEXT NOT NULL,
+			updated_by  TEXT NOT NULL,
+			updated_at  TEXT NOT NULL
+		);
+	`)
+}
+
+func (s *SQLite) SignalOverrides(ctx context.Context, now time.Time) ([]model.SignalOverride, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT signal, risk, reason, created_by, created_at, expires_at
+		FROM signal_overrides
+		WHERE expires_at > ?
+		ORDER BY signal`,
+		sqliteTS(now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []model.SignalOverride
+	for rows.Next() {
+		var o model.SignalOverride
+		if err := rows.Scan(&o.Signal, &o.Risk, &o.Reason, &o.CreatedBy, sqliteTime{&o.CreatedAt}, sqliteTime{&o.ExpiresAt}); err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+func (s *SQLite) SetSignalOverride(ctx context.Context, o model.SignalOverride) error {
+	return s.exec(ctx, `
+		INSERT INTO signal_overrides (signal, risk, reason, created_by, created_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (signal) DO UPDATE SET
+			risk = excluded.risk,
+			reason = excluded.reason,
+			created_by = excluded.created_by,
+			created_at = excluded.created_at,
+			expires_at = excluded.expires_at`,
+		o.Signal, o.Risk, o.Reason, o.CreatedBy, sqliteTS(o.CreatedAt), sqliteTS(o.ExpiresAt),
+	)
+}
+
+func (s *SQLite) DeleteSignalOverride(ctx context.Context, signal string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM signal_overrides WHERE signal = ?", signal)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *SQLite) MigrateSignalOverrides(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS signal_overrides (
+			signal      TEXT PRIMARY KEY,
+			risk        INTEGER NOT NULL,
+			reason      TEXT NOT NULL,
+			created_by  TEXT NOT NULL,
+			created_at  TEXT NOT NULL,
+			expires_at  TEXT NOT NULL
+		);
+	`)
+}
+
+func (s *SQLite) SaveIncident(ctx context.Context, inc model.Incident) error {
+	signals, err := json.Marshal(inc.Signals)
+	if err != nil {
+		return err
+	}
+	headlines, err := json.Marshal(inc.Headlines)
+	if err != nil {
+		return err
+	}
+	return s.exec(ctx,
+		"INSERT INTO incidents (run_id, detected_at, total_risk, signals, headlines) VALUES (?, ?, ?, ?, ?)",
+		inc.RunID, sqliteTS(inc.DetectedAt), inc.TotalRisk, string(signals), string(headlines),
+	)
+}
+
+func (s *SQLite) IncidentsBetween(ctx context.Context, from, to time.Time) ([]model.Incident, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, run_id, detected_at, total_risk, signals, headlines
+		FROM incidents
+		WHERE detected_at >= ? AND detected_at < ?
+		ORDER BY detected_at DESC`,
+		sqliteTS(from), sqliteTS(to),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var incidents []model.Incident
+	for rows.Next() {
+		var inc model.Incident
+		var signals, headlines []byte
+		if err := rows.Scan(&inc.ID, &inc.RunID, sqliteTime{&inc.DetectedAt}, &inc.TotalRisk, &signals, &headlines); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(signals, &inc.Signals); err != nil {
+			return nil, fmt.Errorf("incident %d signals: %w", inc.ID, err)
+		}
+		if err := json.Unmarshal(headlines, &inc.Headlines); err != nil {
+			return nil, fmt.Errorf("incident %d headlines: %w", inc.ID, err)
+		}
+		incidents = append(incidents, inc)
+	}
+	return incidents, rows.Err()
+}
+
+func (s *SQLite) MigrateIncidents(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS incidents (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			run_id      TEXT NOT NULL,
+			detected_at TEXT NOT NULL,
+			total_risk  INTEGER NOT NULL,
+			signals     TEXT NOT NULL,
+			headlines   TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_incidents_detected_at ON incidents (detected_at DESC);
+	`)
+}
+
+func (s *SQLite) CreateWebhook(ctx context.Context, w model.Webhook) (model.Webhook, error) {
+	w.CreatedAt = time.Now().UTC()
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO webhooks (url, threshold, direction, payload_mode, secret, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		w.URL, w.Threshold, w.Direction, w.PayloadMode, w.Secret, sqliteTS(w.CreatedAt),
+	)
+	if err != nil {
+		return w, err
+	}
+	w.ID, err = res.LastInsertId()
+	return w, err
+}
+
+func (s *SQLite) Webhooks(ctx context.Context) ([]model.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, url, threshold, direction, payload_mode, secret, created_at FROM webhooks ORDER BY id",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []model.Webhook
+	for rows.Next() {
+		var w model.Webhook
+		if err := rows.Scan(&w.ID, &w.URL, &w.Threshold, &w.Direction, &w.PayloadMode, &w.Secret, sqliteTime{&w.CreatedAt}); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, rows.Err()
+}
+
+func (s *SQLite) DeleteWebhook(ctx context.Context, id int64) (bool, error) {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (s *SQLite) MigrateWebhooks(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			url          TEXT NOT NULL,
+			threshold    INTEGER NOT NULL,
+			direction    TEXT NOT NULL,
+			payload_mode TEXT NOT NULL DEFAULT 'full',
+			secret       TEXT NOT NULL,
+			created_at   TEXT NOT NULL
+		);
+	`)
+}
+
+func (s *SQLite) SavePulseBaselines(ctx context.Context, baselines []byte) error {
+	return s.exec(ctx,
+		"INSERT INTO pulse_baselines (baselines, created_at) VALUES (?, ?)",
+		string(baselines), sqliteTS(time.Now()),
+	)
+}
+
+func (s *SQLite) LatestPulseBaselines(ctx context.Context) ([]byte, error) {
+	return s.queryBlob(ctx, "SELECT baselines FROM pulse_baselines ORDER BY created_at DESC LIMIT 1")
+}
+
+func (s *SQLite) SavePulseWindow(ctx context.Context, w model.PulseWindow) error {
+	counts, err := json.Marshal(w.Counts)
+	if err != nil {
+		return err
+	}
+	return s.exec(ctx,
+		"INSERT INTO pulse_windows (ended_at, counts) VALUES (?, ?) ON CONFLICT (ended_at) DO UPDATE SET counts = excluded.counts",
+		sqliteTS(w.EndedAt), string(counts),
+	)
+}
+
+func (s *SQLite) PulseWindowsSince(ctx context.Context, since time.Time) ([]model.PulseWindow, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT ended_at, counts FROM pulse_windows WHERE ended_at >= ? ORDER BY ended_at ASC",
+		sqliteTS(since),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []model.PulseWindow
+	for rows.Next() {
+		var w model.PulseWindow
+		var counts []byte
+		if err := rows.Scan(sqliteTime{&w.EndedAt}, &counts); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(counts, &w.Counts); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+func (s *SQLite) MigratePulseBaselines(ctx context.Context) error {
+	return s.exec(ctx, `
+		CREATE TABLE IF NOT EXISTS pulse_baselines (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			baselines   TEXT NOT NULL,
+			created_at  TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_pulse_baselines_created_at ON pulse_baselines (created_at DESC);
+
+		CREATE TABLE IF NOT EXISTS pulse_windows (
+			ended_at    TEXT PRIMARY KEY,
+			counts      TEXT NOT NULL
+		);
+	`)
+}
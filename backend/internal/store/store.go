@@ -1,17 +1,195 @@
 package store
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+)
+
+// SnapshotFunc receives one stored snapshot during iteration. The response
+// slice is only valid for the duration of the call.
+type SnapshotFunc func(createdAt time.Time, response []byte) error
 
 // Store is the repository interface for snapshot persistence.
 type Store interface {
 	// SaveSnapshot stores a JSON response blob.
 	SaveSnapshot(ctx context.Context, response []byte) error
+	// ImportSnapshot stores a JSON response blob with an explicit creation
+	// time. It is a no-op, returning false, if a snapshot already exists at
+	// exactly createdAt, so imports can be re-run safely.
+	ImportSnapshot(ctx context.Context, response []byte, createdAt time.Time) (bool, error)
 	// LatestSnapshot returns the most recent JSON response blob.
 	LatestSnapshot(ctx context.Context) ([]byte, error)
+	// SnapshotAt returns the most recent JSON response blob stored at or
+	// before t, or nil if none exists.
+	SnapshotAt(ctx context.Context, t time.Time) ([]byte, error)
+	// SnapshotsBetween streams snapshots created in [from, to) in ascending
+	// order, calling fn for each row without loading the range into memory.
+	// Iteration stops at the first error returned by fn.
+	SnapshotsBetween(ctx context.Context, from, to time.Time, fn SnapshotFunc) error
+	// TotalRiskSeries returns the total risk of every snapshot created at or
+	// after since, oldest first.
+	TotalRiskSeries(ctx context.Context, since time.Time) ([]model.TotalRiskPoint, error)
+	// TotalRiskBetween returns the total risk of every snapshot created in
+	// [from, to), oldest first, without reading the rest of the blobs.
+	TotalRiskBetween(ctx context.Context, from, to time.Time) ([]model.TotalRiskPoint, error)
 	// Migrate runs database migrations.
 	Migrate(ctx context.Context) error
 	// SaveRadarIdea stores a user-submitted radar idea.
 	SaveRadarIdea(ctx context.Context, idea, countryCode string) error
-	// MigrateRadarIdeas creates the radar_ideas table.
+	// SaveRejectedRadarIdea stores a radar idea that failed spam filtering
+	// in the shadow table, with the reason it was rejected.
+	SaveRejectedRadarIdea(ctx context.Context, idea, countryCode, reason, language string) error
+	// MigrateRadarIdeas creates the radar_ideas and rejected_radar_ideas tables.
 	MigrateRadarIdeas(ctx context.Context) error
+	// SaveAircraftCount records the civil aircraft count observed at a point in time.
+	SaveAircraftCount(ctx context.Context, count int, observedAt time.Time) error
+	// AircraftBaseline returns the average aircraft count observed at the given
+	// UTC hour over the last `days` days, and the number of samples used.
+	AircraftBaseline(ctx context.Context, hour, days int) (float64, int, error)
+	// MigrateAircraftCounts creates the aircraft_counts table.
+	MigrateAircraftCounts(ctx context.Context) error
+	// SaveTankerCount records the tanker count observed at a point in time.
+	SaveTankerCount(ctx context.Context, count int, observedAt time.Time) error
+	// TankerBaseline returns the average tanker count observed at the given
+	// UTC hour and weekday over the last `weeks` weeks, and the sample count.
+	TankerBaseline(ctx context.Context, hour int, weekday time.Weekday, weeks int) (float64, int, error)
+	// MigrateTankerCounts creates the tanker_counts table.
+	MigrateTankerCounts(ctx context.Context) error
+	// SaveVesselCounts records the vessels and tankers under way in the
+	// Strait of Hormuz at a point in time.
+	SaveVesselCounts(ctx context.Context, vessels, tankers int, observedAt time.Time) error
+	// VesselBaseline returns the average vessel and tanker counts observed
+	// at the given UTC hour over the last `days` days, and the sample count.
+	VesselBaseline(ctx context.Context, hour, days int) (vessels, tankers float64, samples int, err error)
+	// MigrateVesselCounts creates the vessel_counts table.
+	MigrateVesselCounts(ctx context.Context) error
+	// SaveCarrierSightings records the major carriers seen in Iranian
+	// airspace at a point in time.
+	SaveCarrierSightings(ctx context.Context, carriers []string, observedAt time.Time) error
+	// CarrierSightings returns the carriers seen by each run at the given
+	// UTC hour over the last `days` days, one entry per run.
+	CarrierSightings(ctx context.Context, hour, days int) ([][]string, error)
+	// MigrateCarrierSightings creates the carrier_sightings table.
+	MigrateCarrierSightings(ctx context.Context) error
+	// SaveTrackPoints stores the positions of one kind of aircraft seen in a run.
+	SaveTrackPoints(ctx context.Context, kind string, positions []model.Position, observedAt time.Time) error
+	// TrackPointsSince returns stored positions observed at or after since,
+	// keeping each aircraft's latest position per step and at most limit of
+	// the most recent positions, ordered by aircraft and time.
+	TrackPointsSince(ctx context.Context, since time.Time, step time.Duration, limit int) ([]model.TrackPoint, error)
+	// PruneTrackPoints deletes positions observed before cutoff and returns
+	// how many were removed.
+	PruneTrackPoints(ctx context.Context, cutoff time.Time) (int64, error)
+	// MigrateTracks creates the track_points table.
+	MigrateTracks(ctx context.Context) error
+	// SaveSignalScores stores one row per signal for a pipeline run.
+	SaveSignalScores(ctx context.Context, runID string, scores []model.NamedSignalScore) error
+	// SignalScoresSince returns per-signal rows created at or after since,
+	// ordered by creation time.
+	SignalScoresSince(ctx context.Context, since time.Time) ([]model.SignalScoreRow, error)
+	// SignalScoresBetween returns one signal's rows created in [from, to],
+	// ordered by creation time.
+	SignalScoresBetween(ctx context.Context, signal string, from, to time.Time) ([]model.SignalScoreRow, error)
+	// MigrateSignalScores creates the signal_scores table.
+	MigrateSignalScores(ctx context.Context) error
+	// SaveKeywords stores a new JSON keyword set; the latest one is active.
+	SaveKeywords(ctx context.Context, keywords []byte) error
+	// LatestKeywords returns the active JSON keyword set, or nil if none is stored.
+	LatestKeywords(ctx context.Context) ([]byte, error)
+	// MigrateKeywords creates the news_keywords table.
+	MigrateKeywords(ctx context.Context) error
+	// SaveMarketRules stores a new JSON Polymarket rule set; the latest one is active.
+	SaveMarketRules(ctx context.Context, rules []byte) error
+	// LatestMarketRules returns the active JSON Polymarket rule set, or nil if none is stored.
+	LatestMarketRules(ctx context.Context) ([]byte, error)
+	// MigrateMarketRules creates the polymarket_rules table.
+	MigrateMarketRules(ctx context.Context) error
+	// SaveDatasetExport records a published daily dataset, replacing any
+	// earlier record for the same day.
+	SaveDatasetExport(ctx context.Context, export model.DatasetExport) error
+	// DatasetExports returns all published datasets, newest day first.
+	DatasetExports(ctx context.Context) ([]model.DatasetExport, error)
+	// MigrateDatasetExports creates the dataset_exports table.
+	MigrateDatasetExports(ctx context.Context) error
+	// SaveModelReport stores a JSON model diagnostics report.
+	SaveModelReport(ctx context.Context, report []byte) error
+	// LatestModelReport returns the most recent JSON model report, or nil if
+	// none exists.
+	LatestModelReport(ctx context.Context) ([]byte, error)
+	// MigrateModelReports creates the model_reports table.
+	MigrateModelReports(ctx context.Context) error
+	// SaveAnnotation stores an operator note about something that happened at occurredAt.
+	SaveAnnotation(ctx context.Context, body string, occurredAt time.Time) error
+	// AnnotationsBetween returns annotations that occurred in [from, to), oldest first.
+	AnnotationsBetween(ctx context.Context, from, to time.Time) ([]model.Annotation, error)
+	// MigrateAnnotations creates the annotations table.
+	MigrateAnnotations(ctx context.Context) error
+	// CreateTenant registers an API consumer under the SHA-256 hash of its key.
+	CreateTenant(ctx context.Context, name, keyHash string, rateLimit int) (model.Tenant, error)
+	// TenantByKeyHash returns the tenant owning a key hash, or nil if none does.
+	TenantByKeyHash(ctx context.Context, keyHash string) (*model.Tenant, error)
+	// RecordTenantUsage adds request and byte counts to each tenant's daily totals.
+	RecordTenantUsage(ctx context.Context, usage []model.TenantUsage) error
+	// TenantUsageSince returns daily usage rows on or after the given UTC day,
+	// with tenant names, ordered by tenant then day.
+	TenantUsageSince(ctx context.Context, since time.Time) ([]model.TenantUsage, error)
+	// MigrateTenants creates the tenants and tenant_usage tables.
+	MigrateTenants(ctx context.Context) error
+	// SaveFeedChecks records each news feed's outcome in one run.
+	SaveFeedChecks(ctx context.Context, results []model.FeedResult, checkedAt time.Time) error
+	// FeedHealth summarizes each feed's checks since the given time, ordered
+	// by URL. Status is left for the caller to judge.
+	FeedHealth(ctx context.Context, since time.Time) ([]model.FeedHealth, error)
+	// MigrateFeedChecks creates the feed_checks table.
+	MigrateFeedChecks(ctx context.Context) error
+	// ConfigOverrides returns every stored setting override, ordered by key.
+	ConfigOverrides(ctx context.Context) ([]model.ConfigOverride, error)
+	// SetConfigOverride stores or replaces the override for a key.
+	SetConfigOverride(ctx context.Context, key, value, updatedBy string) error
+	// DeleteConfigOverride removes a key's override and reports whether
+	// there was one.
+	DeleteConfigOverride(ctx context.Context, key string) (bool, error)
+	// MigrateConfigOverrides creates the config_overrides table.
+	MigrateConfigOverrides(ctx context.Context) error
+	// SignalOverrides returns the signal overrides still in force at now,
+	// ordered by signal.
+	SignalOverrides(ctx context.Context, now time.Time) ([]model.SignalOverride, error)
+	// SetSignalOverride stores or replaces the override for o.Signal.
+	SetSignalOverride(ctx context.Context, o model.SignalOverride) error
+	// DeleteSignalOverride removes a signal's override and reports whether
+	// there was one.
+	DeleteSignalOverride(ctx context.Context, signal string) (bool, error)
+	// MigrateSignalOverrides creates the signal_overrides table.
+	MigrateSignalOverrides(ctx context.Context) error
+	// SaveIncident records a detected incident.
+	SaveIncident(ctx context.Context, inc model.Incident) error
+	// IncidentsBetween returns incidents detected in [from, to), newest first.
+	IncidentsBetween(ctx context.Context, from, to time.Time) ([]model.Incident, error)
+	// MigrateIncidents creates the incidents table.
+	MigrateIncidents(ctx context.Context) error
+	// CreateWebhook registers a webhook, returning it with its ID and
+	// creation time.
+	CreateWebhook(ctx context.Context, w model.Webhook) (model.Webhook, error)
+	// Webhooks returns every registered webhook, secrets included, oldest first.
+	Webhooks(ctx context.Context) ([]model.Webhook, error)
+	// DeleteWebhook removes a webhook and reports whether it existed.
+	DeleteWebhook(ctx context.Context, id int64) (bool, error)
+	// MigrateWebhooks creates the webhooks table.
+	MigrateWebhooks(ctx context.Context) error
+	// SavePulseBaselines stores a new JSON pulse baseline set; the latest
+	// one is active.
+	SavePulseBaselines(ctx context.Context, baselines []byte) error
+	// LatestPulseBaselines returns the active JSON pulse baselines, or nil
+	// if none are stored.
+	LatestPulseBaselines(ctx context.Context) ([]byte, error)
+	// SavePulseWindow records the visits counted over one pulse window.
+	SavePulseWindow(ctx context.Context, w model.PulseWindow) error
+	// PulseWindowsSince returns the pulse windows ending at or after since,
+	// oldest first.
+	PulseWindowsSince(ctx context.Context, since time.Time) ([]model.PulseWindow, error)
+	// MigratePulseBaselines creates the pulse_baselines and pulse_windows
+	// tables.
+	MigratePulseBaselines(ctx context.Context) error
 }
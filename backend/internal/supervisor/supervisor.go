@@ -0,0 +1,164 @@
+// Package supervisor runs the app's background jobs, restarting any that
+// crash, and reports how each is doing.
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+	// stableAfter is how long a job must run before a crash is treated as
+	// a new incident rather than part of a crash loop, resetting backoff.
+	stableAfter = 5 * time.Minute
+)
+
+// Job is a background loop. Start blocks until Stop is called; returning
+// earlier, or panicking, counts as a crash.
+type Job interface {
+	Start(ctx context.Context)
+	Stop()
+}
+
+// Status is one job's state. State is "running", "restarting" while it
+// waits out its backoff after a crash, or "stopped".
+type Status struct {
+	Name          string     `json:"name"`
+	State         string     `json:"state"`
+	Restarts      int        `json:"restarts"`
+	StartedAt     time.Time  `json:"started_at"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastCrashedAt *time.Time `json:"last_crashed_at,omitempty"`
+}
+
+type entry struct {
+	job    Job
+	status Status
+}
+
+// Supervisor owns a fixed set of jobs, added before Start.
+type Supervisor struct {
+	mu       sync.Mutex
+	entries  []*entry
+	stopping bool
+	done     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func New() *Supervisor {
+	return &Supervisor{done: make(chan struct{})}
+}
+
+// Add registers a job under a name used in logs and Status.
+func (s *Supervisor) Add(name string, job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, &entry{job: job, status: Status{Name: name, State: "stopped"}})
+}
+
+// Start runs every job in its own goroutine.
+func (s *Supervisor) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go s.supervise(ctx, e)
+	}
+}
+
+// Stop stops every job in the order they were added and waits for them
+// to return.
+func (s *Supervisor) Stop() {
+	s.mu.Lock()
+	s.stopping = true
+	entries := s.entries
+	s.mu.Unlock()
+	close(s.done)
+	for _, e := range entries {
+		e.job.Stop()
+	}
+	s.wg.Wait()
+}
+
+// Status returns every job's state in the order they were added.
+func (s *Supervisor) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e.status)
+	}
+	return out
+}
+
+// Healthy reports whether every job is running.
+func (s *Supervisor) Healthy() bool {
+	for _, st := range s.Status() {
+		if st.State != "running" {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Supervisor) supervise(ctx context.Context, e *entry) {
+	defer s.wg.Done()
+	backoff := minBackoff
+	for {
+		s.mu.Lock()
+		if s.stopping {
+			e.status.State = "stopped"
+			s.mu.Unlock()
+			return
+		}
+		started := time.Now()
+		e.status.State = "running"
+		e.status.StartedAt = started
+		s.mu.Unlock()
+
+		err := run(ctx, e.job)
+
+		s.mu.Lock()
+		if s.stopping || ctx.Err() != nil {
+			e.status.State = "stopped"
+			s.mu.Unlock()
+			return
+		}
+		if time.Since(started) >= stableAfter {
+			backoff = minBackoff
+		}
+		now := time.Now()
+		e.status.State = "restarting"
+		e.status.Restarts++
+		e.status.LastError = err.Error()
+		e.status.LastCrashedAt = &now
+		s.mu.Unlock()
+
+		slog.Error("supervisor: job crashed, restarting", "job", e.status.Name, "error", err, "backoff", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+		case <-ctx.Done():
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
+
+// run calls job.Start and returns why it ended, taken as a crash unless
+// the supervisor is stopping.
+func run(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("supervisor: job panicked", "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	job.Start(ctx)
+	return fmt.Errorf("returned unexpectedly")
+}
@@ -0,0 +1,233 @@
+// Package tenant authenticates API consumers by key, enforces their rate
+// limits and accounts their usage.
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+)
+
+// lookupTTL is how long a key lookup, including a miss, is cached, so a
+// disabled tenant or revoked key takes effect within this window.
+const lookupTTL = time.Minute
+
+// maxCachedMisses bounds the keys remembered as unknown. Misses come from
+// whatever X-API-Key a client sends, so beyond this they aren't cached.
+const maxCachedMisses = 1024
+
+// flushInterval is how often accumulated usage is written to the store.
+const flushInterval = time.Minute
+
+// Registry resolves API keys to tenants, rate-limits them with a token
+// bucket each, and batches usage counts into the store.
+type Registry struct {
+	store store.Store
+	stop  chan struct{}
+
+	mu      sync.Mutex
+	keys    map[string]cachedTenant
+	misses  map[string]time.Time
+	buckets map[int64]*bucket
+	usage   map[usageKey]*model.TenantUsage
+}
+
+type cachedTenant struct {
+	tenant  *model.Tenant
+	expires time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type usageKey struct {
+	tenantID int64
+	day      string
+}
+
+func New(st store.Store) *Registry {
+	return &Registry{
+		store:   st,
+		stop:    make(chan struct{}),
+		keys:    make(map[string]cachedTenant),
+		misses:  make(map[string]time.Time),
+		buckets: make(map[int64]*bucket),
+		usage:   make(map[usageKey]*model.TenantUsage),
+	}
+}
+
+// GenerateKey returns a new random API key.
+func GenerateKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "aeg_" + hex.EncodeToString(b), nil
+}
+
+// HashKey returns the stored form of an API key.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Lookup returns the enabled tenant owning key, or nil if there is none.
+func (r *Registry) Lookup(ctx context.Context, key string) (*model.Tenant, error) {
+	hash := HashKey(key)
+	now := time.Now()
+
+	r.mu.Lock()
+	c, ok := r.keys[hash]
+	missExpires, missed := r.misses[hash]
+	r.mu.Unlock()
+	if ok && now.Before(c.expires) {
+		return c.tenant, nil
+	}
+	if missed && now.Before(missExpires) {
+		return nil, nil
+	}
+
+	t, err := r.store.TenantByKeyHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if t != nil && t.Disabled {
+		t = nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t != nil {
+		r.keys[hash] = cachedTenant{tenant: t, expires: now.Add(lookupTTL)}
+		return t, nil
+	}
+	delete(r.keys, hash)
+	if len(r.misses) >= maxCachedMisses {
+		r.sweep(now)
+	}
+	if len(r.misses) < maxCachedMisses {
+		r.misses[hash] = now.Add(lookupTTL)
+	}
+	return nil, nil
+}
+
+// sweep drops expired lookups. r.mu must be held.
+func (r *Registry) sweep(now time.Time) {
+	for hash, c := range r.keys {
+		if !now.Before(c.expires) {
+			delete(r.keys, hash)
+		}
+	}
+	for hash, expires := range r.misses {
+		if !now.Before(expires) {
+			delete(r.misses, hash)
+		}
+	}
+}
+
+// Allow takes one request from the tenant's bucket. When the bucket is
+// empty it returns false and how long until a request would be allowed.
+func (r *Registry) Allow(t *model.Tenant, now time.Time) (bool, time.Duration) {
+	if t.RateLimit <= 0 {
+		return true, 0
+	}
+	capacity := float64(t.RateLimit)
+	perSecond := capacity / 60
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b, ok := r.buckets[t.ID]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		r.buckets[t.ID] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Record adds one request of the given response size to the tenant's usage.
+func (r *Registry) Record(tenantID int64, bytes int64, now time.Time) {
+	k := usageKey{tenantID: tenantID, day: now.UTC().Format("2006-01-02")}
+	r.mu.Lock()
+	u, ok := r.usage[k]
+	if !ok {
+		u = &model.TenantUsage{TenantID: tenantID, Day: k.day}
+		r.usage[k] = u
+	}
+	u.Requests++
+	u.Bytes += bytes
+	r.mu.Unlock()
+}
+
+// Start flushes usage to the store and sweeps expired lookups every
+// minute. Blocks until Stop is called.
+func (r *Registry) Start(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	slog.Info("tenant usage flusher started")
+	for {
+		select {
+		case <-ticker.C:
+			r.mu.Lock()
+			r.sweep(time.Now())
+			r.mu.Unlock()
+			r.flush(ctx)
+		case <-r.stop:
+			slog.Info("tenant usage flusher stopped")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop signals the flusher to stop and writes any usage still pending.
+func (r *Registry) Stop() {
+	close(r.stop)
+	r.flush(context.Background())
+}
+
+func (r *Registry) flush(ctx context.Context) {
+	r.mu.Lock()
+	pending := r.usage
+	r.usage = make(map[usageKey]*model.TenantUsage)
+	r.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	rows := make([]model.TenantUsage, 0, len(pending))
+	for _, u := range pending {
+		rows = append(rows, *u)
+	}
+	if err := r.store.RecordTenantUsage(ctx, rows); err != nil {
+		// Put the counts back so the next flush retries them
+		slog.Error("failed to record tenant usage", "tenants", len(rows), "error", err)
+		r.mu.Lock()
+		for k, u := range pending {
+			if cur, ok := r.usage[k]; ok {
+				cur.Requests += u.Requests
+				cur.Bytes += u.Bytes
+			} else {
+				r.usage[k] = u
+			}
+		}
+		r.mu.Unlock()
+	}
+}
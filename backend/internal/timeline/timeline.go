@@ -0,0 +1,162 @@
+// Package timeline turns stored scores and operator annotations into a
+// chronological situation log.
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/fetcher"
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+)
+
+// Event kinds.
+const (
+	KindAlert      = "alert"
+	KindChange     = "change"
+	KindAnnotation = "annotation"
+)
+
+// Event is one entry in the situation log.
+type Event struct {
+	Timestamp time.Time `json:"timestamp"`
+	Kind      string    `json:"kind"`
+	Signal    string    `json:"signal,omitempty"`
+	Title     string    `json:"title"`
+	Risk      *int      `json:"risk,omitempty"`
+}
+
+// Build returns the events between from and to, oldest first. Alerts are
+// emitted when the total risk changes band or more signals become elevated,
+// change-points are each signal's moves between consecutive runs, and
+// annotations are copied as-is. It reads the stored total risk series and
+// per-signal scores, never the snapshot blobs.
+func Build(ctx context.Context, st store.Store, from, to time.Time) ([]Event, error) {
+	events := []Event{}
+
+	totals, err := st.TotalRiskBetween(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("reading total risk: %w", err)
+	}
+	for i := 1; i < len(totals); i++ {
+		prev, cur := totals[i-1].Risk, totals[i].Risk
+		if a, b := risk.Band(prev), risk.Band(cur); a != b {
+			events = append(events, Event{
+				Timestamp: time.UnixMilli(totals[i].Timestamp).UTC(),
+				Kind:      KindAlert,
+				Title:     fmt.Sprintf("Total risk moved from %s to %s (%d%%)", a, b, cur),
+				Risk:      &cur,
+			})
+		}
+	}
+
+	rows, err := st.SignalScoresSince(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("reading signal scores: %w", err)
+	}
+	events = append(events, runEvents(runsOf(rows, to), totals)...)
+
+	annotations, err := st.AnnotationsBetween(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("reading annotations: %w", err)
+	}
+	for _, a := range annotations {
+		events = append(events, Event{
+			Timestamp: a.OccurredAt,
+			Kind:      KindAnnotation,
+			Title:     a.Body,
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	return events, nil
+}
+
+// run is one pipeline run's stored signal scores.
+type run struct {
+	at     time.Time
+	scores map[string]model.SignalScoreRow
+}
+
+// runsOf groups rows, oldest first, into runs, dropping those from to on.
+func runsOf(rows []model.SignalScoreRow, to time.Time) []run {
+	var runs []run
+	index := map[string]int{}
+	for _, r := range rows {
+		if !r.CreatedAt.Before(to) {
+			continue
+		}
+		i, ok := index[r.RunID]
+		if !ok {
+			i = len(runs)
+			index[r.RunID] = i
+			runs = append(runs, run{at: r.CreatedAt, scores: map[string]model.SignalScoreRow{}})
+		}
+		runs[i].scores[r.Signal] = r
+	}
+	return runs
+}
+
+// runEvents compares each run with the one before it. The first run in
+// range has nothing to compare against. Elevated-count alerts carry the
+// total risk published with the run, the latest in totals at or before it.
+func runEvents(runs []run, totals []model.TotalRiskPoint) []Event {
+	var events []Event
+	next := 0
+	var total *int
+	for i, r := range runs {
+		for next < len(totals) && !time.UnixMilli(totals[next].Timestamp).After(r.at) {
+			t := totals[next].Risk
+			total = &t
+			next++
+		}
+		if i == 0 {
+			continue
+		}
+		prev := runs[i-1]
+
+		if n, was := elevated(r), elevated(prev); n > was {
+			events = append(events, Event{
+				Timestamp: r.at,
+				Kind:      KindAlert,
+				Title:     fmt.Sprintf("%d signals elevated (was %d)", n, was),
+				Risk:      total,
+			})
+		}
+
+		for _, d := range fetcher.Descriptors() {
+			s, ok := r.scores[d.Name]
+			before, had := prev.scores[d.Name]
+			if !ok || !had {
+				continue
+			}
+			if c, ok := risk.Change(d.Name, d.Label, before.Risk, s.Risk, s.Detail); ok {
+				to := c.To
+				events = append(events, Event{
+					Timestamp: r.at,
+					Kind:      KindChange,
+					Signal:    c.Signal,
+					Title:     c.Description,
+					Risk:      &to,
+				})
+			}
+		}
+	}
+	return events
+}
+
+func elevated(r run) int {
+	n := 0
+	for _, s := range r.scores {
+		if s.Elevated {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,335 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/store"
+)
+
+const (
+	// maxAttempts bounds how often one delivery is tried; with the backoff
+	// doubling from retryBackoff the last try is about 15s after the first.
+	maxAttempts  = 5
+	retryBackoff = time.Second
+	// queueSize is how many crossings may wait for the worker before new
+	// ones are dropped.
+	queueSize = 16
+)
+
+// Directions a webhook can subscribe to.
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+	DirectionBoth = "both"
+)
+
+var deliveries = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "aegis",
+	Subsystem: "webhook",
+	Name:      "deliveries_total",
+	Help:      "Webhook deliveries by result: delivered, failed or dropped.",
+}, []string{"result"})
+
+// Event is the body POSTed to a webhook when total risk crosses its
+// threshold. Data is the snapshot, or the diff from the previous one,
+// depending on the webhook's payload mode.
+type Event struct {
+	Event     string `json:"event"`
+	WebhookID int64  `json:"webhook_id"`
+	Threshold int    `json:"threshold"`
+	Direction string `json:"direction"`
+	Previous  int    `json:"previous"`
+	Current   int    `json:"current"`
+	// Test marks an event sent by an operator's test alert rather than a
+	// real crossing.
+	Test bool            `json:"test,omitempty"`
+	Data json.RawMessage `json:"data"`
+}
+
+// crossing is one pipeline run's snapshot paired with the one before it.
+type crossing struct {
+	prev, cur         []byte
+	prevRisk, curRisk int
+}
+
+// Dispatcher delivers threshold crossings to registered webhooks. It
+// compares each snapshot the pipeline caches with the previous one and,
+// when total risk moved, hands the pair to a worker that POSTs to every
+// webhook whose threshold lies between the two.
+//
+// Each body is signed with the webhook's secret: X-Aegis-Signature is
+// "sha256=" and the hex HMAC-SHA256 of the X-Aegis-Timestamp header, a
+// dot, and the body. Failed deliveries are retried with backoff.
+type Dispatcher struct {
+	st     store.Store
+	client *http.Client
+
+	mu   sync.Mutex
+	prev []byte
+
+	queue chan crossing
+	stop  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewDispatcher returns a dispatcher for the webhooks in st. prev is the
+// snapshot the next one is compared against, nil if there is none yet.
+func NewDispatcher(st store.Store, prev []byte) *Dispatcher {
+	return &Dispatcher{
+		st:     st,
+		client: &http.Client{Timeout: 10 * time.Second},
+		prev:   prev,
+		queue:  make(chan crossing, queueSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// SnapshotUpdated queues data for delivery if total risk changed since the
+// previous snapshot. It never blocks.
+func (d *Dispatcher) SnapshotUpdated(data []byte) {
+	d.mu.Lock()
+	prev := d.prev
+	d.prev = data
+	d.mu.Unlock()
+
+	if prev == nil {
+		return
+	}
+	prevRisk, err := totalRisk(prev)
+	if err != nil {
+		slog.Warn("webhook: failed to parse previous snapshot", "error", err)
+		return
+	}
+	curRisk, err := totalRisk(data)
+	if err != nil {
+		slog.Warn("webhook: failed to parse snapshot", "error", err)
+		return
+	}
+	if prevRisk == curRisk {
+		return
+	}
+	select {
+	case d.queue <- crossing{prev: prev, cur: data, prevRisk: prevRisk, curRisk: curRisk}:
+	default:
+		deliveries.WithLabelValues("dropped").Inc()
+		slog.Warn("webhook: queue full, dropping crossing", "previous", prevRisk, "current", curRisk)
+	}
+}
+
+// Start delivers queued crossings until Stop is called, then waits for
+// deliveries in flight to finish or give up.
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		d.wg.Wait()
+	}()
+	slog.Info("webhook dispatcher started")
+	for {
+		select {
+		case c := <-d.queue:
+			d.dispatch(ctx, c)
+		case <-d.stop:
+			slog.Info("webhook dispatcher stopped")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Stop signals the dispatcher to stop.
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+}
+
+// dispatch starts a delivery to every webhook c crosses.
+func (d *Dispatcher) dispatch(ctx context.Context, c crossing) {
+	hooks, err := d.st.Webhooks(ctx)
+	if err != nil {
+		slog.Error("webhook: failed to load webhooks", "error", err)
+		return
+	}
+	for _, h := range hooks {
+		if !Crossed(h, c.prevRisk, c.curRisk) {
+			continue
+		}
+		body, err := event(h, c, false)
+		if err != nil {
+			slog.Error("webhook: failed to build event", "webhook", h.ID, "error", err)
+			continue
+		}
+		d.wg.Add(1)
+		go func(h model.Webhook) {
+			defer d.wg.Done()
+			d.deliver(ctx, h, body)
+		}(h)
+	}
+}
+
+// event builds the body delivered to h for c.
+func event(h model.Webhook, c crossing, test bool) ([]byte, error) {
+	data, err := Payload(PayloadMode(h.PayloadMode), c.prev, c.cur)
+	if err != nil {
+		return nil, err
+	}
+	name := "threshold_crossed"
+	if test {
+		name = "test"
+	}
+	return json.Marshal(Event{
+		Event:     name,
+		WebhookID: h.ID,
+		Threshold: h.Threshold,
+		Direction: h.Direction,
+		Previous:  c.prevRisk,
+		Current:   c.curRisk,
+		Test:      test,
+		Data:      data,
+	})
+}
+
+// TestAlert sends the crossing from prev to cur to every webhook, whatever
+// its threshold, as a "test" event. Each is tried once so the results
+// reflect the receiver's first response.
+func (d *Dispatcher) TestAlert(ctx context.Context, prev, cur []byte) []model.AlertTestResult {
+	hooks, err := d.st.Webhooks(ctx)
+	if err != nil {
+		return []model.AlertTestResult{{Channel: "webhooks", Error: "loading webhooks: " + err.Error()}}
+	}
+	c := crossing{prev: prev, cur: cur}
+	if c.prevRisk, err = totalRisk(prev); err == nil {
+		c.curRisk, err = totalRisk(cur)
+	}
+	if err != nil {
+		return []model.AlertTestResult{{Channel: "webhooks", Error: "parsing snapshot: " + err.Error()}}
+	}
+
+	results := make([]model.AlertTestResult, len(hooks))
+	var wg sync.WaitGroup
+	for i, h := range hooks {
+		results[i].Channel = "webhook " + strconv.FormatInt(h.ID, 10)
+		body, err := event(h, c, true)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		wg.Add(1)
+		go func(r *model.AlertTestResult, h model.Webhook) {
+			defer wg.Done()
+			if _, err := d.post(ctx, h, body); err != nil {
+				r.Error = err.Error()
+				return
+			}
+			r.OK = true
+		}(&results[i], h)
+	}
+	wg.Wait()
+	return results
+}
+
+// deliver POSTs body to h, retrying failures that may be transient.
+func (d *Dispatcher) deliver(ctx context.Context, h model.Webhook, body []byte) {
+	backoff := retryBackoff
+	var err error
+attempts:
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var retry bool
+		retry, err = d.post(ctx, h, body)
+		if err == nil {
+			deliveries.WithLabelValues("delivered").Inc()
+			slog.Info("webhook delivered", "webhook", h.ID, "attempt", attempt)
+			return
+		}
+		if !retry || attempt == maxAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			break attempts
+		}
+	}
+	deliveries.WithLabelValues("failed").Inc()
+	slog.Warn("webhook delivery failed", "webhook", h.ID, "url", h.URL, "error", err)
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying. Client errors other than timeouts and rate limits are not.
+func (d *Dispatcher) post(ctx context.Context, h model.Webhook, body []byte) (retry bool, err error) {
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "aegis-webhook")
+	req.Header.Set("X-Aegis-Timestamp", ts)
+	req.Header.Set("X-Aegis-Signature", Sign(h.Secret, ts, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("status %d", resp.StatusCode)
+}
+
+// Sign returns the X-Aegis-Signature value for a delivery, which receivers
+// recompute with their secret to authenticate it.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Crossed reports whether total risk moving from prev to cur crosses h's
+// threshold in a direction it subscribes to. Rising crosses when cur
+// reaches the threshold from below; falling when cur drops below it.
+func Crossed(h model.Webhook, prev, cur int) bool {
+	up := prev < h.Threshold && cur >= h.Threshold
+	down := prev >= h.Threshold && cur < h.Threshold
+	switch h.Direction {
+	case DirectionUp:
+		return up
+	case DirectionDown:
+		return down
+	case DirectionBoth:
+		return up || down
+	}
+	return false
+}
+
+func totalRisk(data []byte) (int, error) {
+	var snap struct {
+		TotalRisk struct {
+			Risk int `json:"risk"`
+		} `json:"total_risk"`
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return 0, err
+	}
+	return snap.TotalRisk.Risk, nil
+}
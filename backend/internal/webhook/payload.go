@@ -0,0 +1,126 @@
+// Package webhook builds the bodies delivered to webhook subscribers.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/backyonatan-alt/aegis/backend/internal/model"
+	"github.com/backyonatan-alt/aegis/backend/internal/risk"
+)
+
+// PayloadMode selects what a subscriber receives for each run.
+type PayloadMode string
+
+const (
+	// PayloadFull sends the entire snapshot, as served by /api/data.
+	PayloadFull PayloadMode = "full"
+	// PayloadDiff sends only the total risk delta and the signals that
+	// changed since the previous snapshot.
+	PayloadDiff PayloadMode = "diff"
+)
+
+// ParsePayloadMode validates a subscriber's payload mode. Empty means full.
+func ParsePayloadMode(s string) (PayloadMode, error) {
+	switch PayloadMode(s) {
+	case "", PayloadFull:
+		return PayloadFull, nil
+	case PayloadDiff:
+		return PayloadDiff, nil
+	}
+	return "", fmt.Errorf("payload mode must be %q or %q", PayloadFull, PayloadDiff)
+}
+
+// Diff is the "diff" payload: how total risk moved and the previous and new
+// state of each signal whose risk, detail or elevated flag changed.
+type Diff struct {
+	LastUpdated string                `json:"last_updated"`
+	TotalRisk   RiskChange            `json:"total_risk"`
+	Signals     map[string]SignalDiff `json:"signals"`
+}
+
+// RiskChange is a total risk movement between two snapshots.
+type RiskChange struct {
+	Previous     int    `json:"previous"`
+	Current      int    `json:"current"`
+	Delta        int    `json:"delta"`
+	PreviousBand string `json:"previous_band"`
+	CurrentBand  string `json:"current_band"`
+}
+
+// SignalDiff is one changed signal. Previous is nil when the signal is new.
+type SignalDiff struct {
+	Previous *SignalState `json:"previous"`
+	Current  SignalState  `json:"current"`
+	Delta    int          `json:"delta"`
+}
+
+// SignalState is the scored part of a signal, without history or raw data.
+type SignalState struct {
+	Risk     int    `json:"risk"`
+	Detail   string `json:"detail"`
+	Elevated bool   `json:"elevated"`
+}
+
+// Payload builds the body for mode from the new snapshot and the one before
+// it. prev may be nil, in which case a diff lists every signal.
+func Payload(mode PayloadMode, prev, cur []byte) ([]byte, error) {
+	if mode != PayloadDiff {
+		return cur, nil
+	}
+	d, err := BuildDiff(prev, cur)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(d)
+}
+
+// BuildDiff compares two serialized snapshots.
+func BuildDiff(prev, cur []byte) (Diff, error) {
+	var c model.Snapshot
+	if err := json.Unmarshal(cur, &c); err != nil {
+		return Diff{}, fmt.Errorf("parse current snapshot: %w", err)
+	}
+	var p *model.Snapshot
+	if prev != nil {
+		p = new(model.Snapshot)
+		if err := json.Unmarshal(prev, p); err != nil {
+			return Diff{}, fmt.Errorf("parse previous snapshot: %w", err)
+		}
+	}
+
+	d := Diff{
+		LastUpdated: c.LastUpdated,
+		TotalRisk: RiskChange{
+			Current:     c.TotalRisk.Risk,
+			CurrentBand: risk.Band(c.TotalRisk.Risk),
+		},
+		Signals: map[string]SignalDiff{},
+	}
+	var prevSignals map[string]*model.Signal
+	if p != nil {
+		d.TotalRisk.Previous = p.TotalRisk.Risk
+		d.TotalRisk.PreviousBand = risk.Band(p.TotalRisk.Risk)
+		prevSignals = p.Signals
+	} else {
+		d.TotalRisk.PreviousBand = d.TotalRisk.CurrentBand
+	}
+	d.TotalRisk.Delta = d.TotalRisk.Current - d.TotalRisk.Previous
+
+	for name, sig := range c.Signals {
+		cs := state(sig)
+		ps, ok := prevSignals[name]
+		if !ok {
+			d.Signals[name] = SignalDiff{Current: cs, Delta: cs.Risk}
+			continue
+		}
+		if before := state(ps); before != cs {
+			d.Signals[name] = SignalDiff{Previous: &before, Current: cs, Delta: cs.Risk - before.Risk}
+		}
+	}
+	return d, nil
+}
+
+func state(s *model.Signal) SignalState {
+	return SignalState{Risk: s.Risk, Detail: s.Detail, Elevated: s.Elevated}
+}
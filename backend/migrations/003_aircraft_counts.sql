@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS aircraft_counts (
+    id             BIGSERIAL PRIMARY KEY,
+    aircraft_count INTEGER NOT NULL,
+    observed_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_aircraft_counts_observed_at ON aircraft_counts (observed_at DESC);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS tanker_counts (
+    id           BIGSERIAL PRIMARY KEY,
+    tanker_count INTEGER NOT NULL,
+    observed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_tanker_counts_observed_at ON tanker_counts (observed_at DESC);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS signal_scores (
+    id          BIGSERIAL PRIMARY KEY,
+    run_id      TEXT NOT NULL,
+    signal      TEXT NOT NULL,
+    risk        INTEGER NOT NULL,
+    detail      TEXT NOT NULL DEFAULT '',
+    elevated    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_signal_scores_signal_created_at ON signal_scores (signal, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_signal_scores_run_id ON signal_scores (run_id);
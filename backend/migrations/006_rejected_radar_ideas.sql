@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS rejected_radar_ideas (
+    id           BIGSERIAL PRIMARY KEY,
+    idea         TEXT NOT NULL,
+    country_code VARCHAR(10) NOT NULL DEFAULT 'XX',
+    reason       TEXT NOT NULL,
+    language     TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_rejected_radar_ideas_created_at ON rejected_radar_ideas (created_at DESC);
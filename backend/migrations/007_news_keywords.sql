@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS news_keywords (
+    id          BIGSERIAL PRIMARY KEY,
+    keywords    JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_news_keywords_created_at ON news_keywords (created_at DESC);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS annotations (
+    id          BIGSERIAL PRIMARY KEY,
+    body        TEXT NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_annotations_occurred_at ON annotations (occurred_at DESC);
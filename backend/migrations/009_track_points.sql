@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS track_points (
+    id          BIGSERIAL PRIMARY KEY,
+    kind        TEXT NOT NULL,
+    icao        TEXT NOT NULL,
+    callsign    TEXT NOT NULL DEFAULT '',
+    lat         DOUBLE PRECISION NOT NULL,
+    lon         DOUBLE PRECISION NOT NULL,
+    altitude    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    heading     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    observed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_track_points_observed_at ON track_points (observed_at);
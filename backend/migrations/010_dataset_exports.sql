@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS dataset_exports (
+    day          DATE PRIMARY KEY,
+    json_key     TEXT NOT NULL,
+    csv_key      TEXT NOT NULL,
+    hours        INTEGER NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
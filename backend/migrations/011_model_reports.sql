@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS model_reports (
+    id          BIGSERIAL PRIMARY KEY,
+    report      JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_model_reports_created_at ON model_reports (created_at DESC);
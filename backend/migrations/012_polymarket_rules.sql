@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS polymarket_rules (
+    id          BIGSERIAL PRIMARY KEY,
+    rules       JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_polymarket_rules_created_at ON polymarket_rules (created_at DESC);
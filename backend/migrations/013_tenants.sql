@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id          BIGSERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    key_hash    TEXT NOT NULL UNIQUE,
+    rate_limit  INTEGER NOT NULL DEFAULT 0,
+    disabled    BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE TABLE IF NOT EXISTS tenant_usage (
+    tenant_id   BIGINT NOT NULL REFERENCES tenants (id) ON DELETE CASCADE,
+    day         DATE NOT NULL,
+    requests    BIGINT NOT NULL DEFAULT 0,
+    bytes       BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, day)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS feed_checks (
+    id          BIGSERIAL PRIMARY KEY,
+    feed_url    TEXT NOT NULL,
+    fetched     BOOLEAN NOT NULL,
+    parsed      BOOLEAN NOT NULL,
+    items       INTEGER NOT NULL DEFAULT 0,
+    matched     INTEGER NOT NULL DEFAULT 0,
+    error       TEXT NOT NULL DEFAULT '',
+    checked_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_feed_checks_url_checked_at ON feed_checks (feed_url, checked_at DESC);
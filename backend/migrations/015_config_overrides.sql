@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS config_overrides (
+    key         TEXT PRIMARY KEY,
+    value       TEXT NOT NULL,
+    updated_by  TEXT NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
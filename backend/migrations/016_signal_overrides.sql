@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS signal_overrides (
+    signal      TEXT PRIMARY KEY,
+    risk        INTEGER NOT NULL,
+    reason      TEXT NOT NULL,
+    created_by  TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at  TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS incidents (
+    id          BIGSERIAL PRIMARY KEY,
+    run_id      TEXT NOT NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    total_risk  INTEGER NOT NULL,
+    signals     JSONB NOT NULL,
+    headlines   JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_incidents_detected_at ON incidents (detected_at DESC);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+    id           BIGSERIAL PRIMARY KEY,
+    url          TEXT NOT NULL,
+    threshold    INTEGER NOT NULL,
+    direction    TEXT NOT NULL,
+    payload_mode TEXT NOT NULL DEFAULT 'full',
+    secret       TEXT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS pulse_baselines (
+    id          BIGSERIAL PRIMARY KEY,
+    baselines   JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_pulse_baselines_created_at ON pulse_baselines (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS pulse_windows (
+    ended_at    TIMESTAMPTZ PRIMARY KEY,
+    counts      JSONB NOT NULL
+);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS vessel_counts (
+    id           BIGSERIAL PRIMARY KEY,
+    vessel_count INTEGER NOT NULL,
+    tanker_count INTEGER NOT NULL,
+    observed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_vessel_counts_observed_at ON vessel_counts (observed_at DESC);
@@ -26,6 +26,24 @@ function initChart(historyData = null) {
                     pointBackgroundColor: '#f97316',
                     pointBorderColor: '#fff',
                     pointBorderWidth: 2
+                }, {
+                    // Uncertainty band: upper edge, filled down to the lower edge
+                    label: 'Uncertainty',
+                    data: state.trendHigh,
+                    borderWidth: 0,
+                    backgroundColor: 'rgba(249, 115, 22, 0.18)',
+                    fill: '+1',
+                    tension: 0.4,
+                    pointRadius: 0,
+                    pointHoverRadius: 0
+                }, {
+                    label: 'Uncertainty low',
+                    data: state.trendLow,
+                    borderWidth: 0,
+                    fill: false,
+                    tension: 0.4,
+                    pointRadius: 0,
+                    pointHoverRadius: 0
                 }]
             },
             options: {
@@ -42,8 +60,12 @@ function initChart(historyData = null) {
                         padding: 12,
                         titleFont: { size: 13 },
                         bodyFont: { size: 14 },
+                        filter: (item) => item.datasetIndex === 0,
                         callbacks: {
-                            label: (context) => `Risk: ${context.parsed.y}%`
+                            label: (context) => {
+                                const u = state.trendUncertainty[context.dataIndex] || 0;
+                                return u > 0 ? `Risk: ${context.parsed.y}% ±${u}` : `Risk: ${context.parsed.y}%`;
+                            }
                         }
                     }
                 },
@@ -89,12 +111,15 @@ function updateChartData(history) {
 
     state.trendLabels = [];
     state.trendData = [];
+    state.trendUncertainty = [];
+    state.trendHigh = [];
+    state.trendLow = [];
 
     // Only use real data from history array
     if (!history || history.length === 0) {
         console.log('No history data available for chart');
         chart.data.labels = [];
-        chart.data.datasets[0].data = [];
+        chart.data.datasets.forEach(ds => { ds.data = []; });
         chart.update('none');
         return;
     }
@@ -123,10 +148,17 @@ function updateChartData(history) {
 
         state.trendLabels.push(label);
         state.trendData.push(point.risk);
+
+        const u = point.uncertainty || 0;
+        state.trendUncertainty.push(u);
+        state.trendHigh.push(Math.min(100, point.risk + u));
+        state.trendLow.push(Math.max(0, point.risk - u));
     });
 
     // Update chart
     chart.data.labels = state.trendLabels;
     chart.data.datasets[0].data = state.trendData;
+    chart.data.datasets[1].data = state.trendHigh;
+    chart.data.datasets[2].data = state.trendLow;
     chart.update('none');
 }
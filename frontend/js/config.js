@@ -5,6 +5,9 @@
 const state = {
     trendLabels: [],
     trendData: [],
+    trendUncertainty: [],
+    trendHigh: [],
+    trendLow: [],
     signalHistory: {
         news: [],
         connectivity: [],
@@ -4,15 +4,21 @@
 
 const DATA_URL = 'https://api.usstrikeradar.com/api/data';
 const PULSE_URL = 'https://api.usstrikeradar.com/api/pulse';
+// Static copy of the latest snapshot (MIRROR_PUBLIC_URL + MIRROR_PREFIX +
+// 'data.json'), read when the API can't be reached. Empty disables it.
+const MIRROR_DATA_URL = '';
 
 async function getData() {
-    try {
-        const res = await fetch(DATA_URL);
-        if (res.ok) {
-            return await res.json();
+    for (const url of [DATA_URL, MIRROR_DATA_URL]) {
+        if (!url) continue;
+        try {
+            const res = await fetch(url);
+            if (res.ok) {
+                return await res.json();
+            }
+        } catch (e) {
+            console.log('Error reading data from', url, e.message);
         }
-    } catch (e) {
-        console.log('Error reading data:', e.message);
     }
     
     // Fallback if data.json can't be read - use new structure
@@ -22,6 +22,12 @@ function displayData(data) {
         }
     });
 
+    // Mark signals the backend served from an earlier run's data. Runs
+    // first so the per-signal freshness checks below can still flag theirs.
+    ['news', 'connectivity', 'flight', 'tanker', 'pentagon', 'polymarket', 'weather'].forEach(sig => {
+        if (data[sig]) setStale(`${sig}Status`, data[sig].is_stale === true, data[sig].stale_since);
+    });
+
     // Display all signals using pre-calculated values from restructured data
     if (data.news) {
         updateSignal('news', data.news.risk, data.news.detail);
@@ -79,6 +85,14 @@ function displayData(data) {
     const total = data.total_risk?.risk || 0;
 
     updateGauge(total);
+
+    // Too many signals failing: the total was scored without them
+    const degradedEl = document.getElementById('gaugeDegraded');
+    if (degradedEl) {
+        const excluded = data.total_risk?.excluded_signals || [];
+        degradedEl.hidden = !data.total_risk?.degraded;
+        degradedEl.textContent = `Degraded: scored without ${excluded.join(', ')}`;
+    }
     updateTimestamp(data.last_updated ? parseUtcTimestamp(data.last_updated) : Date.now());
 
     // Display pulse data (Global Anxiety Pulse)
@@ -16,6 +16,24 @@ function setStatus(id, live) {
     if (el) el.textContent = live ? 'LIVE' : 'STALE';
 }
 
+// Show a signal as degraded when its last fetch failed, or clear a mark
+// left by an earlier refresh.
+function setStale(id, stale, since) {
+    const el = document.getElementById(id);
+    if (!el) return;
+    el.classList.toggle('weak', stale);
+    el.classList.toggle('live', !stale);
+    if (stale) {
+        el.textContent = 'STALE';
+        el.dataset.stale = 'true';
+        el.title = since ? `Fetch failing since ${new Date(since).toLocaleString()}` : 'Last fetch failed';
+    } else if (el.dataset.stale) {
+        el.textContent = 'LIVE';
+        delete el.dataset.stale;
+        el.title = '';
+    }
+}
+
 function showToast(message) {
     const toast = document.createElement('div');
     toast.className = 'toast';